@@ -1,20 +1,35 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 )
 
+// Agent查询的基础 SQL
+const agentSelectSQL = `
+	SELECT id, name, description, COALESCE(type, 'executor'), prompt, provider_id, model,
+	       COALESCE(tools, '[]'), COALESCE(working_dir, ''), COALESCE(max_retries, 3),
+	       COALESCE(fallbacks, '[]'), COALESCE(task_access, 'none'), enabled, created_at
+	FROM agents
+`
+
+// scanAgent 扫描Agent
+func scanAgent(row interface{ Scan(...any) error }) (Agent, error) {
+	var agent Agent
+	err := row.Scan(&agent.ID, &agent.Name, &agent.Description, &agent.Type, &agent.Prompt,
+		&agent.ProviderID, &agent.Model, &agent.Tools, &agent.WorkingDir, &agent.MaxRetries,
+		&agent.Fallbacks, &agent.TaskAccess, &agent.Enabled, &agent.CreatedAt)
+	return agent, err
+}
+
 // GetAgents 获取所有Agent
 func (a *App) GetAgents() ([]Agent, error) {
 	if db == nil {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	rows, err := db.Query(`
-		SELECT id, name, description, COALESCE(type, 'executor'), prompt, provider_id, model,
-		       COALESCE(tools, '[]'), COALESCE(working_dir, ''), COALESCE(max_retries, 3), enabled, created_at
-		FROM agents
+	rows, err := db.Query(agentSelectSQL + `
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -25,10 +40,8 @@ func (a *App) GetAgents() ([]Agent, error) {
 
 	var agents []Agent
 	for rows.Next() {
-		var agent Agent
-		if err := rows.Scan(&agent.ID, &agent.Name, &agent.Description, &agent.Type, &agent.Prompt,
-			&agent.ProviderID, &agent.Model, &agent.Tools, &agent.WorkingDir, &agent.MaxRetries,
-			&agent.Enabled, &agent.CreatedAt); err != nil {
+		agent, err := scanAgent(rows)
+		if err != nil {
 			log.Printf("扫描Agent失败: %v", err)
 			return nil, fmt.Errorf("扫描Agent失败: %v", err)
 		}
@@ -44,14 +57,7 @@ func (a *App) GetAgent(id int64) (*Agent, error) {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	var agent Agent
-	err := db.QueryRow(`
-		SELECT id, name, description, COALESCE(type, 'executor'), prompt, provider_id, model,
-		       COALESCE(tools, '[]'), COALESCE(working_dir, ''), COALESCE(max_retries, 3), enabled, created_at
-		FROM agents WHERE id = ?
-	`, id).Scan(&agent.ID, &agent.Name, &agent.Description, &agent.Type, &agent.Prompt,
-		&agent.ProviderID, &agent.Model, &agent.Tools, &agent.WorkingDir, &agent.MaxRetries,
-		&agent.Enabled, &agent.CreatedAt)
+	agent, err := scanAgent(db.QueryRow(agentSelectSQL+`WHERE id = ?`, id))
 	if err != nil {
 		log.Printf("查询Agent失败: %v", err)
 		return nil, fmt.Errorf("查询Agent失败: %v", err)
@@ -73,7 +79,10 @@ func (a *App) CreateAgent(input AgentInput) (*Agent, error) {
 	// 默认值
 	agentType := input.Type
 	if agentType == "" {
-		agentType = "executor"
+		agentType = AgentTypeExecutor
+	}
+	if agentType != AgentTypePlanner && agentType != AgentTypeExecutor {
+		return nil, fmt.Errorf("不支持的Agent类型: %s", agentType)
 	}
 	tools := input.Tools
 	if tools == "" {
@@ -83,12 +92,20 @@ func (a *App) CreateAgent(input AgentInput) (*Agent, error) {
 	if maxRetries == 0 {
 		maxRetries = 3
 	}
+	fallbacks, err := normalizeModelFallbacks(input.Fallbacks)
+	if err != nil {
+		return nil, err
+	}
+	taskAccess, err := normalizeTaskAccess(input.TaskAccess)
+	if err != nil {
+		return nil, err
+	}
 
 	result, err := db.Exec(`
-		INSERT INTO agents (name, description, type, prompt, provider_id, model, tools, working_dir, max_retries, enabled)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO agents (name, description, type, prompt, provider_id, model, tools, working_dir, max_retries, fallbacks, task_access, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, input.Name, input.Description, agentType, input.Prompt, input.ProviderID, input.Model,
-		tools, input.WorkingDir, maxRetries, input.Enabled)
+		tools, input.WorkingDir, maxRetries, fallbacks, taskAccess, input.Enabled)
 	if err != nil {
 		log.Printf("创建Agent失败: %v", err)
 		return nil, fmt.Errorf("创建Agent失败: %v", err)
@@ -116,20 +133,31 @@ func (a *App) UpdateAgent(input AgentInput) error {
 	// 默认值
 	agentType := input.Type
 	if agentType == "" {
-		agentType = "executor"
+		agentType = AgentTypeExecutor
+	}
+	if agentType != AgentTypePlanner && agentType != AgentTypeExecutor {
+		return fmt.Errorf("不支持的Agent类型: %s", agentType)
 	}
 	tools := input.Tools
 	if tools == "" {
 		tools = "[]"
 	}
+	fallbacks, err := normalizeModelFallbacks(input.Fallbacks)
+	if err != nil {
+		return err
+	}
+	taskAccess, err := normalizeTaskAccess(input.TaskAccess)
+	if err != nil {
+		return err
+	}
 
-	_, err := db.Exec(`
+	_, err = db.Exec(`
 		UPDATE agents
 		SET name = ?, description = ?, type = ?, prompt = ?, provider_id = ?, model = ?,
-		    tools = ?, working_dir = ?, max_retries = ?, enabled = ?
+		    tools = ?, working_dir = ?, max_retries = ?, fallbacks = ?, task_access = ?, enabled = ?
 		WHERE id = ?
 	`, input.Name, input.Description, agentType, input.Prompt, input.ProviderID, input.Model,
-		tools, input.WorkingDir, input.MaxRetries, input.Enabled, input.ID)
+		tools, input.WorkingDir, input.MaxRetries, fallbacks, taskAccess, input.Enabled, input.ID)
 	if err != nil {
 		log.Printf("更新Agent失败: %v", err)
 		return fmt.Errorf("更新Agent失败: %v", err)
@@ -161,10 +189,7 @@ func (a *App) GetEnabledAgents() ([]Agent, error) {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	rows, err := db.Query(`
-		SELECT id, name, description, COALESCE(type, 'executor'), prompt, provider_id, model,
-		       COALESCE(tools, '[]'), COALESCE(working_dir, ''), COALESCE(max_retries, 3), enabled, created_at
-		FROM agents
+	rows, err := db.Query(agentSelectSQL + `
 		WHERE enabled = 1
 		ORDER BY created_at DESC
 	`)
@@ -176,10 +201,8 @@ func (a *App) GetEnabledAgents() ([]Agent, error) {
 
 	var agents []Agent
 	for rows.Next() {
-		var agent Agent
-		if err := rows.Scan(&agent.ID, &agent.Name, &agent.Description, &agent.Type, &agent.Prompt,
-			&agent.ProviderID, &agent.Model, &agent.Tools, &agent.WorkingDir, &agent.MaxRetries,
-			&agent.Enabled, &agent.CreatedAt); err != nil {
+		agent, err := scanAgent(rows)
+		if err != nil {
 			log.Printf("扫描Agent失败: %v", err)
 			return nil, fmt.Errorf("扫描Agent失败: %v", err)
 		}
@@ -188,3 +211,47 @@ func (a *App) GetEnabledAgents() ([]Agent, error) {
 
 	return agents, nil
 }
+
+// parseModelFallbacks 解析备用模型链（格式错误时返回空）
+func parseModelFallbacks(raw string) []ModelFallback {
+	var fallbacks []ModelFallback
+	if raw == "" || raw == "[]" {
+		return fallbacks
+	}
+	if err := json.Unmarshal([]byte(raw), &fallbacks); err != nil {
+		log.Printf("解析备用模型失败: %v", err)
+		return nil
+	}
+	return fallbacks
+}
+
+// normalizeModelFallbacks 校验并规范化备用模型链 JSON
+func normalizeModelFallbacks(raw string) (string, error) {
+	if raw == "" {
+		return "[]", nil
+	}
+
+	var fallbacks []ModelFallback
+	if err := json.Unmarshal([]byte(raw), &fallbacks); err != nil {
+		return "", fmt.Errorf("备用模型格式错误: %v", err)
+	}
+	for i, fb := range fallbacks {
+		if fb.ProviderID <= 0 {
+			return "", fmt.Errorf("第 %d 个备用模型未指定模型提供商", i+1)
+		}
+	}
+
+	data, _ := json.Marshal(fallbacks)
+	return string(data), nil
+}
+
+// normalizeTaskAccess 校验任务管理权限
+func normalizeTaskAccess(access string) (string, error) {
+	switch access {
+	case "":
+		return TaskAccessNone, nil
+	case TaskAccessNone, TaskAccessRead, TaskAccessWrite:
+		return access, nil
+	}
+	return "", fmt.Errorf("无效的任务管理权限: %s", access)
+}
@@ -3,11 +3,11 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
 )
@@ -46,12 +46,13 @@ type AgentAction struct {
 
 // ReActExecutor ReAct模式执行器
 type ReActExecutor struct {
-	app            *App
-	conversationID int64
-	agent          *Agent
-	provider       *ModelProvider
-	toolExecutor   *ToolExecutor
-	maxSteps       int // 最大步骤数，防止无限循环
+	app               *App
+	conversationID    int64
+	agent             *Agent
+	provider          *ModelProvider
+	toolExecutor      *ToolExecutor
+	maxSteps          int // 最大步骤数，防止无限循环
+	maxRepairAttempts int // 响应格式错误时最多自动修正的次数
 }
 
 // NewReActExecutor 创建ReAct执行器
@@ -61,13 +62,19 @@ func NewReActExecutor(app *App, conversationID int64, agent *Agent, provider *Mo
 		workingDir = "."
 	}
 
+	toolExecutor := NewToolExecutor(workingDir)
+	if taskID, err := getConversationTaskID(conversationID); err == nil {
+		toolExecutor.SetTaskContext(app, conversationID, taskID, agent.TaskAccess)
+	}
+
 	return &ReActExecutor{
-		app:            app,
-		conversationID: conversationID,
-		agent:          agent,
-		provider:       provider,
-		toolExecutor:   NewToolExecutor(workingDir),
-		maxSteps:       20, // 默认最多20步
+		app:               app,
+		conversationID:    conversationID,
+		agent:             agent,
+		provider:          provider,
+		toolExecutor:      toolExecutor,
+		maxSteps:          20, // 默认最多20步
+		maxRepairAttempts: 3,
 	}
 }
 
@@ -75,8 +82,17 @@ func NewReActExecutor(app *App, conversationID int64, agent *Agent, provider *Mo
 func (r *ReActExecutor) Run() {
 	log.Printf("开始ReAct执行: conversationID=%d, agent=%s", r.conversationID, r.agent.Name)
 
+	// 会话用到的 MCP 服务器在首次执行时启动，会话结束时关闭
+	r.attachMCPServers()
+	defer releaseConversationMCP(r.conversationID)
+
 	stepNum := 0
+	repairAttempts := 0
 	for stepNum < r.maxSteps {
+		if conversationStopped(r.conversationID) {
+			log.Printf("会话已停止: conversationID=%d", r.conversationID)
+			return
+		}
 		stepNum++
 		log.Printf("执行步骤 %d", stepNum)
 
@@ -88,21 +104,32 @@ func (r *ReActExecutor) Run() {
 		}
 
 		// 2. 调用LLM
-		response, err := r.callLLM(prompt)
+		response, modelUsed, err := r.callLLM(prompt)
 		if err != nil {
 			r.handleError(fmt.Sprintf("调用LLM失败: %v", err))
 			return
 		}
 
-		// 3. 解析响应
+		// 3. 解析并校验响应，格式错误时让模型自行修正
 		action, err := r.parseResponse(response)
+		if err == nil {
+			err = r.validateAction(action)
+		}
 		if err != nil {
-			// 解析失败，可能是格式问题，保存原始响应并等待用户
-			log.Printf("解析响应失败: %v, 原始响应: %s", err, response)
-			r.app.saveMessage(r.conversationID, "assistant", response, MessageTypeText, "{}")
-			r.app.updateConversationStatus(r.conversationID, ConversationStatusWaitingUser)
-			return
+			repairAttempts++
+			log.Printf("解析响应失败 (第%d次): %v, 原始响应: %s", repairAttempts, err, response)
+			r.app.saveMessage(r.conversationID, "assistant", response, MessageTypeText,
+				fmt.Sprintf(`{"step_num":%d,"invalid":true}`, stepNum))
+			if repairAttempts > r.maxRepairAttempts {
+				// 多次修正仍失败，等待用户介入
+				r.app.updateConversationStatus(r.conversationID, ConversationStatusWaitingUser)
+				return
+			}
+			r.app.saveMessage(r.conversationID, "system", buildRepairMessage(err), MessageTypeError,
+				fmt.Sprintf(`{"step_num":%d,"repair_attempt":%d}`, stepNum, repairAttempts))
+			continue
 		}
+		repairAttempts = 0
 
 		// 4. 保存步骤记录
 		step := &AgentStep{
@@ -112,6 +139,7 @@ func (r *ReActExecutor) Run() {
 			Action:         action.Action,
 			ActionInput:    string(action.ActionInput),
 			Status:         StepStatusRunning,
+			Model:          modelUsed,
 		}
 		stepID, err := r.saveStep(step)
 		if err != nil {
@@ -125,7 +153,7 @@ func (r *ReActExecutor) Run() {
 
 		// 5. 检查是否完成
 		if action.Action == ToolComplete {
-			var input ToolInput
+			var input CompleteInput
 			json.Unmarshal(action.ActionInput, &input)
 			r.updateStepStatus(step.ID, StepStatusSuccess, input.Summary, "")
 			r.app.saveMessage(r.conversationID, "assistant", input.Summary, MessageTypeResult, "{}")
@@ -136,7 +164,7 @@ func (r *ReActExecutor) Run() {
 
 		// 6. 检查是否需要询问用户
 		if action.Action == ToolAskUser {
-			var input ToolInput
+			var input AskUserInput
 			json.Unmarshal(action.ActionInput, &input)
 			metadata := "{}"
 			if len(input.Options) > 0 {
@@ -152,8 +180,26 @@ func (r *ReActExecutor) Run() {
 			return
 		}
 
-		// 7. 执行工具
-		result := r.toolExecutor.Execute(action.Action, string(action.ActionInput))
+		// 7. 执行工具（planner 提交计划后等待用户审核）
+		var result ToolResult
+		if action.Action == ToolSubmitPlan {
+			var input SubmitPlanInput
+			json.Unmarshal(action.ActionInput, &input)
+			plan, err := r.app.createTaskPlan(r.conversationID, input)
+			if err == nil {
+				r.updateStepStatus(step.ID, StepStatusSuccess, fmt.Sprintf("计划已提交: ID=%d", plan.ID), "")
+				r.app.saveMessage(r.conversationID, "assistant", renderPlan(plan), MessageTypePlan,
+					fmt.Sprintf(`{"step_num":%d,"plan_id":%d}`, stepNum, plan.ID))
+				r.app.updateConversationStatus(r.conversationID, ConversationStatusWaitingUser)
+				log.Printf("计划已提交，等待审核: planID=%d", plan.ID)
+				return
+			}
+			result = ToolResult{Success: false, Error: err.Error()}
+		} else if action.Action == ToolDelegate {
+			result = r.executeDelegate(step.ID, action.ActionInput)
+		} else {
+			result = r.toolExecutor.Execute(action.Action, string(action.ActionInput))
+		}
 
 		// 8. 更新步骤状态
 		if result.Success {
@@ -224,7 +270,16 @@ func (r *ReActExecutor) buildSystemPrompt() string {
 	var sb strings.Builder
 
 	// 基本角色
-	sb.WriteString("你是一个任务执行Agent，能够自主完成软件工程任务。\n\n")
+	if r.agent.Type == AgentTypePlanner {
+		sb.WriteString("你是一个任务规划Agent，负责将任务拆解为可执行的子任务计划。\n\n")
+		sb.WriteString("## 规划要求\n")
+		sb.WriteString("- 按执行顺序列出子任务，每个子任务应能在一天内完成，并给出预计工时\n")
+		sb.WriteString("- 为每个子任务评估重要程度(priority)和紧急程度(urgency)\n")
+		sb.WriteString("- 用 depends_on 标注依赖的前置子任务序号\n")
+		sb.WriteString("- 信息不足时先用工具了解情况或询问用户，规划完成后调用 submit_plan 提交计划\n\n")
+	} else {
+		sb.WriteString("你是一个任务执行Agent，能够自主完成软件工程任务。\n\n")
+	}
 
 	// Agent自定义提示词
 	if r.agent.Prompt != "" {
@@ -234,65 +289,213 @@ func (r *ReActExecutor) buildSystemPrompt() string {
 	}
 
 	// 可用工具
+	sb.WriteString(r.toolExecutor.registry.BuildToolsPrompt(r.availableToolNames()))
+
+	return sb.String()
+}
+
+// agentToolNames 解析Agent配置的工具列表
+func (r *ReActExecutor) agentToolNames() []string {
 	var toolNames []string
 	if r.agent.Tools != "" && r.agent.Tools != "[]" {
 		json.Unmarshal([]byte(r.agent.Tools), &toolNames)
 	}
+	return toolNames
+}
+
+// attachMCPServers 连接Agent工具列表中引用的 MCP 服务器（同一会话复用已有连接）
+func (r *ReActExecutor) attachMCPServers() {
+	names := mcpServersForTools(r.agentToolNames())
+	if len(names) == 0 {
+		return
+	}
+	servers, err := getEnabledMCPServersByName(names)
+	if err != nil {
+		log.Printf("获取MCP服务器失败: %v", err)
+		return
+	}
+	r.toolExecutor.AttachMCPClients(conversationMCPClients(r.conversationID, servers))
+}
+
+// availableToolNames 获取Agent可用的工具名称列表
+func (r *ReActExecutor) availableToolNames() []string {
+	toolNames := expandMCPToolNames(r.agentToolNames(), r.toolExecutor.registry)
 	if len(toolNames) == 0 {
 		// 默认工具
-		toolNames = []string{ToolShell, ToolReadFile, ToolWriteFile, ToolListFiles, ToolAskUser, ToolComplete}
+		if r.agent.Type == AgentTypePlanner {
+			toolNames = []string{ToolReadFile, ToolListFiles, ToolListProjectTasks}
+		} else {
+			toolNames = []string{ToolShell, ToolReadFile, ToolWriteFile, ToolListFiles, ToolAskUser, ToolComplete}
+		}
 	}
-	tools := r.toolExecutor.registry.GetTools(toolNames)
-	sb.WriteString(BuildToolsPrompt(tools))
 
-	return sb.String()
+	// 去掉Agent权限不允许的任务管理工具，submit_plan 只由 planner 使用
+	allowed := toolNames[:0:0]
+	for _, name := range toolNames {
+		if taskAccessAllows(r.agent.TaskAccess, name) && name != ToolSubmitPlan {
+			allowed = append(allowed, name)
+		}
+	}
+	toolNames = allowed
+
+	// ask_user 和 complete 是执行循环的控制工具，始终可用
+	controls := []string{ToolAskUser, ToolComplete}
+	if r.agent.Type == AgentTypePlanner {
+		controls = append(controls, ToolSubmitPlan)
+	}
+	for _, control := range controls {
+		found := false
+		for _, name := range toolNames {
+			if name == control {
+				found = true
+				break
+			}
+		}
+		if !found {
+			toolNames = append(toolNames, control)
+		}
+	}
+	return toolNames
 }
 
 // parseResponse 解析LLM响应，提取动作
 func (r *ReActExecutor) parseResponse(response string) (*AgentAction, error) {
-	response = strings.TrimSpace(response)
+	return parseAgentResponse(response)
+}
 
-	// 尝试直接解析JSON
-	var action AgentAction
-	if err := json.Unmarshal([]byte(response), &action); err == nil {
-		if action.Action != "" {
-			return &action, nil
+// validateAction 校验动作：工具必须可用，参数必须符合工具的 JSON Schema
+func (r *ReActExecutor) validateAction(action *AgentAction) error {
+	names := r.availableToolNames()
+	allowed := false
+	for _, name := range names {
+		if name == action.Action {
+			allowed = true
+			break
 		}
 	}
+	if _, ok := r.toolExecutor.registry.GetTool(action.Action); !allowed || !ok {
+		return fmt.Errorf("工具 %s 不存在或不可用，可用工具: %s", action.Action, strings.Join(names, ", "))
+	}
 
-	// 尝试从Markdown代码块中提取JSON
-	jsonPattern := regexp.MustCompile("(?s)```(?:json)?\\s*\\n?(.+?)\\n?```")
-	if matches := jsonPattern.FindStringSubmatch(response); len(matches) > 1 {
-		jsonStr := strings.TrimSpace(matches[1])
-		if err := json.Unmarshal([]byte(jsonStr), &action); err == nil {
-			if action.Action != "" {
-				return &action, nil
-			}
-		}
+	return r.toolExecutor.registry.Validate(action.Action, action.ActionInput)
+}
+
+// buildRepairMessage 构建让模型修正输出格式的消息
+func buildRepairMessage(err error) string {
+	return fmt.Sprintf(`[格式错误] 你上一次的输出无法执行: %v
+
+请只输出一个 JSON 对象，不要包含其他文字，格式如下:
+{"thought": "你的思考过程", "action": "工具名称", "action_input": {工具参数}}`, err)
+}
+
+// llmCallError LLM调用错误（带HTTP状态码，用于故障转移判断）
+type llmCallError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *llmCallError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *llmCallError) Unwrap() error {
+	return e.Err
+}
+
+// errorType 错误分类
+func (e *llmCallError) errorType() string {
+	if e.StatusCode > 0 {
+		return classifyProviderError(e.StatusCode, nil)
 	}
+	return classifyProviderError(0, e.Err)
+}
 
-	// 尝试找到JSON对象（{...}）
-	jsonObjPattern := regexp.MustCompile(`(?s)\{[^{}]*"action"[^{}]*\}`)
-	if matches := jsonObjPattern.FindString(response); matches != "" {
-		if err := json.Unmarshal([]byte(matches), &action); err == nil {
-			if action.Action != "" {
-				return &action, nil
-			}
+// shouldFailover 是否属于需要切换到备用模型的故障类错误（网络、服务端、限流）
+func (e *llmCallError) shouldFailover() bool {
+	switch e.errorType() {
+	case ProviderErrorNetwork, ProviderErrorServer, ProviderErrorQuota:
+		return true
+	}
+	return false
+}
+
+// llmTarget 一次LLM调用的目标（提供商 + 模型）
+type llmTarget struct {
+	provider *ModelProvider
+	model    string
+}
+
+// label 用于记录的模型标识
+func (t llmTarget) label() string {
+	return t.provider.Name + "/" + t.model
+}
+
+// buildLLMTargets 构建模型调用链：主模型 + Agent配置的备用模型
+func (r *ReActExecutor) buildLLMTargets() ([]llmTarget, error) {
+	var targets []llmTarget
+
+	model, err := resolveModel(r.provider, r.agent.Model)
+	if err != nil {
+		return nil, err
+	}
+	targets = append(targets, llmTarget{provider: r.provider, model: model})
+
+	for _, fb := range parseModelFallbacks(r.agent.Fallbacks) {
+		provider, err := r.app.GetModelProvider(fb.ProviderID)
+		if err != nil {
+			log.Printf("备用模型提供商不存在: ID=%d", fb.ProviderID)
+			continue
 		}
+		if !provider.Enabled || provider.APIKey == "" {
+			log.Printf("备用模型提供商未启用或未配置API Key: %s", provider.Label)
+			continue
+		}
+		fbModel, err := resolveModel(provider, fb.Model)
+		if err != nil {
+			log.Printf("备用模型配置无效: %v", err)
+			continue
+		}
+		targets = append(targets, llmTarget{provider: provider, model: fbModel})
 	}
 
-	return nil, fmt.Errorf("无法从响应中解析动作JSON")
+	return targets, nil
 }
 
-// callLLM 调用LLM API
-func (r *ReActExecutor) callLLM(messages []ChatMessage) (string, error) {
-	model := r.agent.Model
-	if model == "" {
-		model = "deepseek-chat"
+// callLLM 调用LLM API，主模型故障时按顺序切换到备用模型
+// 返回响应内容和实际应答的模型标识
+func (r *ReActExecutor) callLLM(messages []ChatMessage) (string, string, error) {
+	targets, err := r.buildLLMTargets()
+	if err != nil {
+		return "", "", err
 	}
 
+	var lastErr error
+	for i, target := range targets {
+		start := time.Now()
+		content, err := r.callChatCompletion(target, messages)
+		providerHealth.record(target.provider.ID, time.Since(start), err)
+		if err == nil {
+			if i > 0 {
+				log.Printf("已切换到备用模型: %s", target.label())
+			}
+			return content, target.label(), nil
+		}
+
+		lastErr = err
+		var callErr *llmCallError
+		if !errors.As(err, &callErr) || !callErr.shouldFailover() {
+			return "", target.label(), err
+		}
+		log.Printf("模型 %s 调用失败 (%s)，尝试下一个: %v", target.label(), callErr.errorType(), err)
+	}
+
+	return "", "", fmt.Errorf("所有模型均调用失败: %v", lastErr)
+}
+
+// callChatCompletion 调用单个提供商的 chat/completions 接口
+func (r *ReActExecutor) callChatCompletion(target llmTarget, messages []ChatMessage) (string, error) {
 	reqBody := ChatRequest{
-		Model:       model,
+		Model:       target.model,
 		Messages:    messages,
 		Temperature: 0.3, // 降低温度，使输出更确定
 		MaxTokens:   2000,
@@ -303,13 +506,9 @@ func (r *ReActExecutor) callLLM(messages []ChatMessage) (string, error) {
 		return "", fmt.Errorf("序列化请求失败: %v", err)
 	}
 
-	apiURL := r.provider.BaseURL
-	if !strings.HasSuffix(apiURL, "/") {
-		apiURL += "/"
-	}
-	apiURL += "chat/completions"
+	apiURL := providerEndpoint(target.provider.BaseURL, "chat/completions")
 
-	log.Printf("调用LLM API: %s, model=%s", apiURL, model)
+	log.Printf("调用LLM API: %s, model=%s", apiURL, target.model)
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -317,29 +516,36 @@ func (r *ReActExecutor) callLLM(messages []ChatMessage) (string, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+r.provider.APIKey)
+	req.Header.Set("Authorization", "Bearer "+target.provider.APIKey)
 
-	client := &http.Client{Timeout: 120 * time.Second}
+	client := newLLMClient(120 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("请求失败: %v", err)
+		return "", &llmCallError{Err: fmt.Errorf("请求失败: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
+		return "", &llmCallError{Err: fmt.Errorf("读取响应失败: %w", err)}
 	}
 
 	log.Printf("LLM响应状态: %d", resp.StatusCode)
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))
+		return "", &llmCallError{StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("解析响应失败: %v, body: %s", err, string(body))}
 	}
 
 	if chatResp.Error != nil {
-		return "", fmt.Errorf("API错误: %s", chatResp.Error.Message)
+		return "", &llmCallError{StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("API错误: %s", chatResp.Error.Message)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &llmCallError{StatusCode: resp.StatusCode,
+			Err: fmt.Errorf("API错误: HTTP %d", resp.StatusCode)}
 	}
 
 	if len(chatResp.Choices) == 0 {
@@ -356,9 +562,9 @@ func (r *ReActExecutor) saveStep(step *AgentStep) (int64, error) {
 	}
 
 	result, err := db.Exec(`
-		INSERT INTO agent_steps (conversation_id, step_num, thought, action, action_input, observation, status, error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, step.ConversationID, step.StepNum, step.Thought, step.Action, step.ActionInput, step.Observation, step.Status, step.Error)
+		INSERT INTO agent_steps (conversation_id, step_num, thought, action, action_input, observation, status, error, model)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, step.ConversationID, step.StepNum, step.Thought, step.Action, step.ActionInput, step.Observation, step.Status, step.Error, step.Model)
 
 	if err != nil {
 		return 0, fmt.Errorf("插入步骤失败: %v", err)
@@ -395,6 +601,9 @@ func (r *ReActExecutor) handleError(errMsg string) {
 func (a *App) runAIConversation(conversationID int64, agent *Agent) {
 	log.Printf("开始AI会话: conversationID=%d, agent=%s", conversationID, agent.Name)
 
+	// 本轮执行结束后推进所属的流水线
+	defer a.onConversationFinished(conversationID)
+
 	// 获取Provider
 	if agent.ProviderID == nil {
 		a.saveMessage(conversationID, "assistant", "错误：Agent未配置模型提供商", MessageTypeError, "{}")
@@ -427,7 +636,8 @@ func (a *App) GetConversationSteps(conversationID int64) ([]AgentStep, error) {
 	}
 
 	rows, err := db.Query(`
-		SELECT id, conversation_id, step_num, thought, action, action_input, observation, status, error, created_at
+		SELECT id, conversation_id, step_num, thought, action, action_input, observation, status, error,
+		       COALESCE(model, ''), created_at
 		FROM agent_steps
 		WHERE conversation_id = ?
 		ORDER BY step_num ASC
@@ -441,11 +651,33 @@ func (a *App) GetConversationSteps(conversationID int64) ([]AgentStep, error) {
 	for rows.Next() {
 		var step AgentStep
 		if err := rows.Scan(&step.ID, &step.ConversationID, &step.StepNum, &step.Thought,
-			&step.Action, &step.ActionInput, &step.Observation, &step.Status, &step.Error, &step.CreatedAt); err != nil {
+			&step.Action, &step.ActionInput, &step.Observation, &step.Status, &step.Error, &step.Model, &step.CreatedAt); err != nil {
 			return nil, fmt.Errorf("扫描步骤失败: %v", err)
 		}
 		steps = append(steps, step)
 	}
 
+	// delegate 步骤关联的子会话
+	childRows, err := db.Query(`
+		SELECT id, parent_step_id FROM task_conversations
+		WHERE parent_conversation_id = ? ORDER BY id
+	`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("查询子会话失败: %v", err)
+	}
+	defer childRows.Close()
+
+	children := make(map[int64][]int64)
+	for childRows.Next() {
+		var childID, stepID int64
+		if err := childRows.Scan(&childID, &stepID); err != nil {
+			return nil, fmt.Errorf("扫描子会话失败: %v", err)
+		}
+		children[stepID] = append(children[stepID], childID)
+	}
+	for i := range steps {
+		steps[i].Children = children[steps[i].ID]
+	}
+
 	return steps, nil
 }
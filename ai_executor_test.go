@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// insertTestProvider 保存一个指向 baseURL 的模型提供商
+func insertTestProvider(t *testing.T, a *App, name, baseURL string) *ModelProvider {
+	t.Helper()
+	result, err := db.Exec(`INSERT INTO model_providers (name, label, api_key, base_url, enabled) VALUES (?, ?, 'test-key', ?, 1)`,
+		name, name, baseURL)
+	if err != nil {
+		t.Fatalf("保存提供商失败: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	p, err := a.GetModelProvider(id)
+	if err != nil {
+		t.Fatalf("查询提供商失败: %v", err)
+	}
+	return p
+}
+
+// startTestConversation 创建任务、Agent 和会话（不启动AI处理）
+func startTestConversation(t *testing.T, a *App, input AgentInput) (int64, *Agent) {
+	t.Helper()
+	task, err := a.CreateTask(TaskInput{Name: "整理笔记", Hours: 1})
+	if err != nil {
+		t.Fatalf("创建任务失败: %v", err)
+	}
+	if input.Name == "" {
+		input.Name = "测试Agent"
+	}
+	if input.Model == "" {
+		input.Model = "scripted-model"
+	}
+	if input.WorkingDir == "" {
+		input.WorkingDir = t.TempDir()
+	}
+	input.Enabled = true
+	agent, err := a.CreateAgent(input)
+	if err != nil {
+		t.Fatalf("创建Agent失败: %v", err)
+	}
+	convID, agent, err := a.createConversation(StartConversationInput{TaskID: task.ID, AgentID: agent.ID})
+	if err != nil {
+		t.Fatalf("创建会话失败: %v", err)
+	}
+	return convID, agent
+}
+
+// conversationResult 会话最终状态、步骤和消息
+func conversationResult(t *testing.T, a *App, convID int64) (string, []AgentStep, []ConversationMessage) {
+	t.Helper()
+	detail, err := a.GetConversationDetail(convID)
+	if err != nil {
+		t.Fatalf("查询会话失败: %v", err)
+	}
+	steps, err := a.GetConversationSteps(convID)
+	if err != nil {
+		t.Fatalf("查询步骤失败: %v", err)
+	}
+	return detail.Conversation.Status, steps, detail.Messages
+}
+
+// lastMessage 最后一条指定类型的消息
+func lastMessage(messages []ConversationMessage, msgType string) *ConversationMessage {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].MessageType == msgType {
+			return &messages[i]
+		}
+	}
+	return nil
+}
+
+func TestReActToolThenComplete(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t,
+		// 响应夹杂说明文字，且使用弯引号和结尾逗号
+		ScriptedLLMReply{Content: "好的，我先读取文件：\n{“thought”: “读取笔记”, “action”: “read_file”, “action_input”: {“path”: “notes.txt”,},}"},
+		ScriptedLLMReply{Content: `{"thought": "内容已确认", "action": "complete", "action_input": {"summary": "笔记共两行"}}`},
+	)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID, Tools: `["read_file"]`})
+	os.WriteFile(filepath.Join(agent.WorkingDir, "notes.txt"), []byte("第一行\n第二行"), 0644)
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, messages := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("会话状态为 %s，期望 completed", status)
+	}
+	if len(steps) != 2 || steps[0].Action != ToolReadFile || steps[0].Status != StepStatusSuccess {
+		t.Fatalf("步骤不符合预期: %+v", steps)
+	}
+	if !strings.Contains(steps[0].Observation, "第二行") {
+		t.Fatalf("read_file 的观察结果应包含文件内容，实际: %q", steps[0].Observation)
+	}
+	if steps[0].Model != "scripted/scripted-model" {
+		t.Fatalf("步骤记录的模型为 %q", steps[0].Model)
+	}
+	if msg := lastMessage(messages, MessageTypeResult); msg == nil || msg.Content != "笔记共两行" {
+		t.Fatalf("缺少完成总结消息: %+v", msg)
+	}
+
+	// 第二次调用时，工具执行结果作为用户消息发给模型
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("应调用模型2次，实际 %d 次", len(requests))
+	}
+	last := requests[1].Messages[len(requests[1].Messages)-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "[工具执行结果]") {
+		t.Fatalf("第二次请求的最后一条消息应为工具执行结果，实际: %+v", last)
+	}
+}
+
+func TestReActRepairsInvalidResponse(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t,
+		ScriptedLLMReply{Content: "我觉得任务已经完成了"},
+		ScriptedLLMReply{Content: `{"thought": "调用不存在的工具", "action": "deploy", "action_input": {}}`},
+		ScriptedLLMReply{Content: `{"thought": "缺少必填参数", "action": "read_file", "action_input": {}}`},
+		ScriptedLLMReply{Content: `{"thought": "按格式输出", "action": "complete", "action_input": {"summary": "完成"}}`},
+	)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID, Tools: `["read_file"]`})
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, messages := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("会话状态为 %s，期望 completed", status)
+	}
+	if len(steps) != 1 || steps[0].Action != ToolComplete {
+		t.Fatalf("格式错误的响应不应产生步骤: %+v", steps)
+	}
+	repairs := 0
+	for _, msg := range messages {
+		if msg.MessageType == MessageTypeError && strings.HasPrefix(msg.Content, "[格式错误]") {
+			repairs++
+		}
+	}
+	if repairs != 3 {
+		t.Fatalf("应发送3次修正提示，实际 %d 次", repairs)
+	}
+}
+
+func TestReActRepairLimitWaitsForUser(t *testing.T) {
+	a := openTestDB(t)
+	replies := make([]ScriptedLLMReply, 5)
+	for i := range replies {
+		replies[i] = ScriptedLLMReply{Content: "无法解析的回复"}
+	}
+	server := NewScriptedLLMServer(t, replies...)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID})
+
+	a.runAIConversation(convID, agent)
+
+	status, _, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusWaitingUser {
+		t.Fatalf("多次修正失败后应等待用户，实际状态 %s", status)
+	}
+	if n := len(server.Requests()); n != 4 {
+		t.Fatalf("应在第4次失败后停止，实际调用 %d 次", n)
+	}
+}
+
+func TestReActAskUser(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t,
+		ScriptedLLMReply{Content: `{"thought": "需要确认", "action": "ask_user", "action_input": {"question": "用哪个分支？", "options": ["main", "dev"]}}`},
+	)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID})
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, messages := conversationResult(t, a, convID)
+	if status != ConversationStatusWaitingUser {
+		t.Fatalf("会话状态为 %s，期望 waiting_user", status)
+	}
+	if len(steps) != 1 || steps[0].Action != ToolAskUser {
+		t.Fatalf("步骤不符合预期: %+v", steps)
+	}
+	msg := lastMessage(messages, MessageTypeQuestion)
+	if msg == nil || msg.Content != "用哪个分支？" || !strings.Contains(msg.Metadata, `"dev"`) {
+		t.Fatalf("问题消息不符合预期: %+v", msg)
+	}
+}
+
+func TestReActFailsOnAuthError(t *testing.T) {
+	a := openTestDB(t)
+	primary := NewScriptedLLMServer(t, ScriptedLLMReply{StatusCode: http.StatusUnauthorized, Error: "invalid api key"})
+	backup := NewScriptedLLMServer(t, ScriptedLLMReply{Content: `{"thought": "", "action": "complete", "action_input": {"summary": "不应调用"}}`})
+	provider := insertTestProvider(t, a, "primary", primary.URL)
+	fallback := insertTestProvider(t, a, "backup", backup.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{
+		ProviderID: &provider.ID,
+		Fallbacks:  fmt.Sprintf(`[{"provider_id": %d, "model": "backup-model"}]`, fallback.ID),
+	})
+
+	a.runAIConversation(convID, agent)
+
+	status, _, messages := conversationResult(t, a, convID)
+	if status != ConversationStatusFailed {
+		t.Fatalf("鉴权失败时会话应失败，实际状态 %s", status)
+	}
+	if msg := lastMessage(messages, MessageTypeError); msg == nil || !strings.Contains(msg.Content, "invalid api key") {
+		t.Fatalf("错误消息不符合预期: %+v", msg)
+	}
+	if n := len(backup.Requests()); n != 0 {
+		t.Fatalf("鉴权错误不应切换备用模型，备用模型被调用 %d 次", n)
+	}
+}
+
+func TestReActFailover(t *testing.T) {
+	a := openTestDB(t)
+	primary := NewScriptedLLMServer(t, ScriptedLLMReply{StatusCode: http.StatusServiceUnavailable, Error: "overloaded"})
+	backup := NewScriptedLLMServer(t, ScriptedLLMReply{Content: `{"thought": "备用模型完成", "action": "complete", "action_input": {"summary": "完成"}}`})
+	provider := insertTestProvider(t, a, "primary", primary.URL)
+	fallback := insertTestProvider(t, a, "backup", backup.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{
+		ProviderID: &provider.ID,
+		Fallbacks:  fmt.Sprintf(`[{"provider_id": %d, "model": "backup-model"}]`, fallback.ID),
+	})
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("切换备用模型后应完成，实际状态 %s", status)
+	}
+	if len(steps) != 1 || steps[0].Model != "backup/backup-model" {
+		t.Fatalf("步骤应记录备用模型: %+v", steps)
+	}
+}
+
+func TestReActAllModelsFail(t *testing.T) {
+	a := openTestDB(t)
+	primary := NewScriptedLLMServer(t, ScriptedLLMReply{StatusCode: http.StatusTooManyRequests, Error: "rate limited"})
+	backup := NewScriptedLLMServer(t, ScriptedLLMReply{StatusCode: http.StatusBadGateway, Error: "bad gateway"})
+	provider := insertTestProvider(t, a, "primary", primary.URL)
+	fallback := insertTestProvider(t, a, "backup", backup.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{
+		ProviderID: &provider.ID,
+		Fallbacks:  fmt.Sprintf(`[{"provider_id": %d, "model": "backup-model"}]`, fallback.ID),
+	})
+
+	a.runAIConversation(convID, agent)
+
+	status, _, messages := conversationResult(t, a, convID)
+	if status != ConversationStatusFailed {
+		t.Fatalf("所有模型失败时会话应失败，实际状态 %s", status)
+	}
+	if msg := lastMessage(messages, MessageTypeError); msg == nil || !strings.Contains(msg.Content, "所有模型均调用失败") {
+		t.Fatalf("错误消息不符合预期: %+v", msg)
+	}
+}
+
+func TestReActMaxSteps(t *testing.T) {
+	a := openTestDB(t)
+	replies := make([]ScriptedLLMReply, 3)
+	for i := range replies {
+		replies[i] = ScriptedLLMReply{Content: `{"thought": "再看看", "action": "list_files", "action_input": {"path": "."}}`}
+	}
+	server := NewScriptedLLMServer(t, replies...)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID, Tools: `["list_files"]`})
+
+	executor := NewReActExecutor(a, convID, agent, provider)
+	executor.maxSteps = 3
+	executor.Run()
+
+	status, steps, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusFailed || len(steps) != 3 {
+		t.Fatalf("达到最大步骤数后应失败: status=%s steps=%d", status, len(steps))
+	}
+}
+
+// TestReActReplayFixtures 使用 testdata 中录制的响应离线运行
+func TestReActReplayFixtures(t *testing.T) {
+	a := openTestDB(t)
+	replay, err := NewReplayTransport(filepath.Join("testdata", "llm_replay", "list_then_complete"))
+	if err != nil {
+		t.Fatalf("加载回放数据失败: %v", err)
+	}
+	old := SetLLMTransport(replay)
+	defer SetLLMTransport(old)
+
+	provider := insertTestProvider(t, a, "replay", "http://replay.invalid/v1")
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID, Tools: `["list_files"]`})
+	os.WriteFile(filepath.Join(agent.WorkingDir, "notes.txt"), []byte("todo"), 0644)
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("会话状态为 %s，期望 completed", status)
+	}
+	if len(steps) != 2 || !strings.Contains(steps[0].Observation, "notes.txt") {
+		t.Fatalf("步骤不符合预期: %+v", steps)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("回放数据应已用完，剩余 %d", replay.Remaining())
+	}
+}
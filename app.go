@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 )
 
 // App struct
 type App struct {
-	ctx context.Context
+	ctx   context.Context
+	actor auditActor // 审计日志中记录的操作者，零值表示用户
 }
 
 // NewApp creates a new App application struct
@@ -24,10 +26,24 @@ func (a *App) startup(ctx context.Context) {
 	if err := InitDB(); err != nil {
 		log.Printf("初始化数据库失败: %v", err)
 	}
+
+	// 提前生成近期的重复任务实例
+	horizon := time.Now().AddDate(0, 0, recurrenceHorizonDays).Format("2006-01-02")
+	if err := generateRecurringTasks(horizon); err != nil {
+		log.Printf("生成重复任务实例失败: %v", err)
+	}
+
+	// LLM 录制/回放模式（开发调试用）
+	if err := initLLMTransportFromEnv(); err != nil {
+		log.Printf("初始化LLM录制/回放失败: %v", err)
+	}
 }
 
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
+	// 结束会话仍在使用的 MCP 服务器进程
+	closeAllMCPSessions()
+
 	// 关闭数据库连接
 	if err := CloseDB(); err != nil {
 		log.Printf("关闭数据库失败: %v", err)
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// auditActor 操作者（用户、Agent 会话、API 客户端或系统）
+type auditActor struct {
+	actorType      string
+	conversationID *int64
+	client         string
+}
+
+// withActor 返回以指定操作者身份调用的 App（共享同一个数据库连接）
+func (a *App) withActor(actor auditActor) *App {
+	if a == nil {
+		return &App{actor: actor}
+	}
+	c := *a
+	c.actor = actor
+	return &c
+}
+
+// auditFields 各类对象记录修改的字段
+var auditFields = map[string]struct {
+	table  string
+	fields []string
+}{
+	AuditEntityTask: {"tasks", []string{
+		"name", "description", "project_id", "parent_id", "date", "start_time", "end_time",
+		"hours", "deadline", "priority", "urgency", "status", "completion_rule", "actual_start", "actual_hours",
+	}},
+	AuditEntityProject: {"projects", []string{"name", "description", "color", "archived"}},
+}
+
+const auditSelectSQL = `
+	SELECT id, entity_type, entity_id, action, COALESCE(changes, '[]'), COALESCE(actor_type, 'user'),
+		conversation_id, COALESCE(client, ''), COALESCE(undone, 0), undo_of, created_at
+	FROM audit_log
+`
+
+// scanAuditEntry 扫描审计日志
+func scanAuditEntry(row interface{ Scan(...any) error }) (AuditEntry, error) {
+	var e AuditEntry
+	var changes string
+	if err := row.Scan(&e.ID, &e.EntityType, &e.EntityID, &e.Action, &changes, &e.ActorType,
+		&e.ConversationID, &e.Client, &e.Undone, &e.UndoOf, &e.CreatedAt); err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+		log.Printf("解析审计日志 %d 失败: %v", e.ID, err)
+	}
+	if e.Changes == nil {
+		e.Changes = []FieldChange{}
+	}
+	return e, nil
+}
+
+// snapshotEntity 读取对象当前的字段值（不存在时返回 nil）
+func snapshotEntity(entityType string, id int64) map[string]interface{} {
+	def := auditFields[entityType]
+	values := make([]interface{}, len(def.fields))
+	dest := make([]interface{}, len(def.fields))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, strings.Join(def.fields, ", "), def.table)
+	if err := db.QueryRow(query, id).Scan(dest...); err != nil {
+		return nil
+	}
+
+	snapshot := make(map[string]interface{}, len(def.fields))
+	for i, field := range def.fields {
+		if b, ok := values[i].([]byte); ok {
+			values[i] = string(b)
+		}
+		snapshot[field] = values[i]
+	}
+	return snapshot
+}
+
+// snapshotTask 读取任务当前的字段值
+func snapshotTask(id int64) map[string]interface{} {
+	return snapshotEntity(AuditEntityTask, id)
+}
+
+// snapshotProject 读取项目当前的字段值
+func snapshotProject(id int64) map[string]interface{} {
+	return snapshotEntity(AuditEntityProject, id)
+}
+
+// diffSnapshots 比较修改前后的字段值
+func diffSnapshots(entityType string, before, after map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range auditFields[entityType].fields {
+		if !sameAuditValue(before[field], after[field]) {
+			changes = append(changes, FieldChange{Field: field, Old: before[field], New: after[field]})
+		}
+	}
+	return changes
+}
+
+// sameAuditValue 按 JSON 表示比较字段值（日志中的数字读回后是 float64）
+func sameAuditValue(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// auditTask 记录任务的修改（before 为修改前的快照，创建时为 nil）
+func (a *App) auditTask(id int64, action string, before map[string]interface{}) {
+	a.recordAudit(AuditEntityTask, id, action, before, snapshotTask(id), nil)
+}
+
+// auditSystemTask 以系统身份记录任务的自动联动修改
+func auditSystemTask(id int64, action string, before map[string]interface{}) {
+	(&App{actor: auditActor{actorType: AuditActorSystem}}).auditTask(id, action, before)
+}
+
+// auditProject 记录项目的修改（before 为修改前的快照，创建时为 nil）
+func (a *App) auditProject(id int64, action string, before map[string]interface{}) {
+	a.recordAudit(AuditEntityProject, id, action, before, snapshotProject(id), nil)
+}
+
+// recordAudit 写入审计日志（没有字段变化时不记录）
+func (a *App) recordAudit(entityType string, id int64, action string, before, after map[string]interface{}, undoOf *int64) {
+	changes := diffSnapshots(entityType, before, after)
+	if len(changes) == 0 {
+		return
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("序列化审计日志失败: %v", err)
+		return
+	}
+
+	actorType := a.actor.actorType
+	if actorType == "" {
+		actorType = AuditActorUser
+	}
+	_, err = db.Exec(`
+		INSERT INTO audit_log (entity_type, entity_id, action, changes, actor_type, conversation_id, client, undo_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, entityType, id, action, string(data), actorType, a.actor.conversationID, a.actor.client, undoOf)
+	if err != nil {
+		log.Printf("记录审计日志失败: %v", err)
+	}
+}
+
+// GetTaskHistory 获取任务的修改记录（按时间倒序）
+func (a *App) GetTaskHistory(taskID int64) ([]AuditEntry, error) {
+	return queryAuditLog(auditSelectSQL+`WHERE entity_type = ? AND entity_id = ? ORDER BY id DESC`, AuditEntityTask, taskID)
+}
+
+// GetProjectHistory 获取项目的修改记录（按时间倒序）
+func (a *App) GetProjectHistory(projectID int64) ([]AuditEntry, error) {
+	return queryAuditLog(auditSelectSQL+`WHERE entity_type = ? AND entity_id = ? ORDER BY id DESC`, AuditEntityProject, projectID)
+}
+
+// GetRecentChanges 获取最近的修改记录（limit 为 0 时默认50条）
+func (a *App) GetRecentChanges(limit int) ([]AuditEntry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return queryAuditLog(auditSelectSQL+`ORDER BY id DESC LIMIT ?`, limit)
+}
+
+// queryAuditLog 执行审计日志查询
+func queryAuditLog(query string, args ...interface{}) ([]AuditEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("查询审计日志失败: %v", err)
+		return nil, fmt.Errorf("查询审计日志失败: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			log.Printf("扫描审计日志失败: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// UndoLastChange 撤销最近一次未撤销的修改，返回被撤销的记录
+func (a *App) UndoLastChange() (*AuditEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	var id int64
+	// 系统自动联动的修改（父任务自动完成、计时状态、实际工时汇总等）不作为撤销对象
+	err := db.QueryRow(`
+		SELECT id FROM audit_log
+		WHERE undone = 0 AND action != ? AND COALESCE(actor_type, 'user') != ?
+		ORDER BY id DESC LIMIT 1
+	`, AuditActionUndo, AuditActorSystem).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("没有可以撤销的修改")
+	}
+	if err := a.UndoChange(id); err != nil {
+		return nil, err
+	}
+
+	e, err := scanAuditEntry(db.QueryRow(auditSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %v", err)
+	}
+	return &e, nil
+}
+
+// UndoChange 撤销一条修改：修改恢复为原值，创建的删除，删除的恢复（关联的工时、标签等不恢复）
+func (a *App) UndoChange(entryID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	e, err := scanAuditEntry(db.QueryRow(auditSelectSQL+`WHERE id = ?`, entryID))
+	if err != nil {
+		return fmt.Errorf("修改记录不存在: %v", err)
+	}
+	if e.Undone {
+		return fmt.Errorf("该修改已经撤销")
+	}
+	if e.Action == AuditActionUndo {
+		return fmt.Errorf("不能撤销撤销操作")
+	}
+	def, ok := auditFields[e.EntityType]
+	if !ok {
+		return fmt.Errorf("不支持的对象类型: %s", e.EntityType)
+	}
+
+	current := snapshotEntity(e.EntityType, e.EntityID)
+	undoOf := e.ID
+
+	switch e.Action {
+	case AuditActionCreate:
+		if current == nil {
+			return fmt.Errorf("对象已删除，无需撤销")
+		}
+		if e.EntityType == AuditEntityTask {
+			err = a.DeleteTask(e.EntityID)
+		} else {
+			err = a.DeleteProject(e.EntityID)
+		}
+		if err != nil {
+			return err
+		}
+		// 删除时写入的记录改为撤销记录，避免再次撤销时又恢复
+		_, err = db.Exec(`
+			UPDATE audit_log SET action = ?, undo_of = ?
+			WHERE id = (SELECT MAX(id) FROM audit_log WHERE entity_type = ? AND entity_id = ? AND action = ?)
+		`, AuditActionUndo, undoOf, e.EntityType, e.EntityID, AuditActionDelete)
+		if err != nil {
+			log.Printf("标记撤销记录失败: %v", err)
+		}
+
+	case AuditActionDelete:
+		if current != nil {
+			return fmt.Errorf("对象已存在，无法恢复")
+		}
+		columns := []string{"id"}
+		values := []interface{}{e.EntityID}
+		for _, c := range e.Changes {
+			columns = append(columns, c.Field)
+			values = append(values, c.Old)
+		}
+		query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, def.table, strings.Join(columns, ", "),
+			strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+		if _, err := db.Exec(query, values...); err != nil {
+			log.Printf("恢复删除的对象失败: %v", err)
+			return fmt.Errorf("恢复失败: %v", err)
+		}
+		a.recordAudit(e.EntityType, e.EntityID, AuditActionUndo, nil, snapshotEntity(e.EntityType, e.EntityID), &undoOf)
+
+	default:
+		if current == nil {
+			return fmt.Errorf("对象已删除，无法撤销修改")
+		}
+		// 有工时记录的任务，实际工时和实际开始时间由工时记录汇总，不按日志恢复
+		derived := e.EntityType == AuditEntityTask && countTimeEntries(e.EntityID) > 0
+		var sets []string
+		var values []interface{}
+		for _, c := range e.Changes {
+			if derived && (c.Field == "actual_hours" || c.Field == "actual_start") {
+				continue
+			}
+			if !sameAuditValue(current[c.Field], c.New) {
+				return fmt.Errorf("字段 %s 之后又被修改过，无法撤销", c.Field)
+			}
+			if e.EntityType == AuditEntityTask && c.Field == "parent_id" && c.Old != nil {
+				// 恢复的父任务同样不能形成环
+				parentID, ok := c.Old.(float64)
+				if !ok {
+					return fmt.Errorf("父任务记录无效，无法撤销")
+				}
+				if err := checkParentCycle(e.EntityID, int64(parentID)); err != nil {
+					return err
+				}
+			}
+			sets = append(sets, c.Field+" = ?")
+			values = append(values, c.Old)
+		}
+		if len(sets) == 0 {
+			return fmt.Errorf("实际工时由工时记录计算，请修改工时记录")
+		}
+		query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = ?`, def.table, strings.Join(sets, ", "))
+		if _, err := db.Exec(query, append(values, e.EntityID)...); err != nil {
+			log.Printf("撤销修改失败: %v", err)
+			return fmt.Errorf("撤销修改失败: %v", err)
+		}
+		a.recordAudit(e.EntityType, e.EntityID, AuditActionUndo, current, snapshotEntity(e.EntityType, e.EntityID), &undoOf)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_log SET undone = 1 WHERE id = ?`, e.ID); err != nil {
+		log.Printf("标记撤销失败: %v", err)
+	}
+
+	log.Printf("已撤销修改: %s %d %s (记录 %d)", e.EntityType, e.EntityID, e.Action, e.ID)
+	return nil
+}
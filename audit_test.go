@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+)
+
+// latestTaskChange 任务最近一条修改记录
+func latestTaskChange(t *testing.T, a *App, taskID int64) AuditEntry {
+	t.Helper()
+	history, err := a.GetTaskHistory(taskID)
+	if err != nil || len(history) == 0 {
+		t.Fatalf("任务 %d 没有修改记录: %v", taskID, err)
+	}
+	return history[0]
+}
+
+func TestAutoCompletedParentIsAuditedAndUndoable(t *testing.T) {
+	a := openTestDB(t)
+	parent := createTestTask(t, a, TaskInput{Name: "发布", Hours: 1, CompletionRule: CompletionRuleAuto})
+	child := createTestTask(t, a, TaskInput{Name: "打包", Hours: 1, ParentID: &parent.ID})
+
+	if err := a.UpdateTaskStatus(child.ID, TaskStatusCompleted); err != nil {
+		t.Fatalf("完成子任务失败: %v", err)
+	}
+	entry := latestTaskChange(t, a, parent.ID)
+	if entry.ActorType != AuditActorSystem || len(entry.Changes) != 1 || entry.Changes[0].Field != "status" {
+		t.Fatalf("父任务自动完成应以系统身份记录: %+v", entry)
+	}
+
+	if err := a.UndoChange(entry.ID); err != nil {
+		t.Fatalf("撤销自动完成失败: %v", err)
+	}
+	if got, _ := a.GetTask(parent.ID); got.Status == TaskStatusCompleted {
+		t.Fatalf("撤销后父任务不应为已完成")
+	}
+}
+
+func TestTimerAndActualHoursAreAudited(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "写文档", Hours: 2})
+
+	if _, err := a.StartTimer(task.ID); err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	entry := latestTaskChange(t, a, task.ID)
+	if entry.ActorType != AuditActorSystem || entry.Changes[0].New != TaskStatusInProgress {
+		t.Fatalf("计时改状态应以系统身份记录: %+v", entry)
+	}
+	if _, err := a.StopTimer(); err != nil {
+		t.Fatalf("停止计时失败: %v", err)
+	}
+
+	end := "2026-01-05 11:30"
+	if _, err := a.AddTimeEntry(TimeEntryInput{TaskID: task.ID, StartAt: "2026-01-05 10:00", EndAt: &end}); err != nil {
+		t.Fatalf("添加工时记录失败: %v", err)
+	}
+	entry = latestTaskChange(t, a, task.ID)
+	if entry.ActorType != AuditActorSystem {
+		t.Fatalf("工时汇总应以系统身份记录: %+v", entry)
+	}
+	var hoursChanged bool
+	for _, c := range entry.Changes {
+		hoursChanged = hoursChanged || c.Field == "actual_hours"
+	}
+	if !hoursChanged {
+		t.Fatalf("应记录实际工时的变化: %+v", entry.Changes)
+	}
+
+	// 实际工时由工时记录汇总，不能通过撤销日志改回
+	if err := a.UndoChange(entry.ID); err == nil {
+		t.Fatal("撤销工时汇总应返回错误")
+	}
+	if got, _ := a.GetTask(task.ID); got.ActualHours < 1.5 {
+		t.Fatalf("实际工时不应被撤销: %v", got.ActualHours)
+	}
+}
+
+func TestUndoLastChangeSkipsSystemChanges(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "写文档", Hours: 2})
+	if err := a.UpdateTask(TaskInput{ID: task.ID, Name: "写设计文档", Hours: 2}); err != nil {
+		t.Fatalf("修改任务失败: %v", err)
+	}
+	end := "2026-01-05 12:00"
+	if _, err := a.AddTimeEntry(TimeEntryInput{TaskID: task.ID, StartAt: "2026-01-05 10:00", EndAt: &end}); err != nil {
+		t.Fatalf("添加工时记录失败: %v", err)
+	}
+
+	undone, err := a.UndoLastChange()
+	if err != nil {
+		t.Fatalf("撤销失败: %v", err)
+	}
+	if undone.ActorType == AuditActorSystem {
+		t.Fatalf("不应撤销系统修改: %+v", undone)
+	}
+	got, _ := a.GetTask(task.ID)
+	if got.Name != "写文档" {
+		t.Fatalf("应撤销用户的改名: %s", got.Name)
+	}
+	if got.ActualHours != 2 {
+		t.Fatalf("实际工时不应被撤销: %v", got.ActualHours)
+	}
+}
+
+func TestUndoParentChangeRejectsCycle(t *testing.T) {
+	a := openTestDB(t)
+	first := createTestTask(t, a, TaskInput{Name: "甲", Hours: 1})
+	second := createTestTask(t, a, TaskInput{Name: "乙", Hours: 1})
+
+	if err := a.SetTaskParent(first.ID, &second.ID); err != nil {
+		t.Fatalf("设置父任务失败: %v", err)
+	}
+	if err := a.SetTaskParent(first.ID, nil); err != nil {
+		t.Fatalf("清除父任务失败: %v", err)
+	}
+	cleared := latestTaskChange(t, a, first.ID)
+	if err := a.SetTaskParent(second.ID, &first.ID); err != nil {
+		t.Fatalf("设置父任务失败: %v", err)
+	}
+
+	if err := a.UndoChange(cleared.ID); err == nil {
+		t.Fatal("撤销后会形成环，应返回错误")
+	}
+	if got, _ := a.GetTask(first.ID); got.ParentID != nil {
+		t.Fatalf("撤销失败时父任务不应改变: %v", *got.ParentID)
+	}
+}
+
+func TestDeleteTaskAuditsReparentedChildren(t *testing.T) {
+	a := openTestDB(t)
+	root := createTestTask(t, a, TaskInput{Name: "项目", Hours: 1})
+	middle := createTestTask(t, a, TaskInput{Name: "阶段", Hours: 1, ParentID: &root.ID})
+	leaf := createTestTask(t, a, TaskInput{Name: "步骤", Hours: 1, ParentID: &middle.ID})
+
+	if err := a.DeleteTask(middle.ID); err != nil {
+		t.Fatalf("删除任务失败: %v", err)
+	}
+	entry := latestTaskChange(t, a, leaf.ID)
+	if entry.ActorType != AuditActorSystem || len(entry.Changes) != 1 || entry.Changes[0].Field != "parent_id" {
+		t.Fatalf("子任务上移应以系统身份记录: %+v", entry)
+	}
+	if got, _ := a.GetTask(leaf.ID); got.ParentID == nil || *got.ParentID != root.ID {
+		t.Fatalf("子任务应上移到祖父任务下: %+v", got.ParentID)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// settingWorkCalendar 工作日历的设置键
+const settingWorkCalendar = "work_calendar"
+
+// defaultWorkCalendar 默认工作日历：周一到周五每天8小时，9点开始
+func defaultWorkCalendar() WorkCalendar {
+	return WorkCalendar{
+		WeeklyHours: []float64{0, 8, 8, 8, 8, 8, 0},
+		WorkStart:   "09:00",
+	}
+}
+
+// GetWorkCalendar 获取工作日历（未保存时返回默认日历）
+func (a *App) GetWorkCalendar() (*WorkCalendar, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	cal := defaultWorkCalendar()
+	if _, err := loadSetting(settingWorkCalendar, &cal); err != nil {
+		return nil, err
+	}
+	if len(cal.WeeklyHours) != 7 {
+		cal.WeeklyHours = defaultWorkCalendar().WeeklyHours
+	}
+	return &cal, nil
+}
+
+// SaveWorkCalendar 保存工作日历
+func (a *App) SaveWorkCalendar(cal WorkCalendar) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if len(cal.WeeklyHours) != 7 {
+		return fmt.Errorf("每周工时需要7项（周日到周六）")
+	}
+	for _, h := range cal.WeeklyHours {
+		if h < 0 || h > 24 {
+			return fmt.Errorf("每天工时必须在0到24之间")
+		}
+	}
+	if cal.WorkStart == "" {
+		cal.WorkStart = defaultWorkCalendar().WorkStart
+	}
+	if parseClock(cal.WorkStart) < 0 {
+		return fmt.Errorf("开始工作时间格式错误: %s", cal.WorkStart)
+	}
+
+	if err := saveSetting(settingWorkCalendar, cal); err != nil {
+		return err
+	}
+
+	log.Printf("保存工作日历成功")
+	return nil
+}
+
+// SetCalendarDay 设置某天的工时、节假日或请假
+func (a *App) SetCalendarDay(day CalendarDay) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := time.Parse("2006-01-02", day.Date); err != nil {
+		return fmt.Errorf("日期格式错误: %s", day.Date)
+	}
+	switch day.Kind {
+	case "":
+		day.Kind = CalendarDayWorkday
+	case CalendarDayWorkday, CalendarDayHoliday, CalendarDayLeave:
+	default:
+		return fmt.Errorf("不支持的日期类型: %s", day.Kind)
+	}
+	if day.Hours < 0 || day.Hours > 24 {
+		return fmt.Errorf("工时必须在0到24之间")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO calendar_days (date, hours, kind, name) VALUES (?, ?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET hours = excluded.hours, kind = excluded.kind, name = excluded.name
+	`, day.Date, day.Hours, day.Kind, day.Name)
+	if err != nil {
+		log.Printf("设置日历失败: %v", err)
+		return fmt.Errorf("设置日历失败: %v", err)
+	}
+
+	log.Printf("设置日历: %s %s %.1f 小时", day.Date, day.Kind, day.Hours)
+	return nil
+}
+
+// DeleteCalendarDay 删除某天的设置（恢复每周默认工时）
+func (a *App) DeleteCalendarDay(date string) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := db.Exec(`DELETE FROM calendar_days WHERE date = ?`, date); err != nil {
+		log.Printf("删除日历设置失败: %v", err)
+		return fmt.Errorf("删除日历设置失败: %v", err)
+	}
+	return nil
+}
+
+// GetCalendarDays 获取日期范围内的特定日期设置
+func (a *App) GetCalendarDays(startDate, endDate string) ([]CalendarDay, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	days, err := loadCalendarDays(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []CalendarDay{}
+	for _, d := range days {
+		result = append(result, d)
+	}
+	sortCalendarDays(result)
+	return result, nil
+}
+
+// ImportHolidaysICS 从 ICS 日历内容导入节假日（kind 为 holiday 或 leave），返回导入的天数
+func (a *App) ImportHolidaysICS(content string, kind string) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("数据库未初始化")
+	}
+
+	if kind == "" {
+		kind = CalendarDayHoliday
+	}
+	if kind != CalendarDayHoliday && kind != CalendarDayLeave {
+		return 0, fmt.Errorf("导入类型只能是 holiday 或 leave")
+	}
+
+	days, err := parseICSDays(content)
+	if err != nil {
+		return 0, err
+	}
+	if len(days) == 0 {
+		return 0, fmt.Errorf("日历中没有找到全天事件")
+	}
+
+	for _, d := range days {
+		d.Kind = kind
+		if err := a.SetCalendarDay(d); err != nil {
+			return 0, err
+		}
+	}
+
+	log.Printf("从 ICS 导入 %d 天%s", len(days), kind)
+	return len(days), nil
+}
+
+// GetDayCapacities 获取日期范围内每天的计划工时与可用工时
+func (a *App) GetDayCapacities(startDate, endDate string) ([]DayCapacity, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式错误: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式错误: %s", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("结束日期不能早于开始日期")
+	}
+	if daysBetween(start, end) > 366 {
+		return nil, fmt.Errorf("日期范围不能超过一年")
+	}
+
+	capacities, err := loadCapacities(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT date, COALESCE(SUM(hours), 0), COUNT(*)
+		FROM tasks
+		WHERE date >= ? AND date <= ?
+		GROUP BY date
+	`, startDate, endDate)
+	if err != nil {
+		log.Printf("查询计划工时失败: %v", err)
+		return nil, fmt.Errorf("查询计划工时失败: %v", err)
+	}
+	defer rows.Close()
+
+	planned := make(map[string]float64)
+	counts := make(map[string]int)
+	for rows.Next() {
+		var date string
+		var hours float64
+		var count int
+		if err := rows.Scan(&date, &hours, &count); err != nil {
+			return nil, fmt.Errorf("扫描计划工时失败: %v", err)
+		}
+		planned[date] = hours
+		counts[date] = count
+	}
+
+	result := []DayCapacity{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		c := capacities[date]
+		c.Planned = roundHours(planned[date])
+		c.TaskCount = counts[date]
+		c.Remaining = roundHours(c.Available - c.Planned)
+		c.Overloaded = c.Planned > c.Available
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// loadCalendarDays 日期范围内的特定日期设置
+func loadCalendarDays(startDate, endDate string) (map[string]CalendarDay, error) {
+	rows, err := db.Query(`
+		SELECT date, COALESCE(hours, 0), COALESCE(kind, 'holiday'), COALESCE(name, '')
+		FROM calendar_days
+		WHERE date >= ? AND date <= ?
+	`, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("查询工作日历失败: %v", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]CalendarDay)
+	for rows.Next() {
+		var d CalendarDay
+		if err := rows.Scan(&d.Date, &d.Hours, &d.Kind, &d.Name); err != nil {
+			return nil, fmt.Errorf("扫描工作日历失败: %v", err)
+		}
+		days[d.Date] = d
+	}
+	return days, nil
+}
+
+// loadCapacities 日期范围内每天的可用工时（特定日期设置优先，否则按每周默认工时）
+func loadCapacities(startDate, endDate string) (map[string]DayCapacity, error) {
+	cal := defaultWorkCalendar()
+	if _, err := loadSetting(settingWorkCalendar, &cal); err != nil {
+		return nil, err
+	}
+	if len(cal.WeeklyHours) != 7 {
+		cal.WeeklyHours = defaultWorkCalendar().WeeklyHours
+	}
+
+	overrides, err := loadCalendarDays(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", endDate)
+	}
+
+	result := make(map[string]DayCapacity)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		c := DayCapacity{Date: date, Available: cal.WeeklyHours[d.Weekday()], Kind: CalendarDayWorkday}
+		if c.Available == 0 {
+			c.Kind = CalendarDayWeekend
+		}
+		if o, ok := overrides[date]; ok {
+			c.Available, c.Kind, c.Name = o.Hours, o.Kind, o.Name
+		}
+		result[date] = c
+	}
+	return result, nil
+}
+
+// capacityWarnings 检查某天的计划工时是否超过可用工时
+func capacityWarnings(date string) []string {
+	if date == "" {
+		return nil
+	}
+
+	capacities, err := loadCapacities(date, date)
+	if err != nil {
+		log.Printf("读取工作日历失败: %v", err)
+		return nil
+	}
+	c := capacities[date]
+
+	var planned float64
+	db.QueryRow(`SELECT COALESCE(SUM(hours), 0) FROM tasks WHERE date = ?`, date).Scan(&planned)
+
+	var warnings []string
+	switch {
+	case c.Kind == CalendarDayHoliday || c.Kind == CalendarDayLeave:
+		label := "节假日"
+		if c.Kind == CalendarDayLeave {
+			label = "请假"
+		}
+		if c.Name != "" {
+			label += "（" + c.Name + "）"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s 是%s", date, label))
+	case c.Available == 0:
+		warnings = append(warnings, fmt.Sprintf("%s 不是工作日", date))
+	}
+	if planned > c.Available {
+		warnings = append(warnings, fmt.Sprintf("%s 已安排 %.1f 小时，超过可用工时 %.1f 小时", date, planned, c.Available))
+	}
+	return warnings
+}
+
+// parseICSDays 解析 ICS 中的全天事件（多天事件展开为每一天），带时间的事件不是整天休息，跳过
+func parseICSDays(content string) ([]CalendarDay, error) {
+	// 展开折行（以空格或制表符开头的行接在上一行后面）
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 ICS 失败: %v", err)
+	}
+
+	var days []CalendarDay
+	seen := make(map[string]bool)
+	var inEvent, allDay bool
+	var summary, dtStart, dtEnd string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent, allDay, summary, dtStart, dtEnd = true, false, "", "", ""
+		case line == "END:VEVENT":
+			inEvent = false
+			if !allDay {
+				log.Printf("跳过 ICS 事件「%s」: 不是全天事件", summary)
+				continue
+			}
+			start, err := parseICSDate(dtStart)
+			if err != nil {
+				log.Printf("跳过 ICS 事件「%s」: %v", summary, err)
+				continue
+			}
+			end := start.AddDate(0, 0, 1)
+			if dtEnd != "" {
+				if e, err := parseICSDate(dtEnd); err == nil && e.After(start) {
+					end = e // DTEND 不包含在内
+				}
+			}
+			for d := start; d.Before(end) && daysBetween(start, d) < 366; d = d.AddDate(0, 0, 1) {
+				date := d.Format("2006-01-02")
+				if seen[date] {
+					continue
+				}
+				seen[date] = true
+				days = append(days, CalendarDay{Date: date, Name: summary})
+			}
+		case inEvent:
+			name, params, value := splitICSLine(line)
+			switch name {
+			case "SUMMARY":
+				summary = strings.ReplaceAll(strings.ReplaceAll(value, `\,`, ","), `\n`, " ")
+			case "DTSTART":
+				dtStart = value
+				allDay = isICSAllDay(params, value)
+			case "DTEND":
+				dtEnd = value
+			}
+		}
+	}
+	sortCalendarDays(days)
+	return days, nil
+}
+
+// splitICSLine 拆分 ICS 行为属性名、参数（大写）和值
+func splitICSLine(line string) (string, string, string) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return "", "", ""
+	}
+	name, params := line[:idx], ""
+	if semi := strings.Index(name, ";"); semi >= 0 {
+		name, params = name[:semi], name[semi+1:]
+	}
+	return strings.ToUpper(name), strings.ToUpper(params), line[idx+1:]
+}
+
+// isICSAllDay 判断 DTSTART 是否为全天事件（VALUE=DATE 或只有8位日期）
+func isICSAllDay(params, value string) bool {
+	for _, param := range strings.Split(params, ";") {
+		if param == "VALUE=DATE" {
+			return true
+		}
+	}
+	return len(value) == 8
+}
+
+// parseICSDate 解析 ICS 日期（YYYYMMDD 或 YYYYMMDDTHHMMSS[Z]，只取日期部分）
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("日期格式错误: %s", value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式错误: %s", value)
+	}
+	return t, nil
+}
+
+// sortCalendarDays 按日期排序
+func sortCalendarDays(days []CalendarDay) {
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseICSDaysOnlyAllDayEvents(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:国庆节",
+		"DTSTART;VALUE=DATE:20261001",
+		"DTEND;VALUE=DATE:20261004",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:元旦",
+		"DTSTART:20270101",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:周会",
+		"DTSTART:20261009T100000Z",
+		"DTEND:20261009T110000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:牙医",
+		"DTSTART;TZID=Asia/Shanghai:20261012T150000",
+		"DTEND;TZID=Asia/Shanghai:20261012T160000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	days, err := parseICSDays(ics)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	var dates []string
+	for _, d := range days {
+		dates = append(dates, d.Date+" "+d.Name)
+	}
+	want := []string{"2026-10-01 国庆节", "2026-10-02 国庆节", "2026-10-03 国庆节", "2027-01-01 元旦"}
+	if strings.Join(dates, ",") != strings.Join(want, ",") {
+		t.Fatalf("只应导入全天事件:\n得到 %v\n期望 %v", dates, want)
+	}
+}
@@ -6,22 +6,52 @@ import (
 	"time"
 )
 
+// 会话查询的基础 SQL
+const conversationSelectSQL = `
+	SELECT c.id, c.task_id, c.agent_id, COALESCE(a.name, '') as agent_name, c.status,
+	       c.parent_conversation_id, c.parent_step_id, c.created_at, c.updated_at
+	FROM task_conversations c
+	LEFT JOIN agents a ON c.agent_id = a.id
+`
+
+// scanConversation 扫描会话
+func scanConversation(row interface{ Scan(...any) error }) (TaskConversation, error) {
+	var conv TaskConversation
+	err := row.Scan(&conv.ID, &conv.TaskID, &conv.AgentID, &conv.AgentName, &conv.Status,
+		&conv.ParentConversationID, &conv.ParentStepID, &conv.CreatedAt, &conv.UpdatedAt)
+	return conv, err
+}
+
 // StartConversation 开始一个AI会话
 func (a *App) StartConversation(input StartConversationInput) (*ConversationDetail, error) {
 	if db == nil {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
+	convID, agent, err := a.createConversation(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 触发AI处理（异步）
+	go a.runAIConversation(convID, agent)
+
+	// 返回会话详情
+	return a.GetConversationDetail(convID)
+}
+
+// createConversation 创建会话并保存任务上下文消息，不启动AI处理
+func (a *App) createConversation(input StartConversationInput) (int64, *Agent, error) {
 	// 验证任务存在
 	task, err := a.GetTask(input.TaskID)
 	if err != nil {
-		return nil, fmt.Errorf("任务不存在: %v", err)
+		return 0, nil, fmt.Errorf("任务不存在: %v", err)
 	}
 
 	// 验证Agent存在
 	agent, err := a.GetAgent(input.AgentID)
 	if err != nil {
-		return nil, fmt.Errorf("Agent不存在: %v", err)
+		return 0, nil, fmt.Errorf("Agent不存在: %v", err)
 	}
 
 	// 创建会话
@@ -31,12 +61,12 @@ func (a *App) StartConversation(input StartConversationInput) (*ConversationDeta
 	`, input.TaskID, input.AgentID, ConversationStatusActive)
 	if err != nil {
 		log.Printf("创建会话失败: %v", err)
-		return nil, fmt.Errorf("创建会话失败: %v", err)
+		return 0, nil, fmt.Errorf("创建会话失败: %v", err)
 	}
 
 	convID, err := result.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("获取会话ID失败: %v", err)
+		return 0, nil, fmt.Errorf("获取会话ID失败: %v", err)
 	}
 
 	// 构建初始上下文消息
@@ -65,11 +95,7 @@ func (a *App) StartConversation(input StartConversationInput) (*ConversationDeta
 		log.Printf("保存上下文消息失败: %v", err)
 	}
 
-	// 触发AI处理（异步）
-	go a.runAIConversation(convID, agent)
-
-	// 返回会话详情
-	return a.GetConversationDetail(convID)
+	return convID, agent, nil
 }
 
 // GetConversationDetail 获取会话详情
@@ -79,13 +105,7 @@ func (a *App) GetConversationDetail(conversationID int64) (*ConversationDetail,
 	}
 
 	// 获取会话
-	var conv TaskConversation
-	err := db.QueryRow(`
-		SELECT c.id, c.task_id, c.agent_id, COALESCE(a.name, '') as agent_name, c.status, c.created_at, c.updated_at
-		FROM task_conversations c
-		LEFT JOIN agents a ON c.agent_id = a.id
-		WHERE c.id = ?
-	`, conversationID).Scan(&conv.ID, &conv.TaskID, &conv.AgentID, &conv.AgentName, &conv.Status, &conv.CreatedAt, &conv.UpdatedAt)
+	conv, err := scanConversation(db.QueryRow(conversationSelectSQL+`WHERE c.id = ?`, conversationID))
 	if err != nil {
 		return nil, fmt.Errorf("会话不存在: %v", err)
 	}
@@ -115,13 +135,27 @@ func (a *App) GetTaskConversations(taskID int64) ([]TaskConversation, error) {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	rows, err := db.Query(`
-		SELECT c.id, c.task_id, c.agent_id, COALESCE(a.name, '') as agent_name, c.status, c.created_at, c.updated_at
-		FROM task_conversations c
-		LEFT JOIN agents a ON c.agent_id = a.id
+	return queryConversations(conversationSelectSQL+`
 		WHERE c.task_id = ?
 		ORDER BY c.created_at DESC
 	`, taskID)
+}
+
+// GetChildConversations 获取由会话委派出的子会话
+func (a *App) GetChildConversations(conversationID int64) ([]TaskConversation, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return queryConversations(conversationSelectSQL+`
+		WHERE c.parent_conversation_id = ?
+		ORDER BY c.id
+	`, conversationID)
+}
+
+// queryConversations 查询会话列表
+func queryConversations(query string, args ...interface{}) ([]TaskConversation, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询会话失败: %v", err)
 	}
@@ -129,8 +163,8 @@ func (a *App) GetTaskConversations(taskID int64) ([]TaskConversation, error) {
 
 	var conversations []TaskConversation
 	for rows.Next() {
-		var conv TaskConversation
-		if err := rows.Scan(&conv.ID, &conv.TaskID, &conv.AgentID, &conv.AgentName, &conv.Status, &conv.CreatedAt, &conv.UpdatedAt); err != nil {
+		conv, err := scanConversation(rows)
+		if err != nil {
 			return nil, fmt.Errorf("扫描会话失败: %v", err)
 		}
 		conversations = append(conversations, conv)
@@ -189,10 +223,41 @@ func (a *App) StopConversation(conversationID int64) error {
 	if err != nil {
 		return fmt.Errorf("停止会话失败: %v", err)
 	}
+	releaseConversationMCP(conversationID)
+	a.onConversationFinished(conversationID)
+
+	// 委派出去仍在执行或等待用户的子会话一并停止
+	rows, err := db.Query(`SELECT id FROM task_conversations WHERE parent_conversation_id = ? AND status IN (?, ?)`,
+		conversationID, ConversationStatusActive, ConversationStatusWaitingUser)
+	if err != nil {
+		return fmt.Errorf("查询子会话失败: %v", err)
+	}
+	var children []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err == nil {
+			children = append(children, id)
+		}
+	}
+	rows.Close()
+	for _, id := range children {
+		if err := a.StopConversation(id); err != nil {
+			log.Printf("停止子会话 %d 失败: %v", id, err)
+		}
+	}
 
 	return nil
 }
 
+// conversationStopped 会话是否已不在执行中（被用户停止或级联停止）
+func conversationStopped(conversationID int64) bool {
+	var status string
+	if err := db.QueryRow(`SELECT status FROM task_conversations WHERE id = ?`, conversationID).Scan(&status); err != nil {
+		return true
+	}
+	return status != ConversationStatusActive
+}
+
 // getConversationMessages 获取会话消息
 func (a *App) getConversationMessages(conversationID int64) ([]ConversationMessage, error) {
 	rows, err := db.Query(`
@@ -241,6 +306,16 @@ func (a *App) updateConversationStatus(conversationID int64, status string) erro
 	return err
 }
 
+// getConversationTaskID 获取会话关联的任务ID
+func getConversationTaskID(conversationID int64) (int64, error) {
+	var taskID int64
+	err := db.QueryRow(`SELECT task_id FROM task_conversations WHERE id = ?`, conversationID).Scan(&taskID)
+	if err != nil {
+		return 0, fmt.Errorf("会话不存在: %v", err)
+	}
+	return taskID, nil
+}
+
 // safeString 安全获取字符串指针的值
 func safeString(s *string) string {
 	if s == nil {
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// customToolNamePattern 自定义工具名称格式
+var customToolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// templateParamPattern 命令模板中的参数占位符 {{name}}
+var templateParamPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// 自定义工具查询的基础 SQL
+const customToolSelectSQL = `
+	SELECT id, name, description, schema, command, working_dir, timeout,
+	       output_mode, output_field, enabled, created_at
+	FROM tools
+`
+
+// scanCustomTool 扫描自定义工具
+func scanCustomTool(row interface{ Scan(...any) error }) (CustomTool, error) {
+	var t CustomTool
+	err := row.Scan(&t.ID, &t.Name, &t.Description, &t.Schema, &t.Command, &t.WorkingDir,
+		&t.Timeout, &t.OutputMode, &t.OutputField, &t.Enabled, &t.CreatedAt)
+	return t, err
+}
+
+// GetCustomTools 获取所有自定义工具
+func (a *App) GetCustomTools() ([]CustomTool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	return queryCustomTools(customToolSelectSQL + `ORDER BY name`)
+}
+
+// getEnabledCustomTools 获取已启用的自定义工具
+func getEnabledCustomTools() ([]CustomTool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	return queryCustomTools(customToolSelectSQL + `WHERE enabled = 1 ORDER BY name`)
+}
+
+// queryCustomTools 查询自定义工具列表
+func queryCustomTools(query string, args ...interface{}) ([]CustomTool, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("查询自定义工具失败: %v", err)
+		return nil, fmt.Errorf("查询自定义工具失败: %v", err)
+	}
+	defer rows.Close()
+
+	var tools []CustomTool
+	for rows.Next() {
+		t, err := scanCustomTool(rows)
+		if err != nil {
+			log.Printf("扫描自定义工具失败: %v", err)
+			return nil, fmt.Errorf("扫描自定义工具失败: %v", err)
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// GetCustomTool 获取单个自定义工具
+func (a *App) GetCustomTool(id int64) (*CustomTool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	t, err := scanCustomTool(db.QueryRow(customToolSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		log.Printf("查询自定义工具失败: %v", err)
+		return nil, fmt.Errorf("查询自定义工具失败: %v", err)
+	}
+	return &t, nil
+}
+
+// validateCustomToolInput 校验并补全自定义工具输入
+func validateCustomToolInput(input *CustomToolInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if !customToolNamePattern.MatchString(input.Name) {
+		return fmt.Errorf("工具名称只能包含小写字母、数字和下划线，且以字母开头")
+	}
+	if strings.HasPrefix(input.Name, mcpToolPrefix) {
+		return fmt.Errorf("工具名称不能以 %s 开头，该前缀保留给 MCP 工具", mcpToolPrefix)
+	}
+	for _, builtin := range append(builtinTools(), append(taskTools(), submitPlanTool())...) {
+		if builtin.Name == input.Name {
+			return fmt.Errorf("工具名称 %s 与内置工具冲突", input.Name)
+		}
+	}
+	if strings.TrimSpace(input.Command) == "" {
+		return fmt.Errorf("命令模板不能为空")
+	}
+
+	if strings.TrimSpace(input.Schema) == "" {
+		input.Schema = `{"type": "object", "properties": {}}`
+	}
+	schema, err := ParseJSONSchema(input.Schema)
+	if err != nil {
+		return err
+	}
+	if schema.Type != "" && schema.Type != "object" {
+		return fmt.Errorf("参数定义必须是 object 类型")
+	}
+
+	// 模板中的占位符必须在参数定义中声明
+	for _, m := range templateParamPattern.FindAllStringSubmatch(input.Command, -1) {
+		if _, ok := schema.Properties[m[1]]; !ok {
+			return fmt.Errorf("命令模板中的参数 {{%s}} 未在参数定义中声明", m[1])
+		}
+	}
+	if err := checkPlaceholderQuoting(input.Command); err != nil {
+		return err
+	}
+
+	if input.Timeout <= 0 {
+		input.Timeout = 60
+	}
+	switch input.OutputMode {
+	case "":
+		input.OutputMode = ToolOutputText
+	case ToolOutputText, ToolOutputJSON, ToolOutputLines:
+	default:
+		return fmt.Errorf("不支持的输出解析方式: %s", input.OutputMode)
+	}
+	return nil
+}
+
+// CreateCustomTool 创建自定义工具
+func (a *App) CreateCustomTool(input CustomToolInput) (*CustomTool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if err := validateCustomToolInput(&input); err != nil {
+		return nil, err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO tools (name, description, schema, command, working_dir, timeout, output_mode, output_field, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Description, input.Schema, input.Command, input.WorkingDir,
+		input.Timeout, input.OutputMode, input.OutputField, input.Enabled)
+	if err != nil {
+		log.Printf("创建自定义工具失败: %v", err)
+		return nil, fmt.Errorf("创建自定义工具失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取自定义工具ID失败: %v", err)
+	}
+
+	log.Printf("创建自定义工具成功: %s (ID: %d)", input.Name, id)
+	return a.GetCustomTool(id)
+}
+
+// UpdateCustomTool 更新自定义工具
+func (a *App) UpdateCustomTool(input CustomToolInput) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if err := validateCustomToolInput(&input); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE tools
+		SET name = ?, description = ?, schema = ?, command = ?, working_dir = ?,
+		    timeout = ?, output_mode = ?, output_field = ?, enabled = ?
+		WHERE id = ?
+	`, input.Name, input.Description, input.Schema, input.Command, input.WorkingDir,
+		input.Timeout, input.OutputMode, input.OutputField, input.Enabled, input.ID)
+	if err != nil {
+		log.Printf("更新自定义工具失败: %v", err)
+		return fmt.Errorf("更新自定义工具失败: %v", err)
+	}
+
+	log.Printf("更新自定义工具成功: ID=%d", input.ID)
+	return nil
+}
+
+// DeleteCustomTool 删除自定义工具
+func (a *App) DeleteCustomTool(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	_, err := db.Exec(`DELETE FROM tools WHERE id = ?`, id)
+	if err != nil {
+		log.Printf("删除自定义工具失败: %v", err)
+		return fmt.Errorf("删除自定义工具失败: %v", err)
+	}
+
+	log.Printf("删除自定义工具成功: ID=%d", id)
+	return nil
+}
+
+// GetAvailableTools 获取Agent可选择的全部工具（内置 + 已启用的自定义工具）
+func (a *App) GetAvailableTools() ([]AgentTool, error) {
+	tools := append(builtinTools(), taskTools()...)
+
+	customTools, err := getEnabledCustomTools()
+	if err != nil {
+		return nil, err
+	}
+	for _, ct := range customTools {
+		tools = append(tools, ct.agentTool())
+	}
+	return tools, nil
+}
+
+// agentTool 转换为工具定义
+func (t CustomTool) agentTool() AgentTool {
+	return AgentTool{
+		Name:        t.Name,
+		Description: t.Description,
+		Type:        "cli",
+		Schema:      t.Schema,
+	}
+}
+
+// registerCustomTools 从数据库加载已启用的自定义工具
+func (r *ToolRegistry) registerCustomTools() {
+	if db == nil {
+		return
+	}
+
+	customTools, err := getEnabledCustomTools()
+	if err != nil {
+		log.Printf("加载自定义工具失败: %v", err)
+		return
+	}
+
+	for _, ct := range customTools {
+		// 旧数据中可能存在与内置工具或 MCP 前缀冲突的名称，跳过以免覆盖
+		if _, exists := r.tools[ct.Name]; exists || strings.HasPrefix(ct.Name, mcpToolPrefix) {
+			log.Printf("自定义工具 %s 名称冲突，已跳过注册", ct.Name)
+			continue
+		}
+		if err := r.Register(ct.agentTool()); err != nil {
+			log.Printf("注册自定义工具失败: %v", err)
+			continue
+		}
+		r.custom[ct.Name] = ct
+	}
+}
+
+// checkPlaceholderQuoting 占位符不能写在引号内：参数值会自动加单引号转义，
+// 外面再套引号会使转义失效（例如 '{{q}}' 代入后单引号成对抵消，值又回到了引号外）
+func checkPlaceholderQuoting(template string) error {
+	var quote byte // 当前所在的引号，0 表示不在引号内
+	matches := templateParamPattern.FindAllStringSubmatchIndex(template, -1)
+	next := 0
+	for i := 0; i < len(template); i++ {
+		if next < len(matches) && i == matches[next][0] {
+			if quote != 0 {
+				name := template[matches[next][2]:matches[next][3]]
+				return fmt.Errorf("命令模板中的参数 {{%s}} 不能写在引号内，参数值会自动转义", name)
+			}
+			i = matches[next][1] - 1
+			next++
+			continue
+		}
+
+		c := template[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			// 反斜杠转义下一个字符（单引号内除外），紧跟占位符时会转义掉参数值的开头引号
+			if next < len(matches) && matches[next][0] == i+1 {
+				name := template[matches[next][2]:matches[next][3]]
+				return fmt.Errorf("命令模板中的参数 {{%s}} 不能紧跟在反斜杠之后", name)
+			}
+			i++
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		}
+	}
+	if quote != 0 {
+		return fmt.Errorf("命令模板中的引号未闭合")
+	}
+	return nil
+}
+
+// renderCommandTemplate 将参数代入命令模板，所有参数值都经过 shell 转义
+// 占位符写在引号内的模板拒绝执行（转义会失效）
+func renderCommandTemplate(template string, params map[string]interface{}) (string, error) {
+	if err := checkPlaceholderQuoting(template); err != nil {
+		return "", err
+	}
+	return templateParamPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := templateParamPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := params[name]
+		if !ok || value == nil {
+			return "''"
+		}
+
+		var str string
+		switch v := value.(type) {
+		case string:
+			str = v
+		default:
+			b, _ := json.Marshal(v)
+			str = string(b)
+		}
+		return shellQuote(str)
+	}), nil
+}
+
+// shellQuote 使用单引号转义 shell 参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// executeCustomTool 执行自定义命令行工具
+func (e *ToolExecutor) executeCustomTool(tool CustomTool, inputJSON string) ToolResult {
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(inputJSON), &params); err != nil {
+		return ToolResult{Success: false, Error: fmt.Sprintf("解析输入失败: %v", err)}
+	}
+
+	// 未传入的可选参数使用 Schema 中的默认值
+	if schema, ok := e.registry.GetSchema(tool.Name); ok {
+		for name, prop := range schema.Properties {
+			if _, exists := params[name]; !exists && prop.Default != nil {
+				params[name] = prop.Default
+			}
+		}
+	}
+
+	command, err := renderCommandTemplate(tool.Command, params)
+	if err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
+	}
+
+	workingDir := tool.WorkingDir
+	if workingDir == "" {
+		workingDir = e.workingDir
+	} else if !filepath.IsAbs(workingDir) && e.workingDir != "" {
+		workingDir = filepath.Join(e.workingDir, workingDir)
+	}
+
+	timeout := time.Duration(tool.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Printf("执行自定义工具: %s, 命令: %s", tool.Name, command)
+	cmd := shellCommand(ctx, command)
+	if workingDir != "" {
+		cmd.Dir = workingDir
+	}
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return ToolResult{
+			Success: false,
+			Output:  string(output),
+			Error:   fmt.Sprintf("命令执行超时 (%d秒)", tool.Timeout),
+		}
+	}
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Output:  string(output),
+			Error:   fmt.Sprintf("命令执行失败: %v", err),
+		}
+	}
+
+	parsed, err := parseToolOutput(string(output), tool.OutputMode, tool.OutputField)
+	if err != nil {
+		return ToolResult{Success: false, Output: string(output), Error: err.Error()}
+	}
+	return ToolResult{Success: true, Output: parsed}
+}
+
+// parseToolOutput 按输出解析方式处理命令输出
+func parseToolOutput(output, mode, field string) (string, error) {
+	switch mode {
+	case ToolOutputJSON:
+		var value interface{}
+		if err := json.Unmarshal([]byte(output), &value); err != nil {
+			return "", fmt.Errorf("输出不是合法的 JSON: %v", err)
+		}
+		if field != "" {
+			for _, key := range strings.Split(field, ".") {
+				obj, ok := value.(map[string]interface{})
+				if !ok {
+					return "", fmt.Errorf("输出中不存在字段 %s", field)
+				}
+				if value, ok = obj[key]; !ok {
+					return "", fmt.Errorf("输出中不存在字段 %s", field)
+				}
+			}
+		}
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		pretty, _ := json.MarshalIndent(value, "", "  ")
+		return string(pretty), nil
+	case ToolOutputLines:
+		var lines []string
+		for _, line := range strings.Split(output, "\n") {
+			if line = strings.TrimRight(line, "\r "); strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	default:
+		return output, nil
+	}
+}
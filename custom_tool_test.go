@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPlaceholderQuoting(t *testing.T) {
+	cases := []struct {
+		template string
+		valid    bool
+	}{
+		{`grep -rn {{q}} .`, true},
+		{`grep --include={{glob}} -e {{q}} "src dir"`, true},
+		{`echo 'it''s' {{q}}`, true},
+		{`echo "a \" b" {{q}}`, true},
+		{`grep '{{q}}' f`, false},
+		{`grep "{{q}}" f`, false},
+		{`grep "prefix {{q}} suffix" f`, false},
+		{`echo 'a' "b {{q}}"`, false},
+		{`echo \{{q}}`, false},
+		{`echo 'unterminated {{q}}`, false},
+		{`echo "unterminated`, false},
+	}
+	for _, c := range cases {
+		err := checkPlaceholderQuoting(c.template)
+		if (err == nil) != c.valid {
+			t.Errorf("%s: 期望 valid=%v，实际错误: %v", c.template, c.valid, err)
+		}
+	}
+}
+
+func TestValidateCustomToolRejectsQuotedPlaceholder(t *testing.T) {
+	input := CustomToolInput{
+		Name:    "search_code",
+		Command: `grep -rn '{{q}}' .`,
+		Schema:  `{"type": "object", "properties": {"q": {"type": "string"}}, "required": ["q"]}`,
+	}
+	if err := validateCustomToolInput(&input); err == nil || !strings.Contains(err.Error(), "引号") {
+		t.Fatalf("占位符写在引号内应校验失败，实际: %v", err)
+	}
+}
+
+func TestValidateCustomToolRejectsReservedNames(t *testing.T) {
+	for _, name := range []string{ToolShell, ToolComplete, ToolCreateTask, mcpToolPrefix + "fs__read"} {
+		input := CustomToolInput{Name: name, Command: "echo hi"}
+		if err := validateCustomToolInput(&input); err == nil {
+			t.Errorf("名称 %s 应被拒绝", name)
+		}
+	}
+	input := CustomToolInput{Name: "my_tool", Command: "echo hi"}
+	if err := validateCustomToolInput(&input); err != nil {
+		t.Fatalf("普通名称应通过校验: %v", err)
+	}
+}
+
+func TestRenderCommandTemplate(t *testing.T) {
+	command, err := renderCommandTemplate(`echo {{a}} {{b}} {{missing}}`, map[string]interface{}{
+		"a": "it's; rm -rf /",
+		"b": 3,
+	})
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	want := `echo 'it'\''s; rm -rf /' '3' ''`
+	if command != want {
+		t.Fatalf("渲染结果为 %s，期望 %s", command, want)
+	}
+
+	if _, err := renderCommandTemplate(`grep '{{q}}' f`, map[string]interface{}{"q": "x"}); err == nil {
+		t.Fatalf("占位符写在引号内的模板应拒绝渲染")
+	}
+}
+
+func TestExecuteCustomToolDoesNotInject(t *testing.T) {
+	dir := t.TempDir()
+	executor := NewToolExecutor(dir)
+	tool := CustomTool{Name: "echo_arg", Command: `printf '%s\n' {{text}}`, Schema: `{"type": "object", "properties": {"text": {"type": "string"}}}`, Timeout: 5}
+	if err := executor.registry.Register(tool.agentTool()); err != nil {
+		t.Fatalf("注册工具失败: %v", err)
+	}
+
+	payload := `'; touch pwned; echo '$(touch pwned2)`
+	result := executor.executeCustomTool(tool, `{"text": "`+strings.ReplaceAll(payload, `"`, `\"`)+`"}`)
+	if !result.Success {
+		t.Fatalf("执行失败: %+v", result)
+	}
+	if strings.TrimSpace(result.Output) != payload {
+		t.Fatalf("参数值应原样传给命令，实际输出: %q", result.Output)
+	}
+	listing := executor.executeListFiles(ListFilesInput{Path: "."})
+	if strings.Contains(listing.Output, "pwned") {
+		t.Fatalf("参数值被当作命令执行: %s", listing.Output)
+	}
+
+	// 旧数据中引号内的占位符在执行时被拒绝
+	tool.Command = `printf '%s\n' '{{text}}'`
+	if result := executor.executeCustomTool(tool, `{"text": "x"}`); result.Success {
+		t.Fatalf("占位符写在引号内的模板应拒绝执行")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,11 @@ var (
 	dbErr  error
 )
 
+// sqlExecer 数据库连接或事务，供需要在事务中复用的写入函数使用
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 // getConfigDir 获取配置目录
 func getConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -46,41 +52,57 @@ func InitDB() error {
 			return
 		}
 
-		dbPath := filepath.Join(configDir, "workbench.db")
-		log.Printf("数据库路径: %s", dbPath)
+		dbErr = openDB(filepath.Join(configDir, "workbench.db"))
+	})
 
-		// 使用 WAL 模式和超时设置
-		dsn := fmt.Sprintf("%s?_busy_timeout=5000&_journal_mode=WAL", dbPath)
-		db, err = sql.Open("sqlite", dsn)
-		if err != nil {
-			dbErr = fmt.Errorf("打开数据库失败: %v", err)
-			log.Printf("数据库初始化失败: %v", dbErr)
-			return
-		}
+	return dbErr
+}
 
-		// 设置连接池参数
-		db.SetMaxOpenConns(1)
-		db.SetMaxIdleConns(1)
-		db.SetConnMaxLifetime(time.Hour)
+// InitDBAt 使用指定路径初始化数据库（用于离线测试和回放，可重复调用以切换数据库）
+func InitDBAt(dbPath string) error {
+	if err := CloseDB(); err != nil {
+		return err
+	}
+	db = nil
+	return openDB(dbPath)
+}
 
-		// 验证连接
-		if err := db.Ping(); err != nil {
-			dbErr = fmt.Errorf("数据库连接失败: %v", err)
-			log.Printf("数据库初始化失败: %v", dbErr)
-			return
-		}
+// openDB 打开数据库并创建表
+func openDB(dbPath string) error {
+	log.Printf("数据库路径: %s", dbPath)
 
-		// 创建表
-		if err := createTables(); err != nil {
-			dbErr = fmt.Errorf("创建表失败: %v", err)
-			log.Printf("数据库初始化失败: %v", dbErr)
-			return
-		}
+	// 使用 WAL 模式和超时设置，并开启外键约束（SQLite 默认关闭，否则 ON DELETE 级联不生效）
+	dsn := fmt.Sprintf("%s?_busy_timeout=5000&_journal_mode=WAL&_pragma=foreign_keys(1)", dbPath)
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		err = fmt.Errorf("打开数据库失败: %v", err)
+		log.Printf("数据库初始化失败: %v", err)
+		return err
+	}
 
-		log.Println("数据库初始化成功")
-	})
+	// 设置连接池参数
+	conn.SetMaxOpenConns(1)
+	conn.SetMaxIdleConns(1)
+	conn.SetConnMaxLifetime(time.Hour)
+
+	// 验证连接
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		err = fmt.Errorf("数据库连接失败: %v", err)
+		log.Printf("数据库初始化失败: %v", err)
+		return err
+	}
+	db = conn
 
-	return dbErr
+	// 创建表
+	if err := createTables(); err != nil {
+		err = fmt.Errorf("创建表失败: %v", err)
+		log.Printf("数据库初始化失败: %v", err)
+		return err
+	}
+
+	log.Println("数据库初始化成功")
+	return nil
 }
 
 // createTables 创建数据库表
@@ -129,6 +151,8 @@ func createTables() error {
 			tools TEXT DEFAULT '[]',
 			working_dir TEXT DEFAULT '',
 			max_retries INTEGER DEFAULT 3,
+			fallbacks TEXT DEFAULT '[]',
+			task_access TEXT DEFAULT 'none',
 			enabled INTEGER DEFAULT 1,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (provider_id) REFERENCES model_providers(id) ON DELETE SET NULL
@@ -155,8 +179,14 @@ func createTables() error {
 			status TEXT DEFAULT 'pending',
 			actual_start TEXT,
 			actual_hours REAL DEFAULT 0,
+			parent_id INTEGER,
+			completion_rule TEXT DEFAULT 'manual',
+			recurrence_id INTEGER,
+			occurrence_date TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE SET NULL
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE SET NULL,
+			FOREIGN KEY (parent_id) REFERENCES tasks(id) ON DELETE SET NULL,
+			FOREIGN KEY (recurrence_id) REFERENCES task_recurrences(id) ON DELETE SET NULL
 		)
 	`)
 	if err != nil {
@@ -176,6 +206,17 @@ func createTables() error {
 		"ALTER TABLE agents ADD COLUMN tools TEXT DEFAULT '[]'",
 		"ALTER TABLE agents ADD COLUMN working_dir TEXT DEFAULT ''",
 		"ALTER TABLE agents ADD COLUMN max_retries INTEGER DEFAULT 3",
+		"ALTER TABLE agents ADD COLUMN fallbacks TEXT DEFAULT '[]'",
+		"ALTER TABLE agent_steps ADD COLUMN model TEXT DEFAULT ''",
+		"ALTER TABLE agents ADD COLUMN task_access TEXT DEFAULT 'none'",
+		"ALTER TABLE tasks ADD COLUMN parent_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL",
+		"ALTER TABLE tasks ADD COLUMN completion_rule TEXT DEFAULT 'manual'",
+		"ALTER TABLE task_conversations ADD COLUMN parent_conversation_id INTEGER",
+		"ALTER TABLE task_conversations ADD COLUMN parent_step_id INTEGER",
+		"ALTER TABLE tasks ADD COLUMN recurrence_id INTEGER",
+		"ALTER TABLE tasks ADD COLUMN occurrence_date TEXT",
+		"ALTER TABLE pipeline_runs ADD COLUMN next_attempt INTEGER DEFAULT 1",
+		"ALTER TABLE pipeline_runs ADD COLUMN next_note TEXT DEFAULT ''",
 	}
 	for _, sql := range migrationColumns {
 		db.Exec(sql) // 忽略错误，因为列可能已存在
@@ -193,6 +234,7 @@ func createTables() error {
 			observation TEXT DEFAULT '',
 			status TEXT DEFAULT 'pending',
 			error TEXT DEFAULT '',
+			model TEXT DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (conversation_id) REFERENCES task_conversations(id) ON DELETE CASCADE
 		)
@@ -206,6 +248,337 @@ func createTables() error {
 		return fmt.Errorf("创建 steps conversation_id 索引失败: %v", err)
 	}
 
+	// 自定义工具表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS tools (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT DEFAULT '',
+			schema TEXT DEFAULT '{}',
+			command TEXT NOT NULL,
+			working_dir TEXT DEFAULT '',
+			timeout INTEGER DEFAULT 60,
+			output_mode TEXT DEFAULT 'text',
+			output_field TEXT DEFAULT '',
+			enabled INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 tools 表失败: %v", err)
+	}
+
+	// MCP 服务器表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS mcp_servers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			transport TEXT DEFAULT 'stdio',
+			command TEXT DEFAULT '',
+			args TEXT DEFAULT '[]',
+			env TEXT DEFAULT '{}',
+			url TEXT DEFAULT '',
+			enabled INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 mcp_servers 表失败: %v", err)
+	}
+
+	// 子任务计划表（planner Agent 生成，审核后创建子任务）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_plans (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			conversation_id INTEGER NOT NULL,
+			task_id INTEGER NOT NULL,
+			summary TEXT DEFAULT '',
+			items TEXT DEFAULT '[]',
+			status TEXT DEFAULT 'pending',
+			created_task_ids TEXT DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			reviewed_at DATETIME,
+			FOREIGN KEY (conversation_id) REFERENCES task_conversations(id) ON DELETE CASCADE,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 task_plans 表失败: %v", err)
+	}
+
+	// 流水线定义表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pipelines (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT DEFAULT '',
+			stages TEXT DEFAULT '[]',
+			enabled INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 pipelines 表失败: %v", err)
+	}
+
+	// 流水线运行表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pipeline_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pipeline_id INTEGER NOT NULL,
+			task_id INTEGER NOT NULL,
+			status TEXT DEFAULT 'running',
+			current_stage INTEGER DEFAULT 0,
+			context TEXT DEFAULT '',
+			error TEXT DEFAULT '',
+			next_attempt INTEGER DEFAULT 1,
+			next_note TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (pipeline_id) REFERENCES pipelines(id) ON DELETE CASCADE,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 pipeline_runs 表失败: %v", err)
+	}
+
+	// 流水线阶段运行表（每次尝试对应一个会话）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pipeline_stage_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			stage_index INTEGER NOT NULL,
+			stage_name TEXT DEFAULT '',
+			agent_id INTEGER NOT NULL,
+			conversation_id INTEGER NOT NULL,
+			attempt INTEGER DEFAULT 1,
+			status TEXT DEFAULT 'running',
+			summary TEXT DEFAULT '',
+			error TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			finished_at DATETIME,
+			FOREIGN KEY (run_id) REFERENCES pipeline_runs(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 pipeline_stage_runs 表失败: %v", err)
+	}
+
+	// 流水线产物表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pipeline_artifacts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			stage_run_id INTEGER NOT NULL,
+			stage_name TEXT DEFAULT '',
+			kind TEXT DEFAULT 'summary',
+			name TEXT DEFAULT '',
+			content TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (run_id) REFERENCES pipeline_runs(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 pipeline_artifacts 表失败: %v", err)
+	}
+
+	// 任务依赖表（完成-开始：task_id 在 depends_on_id 完成后才能开始）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_dependencies (
+			task_id INTEGER NOT NULL,
+			depends_on_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (task_id, depends_on_id),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+			FOREIGN KEY (depends_on_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 task_dependencies 表失败: %v", err)
+	}
+
+	// 重复任务规则表（任务模板 + RRULE，实例生成到 tasks）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_recurrences (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			project_id INTEGER,
+			name TEXT NOT NULL,
+			description TEXT DEFAULT '',
+			start_time TEXT,
+			end_time TEXT,
+			hours REAL DEFAULT 0,
+			priority TEXT DEFAULT 'medium',
+			urgency TEXT DEFAULT 'medium',
+			rrule TEXT NOT NULL,
+			start_date TEXT NOT NULL,
+			end_date TEXT DEFAULT '',
+			generated_until TEXT DEFAULT '',
+			active INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE SET NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 task_recurrences 表失败: %v", err)
+	}
+
+	// 重复任务例外表（跳过的日期不再生成实例）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS recurrence_exceptions (
+			recurrence_id INTEGER NOT NULL,
+			date TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (recurrence_id, date),
+			FOREIGN KEY (recurrence_id) REFERENCES task_recurrences(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 recurrence_exceptions 表失败: %v", err)
+	}
+
+	// 工时记录表（时间为本地时间 YYYY-MM-DD HH:MM:SS，end_at 为空表示计时中）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS time_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			start_at TEXT NOT NULL,
+			end_at TEXT,
+			source TEXT DEFAULT 'timer',
+			paused INTEGER DEFAULT 0,
+			note TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 time_entries 表失败: %v", err)
+	}
+
+	// 任务改期记录表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_reschedules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			from_date TEXT NOT NULL,
+			to_date TEXT,
+			reason TEXT DEFAULT 'manual',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 task_reschedules 表失败: %v", err)
+	}
+
+	// 设置表（键值对，值为 JSON）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL DEFAULT '{}',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 settings 表失败: %v", err)
+	}
+
+	// 工作日历表（特定日期的工时、节假日和请假）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS calendar_days (
+			date TEXT PRIMARY KEY,
+			hours REAL DEFAULT 0,
+			kind TEXT DEFAULT 'holiday',
+			name TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 calendar_days 表失败: %v", err)
+	}
+
+	// 保存的视图表（命名的任务查询条件）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_views (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			filter TEXT NOT NULL DEFAULT '{}',
+			position INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 saved_views 表失败: %v", err)
+	}
+
+	// 标签表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			color TEXT DEFAULT '#86909c',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 tags 表失败: %v", err)
+	}
+
+	// 任务标签关联表
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_tags (
+			task_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (task_id, tag_id),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 task_tags 表失败: %v", err)
+	}
+
+	// 审计日志表（任务和项目的字段级修改记录）
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			changes TEXT NOT NULL DEFAULT '[]',
+			actor_type TEXT DEFAULT 'user',
+			conversation_id INTEGER,
+			client TEXT DEFAULT '',
+			undone INTEGER DEFAULT 0,
+			undo_of INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("创建 audit_log 表失败: %v", err)
+	}
+
+	// 迁移：旧的实际工时（actual_start + actual_hours）转为工时记录
+	_, err = db.Exec(`
+		INSERT INTO time_entries (task_id, start_at, end_at, source)
+		SELECT id, start_at, datetime(start_at, '+' || CAST(ROUND(actual_hours * 60) AS INTEGER) || ' minutes'), 'migrated'
+		FROM (
+			SELECT id, actual_hours,
+				COALESCE(datetime(day || ' ' || actual_start), datetime(day || ' 09:00')) AS start_at
+			FROM (
+				SELECT id, actual_hours, actual_start, COALESCE(date, date(created_at)) AS day
+				FROM tasks t
+				WHERE actual_hours > 0
+				  AND NOT EXISTS (SELECT 1 FROM time_entries e WHERE e.task_id = t.id)
+			)
+		)
+		WHERE start_at IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("迁移实际工时失败: %v", err)
+	}
+
 	// 初始化默认模型提供商
 	defaultProviders := []struct {
 		name    string
@@ -232,6 +605,11 @@ func createTables() error {
 		return fmt.Errorf("创建 status 索引失败: %v", err)
 	}
 
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id)`)
+	if err != nil {
+		return fmt.Errorf("创建 parent_id 索引失败: %v", err)
+	}
+
 	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id)`)
 	if err != nil {
 		return fmt.Errorf("创建 project_id 索引失败: %v", err)
@@ -242,6 +620,37 @@ func createTables() error {
 		return fmt.Errorf("创建 deadline 索引失败: %v", err)
 	}
 
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_time_entries_task ON time_entries(task_id)`)
+	if err != nil {
+		return fmt.Errorf("创建 time_entries 索引失败: %v", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_time_entries_start ON time_entries(start_at)`)
+	if err != nil {
+		return fmt.Errorf("创建 time_entries 索引失败: %v", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_task_reschedules_task ON task_reschedules(task_id)`)
+	if err != nil {
+		return fmt.Errorf("创建 task_reschedules 索引失败: %v", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tag_id)`)
+	if err != nil {
+		return fmt.Errorf("创建 task_tags 索引失败: %v", err)
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id)`)
+	if err != nil {
+		return fmt.Errorf("创建 audit_log 索引失败: %v", err)
+	}
+
+	// 同一重复规则每个日期只生成一个实例
+	_, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_occurrence ON tasks(recurrence_id, occurrence_date)`)
+	if err != nil {
+		return fmt.Errorf("创建 occurrence 索引失败: %v", err)
+	}
+
 	// AI会话表
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS task_conversations (
@@ -249,6 +658,8 @@ func createTables() error {
 			task_id INTEGER NOT NULL,
 			agent_id INTEGER NOT NULL,
 			status TEXT DEFAULT 'active',
+			parent_conversation_id INTEGER,
+			parent_step_id INTEGER,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
@@ -287,6 +698,88 @@ func createTables() error {
 		return fmt.Errorf("创建 conversation_id 索引失败: %v", err)
 	}
 
+	if err := cleanOrphanRows(); err != nil {
+		return err
+	}
+
+	if err := createSearchIndexes(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// cleanOrphanRows 清理外键约束开启前遗留的孤儿记录（按依赖顺序，先父后子）
+func cleanOrphanRows() error {
+	statements := []string{
+		`DELETE FROM task_conversations WHERE task_id NOT IN (SELECT id FROM tasks) OR agent_id NOT IN (SELECT id FROM agents)`,
+		`DELETE FROM conversation_messages WHERE conversation_id NOT IN (SELECT id FROM task_conversations)`,
+		`DELETE FROM agent_steps WHERE conversation_id NOT IN (SELECT id FROM task_conversations)`,
+		`DELETE FROM task_plans WHERE task_id NOT IN (SELECT id FROM tasks) OR conversation_id NOT IN (SELECT id FROM task_conversations)`,
+		`DELETE FROM pipeline_runs WHERE task_id NOT IN (SELECT id FROM tasks) OR pipeline_id NOT IN (SELECT id FROM pipelines)`,
+		`DELETE FROM pipeline_stage_runs WHERE run_id NOT IN (SELECT id FROM pipeline_runs)`,
+		`DELETE FROM pipeline_artifacts WHERE run_id NOT IN (SELECT id FROM pipeline_runs)`,
+		`DELETE FROM task_dependencies WHERE task_id NOT IN (SELECT id FROM tasks) OR depends_on_id NOT IN (SELECT id FROM tasks)`,
+		`DELETE FROM time_entries WHERE task_id NOT IN (SELECT id FROM tasks)`,
+		`DELETE FROM task_reschedules WHERE task_id NOT IN (SELECT id FROM tasks)`,
+		`DELETE FROM task_tags WHERE task_id NOT IN (SELECT id FROM tasks) OR tag_id NOT IN (SELECT id FROM tags)`,
+		`UPDATE tasks SET parent_id = NULL WHERE parent_id IS NOT NULL AND parent_id NOT IN (SELECT id FROM tasks)`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("清理孤儿记录失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// searchIndexes 全文搜索索引（trigram 分词，支持中文子串匹配），由触发器与源表保持同步
+var searchIndexes = []struct {
+	name    string
+	table   string
+	columns []string
+}{
+	{"tasks_fts", "tasks", []string{"name", "description"}},
+	{"projects_fts", "projects", []string{"name", "description"}},
+	{"messages_fts", "conversation_messages", []string{"content"}},
+}
+
+// createSearchIndexes 创建全文搜索索引和同步触发器，新建索引时从源表重建
+func createSearchIndexes() error {
+	for _, idx := range searchIndexes {
+		var exists int
+		db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, idx.name).Scan(&exists)
+
+		cols := strings.Join(idx.columns, ", ")
+		newCols := "new." + strings.Join(idx.columns, ", new.")
+		oldCols := "old." + strings.Join(idx.columns, ", old.")
+		statements := []string{
+			fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id', tokenize='trigram')`,
+				idx.name, cols, idx.table),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_ai AFTER INSERT ON %s BEGIN
+				INSERT INTO %s(rowid, %s) VALUES (new.id, %s);
+			END`, idx.name, idx.table, idx.name, cols, newCols),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_ad AFTER DELETE ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);
+			END`, idx.name, idx.table, idx.name, idx.name, cols, oldCols),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_au AFTER UPDATE OF %s ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);
+				INSERT INTO %s(rowid, %s) VALUES (new.id, %s);
+			END`, idx.name, cols, idx.table, idx.name, idx.name, cols, oldCols, idx.name, cols, newCols),
+		}
+		for _, stmt := range statements {
+			if _, err := db.Exec(stmt); err != nil {
+				return fmt.Errorf("创建 %s 全文索引失败: %v", idx.name, err)
+			}
+		}
+
+		if exists == 0 {
+			if _, err := db.Exec(fmt.Sprintf(`INSERT INTO %s(%s) VALUES ('rebuild')`, idx.name, idx.name)); err != nil {
+				return fmt.Errorf("重建 %s 全文索引失败: %v", idx.name, err)
+			}
+			log.Printf("已建立全文索引: %s", idx.name)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// 测试时不输出业务日志
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// openTestDB 使用临时目录中的数据库，测试结束时关闭
+func openTestDB(t *testing.T) *App {
+	t.Helper()
+	if err := InitDBAt(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDB()
+		db = nil
+	})
+	return &App{}
+}
+
+// TestDeleteTaskRemovesDependents 删除任务时由外键级联清理会话、计划和流水线运行
+func TestDeleteTaskRemovesDependents(t *testing.T) {
+	a := openTestDB(t)
+	plan := submitTestPlan(t, a, []PlanItem{{Name: "子步骤", Hours: 1}})
+	taskID := plan.TaskID
+
+	child, err := a.CreateTask(TaskInput{Name: "子任务", Hours: 1, ParentID: &taskID})
+	if err != nil {
+		t.Fatalf("创建子任务失败: %v", err)
+	}
+	result, err := db.Exec(`INSERT INTO pipelines (name) VALUES ('测试流水线')`)
+	if err != nil {
+		t.Fatalf("创建流水线失败: %v", err)
+	}
+	pipelineID, _ := result.LastInsertId()
+	result, err = db.Exec(`INSERT INTO pipeline_runs (pipeline_id, task_id) VALUES (?, ?)`, pipelineID, taskID)
+	if err != nil {
+		t.Fatalf("创建流水线运行失败: %v", err)
+	}
+	runID, _ := result.LastInsertId()
+	if _, err := db.Exec(`INSERT INTO pipeline_stage_runs (run_id, stage_index, agent_id, conversation_id)
+		VALUES (?, 0, 1, ?)`, runID, plan.ConversationID); err != nil {
+		t.Fatalf("创建阶段运行失败: %v", err)
+	}
+
+	if err := a.DeleteTask(taskID); err != nil {
+		t.Fatalf("删除任务失败: %v", err)
+	}
+
+	for _, table := range []string{"task_plans", "task_conversations", "conversation_messages", "pipeline_runs", "pipeline_stage_runs"} {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&count); err != nil {
+			t.Fatalf("查询 %s 失败: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("删除任务后 %s 仍有 %d 条记录", table, count)
+		}
+	}
+	remaining, err := a.GetTask(child.ID)
+	if err != nil || remaining.ParentID != nil {
+		t.Fatalf("子任务应保留并上移为顶层任务: %+v, %v", remaining, err)
+	}
+}
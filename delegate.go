@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+const (
+	maxDelegateDepth    = 2 // 委派最多嵌套层数（子会话的子会话）
+	maxDelegateParallel = 4 // 同时执行的子会话上限
+)
+
+// delegateChild 一个子会话及其执行结果
+type delegateChild struct {
+	task           DelegateTask
+	agent          *Agent
+	conversationID int64
+	status         string
+	result         string
+}
+
+// executeDelegate 创建子会话执行委派的子目标，等待全部结束后汇总结果
+func (r *ReActExecutor) executeDelegate(stepID int64, raw json.RawMessage) ToolResult {
+	var input DelegateInput
+	if err := decodeToolInput(string(raw), &input); err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
+	}
+
+	tasks := input.Tasks
+	if len(tasks) == 0 {
+		tasks = []DelegateTask{input.DelegateTask}
+	}
+
+	depth, err := conversationDepth(r.conversationID)
+	if err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
+	}
+	if depth >= maxDelegateDepth {
+		return ToolResult{Success: false, Error: fmt.Sprintf("委派层数已达上限 (%d)，请直接完成该目标", maxDelegateDepth)}
+	}
+
+	taskID, err := getConversationTaskID(r.conversationID)
+	if err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
+	}
+
+	// 先校验全部子目标，避免部分子会话已启动后才发现错误
+	children := make([]*delegateChild, len(tasks))
+	for i, t := range tasks {
+		t.Goal = strings.TrimSpace(t.Goal)
+		if t.Goal == "" {
+			return ToolResult{Success: false, Error: fmt.Sprintf("第 %d 个委派缺少 goal", i+1)}
+		}
+		agent, err := r.app.findEnabledAgentByName(t.Agent)
+		if err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		children[i] = &delegateChild{task: t, agent: agent}
+	}
+
+	for _, child := range children {
+		convID, _, err := r.app.createConversation(StartConversationInput{
+			TaskID:       taskID,
+			AgentID:      child.agent.ID,
+			ExtraContext: buildDelegateContext(r.agent.Name, child.task),
+		})
+		if err != nil {
+			return ToolResult{Success: false, Error: fmt.Sprintf("创建子会话失败: %v", err)}
+		}
+		_, err = db.Exec(`UPDATE task_conversations SET parent_conversation_id = ?, parent_step_id = ? WHERE id = ?`,
+			r.conversationID, stepID, convID)
+		if err != nil {
+			log.Printf("保存委派关系失败: %v", err)
+		}
+		child.conversationID = convID
+	}
+
+	parallel := input.MaxParallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+	if parallel > maxDelegateParallel {
+		parallel = maxDelegateParallel
+	}
+
+	log.Printf("委派子会话: conversationID=%d, 子会话数=%d, 并行=%d", r.conversationID, len(children), parallel)
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, child := range children {
+		wg.Add(1)
+		go func(child *delegateChild) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r.app.runAIConversation(child.conversationID, child.agent)
+			child.status, child.result = delegateOutcome(child.conversationID)
+		}(child)
+	}
+	wg.Wait()
+
+	return buildDelegateResult(children)
+}
+
+// findEnabledAgentByName 按名称查找已启用的Agent
+func (a *App) findEnabledAgentByName(name string) (*Agent, error) {
+	agents, err := a.GetEnabledAgents()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i := range agents {
+		if agents[i].Name == strings.TrimSpace(name) {
+			return &agents[i], nil
+		}
+		names = append(names, agents[i].Name)
+	}
+	return nil, fmt.Errorf("Agent不存在或未启用: %s，可用的Agent: %s", name, strings.Join(names, ", "))
+}
+
+// conversationDepth 计算会话的委派层数（顶层会话为0）
+func conversationDepth(conversationID int64) (int, error) {
+	depth := 0
+	current := conversationID
+	for depth <= maxDelegateDepth {
+		var parent *int64
+		err := db.QueryRow(`SELECT parent_conversation_id FROM task_conversations WHERE id = ?`, current).Scan(&parent)
+		if err != nil {
+			return 0, fmt.Errorf("会话不存在: %v", err)
+		}
+		if parent == nil {
+			break
+		}
+		depth++
+		current = *parent
+	}
+	return depth, nil
+}
+
+// buildDelegateContext 子会话的上下文说明
+func buildDelegateContext(parentAgent string, task DelegateTask) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## 委派目标（来自 %s）\n%s\n", parentAgent, task.Goal))
+	if task.Context != "" {
+		sb.WriteString("\n## 背景\n" + task.Context + "\n")
+	}
+	sb.WriteString("\n只需完成上述目标，完成后调用 complete，在 summary 中给出结果。")
+	return sb.String()
+}
+
+// delegateOutcome 获取子会话的结束状态和结果
+func delegateOutcome(conversationID int64) (string, string) {
+	var status string
+	db.QueryRow(`SELECT status FROM task_conversations WHERE id = ?`, conversationID).Scan(&status)
+
+	switch status {
+	case ConversationStatusCompleted:
+		return status, conversationSummary(conversationID)
+	case ConversationStatusWaitingUser:
+		var question string
+		db.QueryRow(`
+			SELECT content FROM conversation_messages
+			WHERE conversation_id = ? AND message_type = ?
+			ORDER BY id DESC LIMIT 1
+		`, conversationID, MessageTypeQuestion).Scan(&question)
+		return status, "子Agent需要用户输入: " + question
+	default:
+		return status, conversationError(conversationID)
+	}
+}
+
+// buildDelegateResult 汇总子会话结果作为观察结果
+func buildDelegateResult(children []*delegateChild) ToolResult {
+	var sb strings.Builder
+	success := true
+	for i, child := range children {
+		if child.status != ConversationStatusCompleted {
+			success = false
+		}
+		sb.WriteString(fmt.Sprintf("## 子目标 %d（%s，会话 %d，状态 %s）\n%s\n%s\n\n",
+			i+1, child.agent.Name, child.conversationID, child.status, child.task.Goal, child.result))
+	}
+
+	result := ToolResult{Success: success, Output: strings.TrimSpace(sb.String())}
+	if !success {
+		result.Error = "部分子目标未完成"
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyTransport 记录同时进行的请求数，每个请求延迟返回以便观察并发
+type concurrencyTransport struct {
+	inner  http.RoundTripper
+	delay  time.Duration
+	mu     sync.Mutex
+	active int
+	peak   int
+}
+
+func (c *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.peak {
+		c.peak = c.active
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}()
+
+	time.Sleep(c.delay)
+	return c.inner.RoundTrip(req)
+}
+
+func TestDelegateRunsChildrenWithParallelCap(t *testing.T) {
+	a := openTestDB(t)
+	replay, err := NewReplayTransport(filepath.Join("testdata", "llm_replay", "delegate_parallel"))
+	if err != nil {
+		t.Fatalf("加载回放数据失败: %v", err)
+	}
+	transport := &concurrencyTransport{inner: replay, delay: 100 * time.Millisecond}
+	old := SetLLMTransport(transport)
+	defer SetLLMTransport(old)
+
+	provider := insertTestProvider(t, a, "replay", "http://replay.invalid/v1")
+	if _, err := a.CreateAgent(AgentInput{
+		Name: "助手", ProviderID: &provider.ID, Model: "scripted-model", WorkingDir: t.TempDir(), Enabled: true,
+	}); err != nil {
+		t.Fatalf("创建Agent失败: %v", err)
+	}
+	convID, agent := startTestConversation(t, a, AgentInput{Name: "主管", ProviderID: &provider.ID, Tools: `["delegate"]`})
+
+	a.runAIConversation(convID, agent)
+
+	status, steps, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("会话状态为 %s，期望 completed", status)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("回放数据应已用完，剩余 %d", replay.Remaining())
+	}
+	if transport.peak != 2 {
+		t.Fatalf("子会话最多应同时执行2个，实际峰值 %d", transport.peak)
+	}
+
+	// 委派步骤的观察结果汇总了全部子目标
+	if len(steps) != 2 || steps[0].Action != ToolDelegate || steps[0].Status != StepStatusSuccess {
+		t.Fatalf("步骤不符合预期: %+v", steps)
+	}
+	for _, goal := range []string{"整理第1章笔记", "整理第4章笔记", "章节笔记已整理"} {
+		if !strings.Contains(steps[0].Observation, goal) {
+			t.Fatalf("委派结果应包含 %s: %s", goal, steps[0].Observation)
+		}
+	}
+	children, err := a.GetChildConversations(convID)
+	if err != nil || len(children) != 4 {
+		t.Fatalf("应创建4个子会话: %v %d", err, len(children))
+	}
+}
+
+func TestDelegateRejectsBeyondMaxDepth(t *testing.T) {
+	a := openTestDB(t)
+	provider := insertTestProvider(t, a, "scripted", "http://scripted.invalid/v1")
+	convID, agent := startTestConversation(t, a, AgentInput{Name: "助手", ProviderID: &provider.ID, Tools: `["delegate"]`})
+
+	taskID, _ := getConversationTaskID(convID)
+
+	// 构造 maxDelegateDepth 层的委派链，最深的会话不能再委派
+	current := convID
+	for i := 0; i < maxDelegateDepth; i++ {
+		child, _, err := a.createConversation(StartConversationInput{TaskID: taskID, AgentID: agent.ID})
+		if err != nil {
+			t.Fatalf("创建会话失败: %v", err)
+		}
+		db.Exec(`UPDATE task_conversations SET parent_conversation_id = ? WHERE id = ?`, current, child)
+		current = child
+	}
+
+	executor := NewReActExecutor(a, current, agent, provider)
+	raw, _ := json.Marshal(DelegateInput{DelegateTask: DelegateTask{Agent: "助手", Goal: "再拆一层"}})
+	result := executor.executeDelegate(0, raw)
+	if result.Success || !strings.Contains(result.Error, "上限") {
+		t.Fatalf("超过委派层数应失败: %+v", result)
+	}
+}
+
+func TestStopConversationStopsChildren(t *testing.T) {
+	a := openTestDB(t)
+	convID, agent := startTestConversation(t, a, AgentInput{})
+	taskID, _ := getConversationTaskID(convID)
+	var children []int64
+	for i := 0; i < 2; i++ {
+		child, _, err := a.createConversation(StartConversationInput{TaskID: taskID, AgentID: agent.ID})
+		if err != nil {
+			t.Fatalf("创建会话失败: %v", err)
+		}
+		db.Exec(`UPDATE task_conversations SET parent_conversation_id = ? WHERE id = ?`, convID, child)
+		children = append(children, child)
+	}
+	db.Exec(`UPDATE task_conversations SET status = ? WHERE id = ?`, ConversationStatusCompleted, children[1])
+
+	if err := a.StopConversation(convID); err != nil {
+		t.Fatalf("停止会话失败: %v", err)
+	}
+	if !conversationStopped(children[0]) {
+		t.Fatal("执行中的子会话应被一并停止")
+	}
+	if status, _, _ := conversationResult(t, a, children[1]); status != ConversationStatusCompleted {
+		t.Fatalf("已完成的子会话不应改变状态: %s", status)
+	}
+}
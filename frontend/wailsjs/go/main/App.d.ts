@@ -2,9 +2,21 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function AddTaskDependency(arg1:number,arg2:number):Promise<void>;
+
+export function AddTaskTag(arg1:number,arg2:string):Promise<main.Tag>;
+
+export function AddTimeEntry(arg1:main.TimeEntryInput):Promise<main.TimeEntry>;
+
+export function ApplyOverdueTriage(arg1:Array<main.OverdueTriageItem>):Promise<number>;
+
+export function ApplySchedule(arg1:Array<main.ScheduleItem>):Promise<number>;
+
+export function ApprovePlan(arg1:main.ApprovePlanInput):Promise<main.TaskPlan>;
+
 export function ArchiveProject(arg1:number,arg2:boolean):Promise<void>;
 
-export function AssignTaskToDate(arg1:number,arg2:string):Promise<void>;
+export function AssignTaskToDate(arg1:number,arg2:string):Promise<Array<string>>;
 
 export function CalculateHours(arg1:string,arg2:string):Promise<number>;
 
@@ -12,32 +24,82 @@ export function CompleteTask(arg1:main.CompleteTaskInput):Promise<void>;
 
 export function CreateAgent(arg1:main.AgentInput):Promise<main.Agent>;
 
+export function CreateCustomTool(arg1:main.CustomToolInput):Promise<main.CustomTool>;
+
+export function CreateMCPServer(arg1:main.MCPServerInput):Promise<main.MCPServer>;
+
+export function CreatePipeline(arg1:main.PipelineInput):Promise<main.Pipeline>;
+
 export function CreateProject(arg1:string,arg2:string,arg3:string):Promise<main.Project>;
 
+export function CreateRecurrence(arg1:main.RecurrenceInput):Promise<main.TaskRecurrence>;
+
+export function CreateSavedView(arg1:main.SavedViewInput):Promise<main.SavedView>;
+
+export function CreateTag(arg1:string,arg2:string):Promise<main.Tag>;
+
 export function CreateTask(arg1:main.TaskInput):Promise<main.Task>;
 
 export function DeleteAgent(arg1:number):Promise<void>;
 
+export function DeleteCalendarDay(arg1:string):Promise<void>;
+
+export function DeleteCustomTool(arg1:number):Promise<void>;
+
+export function DeleteMCPServer(arg1:number):Promise<void>;
+
+export function DeletePipeline(arg1:number):Promise<void>;
+
 export function DeleteProject(arg1:number):Promise<void>;
 
+export function DeleteRecurrence(arg1:number,arg2:boolean):Promise<void>;
+
+export function DeleteSavedView(arg1:number):Promise<void>;
+
+export function DeleteTag(arg1:number):Promise<void>;
+
 export function DeleteTask(arg1:number):Promise<void>;
 
+export function DeleteTimeEntry(arg1:number):Promise<void>;
+
+export function DiscoverMCPTools(arg1:number):Promise<Array<main.MCPToolInfo>>;
+
+export function GetActiveTimer():Promise<main.ActiveTimer>;
+
 export function GetAgent(arg1:number):Promise<main.Agent>;
 
 export function GetAgents():Promise<Array<main.Agent>>;
 
 export function GetAllProjects():Promise<Array<main.Project>>;
 
+export function GetAvailableTools():Promise<Array<main.AgentTool>>;
+
+export function GetCalendarDays(arg1:string,arg2:string):Promise<Array<main.CalendarDay>>;
+
+export function GetChildConversations(arg1:number):Promise<Array<main.TaskConversation>>;
+
+export function GetChronicallyDeferredTasks(arg1:number):Promise<Array<main.DeferredTask>>;
+
 export function GetConversationDetail(arg1:number):Promise<main.ConversationDetail>;
 
 export function GetConversationSteps(arg1:number):Promise<Array<main.AgentStep>>;
 
+export function GetCustomTool(arg1:number):Promise<main.CustomTool>;
+
+export function GetCustomTools():Promise<Array<main.CustomTool>>;
+
 export function GetDailyTaskStats(arg1:string,arg2:string,arg3:Array<number>):Promise<Array<main.DailyTaskStats>>;
 
+export function GetDayCapacities(arg1:string,arg2:string):Promise<Array<main.DayCapacity>>;
+
+export function GetEisenhowerMatrix():Promise<main.EisenhowerMatrix>;
+
 export function GetEnabledAgents():Promise<Array<main.Agent>>;
 
 export function GetEnabledProviders():Promise<Array<main.ModelProvider>>;
 
+export function GetMCPServers():Promise<Array<main.MCPServer>>;
+
 export function GetModelProvider(arg1:number):Promise<main.ModelProvider>;
 
 export function GetModelProviders():Promise<Array<main.ModelProvider>>;
@@ -46,38 +108,172 @@ export function GetOverdueTasks():Promise<Array<main.Task>>;
 
 export function GetPendingTasks():Promise<Array<main.Task>>;
 
+export function GetPendingTasksTree():Promise<Array<main.TaskNode>>;
+
+export function GetPipeline(arg1:number):Promise<main.Pipeline>;
+
+export function GetPipelineRun(arg1:number):Promise<main.PipelineRunDetail>;
+
+export function GetPipelines():Promise<Array<main.Pipeline>>;
+
+export function GetPrioritizedTasks(arg1:number):Promise<Array<main.ScoredTask>>;
+
+export function GetProjectCriticalPath(arg1:number):Promise<main.CriticalPath>;
+
+export function GetProjectHistory(arg1:number):Promise<Array<main.AuditEntry>>;
+
 export function GetProjectTimeStats(arg1:string,arg2:string,arg3:Array<number>):Promise<Array<main.ProjectTimeStats>>;
 
 export function GetProjects():Promise<Array<main.Project>>;
 
+export function GetProviderHealth():Promise<Array<main.ProviderHealth>>;
+
+export function GetRecentChanges(arg1:number):Promise<Array<main.AuditEntry>>;
+
+export function GetRecurrence(arg1:number):Promise<main.TaskRecurrence>;
+
+export function GetRecurrences():Promise<Array<main.TaskRecurrence>>;
+
 export function GetReportData(arg1:string,arg2:string,arg3:Array<number>):Promise<main.ReportData>;
 
+export function GetSavedView(arg1:number):Promise<main.SavedView>;
+
+export function GetSavedViewTasks(arg1:number,arg2:string):Promise<main.TaskPage>;
+
+export function GetSavedViews():Promise<Array<main.SavedView>>;
+
+export function GetScoringConfig():Promise<main.ScoringConfig>;
+
+export function GetSubtasks(arg1:number):Promise<Array<main.Task>>;
+
+export function GetTagTimeStats(arg1:string,arg2:string,arg3:Array<number>):Promise<Array<main.TagTimeStats>>;
+
+export function GetTags():Promise<Array<main.Tag>>;
+
 export function GetTask(arg1:number):Promise<main.Task>;
 
 export function GetTaskConversations(arg1:number):Promise<Array<main.TaskConversation>>;
 
+export function GetTaskDependencies(arg1:number):Promise<main.TaskDependencies>;
+
+export function GetTaskHistory(arg1:number):Promise<Array<main.AuditEntry>>;
+
+export function GetTaskPipelineRuns(arg1:number):Promise<Array<main.PipelineRun>>;
+
+export function GetTaskPlan(arg1:number):Promise<main.TaskPlan>;
+
+export function GetTaskPlans(arg1:number):Promise<Array<main.TaskPlan>>;
+
+export function GetTaskRescheduleHistory(arg1:number):Promise<Array<main.TaskReschedule>>;
+
+export function GetTaskTree(arg1:number):Promise<main.TaskNode>;
+
 export function GetTasksByDate(arg1:string):Promise<Array<main.Task>>;
 
 export function GetTasksByDateRange(arg1:string,arg2:string):Promise<Array<main.Task>>;
 
+export function GetTasksByDateTree(arg1:string):Promise<Array<main.TaskNode>>;
+
+export function GetTasksByProject(arg1:number,arg2:string):Promise<Array<main.Task>>;
+
+export function GetTimeEntries(arg1:number):Promise<Array<main.TimeEntry>>;
+
+export function GetTimeEntriesByDateRange(arg1:string,arg2:string):Promise<Array<main.TimeEntry>>;
+
+export function GetWorkCalendar():Promise<main.WorkCalendar>;
+
 export function GetWorkbenchData():Promise<main.WorkbenchData>;
 
+export function ImportHolidaysICS(arg1:string,arg2:string):Promise<number>;
+
+export function ListProviderModels(arg1:number):Promise<Array<main.ProviderModel>>;
+
+export function LogTaskHours(arg1:number,arg2:number):Promise<void>;
+
+export function MergeTags(arg1:Array<number>,arg2:number):Promise<void>;
+
+export function PausePipelineRun(arg1:number):Promise<void>;
+
+export function PauseTimer():Promise<main.TimeEntry>;
+
+export function PreviewOverdueTriage(arg1:main.OverdueTriageInput):Promise<main.OverdueTriageProposal>;
+
+export function PreviewSchedule(arg1:main.AutoScheduleInput):Promise<main.ScheduleProposal>;
+
+export function QueryTasks(arg1:main.TaskFilter):Promise<main.TaskPage>;
+
+export function RejectPlan(arg1:main.RejectPlanInput):Promise<main.TaskPlan>;
+
+export function RemoveTaskDependency(arg1:number,arg2:number):Promise<void>;
+
+export function RemoveTaskTag(arg1:number,arg2:number):Promise<void>;
+
+export function ReorderSavedViews(arg1:Array<number>):Promise<void>;
+
 export function RescheduleAllOverdueTasks():Promise<number>;
 
 export function RescheduleTask(arg1:number,arg2:string):Promise<void>;
 
+export function RestoreOccurrence(arg1:number,arg2:string):Promise<void>;
+
+export function ResumePipelineRun(arg1:number):Promise<main.PipelineRunDetail>;
+
+export function ResumeTimer():Promise<main.TimeEntry>;
+
+export function SaveScoringConfig(arg1:main.ScoringConfig):Promise<void>;
+
+export function SaveWorkCalendar(arg1:main.WorkCalendar):Promise<void>;
+
+export function Search(arg1:main.SearchInput):Promise<Array<main.SearchResult>>;
+
 export function SendMessage(arg1:main.SendMessageInput):Promise<main.ConversationDetail>;
 
+export function SetCalendarDay(arg1:main.CalendarDay):Promise<void>;
+
+export function SetTaskParent(arg1:number,arg2:any):Promise<void>;
+
+export function SetTaskTags(arg1:number,arg2:Array<number>):Promise<void>;
+
+export function SkipOccurrence(arg1:number,arg2:string):Promise<void>;
+
 export function StartConversation(arg1:main.StartConversationInput):Promise<main.ConversationDetail>;
 
+export function StartPipelineRun(arg1:main.StartPipelineInput):Promise<main.PipelineRunDetail>;
+
+export function StartTimer(arg1:number):Promise<main.TimeEntry>;
+
 export function StopConversation(arg1:number):Promise<void>;
 
+export function StopTimer():Promise<main.TimeEntry>;
+
+export function SuggestTags(arg1:string,arg2:number):Promise<Array<main.Tag>>;
+
+export function TestModelProvider(arg1:main.ModelProviderInput):Promise<main.ProviderTestResult>;
+
+export function UndoChange(arg1:number):Promise<void>;
+
+export function UndoLastChange():Promise<main.AuditEntry>;
+
 export function UpdateAgent(arg1:main.AgentInput):Promise<void>;
 
+export function UpdateCustomTool(arg1:main.CustomToolInput):Promise<void>;
+
+export function UpdateMCPServer(arg1:main.MCPServerInput):Promise<void>;
+
 export function UpdateModelProvider(arg1:main.ModelProviderInput):Promise<void>;
 
+export function UpdateOccurrence(arg1:main.UpdateOccurrenceInput):Promise<void>;
+
+export function UpdatePipeline(arg1:main.PipelineInput):Promise<void>;
+
 export function UpdateProject(arg1:number,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function UpdateSavedView(arg1:main.SavedViewInput):Promise<void>;
+
+export function UpdateTag(arg1:number,arg2:string,arg3:string):Promise<void>;
+
 export function UpdateTask(arg1:main.TaskInput):Promise<void>;
 
 export function UpdateTaskStatus(arg1:number,arg2:string):Promise<void>;
+
+export function UpdateTimeEntry(arg1:main.TimeEntryInput):Promise<main.TimeEntry>;
@@ -2,6 +2,30 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddTaskDependency(arg1, arg2) {
+  return window['go']['main']['App']['AddTaskDependency'](arg1, arg2);
+}
+
+export function AddTaskTag(arg1, arg2) {
+  return window['go']['main']['App']['AddTaskTag'](arg1, arg2);
+}
+
+export function AddTimeEntry(arg1) {
+  return window['go']['main']['App']['AddTimeEntry'](arg1);
+}
+
+export function ApplyOverdueTriage(arg1) {
+  return window['go']['main']['App']['ApplyOverdueTriage'](arg1);
+}
+
+export function ApplySchedule(arg1) {
+  return window['go']['main']['App']['ApplySchedule'](arg1);
+}
+
+export function ApprovePlan(arg1) {
+  return window['go']['main']['App']['ApprovePlan'](arg1);
+}
+
 export function ArchiveProject(arg1, arg2) {
   return window['go']['main']['App']['ArchiveProject'](arg1, arg2);
 }
@@ -22,10 +46,34 @@ export function CreateAgent(arg1) {
   return window['go']['main']['App']['CreateAgent'](arg1);
 }
 
+export function CreateCustomTool(arg1) {
+  return window['go']['main']['App']['CreateCustomTool'](arg1);
+}
+
+export function CreateMCPServer(arg1) {
+  return window['go']['main']['App']['CreateMCPServer'](arg1);
+}
+
+export function CreatePipeline(arg1) {
+  return window['go']['main']['App']['CreatePipeline'](arg1);
+}
+
 export function CreateProject(arg1, arg2, arg3) {
   return window['go']['main']['App']['CreateProject'](arg1, arg2, arg3);
 }
 
+export function CreateRecurrence(arg1) {
+  return window['go']['main']['App']['CreateRecurrence'](arg1);
+}
+
+export function CreateSavedView(arg1) {
+  return window['go']['main']['App']['CreateSavedView'](arg1);
+}
+
+export function CreateTag(arg1, arg2) {
+  return window['go']['main']['App']['CreateTag'](arg1, arg2);
+}
+
 export function CreateTask(arg1) {
   return window['go']['main']['App']['CreateTask'](arg1);
 }
@@ -34,14 +82,54 @@ export function DeleteAgent(arg1) {
   return window['go']['main']['App']['DeleteAgent'](arg1);
 }
 
+export function DeleteCalendarDay(arg1) {
+  return window['go']['main']['App']['DeleteCalendarDay'](arg1);
+}
+
+export function DeleteCustomTool(arg1) {
+  return window['go']['main']['App']['DeleteCustomTool'](arg1);
+}
+
+export function DeleteMCPServer(arg1) {
+  return window['go']['main']['App']['DeleteMCPServer'](arg1);
+}
+
+export function DeletePipeline(arg1) {
+  return window['go']['main']['App']['DeletePipeline'](arg1);
+}
+
 export function DeleteProject(arg1) {
   return window['go']['main']['App']['DeleteProject'](arg1);
 }
 
+export function DeleteRecurrence(arg1, arg2) {
+  return window['go']['main']['App']['DeleteRecurrence'](arg1, arg2);
+}
+
+export function DeleteSavedView(arg1) {
+  return window['go']['main']['App']['DeleteSavedView'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
 export function DeleteTask(arg1) {
   return window['go']['main']['App']['DeleteTask'](arg1);
 }
 
+export function DeleteTimeEntry(arg1) {
+  return window['go']['main']['App']['DeleteTimeEntry'](arg1);
+}
+
+export function DiscoverMCPTools(arg1) {
+  return window['go']['main']['App']['DiscoverMCPTools'](arg1);
+}
+
+export function GetActiveTimer() {
+  return window['go']['main']['App']['GetActiveTimer']();
+}
+
 export function GetAgent(arg1) {
   return window['go']['main']['App']['GetAgent'](arg1);
 }
@@ -54,6 +142,22 @@ export function GetAllProjects() {
   return window['go']['main']['App']['GetAllProjects']();
 }
 
+export function GetAvailableTools() {
+  return window['go']['main']['App']['GetAvailableTools']();
+}
+
+export function GetCalendarDays(arg1, arg2) {
+  return window['go']['main']['App']['GetCalendarDays'](arg1, arg2);
+}
+
+export function GetChildConversations(arg1) {
+  return window['go']['main']['App']['GetChildConversations'](arg1);
+}
+
+export function GetChronicallyDeferredTasks(arg1) {
+  return window['go']['main']['App']['GetChronicallyDeferredTasks'](arg1);
+}
+
 export function GetConversationDetail(arg1) {
   return window['go']['main']['App']['GetConversationDetail'](arg1);
 }
@@ -62,10 +166,26 @@ export function GetConversationSteps(arg1) {
   return window['go']['main']['App']['GetConversationSteps'](arg1);
 }
 
+export function GetCustomTool(arg1) {
+  return window['go']['main']['App']['GetCustomTool'](arg1);
+}
+
+export function GetCustomTools() {
+  return window['go']['main']['App']['GetCustomTools']();
+}
+
 export function GetDailyTaskStats(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetDailyTaskStats'](arg1, arg2, arg3);
 }
 
+export function GetDayCapacities(arg1, arg2) {
+  return window['go']['main']['App']['GetDayCapacities'](arg1, arg2);
+}
+
+export function GetEisenhowerMatrix() {
+  return window['go']['main']['App']['GetEisenhowerMatrix']();
+}
+
 export function GetEnabledAgents() {
   return window['go']['main']['App']['GetEnabledAgents']();
 }
@@ -74,6 +194,10 @@ export function GetEnabledProviders() {
   return window['go']['main']['App']['GetEnabledProviders']();
 }
 
+export function GetMCPServers() {
+  return window['go']['main']['App']['GetMCPServers']();
+}
+
 export function GetModelProvider(arg1) {
   return window['go']['main']['App']['GetModelProvider'](arg1);
 }
@@ -90,6 +214,34 @@ export function GetPendingTasks() {
   return window['go']['main']['App']['GetPendingTasks']();
 }
 
+export function GetPendingTasksTree() {
+  return window['go']['main']['App']['GetPendingTasksTree']();
+}
+
+export function GetPipeline(arg1) {
+  return window['go']['main']['App']['GetPipeline'](arg1);
+}
+
+export function GetPipelineRun(arg1) {
+  return window['go']['main']['App']['GetPipelineRun'](arg1);
+}
+
+export function GetPipelines() {
+  return window['go']['main']['App']['GetPipelines']();
+}
+
+export function GetPrioritizedTasks(arg1) {
+  return window['go']['main']['App']['GetPrioritizedTasks'](arg1);
+}
+
+export function GetProjectCriticalPath(arg1) {
+  return window['go']['main']['App']['GetProjectCriticalPath'](arg1);
+}
+
+export function GetProjectHistory(arg1) {
+  return window['go']['main']['App']['GetProjectHistory'](arg1);
+}
+
 export function GetProjectTimeStats(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetProjectTimeStats'](arg1, arg2, arg3);
 }
@@ -98,10 +250,54 @@ export function GetProjects() {
   return window['go']['main']['App']['GetProjects']();
 }
 
+export function GetProviderHealth() {
+  return window['go']['main']['App']['GetProviderHealth']();
+}
+
+export function GetRecentChanges(arg1) {
+  return window['go']['main']['App']['GetRecentChanges'](arg1);
+}
+
+export function GetRecurrence(arg1) {
+  return window['go']['main']['App']['GetRecurrence'](arg1);
+}
+
+export function GetRecurrences() {
+  return window['go']['main']['App']['GetRecurrences']();
+}
+
 export function GetReportData(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetReportData'](arg1, arg2, arg3);
 }
 
+export function GetSavedView(arg1) {
+  return window['go']['main']['App']['GetSavedView'](arg1);
+}
+
+export function GetSavedViewTasks(arg1, arg2) {
+  return window['go']['main']['App']['GetSavedViewTasks'](arg1, arg2);
+}
+
+export function GetSavedViews() {
+  return window['go']['main']['App']['GetSavedViews']();
+}
+
+export function GetScoringConfig() {
+  return window['go']['main']['App']['GetScoringConfig']();
+}
+
+export function GetSubtasks(arg1) {
+  return window['go']['main']['App']['GetSubtasks'](arg1);
+}
+
+export function GetTagTimeStats(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTagTimeStats'](arg1, arg2, arg3);
+}
+
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
 export function GetTask(arg1) {
   return window['go']['main']['App']['GetTask'](arg1);
 }
@@ -110,6 +306,34 @@ export function GetTaskConversations(arg1) {
   return window['go']['main']['App']['GetTaskConversations'](arg1);
 }
 
+export function GetTaskDependencies(arg1) {
+  return window['go']['main']['App']['GetTaskDependencies'](arg1);
+}
+
+export function GetTaskHistory(arg1) {
+  return window['go']['main']['App']['GetTaskHistory'](arg1);
+}
+
+export function GetTaskPipelineRuns(arg1) {
+  return window['go']['main']['App']['GetTaskPipelineRuns'](arg1);
+}
+
+export function GetTaskPlan(arg1) {
+  return window['go']['main']['App']['GetTaskPlan'](arg1);
+}
+
+export function GetTaskPlans(arg1) {
+  return window['go']['main']['App']['GetTaskPlans'](arg1);
+}
+
+export function GetTaskRescheduleHistory(arg1) {
+  return window['go']['main']['App']['GetTaskRescheduleHistory'](arg1);
+}
+
+export function GetTaskTree(arg1) {
+  return window['go']['main']['App']['GetTaskTree'](arg1);
+}
+
 export function GetTasksByDate(arg1) {
   return window['go']['main']['App']['GetTasksByDate'](arg1);
 }
@@ -118,10 +342,82 @@ export function GetTasksByDateRange(arg1, arg2) {
   return window['go']['main']['App']['GetTasksByDateRange'](arg1, arg2);
 }
 
+export function GetTasksByDateTree(arg1) {
+  return window['go']['main']['App']['GetTasksByDateTree'](arg1);
+}
+
+export function GetTasksByProject(arg1, arg2) {
+  return window['go']['main']['App']['GetTasksByProject'](arg1, arg2);
+}
+
+export function GetTimeEntries(arg1) {
+  return window['go']['main']['App']['GetTimeEntries'](arg1);
+}
+
+export function GetTimeEntriesByDateRange(arg1, arg2) {
+  return window['go']['main']['App']['GetTimeEntriesByDateRange'](arg1, arg2);
+}
+
+export function GetWorkCalendar() {
+  return window['go']['main']['App']['GetWorkCalendar']();
+}
+
 export function GetWorkbenchData() {
   return window['go']['main']['App']['GetWorkbenchData']();
 }
 
+export function ImportHolidaysICS(arg1, arg2) {
+  return window['go']['main']['App']['ImportHolidaysICS'](arg1, arg2);
+}
+
+export function ListProviderModels(arg1) {
+  return window['go']['main']['App']['ListProviderModels'](arg1);
+}
+
+export function LogTaskHours(arg1, arg2) {
+  return window['go']['main']['App']['LogTaskHours'](arg1, arg2);
+}
+
+export function MergeTags(arg1, arg2) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2);
+}
+
+export function PausePipelineRun(arg1) {
+  return window['go']['main']['App']['PausePipelineRun'](arg1);
+}
+
+export function PauseTimer() {
+  return window['go']['main']['App']['PauseTimer']();
+}
+
+export function PreviewOverdueTriage(arg1) {
+  return window['go']['main']['App']['PreviewOverdueTriage'](arg1);
+}
+
+export function PreviewSchedule(arg1) {
+  return window['go']['main']['App']['PreviewSchedule'](arg1);
+}
+
+export function QueryTasks(arg1) {
+  return window['go']['main']['App']['QueryTasks'](arg1);
+}
+
+export function RejectPlan(arg1) {
+  return window['go']['main']['App']['RejectPlan'](arg1);
+}
+
+export function RemoveTaskDependency(arg1, arg2) {
+  return window['go']['main']['App']['RemoveTaskDependency'](arg1, arg2);
+}
+
+export function RemoveTaskTag(arg1, arg2) {
+  return window['go']['main']['App']['RemoveTaskTag'](arg1, arg2);
+}
+
+export function ReorderSavedViews(arg1) {
+  return window['go']['main']['App']['ReorderSavedViews'](arg1);
+}
+
 export function RescheduleAllOverdueTasks() {
   return window['go']['main']['App']['RescheduleAllOverdueTasks']();
 }
@@ -130,30 +426,122 @@ export function RescheduleTask(arg1, arg2) {
   return window['go']['main']['App']['RescheduleTask'](arg1, arg2);
 }
 
+export function RestoreOccurrence(arg1, arg2) {
+  return window['go']['main']['App']['RestoreOccurrence'](arg1, arg2);
+}
+
+export function ResumePipelineRun(arg1) {
+  return window['go']['main']['App']['ResumePipelineRun'](arg1);
+}
+
+export function ResumeTimer() {
+  return window['go']['main']['App']['ResumeTimer']();
+}
+
+export function SaveScoringConfig(arg1) {
+  return window['go']['main']['App']['SaveScoringConfig'](arg1);
+}
+
+export function SaveWorkCalendar(arg1) {
+  return window['go']['main']['App']['SaveWorkCalendar'](arg1);
+}
+
+export function Search(arg1) {
+  return window['go']['main']['App']['Search'](arg1);
+}
+
 export function SendMessage(arg1) {
   return window['go']['main']['App']['SendMessage'](arg1);
 }
 
+export function SetCalendarDay(arg1) {
+  return window['go']['main']['App']['SetCalendarDay'](arg1);
+}
+
+export function SetTaskParent(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskParent'](arg1, arg2);
+}
+
+export function SetTaskTags(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskTags'](arg1, arg2);
+}
+
+export function SkipOccurrence(arg1, arg2) {
+  return window['go']['main']['App']['SkipOccurrence'](arg1, arg2);
+}
+
 export function StartConversation(arg1) {
   return window['go']['main']['App']['StartConversation'](arg1);
 }
 
+export function StartPipelineRun(arg1) {
+  return window['go']['main']['App']['StartPipelineRun'](arg1);
+}
+
+export function StartTimer(arg1) {
+  return window['go']['main']['App']['StartTimer'](arg1);
+}
+
 export function StopConversation(arg1) {
   return window['go']['main']['App']['StopConversation'](arg1);
 }
 
+export function StopTimer() {
+  return window['go']['main']['App']['StopTimer']();
+}
+
+export function SuggestTags(arg1, arg2) {
+  return window['go']['main']['App']['SuggestTags'](arg1, arg2);
+}
+
+export function TestModelProvider(arg1) {
+  return window['go']['main']['App']['TestModelProvider'](arg1);
+}
+
+export function UndoChange(arg1) {
+  return window['go']['main']['App']['UndoChange'](arg1);
+}
+
+export function UndoLastChange() {
+  return window['go']['main']['App']['UndoLastChange']();
+}
+
 export function UpdateAgent(arg1) {
   return window['go']['main']['App']['UpdateAgent'](arg1);
 }
 
+export function UpdateCustomTool(arg1) {
+  return window['go']['main']['App']['UpdateCustomTool'](arg1);
+}
+
+export function UpdateMCPServer(arg1) {
+  return window['go']['main']['App']['UpdateMCPServer'](arg1);
+}
+
 export function UpdateModelProvider(arg1) {
   return window['go']['main']['App']['UpdateModelProvider'](arg1);
 }
 
+export function UpdateOccurrence(arg1) {
+  return window['go']['main']['App']['UpdateOccurrence'](arg1);
+}
+
+export function UpdatePipeline(arg1) {
+  return window['go']['main']['App']['UpdatePipeline'](arg1);
+}
+
 export function UpdateProject(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['UpdateProject'](arg1, arg2, arg3, arg4);
 }
 
+export function UpdateSavedView(arg1) {
+  return window['go']['main']['App']['UpdateSavedView'](arg1);
+}
+
+export function UpdateTag(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateTag'](arg1, arg2, arg3);
+}
+
 export function UpdateTask(arg1) {
   return window['go']['main']['App']['UpdateTask'](arg1);
 }
@@ -161,3 +549,7 @@ export function UpdateTask(arg1) {
 export function UpdateTaskStatus(arg1, arg2) {
   return window['go']['main']['App']['UpdateTaskStatus'](arg1, arg2);
 }
+
+export function UpdateTimeEntry(arg1) {
+  return window['go']['main']['App']['UpdateTimeEntry'](arg1);
+}
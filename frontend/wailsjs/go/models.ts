@@ -1,5 +1,94 @@
 export namespace main {
 	
+	export class TimeEntry {
+	    id: number;
+	    task_id: number;
+	    task_name: string;
+	    project_id?: number;
+	    start_at: string;
+	    end_at?: string;
+	    hours: number;
+	    source: string;
+	    paused: boolean;
+	    note: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.task_name = source["task_name"];
+	        this.project_id = source["project_id"];
+	        this.start_at = source["start_at"];
+	        this.end_at = source["end_at"];
+	        this.hours = source["hours"];
+	        this.source = source["source"];
+	        this.paused = source["paused"];
+	        this.note = source["note"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ActiveTimer {
+	    entry: TimeEntry;
+	    running: boolean;
+	    paused: boolean;
+	    session_hours: number;
+	    task_hours: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActiveTimer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entry = this.convertValues(source["entry"], TimeEntry);
+	        this.running = source["running"];
+	        this.paused = source["paused"];
+	        this.session_hours = source["session_hours"];
+	        this.task_hours = source["task_hours"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Agent {
 	    id: number;
 	    name: string;
@@ -11,6 +100,8 @@ export namespace main {
 	    tools: string;
 	    working_dir: string;
 	    max_retries: number;
+	    fallbacks: string;
+	    task_access: string;
 	    enabled: boolean;
 	    // Go type: time
 	    created_at: any;
@@ -31,6 +122,8 @@ export namespace main {
 	        this.tools = source["tools"];
 	        this.working_dir = source["working_dir"];
 	        this.max_retries = source["max_retries"];
+	        this.fallbacks = source["fallbacks"];
+	        this.task_access = source["task_access"];
 	        this.enabled = source["enabled"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	    }
@@ -64,6 +157,8 @@ export namespace main {
 	    tools: string;
 	    working_dir: string;
 	    max_retries: number;
+	    fallbacks: string;
+	    task_access: string;
 	    enabled: boolean;
 	
 	    static createFrom(source: any = {}) {
@@ -82,6 +177,8 @@ export namespace main {
 	        this.tools = source["tools"];
 	        this.working_dir = source["working_dir"];
 	        this.max_retries = source["max_retries"];
+	        this.fallbacks = source["fallbacks"];
+	        this.task_access = source["task_access"];
 	        this.enabled = source["enabled"];
 	    }
 	}
@@ -95,6 +192,8 @@ export namespace main {
 	    observation: string;
 	    status: string;
 	    error: string;
+	    model: string;
+	    children: number[];
 	    // Go type: time
 	    created_at: any;
 	
@@ -113,6 +212,149 @@ export namespace main {
 	        this.observation = source["observation"];
 	        this.status = source["status"];
 	        this.error = source["error"];
+	        this.model = source["model"];
+	        this.children = source["children"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AgentTool {
+	    name: string;
+	    description: string;
+	    type: string;
+	    schema: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgentTool(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.type = source["type"];
+	        this.schema = source["schema"];
+	    }
+	}
+	export class PlanItem {
+	    name: string;
+	    description: string;
+	    hours: number;
+	    priority: string;
+	    urgency: string;
+	    date?: string;
+	    depends_on: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PlanItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.hours = source["hours"];
+	        this.priority = source["priority"];
+	        this.urgency = source["urgency"];
+	        this.date = source["date"];
+	        this.depends_on = source["depends_on"];
+	    }
+	}
+	export class ApprovePlanInput {
+	    plan_id: number;
+	    items: PlanItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ApprovePlanInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plan_id = source["plan_id"];
+	        this.items = this.convertValues(source["items"], PlanItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldChange {
+	    field: string;
+	    old: any;
+	    new: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class AuditEntry {
+	    id: number;
+	    entity_type: string;
+	    entity_id: number;
+	    action: string;
+	    changes: FieldChange[];
+	    actor_type: string;
+	    conversation_id?: number;
+	    client: string;
+	    undone: boolean;
+	    undo_of?: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entity_type = source["entity_type"];
+	        this.entity_id = source["entity_id"];
+	        this.action = source["action"];
+	        this.changes = this.convertValues(source["changes"], FieldChange);
+	        this.actor_type = source["actor_type"];
+	        this.conversation_id = source["conversation_id"];
+	        this.client = source["client"];
+	        this.undone = source["undone"];
+	        this.undo_of = source["undo_of"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	    }
 	
@@ -134,6 +376,46 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class AutoScheduleInput {
+	    start_date: string;
+	    days: number;
+	    work_start: string;
+	    work_end: string;
+	    include_weekends: boolean;
+	    task_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoScheduleInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.days = source["days"];
+	        this.work_start = source["work_start"];
+	        this.work_end = source["work_end"];
+	        this.include_weekends = source["include_weekends"];
+	        this.task_ids = source["task_ids"];
+	    }
+	}
+	export class CalendarDay {
+	    date: string;
+	    hours: number;
+	    kind: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalendarDay(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.hours = source["hours"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	    }
+	}
 	export class CompleteTaskInput {
 	    id: number;
 	    actual_start?: string;
@@ -150,6 +432,45 @@ export namespace main {
 	        this.actual_hours = source["actual_hours"];
 	    }
 	}
+	export class Tag {
+	    id: number;
+	    name: string;
+	    color: string;
+	    task_count: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.task_count = source["task_count"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Task {
 	    id: number;
 	    project_id?: number;
@@ -166,8 +487,21 @@ export namespace main {
 	    status: string;
 	    actual_start?: string;
 	    actual_hours: number;
+	    parent_id?: number;
+	    completion_rule: string;
+	    recurrence_id?: number;
+	    occurrence_date?: string;
 	    // Go type: time
 	    created_at: any;
+	    child_count: number;
+	    open_child_count: number;
+	    subtask_hours: number;
+	    subtask_actual_hours: number;
+	    blocked: boolean;
+	    blocked_by: number[];
+	    slip_count: number;
+	    tags: Tag[];
+	    warnings?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Task(source);
@@ -190,7 +524,20 @@ export namespace main {
 	        this.status = source["status"];
 	        this.actual_start = source["actual_start"];
 	        this.actual_hours = source["actual_hours"];
+	        this.parent_id = source["parent_id"];
+	        this.completion_rule = source["completion_rule"];
+	        this.recurrence_id = source["recurrence_id"];
+	        this.occurrence_date = source["occurrence_date"];
 	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.child_count = source["child_count"];
+	        this.open_child_count = source["open_child_count"];
+	        this.subtask_hours = source["subtask_hours"];
+	        this.subtask_actual_hours = source["subtask_actual_hours"];
+	        this.blocked = source["blocked"];
+	        this.blocked_by = source["blocked_by"];
+	        this.slip_count = source["slip_count"];
+	        this.tags = this.convertValues(source["tags"], Tag);
+	        this.warnings = source["warnings"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -260,6 +607,8 @@ export namespace main {
 	    agent_id: number;
 	    agent_name: string;
 	    status: string;
+	    parent_conversation_id?: number;
+	    parent_step_id?: number;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
@@ -276,6 +625,8 @@ export namespace main {
 	        this.agent_id = source["agent_id"];
 	        this.agent_name = source["agent_name"];
 	        this.status = source["status"];
+	        this.parent_conversation_id = source["parent_conversation_id"];
+	        this.parent_step_id = source["parent_step_id"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	    }
@@ -333,47 +684,32 @@ export namespace main {
 		}
 	}
 	
-	export class DailyTaskStats {
-	    date: string;
-	    total_count: number;
-	    completed_count: number;
-	    completion_rate: number;
+	export class CriticalPathTask {
+	    task: Task;
+	    earliest_start: number;
+	    earliest_finish: number;
+	    latest_start: number;
+	    latest_finish: number;
+	    slack: number;
+	    critical: boolean;
+	    projected_date: string;
+	    late: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new DailyTaskStats(source);
+	        return new CriticalPathTask(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.date = source["date"];
-	        this.total_count = source["total_count"];
-	        this.completed_count = source["completed_count"];
-	        this.completion_rate = source["completion_rate"];
-	    }
-	}
-	export class ModelProvider {
-	    id: number;
-	    name: string;
-	    label: string;
-	    api_key: string;
-	    base_url: string;
-	    enabled: boolean;
-	    // Go type: time
-	    created_at: any;
-	
-	    static createFrom(source: any = {}) {
-	        return new ModelProvider(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.label = source["label"];
-	        this.api_key = source["api_key"];
-	        this.base_url = source["base_url"];
-	        this.enabled = source["enabled"];
-	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.earliest_start = source["earliest_start"];
+	        this.earliest_finish = source["earliest_finish"];
+	        this.latest_start = source["latest_start"];
+	        this.latest_finish = source["latest_finish"];
+	        this.slack = source["slack"];
+	        this.critical = source["critical"];
+	        this.projected_date = source["projected_date"];
+	        this.late = source["late"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -394,40 +730,63 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class ModelProviderInput {
-	    id: number;
-	    name: string;
-	    label: string;
-	    api_key: string;
-	    base_url: string;
-	    enabled: boolean;
+	export class CriticalPath {
+	    project_id: number;
+	    tasks: CriticalPathTask[];
+	    path: number[];
+	    total_hours: number;
+	    projected_finish: string;
+	    late_tasks: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ModelProviderInput(source);
+	        return new CriticalPath(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.label = source["label"];
-	        this.api_key = source["api_key"];
-	        this.base_url = source["base_url"];
-	        this.enabled = source["enabled"];
+	        this.project_id = source["project_id"];
+	        this.tasks = this.convertValues(source["tasks"], CriticalPathTask);
+	        this.path = source["path"];
+	        this.total_hours = source["total_hours"];
+	        this.projected_finish = source["projected_finish"];
+	        this.late_tasks = source["late_tasks"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class Project {
+	
+	export class CustomTool {
 	    id: number;
 	    name: string;
 	    description: string;
-	    color: string;
-	    archived: boolean;
+	    schema: string;
+	    command: string;
+	    working_dir: string;
+	    timeout: number;
+	    output_mode: string;
+	    output_field: string;
+	    enabled: boolean;
 	    // Go type: time
 	    created_at: any;
-	    task_count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Project(source);
+	        return new CustomTool(source);
 	    }
 	
 	    constructor(source: any = {}) {
@@ -435,10 +794,14 @@ export namespace main {
 	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.description = source["description"];
-	        this.color = source["color"];
-	        this.archived = source["archived"];
+	        this.schema = source["schema"];
+	        this.command = source["command"];
+	        this.working_dir = source["working_dir"];
+	        this.timeout = source["timeout"];
+	        this.output_mode = source["output_mode"];
+	        this.output_field = source["output_field"];
+	        this.enabled = source["enabled"];
 	        this.created_at = this.convertValues(source["created_at"], null);
-	        this.task_count = source["task_count"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -459,62 +822,121 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class ProjectTimeStats {
-	    project_id: number;
-	    project_name: string;
-	    color: string;
-	    total_hours: number;
+	export class CustomToolInput {
+	    id: number;
+	    name: string;
+	    description: string;
+	    schema: string;
+	    command: string;
+	    working_dir: string;
+	    timeout: number;
+	    output_mode: string;
+	    output_field: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CustomToolInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.schema = source["schema"];
+	        this.command = source["command"];
+	        this.working_dir = source["working_dir"];
+	        this.timeout = source["timeout"];
+	        this.output_mode = source["output_mode"];
+	        this.output_field = source["output_field"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class DailyTaskStats {
+	    date: string;
+	    total_count: number;
+	    completed_count: number;
+	    completion_rate: number;
+	    hours: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyTaskStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.total_count = source["total_count"];
+	        this.completed_count = source["completed_count"];
+	        this.completion_rate = source["completion_rate"];
+	        this.hours = source["hours"];
+	    }
+	}
+	export class DayCapacity {
+	    date: string;
+	    available: number;
+	    planned: number;
+	    remaining: number;
+	    overloaded: boolean;
 	    task_count: number;
-	    percentage: number;
+	    kind: string;
+	    name: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ProjectTimeStats(source);
+	        return new DayCapacity(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.project_id = source["project_id"];
-	        this.project_name = source["project_name"];
-	        this.color = source["color"];
-	        this.total_hours = source["total_hours"];
+	        this.date = source["date"];
+	        this.available = source["available"];
+	        this.planned = source["planned"];
+	        this.remaining = source["remaining"];
+	        this.overloaded = source["overloaded"];
 	        this.task_count = source["task_count"];
-	        this.percentage = source["percentage"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
 	    }
 	}
-	export class ReportSummary {
-	    total_tasks: number;
-	    completed_tasks: number;
-	    total_hours: number;
-	    completed_hours: number;
-	    average_rate: number;
+	export class DayLoad {
+	    date: string;
+	    capacity: number;
+	    scheduled_hours: number;
+	    added_hours: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ReportSummary(source);
+	        return new DayLoad(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.total_tasks = source["total_tasks"];
-	        this.completed_tasks = source["completed_tasks"];
-	        this.total_hours = source["total_hours"];
-	        this.completed_hours = source["completed_hours"];
-	        this.average_rate = source["average_rate"];
+	        this.date = source["date"];
+	        this.capacity = source["capacity"];
+	        this.scheduled_hours = source["scheduled_hours"];
+	        this.added_hours = source["added_hours"];
 	    }
 	}
-	export class ReportData {
-	    project_stats: ProjectTimeStats[];
-	    daily_stats: DailyTaskStats[];
-	    summary: ReportSummary;
+	export class TaskReschedule {
+	    id: number;
+	    task_id: number;
+	    from_date: string;
+	    to_date?: string;
+	    reason: string;
+	    // Go type: time
+	    created_at: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new ReportData(source);
+	        return new TaskReschedule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.project_stats = this.convertValues(source["project_stats"], ProjectTimeStats);
-	        this.daily_stats = this.convertValues(source["daily_stats"], DailyTaskStats);
-	        this.summary = this.convertValues(source["summary"], ReportSummary);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.from_date = source["from_date"];
+	        this.to_date = source["to_date"];
+	        this.reason = source["reason"];
+	        this.created_at = this.convertValues(source["created_at"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -535,71 +957,1666 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class DeferredTask {
+	    task: Task;
+	    slip_count: number;
+	    original_date: string;
+	    history: TaskReschedule[];
 	
-	export class SendMessageInput {
-	    conversation_id: number;
-	    content: string;
+	    static createFrom(source: any = {}) {
+	        return new DeferredTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.slip_count = source["slip_count"];
+	        this.original_date = source["original_date"];
+	        this.history = this.convertValues(source["history"], TaskReschedule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScoreBreakdown {
+	    importance: number;
+	    urgency: number;
+	    deadline: number;
+	    size: number;
+	    age: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new SendMessageInput(source);
+	        return new ScoreBreakdown(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.conversation_id = source["conversation_id"];
-	        this.content = source["content"];
+	        this.importance = source["importance"];
+	        this.urgency = source["urgency"];
+	        this.deadline = source["deadline"];
+	        this.size = source["size"];
+	        this.age = source["age"];
 	    }
 	}
-	export class StartConversationInput {
-	    task_id: number;
-	    agent_id: number;
-	    extra_context: string;
+	export class ScoredTask {
+	    task: Task;
+	    score: number;
+	    breakdown: ScoreBreakdown;
+	    quadrant: string;
+	    effective_urgency: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new StartConversationInput(source);
+	        return new ScoredTask(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.task_id = source["task_id"];
-	        this.agent_id = source["agent_id"];
-	        this.extra_context = source["extra_context"];
+	        this.task = this.convertValues(source["task"], Task);
+	        this.score = source["score"];
+	        this.breakdown = this.convertValues(source["breakdown"], ScoreBreakdown);
+	        this.quadrant = source["quadrant"];
+	        this.effective_urgency = source["effective_urgency"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	export class EisenhowerMatrix {
+	    do_first: ScoredTask[];
+	    schedule: ScoredTask[];
+	    delegate: ScoredTask[];
+	    eliminate: ScoredTask[];
 	
+	    static createFrom(source: any = {}) {
+	        return new EisenhowerMatrix(source);
+	    }
 	
-	export class TaskInput {
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.do_first = this.convertValues(source["do_first"], ScoredTask);
+	        this.schedule = this.convertValues(source["schedule"], ScoredTask);
+	        this.delegate = this.convertValues(source["delegate"], ScoredTask);
+	        this.eliminate = this.convertValues(source["eliminate"], ScoredTask);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class MCPServer {
 	    id: number;
-	    project_id?: number;
 	    name: string;
-	    description: string;
-	    date?: string;
-	    start_time?: string;
-	    end_time?: string;
-	    hours: number;
-	    deadline?: string;
-	    priority: string;
-	    urgency: string;
-	    status: string;
+	    transport: string;
+	    command: string;
+	    args: string;
+	    env: string;
+	    url: string;
+	    enabled: boolean;
+	    // Go type: time
+	    created_at: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new TaskInput(source);
+	        return new MCPServer(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.project_id = source["project_id"];
 	        this.name = source["name"];
-	        this.description = source["description"];
-	        this.date = source["date"];
-	        this.start_time = source["start_time"];
-	        this.end_time = source["end_time"];
-	        this.hours = source["hours"];
-	        this.deadline = source["deadline"];
-	        this.priority = source["priority"];
-	        this.urgency = source["urgency"];
-	        this.status = source["status"];
+	        this.transport = source["transport"];
+	        this.command = source["command"];
+	        this.args = source["args"];
+	        this.env = source["env"];
+	        this.url = source["url"];
+	        this.enabled = source["enabled"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MCPServerInput {
+	    id: number;
+	    name: string;
+	    transport: string;
+	    command: string;
+	    args: string;
+	    env: string;
+	    url: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MCPServerInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.transport = source["transport"];
+	        this.command = source["command"];
+	        this.args = source["args"];
+	        this.env = source["env"];
+	        this.url = source["url"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class MCPToolInfo {
+	    name: string;
+	    description: string;
+	    inputSchema: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MCPToolInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.inputSchema = source["inputSchema"];
+	    }
+	}
+	export class ModelProvider {
+	    id: number;
+	    name: string;
+	    label: string;
+	    api_key: string;
+	    base_url: string;
+	    enabled: boolean;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelProvider(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.api_key = source["api_key"];
+	        this.base_url = source["base_url"];
+	        this.enabled = source["enabled"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModelProviderInput {
+	    id: number;
+	    name: string;
+	    label: string;
+	    api_key: string;
+	    base_url: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelProviderInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.api_key = source["api_key"];
+	        this.base_url = source["base_url"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class OverdueTriageInput {
+	    start_date: string;
+	    days: number;
+	    daily_hours: number;
+	    include_weekends: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OverdueTriageInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.days = source["days"];
+	        this.daily_hours = source["daily_hours"];
+	        this.include_weekends = source["include_weekends"];
+	    }
+	}
+	export class OverdueTriageItem {
+	    task_id: number;
+	    task_name: string;
+	    from_date: string;
+	    to_date: string;
+	    hours: number;
+	    score: number;
+	    slip_count: number;
+	    deadline?: string;
+	    late: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OverdueTriageItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.task_name = source["task_name"];
+	        this.from_date = source["from_date"];
+	        this.to_date = source["to_date"];
+	        this.hours = source["hours"];
+	        this.score = source["score"];
+	        this.slip_count = source["slip_count"];
+	        this.deadline = source["deadline"];
+	        this.late = source["late"];
+	    }
+	}
+	export class UnscheduledTask {
+	    task_id: number;
+	    task_name: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UnscheduledTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.task_name = source["task_name"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class OverdueTriageProposal {
+	    items: OverdueTriageItem[];
+	    unplaced: UnscheduledTask[];
+	    days: DayLoad[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OverdueTriageProposal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], OverdueTriageItem);
+	        this.unplaced = this.convertValues(source["unplaced"], UnscheduledTask);
+	        this.days = this.convertValues(source["days"], DayLoad);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StageCriteria {
+	    type: string;
+	    command: string;
+	    path: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StageCriteria(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.command = source["command"];
+	        this.path = source["path"];
+	        this.text = source["text"];
+	    }
+	}
+	export class PipelineStage {
+	    name: string;
+	    agent_id: number;
+	    input_template: string;
+	    success_criteria?: StageCriteria;
+	    on_failure: string;
+	    max_attempts: number;
+	    artifacts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineStage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.agent_id = source["agent_id"];
+	        this.input_template = source["input_template"];
+	        this.success_criteria = this.convertValues(source["success_criteria"], StageCriteria);
+	        this.on_failure = source["on_failure"];
+	        this.max_attempts = source["max_attempts"];
+	        this.artifacts = source["artifacts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Pipeline {
+	    id: number;
+	    name: string;
+	    description: string;
+	    stages: PipelineStage[];
+	    enabled: boolean;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Pipeline(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.stages = this.convertValues(source["stages"], PipelineStage);
+	        this.enabled = source["enabled"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PipelineArtifact {
+	    id: number;
+	    run_id: number;
+	    stage_run_id: number;
+	    stage_name: string;
+	    kind: string;
+	    name: string;
+	    content: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineArtifact(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.run_id = source["run_id"];
+	        this.stage_run_id = source["stage_run_id"];
+	        this.stage_name = source["stage_name"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.content = source["content"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PipelineInput {
+	    id: number;
+	    name: string;
+	    description: string;
+	    stages: PipelineStage[];
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.stages = this.convertValues(source["stages"], PipelineStage);
+	        this.enabled = source["enabled"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PipelineRun {
+	    id: number;
+	    pipeline_id: number;
+	    pipeline_name: string;
+	    task_id: number;
+	    status: string;
+	    current_stage: number;
+	    context: string;
+	    error: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.pipeline_id = source["pipeline_id"];
+	        this.pipeline_name = source["pipeline_name"];
+	        this.task_id = source["task_id"];
+	        this.status = source["status"];
+	        this.current_stage = source["current_stage"];
+	        this.context = source["context"];
+	        this.error = source["error"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PipelineStageRun {
+	    id: number;
+	    run_id: number;
+	    stage_index: number;
+	    stage_name: string;
+	    agent_id: number;
+	    conversation_id: number;
+	    attempt: number;
+	    status: string;
+	    summary: string;
+	    error: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    finished_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineStageRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.run_id = source["run_id"];
+	        this.stage_index = source["stage_index"];
+	        this.stage_name = source["stage_name"];
+	        this.agent_id = source["agent_id"];
+	        this.conversation_id = source["conversation_id"];
+	        this.attempt = source["attempt"];
+	        this.status = source["status"];
+	        this.summary = source["summary"];
+	        this.error = source["error"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PipelineRunDetail {
+	    run: PipelineRun;
+	    stages: PipelineStage[];
+	    stage_runs: PipelineStageRun[];
+	    artifacts: PipelineArtifact[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PipelineRunDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.run = this.convertValues(source["run"], PipelineRun);
+	        this.stages = this.convertValues(source["stages"], PipelineStage);
+	        this.stage_runs = this.convertValues(source["stage_runs"], PipelineStageRun);
+	        this.artifacts = this.convertValues(source["artifacts"], PipelineArtifact);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class Project {
+	    id: number;
+	    name: string;
+	    description: string;
+	    color: string;
+	    archived: boolean;
+	    // Go type: time
+	    created_at: any;
+	    task_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Project(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.task_count = source["task_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProjectTimeStats {
+	    project_id: number;
+	    project_name: string;
+	    color: string;
+	    total_hours: number;
+	    task_count: number;
+	    percentage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectTimeStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.project_name = source["project_name"];
+	        this.color = source["color"];
+	        this.total_hours = source["total_hours"];
+	        this.task_count = source["task_count"];
+	        this.percentage = source["percentage"];
+	    }
+	}
+	export class ProviderHealth {
+	    provider_id: number;
+	    name: string;
+	    label: string;
+	    total_calls: number;
+	    error_count: number;
+	    error_rate: number;
+	    avg_latency_ms: number;
+	    last_error: string;
+	    last_call_at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider_id = source["provider_id"];
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.total_calls = source["total_calls"];
+	        this.error_count = source["error_count"];
+	        this.error_rate = source["error_rate"];
+	        this.avg_latency_ms = source["avg_latency_ms"];
+	        this.last_error = source["last_error"];
+	        this.last_call_at = source["last_call_at"];
+	    }
+	}
+	export class ProviderModel {
+	    id: string;
+	    owned_by: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.owned_by = source["owned_by"];
+	    }
+	}
+	export class ProviderTestResult {
+	    success: boolean;
+	    latency_ms: number;
+	    status_code: number;
+	    error_type: string;
+	    error: string;
+	    models_supported: boolean;
+	    model_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderTestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.latency_ms = source["latency_ms"];
+	        this.status_code = source["status_code"];
+	        this.error_type = source["error_type"];
+	        this.error = source["error"];
+	        this.models_supported = source["models_supported"];
+	        this.model_count = source["model_count"];
+	    }
+	}
+	export class RecurrencePattern {
+	    type: string;
+	    interval: number;
+	    weekdays: number[];
+	    month_day: number;
+	    nth: number;
+	    weekday: number;
+	    until: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrencePattern(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.interval = source["interval"];
+	        this.weekdays = source["weekdays"];
+	        this.month_day = source["month_day"];
+	        this.nth = source["nth"];
+	        this.weekday = source["weekday"];
+	        this.until = source["until"];
+	        this.count = source["count"];
+	    }
+	}
+	export class RecurrenceInput {
+	    project_id?: number;
+	    name: string;
+	    description: string;
+	    start_time?: string;
+	    end_time?: string;
+	    hours: number;
+	    priority: string;
+	    urgency: string;
+	    rrule: string;
+	    pattern?: RecurrencePattern;
+	    start_date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrenceInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.hours = source["hours"];
+	        this.priority = source["priority"];
+	        this.urgency = source["urgency"];
+	        this.rrule = source["rrule"];
+	        this.pattern = this.convertValues(source["pattern"], RecurrencePattern);
+	        this.start_date = source["start_date"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RejectPlanInput {
+	    plan_id: number;
+	    feedback: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RejectPlanInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plan_id = source["plan_id"];
+	        this.feedback = source["feedback"];
+	    }
+	}
+	export class ReportSummary {
+	    total_tasks: number;
+	    completed_tasks: number;
+	    total_hours: number;
+	    completed_hours: number;
+	    average_rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total_tasks = source["total_tasks"];
+	        this.completed_tasks = source["completed_tasks"];
+	        this.total_hours = source["total_hours"];
+	        this.completed_hours = source["completed_hours"];
+	        this.average_rate = source["average_rate"];
+	    }
+	}
+	export class ReportData {
+	    project_stats: ProjectTimeStats[];
+	    daily_stats: DailyTaskStats[];
+	    summary: ReportSummary;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportData(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_stats = this.convertValues(source["project_stats"], ProjectTimeStats);
+	        this.daily_stats = this.convertValues(source["daily_stats"], DailyTaskStats);
+	        this.summary = this.convertValues(source["summary"], ReportSummary);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskFilter {
+	    project_ids: number[];
+	    statuses: string[];
+	    priorities: string[];
+	    urgencies: string[];
+	    scheduled?: boolean;
+	    date_from: string;
+	    date_to: string;
+	    overdue: boolean;
+	    deadline_from: string;
+	    deadline_to: string;
+	    created_from: string;
+	    created_to: string;
+	    text: string;
+	    parent_id?: number;
+	    top_level: boolean;
+	    tag_ids: number[];
+	    tag_match_all: boolean;
+	    sort_by: string;
+	    sort_desc: boolean;
+	    limit: number;
+	    cursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_ids = source["project_ids"];
+	        this.statuses = source["statuses"];
+	        this.priorities = source["priorities"];
+	        this.urgencies = source["urgencies"];
+	        this.scheduled = source["scheduled"];
+	        this.date_from = source["date_from"];
+	        this.date_to = source["date_to"];
+	        this.overdue = source["overdue"];
+	        this.deadline_from = source["deadline_from"];
+	        this.deadline_to = source["deadline_to"];
+	        this.created_from = source["created_from"];
+	        this.created_to = source["created_to"];
+	        this.text = source["text"];
+	        this.parent_id = source["parent_id"];
+	        this.top_level = source["top_level"];
+	        this.tag_ids = source["tag_ids"];
+	        this.tag_match_all = source["tag_match_all"];
+	        this.sort_by = source["sort_by"];
+	        this.sort_desc = source["sort_desc"];
+	        this.limit = source["limit"];
+	        this.cursor = source["cursor"];
+	    }
+	}
+	export class SavedView {
+	    id: number;
+	    name: string;
+	    filter: TaskFilter;
+	    position: number;
+	    count: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.filter = this.convertValues(source["filter"], TaskFilter);
+	        this.position = source["position"];
+	        this.count = source["count"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SavedViewInput {
+	    id: number;
+	    name: string;
+	    filter: TaskFilter;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedViewInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.filter = this.convertValues(source["filter"], TaskFilter);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScheduleItem {
+	    task_id: number;
+	    task_name: string;
+	    date: string;
+	    start_time: string;
+	    end_time: string;
+	    hours: number;
+	    deadline?: string;
+	    late: boolean;
+	    part: number;
+	    parts: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.task_name = source["task_name"];
+	        this.date = source["date"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.hours = source["hours"];
+	        this.deadline = source["deadline"];
+	        this.late = source["late"];
+	        this.part = source["part"];
+	        this.parts = source["parts"];
+	    }
+	}
+	export class ScheduleProposal {
+	    items: ScheduleItem[];
+	    unscheduled: UnscheduledTask[];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleProposal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ScheduleItem);
+	        this.unscheduled = this.convertValues(source["unscheduled"], UnscheduledTask);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class ScoringConfig {
+	    importance_weight: number;
+	    urgency_weight: number;
+	    deadline_weight: number;
+	    size_weight: number;
+	    age_weight: number;
+	    deadline_horizon: number;
+	    high_urgency_days: number;
+	    medium_urgency_days: number;
+	    matrix_level: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScoringConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.importance_weight = source["importance_weight"];
+	        this.urgency_weight = source["urgency_weight"];
+	        this.deadline_weight = source["deadline_weight"];
+	        this.size_weight = source["size_weight"];
+	        this.age_weight = source["age_weight"];
+	        this.deadline_horizon = source["deadline_horizon"];
+	        this.high_urgency_days = source["high_urgency_days"];
+	        this.medium_urgency_days = source["medium_urgency_days"];
+	        this.matrix_level = source["matrix_level"];
+	    }
+	}
+	export class SearchInput {
+	    query: string;
+	    types: string[];
+	    project_id?: number;
+	    status: string;
+	    start_date: string;
+	    end_date: string;
+	    include_archived: boolean;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = source["query"];
+	        this.types = source["types"];
+	        this.project_id = source["project_id"];
+	        this.status = source["status"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.include_archived = source["include_archived"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class SearchResult {
+	    type: string;
+	    id: number;
+	    title: string;
+	    snippet: string;
+	    score: number;
+	    project_id?: number;
+	    project_name: string;
+	    task_id?: number;
+	    conversation_id?: number;
+	    status: string;
+	    date?: string;
+	    archived: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.snippet = source["snippet"];
+	        this.score = source["score"];
+	        this.project_id = source["project_id"];
+	        this.project_name = source["project_name"];
+	        this.task_id = source["task_id"];
+	        this.conversation_id = source["conversation_id"];
+	        this.status = source["status"];
+	        this.date = source["date"];
+	        this.archived = source["archived"];
+	    }
+	}
+	export class SendMessageInput {
+	    conversation_id: number;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SendMessageInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.conversation_id = source["conversation_id"];
+	        this.content = source["content"];
+	    }
+	}
+	
+	export class StartConversationInput {
+	    task_id: number;
+	    agent_id: number;
+	    extra_context: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartConversationInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.agent_id = source["agent_id"];
+	        this.extra_context = source["extra_context"];
+	    }
+	}
+	export class StartPipelineInput {
+	    pipeline_id: number;
+	    task_id: number;
+	    context: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartPipelineInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pipeline_id = source["pipeline_id"];
+	        this.task_id = source["task_id"];
+	        this.context = source["context"];
+	    }
+	}
+	
+	export class TagTimeStats {
+	    tag_id: number;
+	    tag_name: string;
+	    color: string;
+	    total_hours: number;
+	    task_count: number;
+	    percentage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagTimeStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_id = source["tag_id"];
+	        this.tag_name = source["tag_name"];
+	        this.color = source["color"];
+	        this.total_hours = source["total_hours"];
+	        this.task_count = source["task_count"];
+	        this.percentage = source["percentage"];
+	    }
+	}
+	
+	
+	export class TaskDependencies {
+	    predecessors: Task[];
+	    successors: Task[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskDependencies(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.predecessors = this.convertValues(source["predecessors"], Task);
+	        this.successors = this.convertValues(source["successors"], Task);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskInput {
+	    id: number;
+	    project_id?: number;
+	    name: string;
+	    description: string;
+	    date?: string;
+	    start_time?: string;
+	    end_time?: string;
+	    hours: number;
+	    deadline?: string;
+	    priority: string;
+	    urgency: string;
+	    status: string;
+	    parent_id?: number;
+	    completion_rule: string;
+	    tag_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.date = source["date"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.hours = source["hours"];
+	        this.deadline = source["deadline"];
+	        this.priority = source["priority"];
+	        this.urgency = source["urgency"];
+	        this.status = source["status"];
+	        this.parent_id = source["parent_id"];
+	        this.completion_rule = source["completion_rule"];
+	        this.tag_ids = source["tag_ids"];
+	    }
+	}
+	export class TaskNode {
+	    task: Task;
+	    children: TaskNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.children = this.convertValues(source["children"], TaskNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPage {
+	    tasks: Task[];
+	    total: number;
+	    next_cursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tasks = this.convertValues(source["tasks"], Task);
+	        this.total = source["total"];
+	        this.next_cursor = source["next_cursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPlan {
+	    id: number;
+	    conversation_id: number;
+	    task_id: number;
+	    summary: string;
+	    items: PlanItem[];
+	    status: string;
+	    created_task_ids: number[];
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    reviewed_at?: any;
+	    warnings?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.conversation_id = source["conversation_id"];
+	        this.task_id = source["task_id"];
+	        this.summary = source["summary"];
+	        this.items = this.convertValues(source["items"], PlanItem);
+	        this.status = source["status"];
+	        this.created_task_ids = source["created_task_ids"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.reviewed_at = this.convertValues(source["reviewed_at"], null);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskRecurrence {
+	    id: number;
+	    project_id?: number;
+	    project_name: string;
+	    name: string;
+	    description: string;
+	    start_time?: string;
+	    end_time?: string;
+	    hours: number;
+	    priority: string;
+	    urgency: string;
+	    rrule: string;
+	    start_date: string;
+	    end_date: string;
+	    generated_until: string;
+	    active: boolean;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskRecurrence(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.project_id = source["project_id"];
+	        this.project_name = source["project_name"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.hours = source["hours"];
+	        this.priority = source["priority"];
+	        this.urgency = source["urgency"];
+	        this.rrule = source["rrule"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.generated_until = source["generated_until"];
+	        this.active = source["active"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class TimeEntryInput {
+	    id: number;
+	    task_id: number;
+	    start_at: string;
+	    end_at?: string;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeEntryInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.start_at = source["start_at"];
+	        this.end_at = source["end_at"];
+	        this.note = source["note"];
+	    }
+	}
+	
+	export class UpdateOccurrenceInput {
+	    task: TaskInput;
+	    scope: string;
+	    rrule: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateOccurrenceInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], TaskInput);
+	        this.scope = source["scope"];
+	        this.rrule = source["rrule"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkCalendar {
+	    weekly_hours: number[];
+	    work_start: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkCalendar(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.weekly_hours = source["weekly_hours"];
+	        this.work_start = source["work_start"];
 	    }
 	}
 	export class WorkbenchData {
@@ -609,6 +2626,8 @@ export namespace main {
 	    planned_hours: number;
 	    completed_hours: number;
 	    pending_count: number;
+	    available_hours: number;
+	    overloaded: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new WorkbenchData(source);
@@ -622,6 +2641,8 @@ export namespace main {
 	        this.planned_hours = source["planned_hours"];
 	        this.completed_hours = source["completed_hours"];
 	        this.pending_count = source["pending_count"];
+	        this.available_hours = source["available_hours"];
+	        this.overloaded = source["overloaded"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
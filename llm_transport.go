@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 录制/回放相关环境变量
+const (
+	envLLMRecordDir = "WORKBENCH_LLM_RECORD" // 录制目录：真实请求的请求/响应写入该目录
+	envLLMReplayDir = "WORKBENCH_LLM_REPLAY" // 回放目录：从该目录读取响应，不访问网络
+)
+
+var (
+	llmTransportMu sync.RWMutex
+	llmTransport   http.RoundTripper // nil 表示使用 http.DefaultTransport
+)
+
+// SetLLMTransport 替换所有LLM请求使用的传输层（nil 恢复默认），返回原传输层
+func SetLLMTransport(rt http.RoundTripper) http.RoundTripper {
+	llmTransportMu.Lock()
+	defer llmTransportMu.Unlock()
+	old := llmTransport
+	llmTransport = rt
+	return old
+}
+
+// newLLMClient 创建LLM请求使用的 HTTP 客户端
+func newLLMClient(timeout time.Duration) *http.Client {
+	llmTransportMu.RLock()
+	defer llmTransportMu.RUnlock()
+	return &http.Client{Timeout: timeout, Transport: llmTransport}
+}
+
+// initLLMTransportFromEnv 根据环境变量启用录制或回放模式
+func initLLMTransportFromEnv() error {
+	if dir := os.Getenv(envLLMReplayDir); dir != "" {
+		rt, err := NewReplayTransport(dir)
+		if err != nil {
+			return err
+		}
+		SetLLMTransport(rt)
+		log.Printf("LLM回放模式: %s", dir)
+		return nil
+	}
+
+	if dir := os.Getenv(envLLMRecordDir); dir != "" {
+		rt, err := NewRecordingTransport(dir, nil)
+		if err != nil {
+			return err
+		}
+		SetLLMTransport(rt)
+		log.Printf("LLM录制模式: %s", dir)
+	}
+	return nil
+}
+
+// LLMFixture 一次录制的请求/响应
+type LLMFixture struct {
+	Request  LLMFixtureRequest  `json:"request"`
+	Response LLMFixtureResponse `json:"response"`
+}
+
+// LLMFixtureRequest 录制的请求（不包含 Authorization 等敏感头）
+type LLMFixtureRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// LLMFixtureResponse 录制的响应
+type LLMFixtureResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// RecordingTransport 将真实请求/响应按顺序写入录制目录
+type RecordingTransport struct {
+	dir   string
+	inner http.RoundTripper
+	mu    sync.Mutex
+	seq   int
+}
+
+// NewRecordingTransport 创建录制传输层，inner 为 nil 时使用 http.DefaultTransport
+func NewRecordingTransport(dir string, inner http.RoundTripper) (*RecordingTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %v", err)
+	}
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+
+	existing, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return &RecordingTransport{dir: dir, inner: inner, seq: len(existing)}, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readAndRestoreBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readAndRestoreBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := LLMFixture{
+		Request:  LLMFixtureRequest{Method: req.Method, Path: req.URL.Path, Body: asRawJSON(reqBody)},
+		Response: LLMFixtureResponse{StatusCode: resp.StatusCode, Body: asRawJSON(respBody)},
+	}
+
+	t.mu.Lock()
+	t.seq++
+	path := filepath.Join(t.dir, fmt.Sprintf("%04d.json", t.seq))
+	t.mu.Unlock()
+
+	data, _ := json.MarshalIndent(fixture, "", "  ")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("写入录制文件失败: %v", err)
+	}
+
+	return resp, nil
+}
+
+// ReplayTransport 按录制顺序回放响应，不访问网络
+type ReplayTransport struct {
+	mu       sync.Mutex
+	fixtures []LLMFixture
+	next     int
+}
+
+// NewReplayTransport 从录制目录加载回放数据（按文件名排序）
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("读取回放目录失败: %v", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("回放目录为空: %s", dir)
+	}
+	sort.Strings(files)
+
+	var fixtures []LLMFixture
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("读取回放文件失败: %v", err)
+		}
+		var fixture LLMFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("解析回放文件 %s 失败: %v", filepath.Base(f), err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return &ReplayTransport{fixtures: fixtures}, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.next >= len(t.fixtures) {
+		return nil, fmt.Errorf("回放数据已用完（共 %d 条）", len(t.fixtures))
+	}
+	fixture := t.fixtures[t.next]
+	t.next++
+
+	if fixture.Request.Path != "" && fixture.Request.Path != req.URL.Path {
+		return nil, fmt.Errorf("回放请求不匹配: 期望 %s, 实际 %s", fixture.Request.Path, req.URL.Path)
+	}
+
+	return &http.Response{
+		StatusCode: fixture.Response.StatusCode,
+		Status:     fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(fixture.Response.Body)),
+		Request:    req,
+	}, nil
+}
+
+// Remaining 剩余未回放的条数
+func (t *ReplayTransport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.fixtures) - t.next
+}
+
+// readAndRestoreBody 读取 body 并放回可再次读取的副本
+func readAndRestoreBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取 body 失败: %v", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// asRawJSON 将 body 转为 JSON，非 JSON 内容作为字符串保存
+func asRawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
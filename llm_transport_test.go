@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ScriptedLLMReply 脚本化提供商的一次应答
+type ScriptedLLMReply struct {
+	Content    string // 助手回复内容
+	StatusCode int    // 非0时返回该状态码和 Error 信息
+	Error      string
+}
+
+// ScriptedLLMServer 按脚本顺序应答的 OpenAI 兼容假提供商（基于 httptest）
+type ScriptedLLMServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	replies  []ScriptedLLMReply
+	requests []ChatRequest
+}
+
+// NewScriptedLLMServer 启动脚本化假提供商，测试结束时自动关闭
+func NewScriptedLLMServer(t *testing.T, replies ...ScriptedLLMReply) *ScriptedLLMServer {
+	s := &ScriptedLLMServer{replies: replies}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Provider 返回指向该服务的模型提供商配置
+func (s *ScriptedLLMServer) Provider() *ModelProvider {
+	return &ModelProvider{Name: "scripted", Label: "Scripted", APIKey: "test-key", BaseURL: s.URL, Enabled: true}
+}
+
+// Requests 返回收到的全部 chat 请求
+func (s *ScriptedLLMServer) Requests() []ChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ChatRequest(nil), s.requests...)
+}
+
+func (s *ScriptedLLMServer) handle(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if req.Method == "GET" && strings.HasSuffix(req.URL.Path, "/models") {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []ProviderModel{{ID: "scripted-model", OwnedBy: "scripted"}},
+		})
+		return
+	}
+
+	var chatReq ChatRequest
+	json.NewDecoder(req.Body).Decode(&chatReq)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, chatReq)
+	idx := len(s.requests) - 1
+	s.mu.Unlock()
+
+	if idx >= len(s.replies) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error":{"message":"脚本已用完（共 %d 条）"}}`, len(s.replies))
+		return
+	}
+
+	reply := s.replies[idx]
+	if reply.StatusCode != 0 && reply.StatusCode != http.StatusOK {
+		w.WriteHeader(reply.StatusCode)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]string{"message": reply.Error},
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(ChatResponse{
+		Choices: []ChatChoice{{
+			Message:      ChatMessage{Role: "assistant", Content: reply.Content},
+			FinishReason: "stop",
+		}},
+	})
+}
+
+// TestRecordThenReplay 录制的请求/响应可以原样回放
+func TestRecordThenReplay(t *testing.T) {
+	server := NewScriptedLLMServer(t,
+		ScriptedLLMReply{Content: "第一条"},
+		ScriptedLLMReply{StatusCode: http.StatusTooManyRequests, Error: "rate limited"},
+	)
+	dir := t.TempDir()
+
+	recorder, err := NewRecordingTransport(dir, nil)
+	if err != nil {
+		t.Fatalf("创建录制传输层失败: %v", err)
+	}
+	target := llmTarget{provider: server.Provider(), model: "scripted-model"}
+	executor := &ReActExecutor{}
+	messages := []ChatMessage{{Role: "user", Content: "hi"}}
+
+	old := SetLLMTransport(recorder)
+	defer SetLLMTransport(old)
+	if content, err := executor.callChatCompletion(target, messages); err != nil || content != "第一条" {
+		t.Fatalf("录制第一条: content=%q err=%v", content, err)
+	}
+	if _, err := executor.callChatCompletion(target, messages); err == nil {
+		t.Fatalf("录制第二条应返回错误")
+	}
+
+	replay, err := NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("加载回放失败: %v", err)
+	}
+	SetLLMTransport(replay)
+	server.Close() // 回放不访问网络
+
+	if content, err := executor.callChatCompletion(target, messages); err != nil || content != "第一条" {
+		t.Fatalf("回放第一条: content=%q err=%v", content, err)
+	}
+	_, err = executor.callChatCompletion(target, messages)
+	callErr, ok := err.(*llmCallError)
+	if !ok || callErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("回放第二条应返回 429，实际: %v", err)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("回放数据应已用完，剩余 %d", replay.Remaining())
+	}
+	if _, err := executor.callChatCompletion(target, messages); err == nil {
+		t.Fatalf("回放数据用完后应返回错误")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +13,15 @@ import (
 var assets embed.FS
 
 func main() {
+	// MCP 服务器模式: Workbench mcp
+	if len(os.Args) > 1 && os.Args[1] == "mcp" {
+		if err := RunMCPServer(); err != nil {
+			println("Error:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
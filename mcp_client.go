@@ -0,0 +1,885 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mcpToolPrefix MCP 工具在注册表中的名称前缀：mcp__<服务器>__<工具>
+const mcpToolPrefix = "mcp__"
+
+// mcpCallTimeout 单次 MCP 请求超时
+const mcpCallTimeout = 2 * time.Minute
+
+// mcpServerNamePattern MCP 服务器名称格式
+var mcpServerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,39}$`)
+
+// mcpToolName 组合注册表中的工具名称
+func mcpToolName(server, tool string) string {
+	return mcpToolPrefix + server + "__" + tool
+}
+
+// mcpTransport MCP 传输层
+type mcpTransport interface {
+	// roundTrip 发送请求并等待对应 id 的响应；通知（无 id）不等待响应
+	roundTrip(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, error)
+	// alive 连接是否仍可用（stdio 服务器进程退出或被结束后返回 false）
+	alive() bool
+	close() error
+}
+
+// MCPClient MCP 客户端
+type MCPClient struct {
+	server    MCPServer
+	transport mcpTransport
+	mu        sync.Mutex
+	nextID    int64
+}
+
+// ConnectMCPServer 连接 MCP 服务器并完成初始化握手
+func ConnectMCPServer(server MCPServer) (*MCPClient, error) {
+	var transport mcpTransport
+	var err error
+	switch server.Transport {
+	case MCPTransportStdio, "":
+		transport, err = newMCPStdioTransport(server)
+	case MCPTransportHTTP:
+		transport = &mcpHTTPTransport{url: server.URL, client: &http.Client{Timeout: mcpCallTimeout}}
+	default:
+		err = fmt.Errorf("不支持的 MCP 传输方式: %s", server.Transport)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &MCPClient{server: server, transport: transport}
+	if err := c.initialize(); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("MCP 服务器 %s 初始化失败: %v", server.Name, err)
+	}
+	log.Printf("已连接 MCP 服务器: %s (%s)", server.Name, server.Transport)
+	return c, nil
+}
+
+// initialize 初始化握手
+func (c *MCPClient) initialize() error {
+	params := map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "workbench", "version": "1.0.0"},
+	}
+	if _, err := c.call("initialize", params); err != nil {
+		return err
+	}
+	return c.notify("notifications/initialized", nil)
+}
+
+// call 发送请求并返回结果
+func (c *MCPClient) call(method string, params interface{}) (json.RawMessage, error) {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	msg := &jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprintf("%d", id)), Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("序列化参数失败: %v", err)
+		}
+		msg.Params = data
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpCallTimeout)
+	defer cancel()
+
+	resp, err := c.transport.roundTrip(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+// notify 发送通知
+func (c *MCPClient) notify(method string, params interface{}) error {
+	msg := &jsonrpcMessage{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		data, _ := json.Marshal(params)
+		msg.Params = data
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mcpCallTimeout)
+	defer cancel()
+	_, err := c.transport.roundTrip(ctx, msg)
+	return err
+}
+
+// ListTools 获取服务器提供的全部工具（处理分页）
+func (c *MCPClient) ListTools() ([]MCPToolInfo, error) {
+	var tools []MCPToolInfo
+	cursor := ""
+	for {
+		var params interface{}
+		if cursor != "" {
+			params = map[string]string{"cursor": cursor}
+		}
+		result, err := c.call("tools/list", params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Tools      []MCPToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
+			return nil, fmt.Errorf("解析工具列表失败: %v", err)
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool 调用工具
+func (c *MCPClient) CallTool(name string, args json.RawMessage) (*MCPCallResult, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	result, err := c.call("tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if err != nil {
+		return nil, err
+	}
+
+	var callResult MCPCallResult
+	if err := json.Unmarshal(result, &callResult); err != nil {
+		return nil, fmt.Errorf("解析工具结果失败: %v", err)
+	}
+	return &callResult, nil
+}
+
+// Close 关闭连接（stdio 模式会结束服务器进程）
+func (c *MCPClient) Close() error {
+	return c.transport.close()
+}
+
+// alive 连接是否仍可用
+func (c *MCPClient) alive() bool {
+	return c.transport.alive()
+}
+
+// ========== stdio 传输 ==========
+
+// mcpStderrLimit stdio 服务器 stderr 保留的最大字节数（只保留最近的输出，用于错误信息）
+const mcpStderrLimit = 16 * 1024
+
+// mcpStderrBuffer 收集服务器进程的 stderr，复制协程写入与读取错误信息可能并发，需加锁
+type mcpStderrBuffer struct {
+	mu   sync.Mutex
+	buf  []byte
+	done chan struct{} // stderr 读取结束后关闭
+}
+
+func (b *mcpStderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > mcpStderrLimit {
+		b.buf = append([]byte(nil), b.buf[len(b.buf)-mcpStderrLimit:]...)
+	}
+	return len(p), nil
+}
+
+func (b *mcpStderrBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+// mcpStdioTransport 通过子进程的 stdin/stdout 传输换行分隔的 JSON-RPC 消息
+// 由一个读取协程按 id 分发响应，进程退出或被结束后读取协程随之退出
+type mcpStdioTransport struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	stderr  *mcpStderrBuffer
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan *jsonrpcMessage // 等待响应的请求 id
+	done    chan struct{}                   // 读取协程退出后关闭
+	err     error                           // 读取协程退出的原因（done 关闭后可读）
+
+	closeOnce sync.Once
+}
+
+func newMCPStdioTransport(server MCPServer) (*mcpStdioTransport, error) {
+	if server.Command == "" {
+		return nil, fmt.Errorf("MCP 服务器 %s 未配置启动命令", server.Name)
+	}
+
+	var args []string
+	if server.Args != "" && server.Args != "[]" {
+		if err := json.Unmarshal([]byte(server.Args), &args); err != nil {
+			return nil, fmt.Errorf("命令参数格式错误: %v", err)
+		}
+	}
+	env := os.Environ()
+	if server.Env != "" && server.Env != "{}" {
+		var extra map[string]string
+		if err := json.Unmarshal([]byte(server.Env), &extra); err != nil {
+			return nil, fmt.Errorf("环境变量格式错误: %v", err)
+		}
+		for k, v := range extra {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	cmd := exec.Command(server.Command, args...)
+	cmd.Env = env
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建输入管道失败: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建输出管道失败: %v", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建错误管道失败: %v", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 MCP 服务器失败: %v", err)
+	}
+
+	stderr := &mcpStderrBuffer{done: make(chan struct{})}
+	go func() {
+		io.Copy(stderr, stderrPipe)
+		close(stderr.done)
+	}()
+
+	t := &mcpStdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+		pending: make(map[string]chan *jsonrpcMessage),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop()
+	return t, nil
+}
+
+// readLoop 读取服务器输出，把响应交给对应的请求
+func (t *mcpStdioTransport) readLoop() {
+	reader := bufio.NewReader(t.stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// 进程退出时 stderr 可能还没读完，稍等片刻以便错误信息包含最后的输出
+			select {
+			case <-t.stderr.done:
+			case <-time.After(time.Second):
+			}
+			t.err = fmt.Errorf("读取 MCP 响应失败: %v %s", err, strings.TrimSpace(t.stderr.String()))
+			close(t.done)
+			return
+		}
+
+		var resp jsonrpcMessage
+		if err := json.Unmarshal(line, &resp); err != nil {
+			// 非 JSON 输出（如日志）忽略
+			continue
+		}
+		if resp.isResponse() {
+			t.mu.Lock()
+			ch := t.pending[string(resp.ID)]
+			delete(t.pending, string(resp.ID))
+			t.mu.Unlock()
+			if ch != nil {
+				ch <- &resp
+			}
+			continue
+		}
+		if resp.Method != "" && len(resp.ID) > 0 {
+			// 服务器发来的请求（如 sampling），本客户端不支持
+			t.write(&jsonrpcMessage{JSONRPC: "2.0", ID: resp.ID,
+				Error: &jsonrpcError{Code: jsonrpcMethodNotFound, Message: "客户端不支持: " + resp.Method}})
+		}
+	}
+}
+
+// write 写入一条消息
+func (t *mcpStdioTransport) write(msg *jsonrpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入 MCP 请求失败: %v", err)
+	}
+	return nil
+}
+
+func (t *mcpStdioTransport) roundTrip(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, error) {
+	if len(msg.ID) == 0 {
+		return nil, t.write(msg)
+	}
+
+	ch := make(chan *jsonrpcMessage, 1)
+	t.mu.Lock()
+	select {
+	case <-t.done:
+		t.mu.Unlock()
+		return nil, t.err
+	default:
+	}
+	t.pending[string(msg.ID)] = ch
+	t.mu.Unlock()
+
+	if err := t.write(msg); err != nil {
+		t.forget(msg.ID)
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-t.done:
+		return nil, t.err
+	case <-ctx.Done():
+		// 超时后进程状态未知，结束进程，读取协程随之退出
+		t.forget(msg.ID)
+		t.kill()
+		return nil, fmt.Errorf("MCP 请求超时: %s", msg.Method)
+	}
+}
+
+// forget 不再等待该请求的响应
+func (t *mcpStdioTransport) forget(id json.RawMessage) {
+	t.mu.Lock()
+	delete(t.pending, string(id))
+	t.mu.Unlock()
+}
+
+// kill 结束服务器进程，并关闭输出管道使读取协程立即退出（子进程可能仍占用管道）
+func (t *mcpStdioTransport) kill() {
+	t.cmd.Process.Kill()
+	t.stdout.Close()
+}
+
+func (t *mcpStdioTransport) alive() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (t *mcpStdioTransport) close() error {
+	t.closeOnce.Do(func() {
+		t.stdin.Close()
+		exited := make(chan error, 1)
+		go func() { exited <- t.cmd.Wait() }()
+		select {
+		case <-exited:
+		case <-time.After(3 * time.Second):
+			t.kill()
+			<-exited
+		}
+		<-t.done
+	})
+	return nil
+}
+
+// ========== HTTP 传输 ==========
+
+// mcpHTTPTransport 通过 HTTP POST 传输（兼容 JSON 和 SSE 响应）
+type mcpHTTPTransport struct {
+	url       string
+	client    *http.Client
+	sessionID string
+	mu        sync.Mutex
+}
+
+func (t *mcpHTTPTransport) roundTrip(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("MCP 请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" {
+		t.mu.Lock()
+		t.sessionID = sid
+		t.mu.Unlock()
+	}
+
+	if len(msg.ID) == 0 {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("MCP 服务器返回 HTTP %d: %s", resp.StatusCode, truncateString(string(body), 200))
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readSSEResponse(resp.Body, msg.ID)
+	}
+
+	var result jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析 MCP 响应失败: %v", err)
+	}
+	return &result, nil
+}
+
+// readSSEResponse 从 SSE 流中读取与请求 id 对应的响应
+func readSSEResponse(body io.Reader, id json.RawMessage) (*jsonrpcMessage, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			continue
+		}
+		if line == "" && data.Len() > 0 {
+			var msg jsonrpcMessage
+			if err := json.Unmarshal([]byte(data.String()), &msg); err == nil &&
+				msg.isResponse() && string(msg.ID) == string(id) {
+				return &msg, nil
+			}
+			data.Reset()
+		}
+	}
+	if data.Len() > 0 {
+		var msg jsonrpcMessage
+		if err := json.Unmarshal([]byte(data.String()), &msg); err == nil && msg.isResponse() {
+			return &msg, nil
+		}
+	}
+	return nil, fmt.Errorf("SSE 流中未找到响应")
+}
+
+func (t *mcpHTTPTransport) alive() bool {
+	return true
+}
+
+func (t *mcpHTTPTransport) close() error {
+	return nil
+}
+
+// ========== 工具执行器集成 ==========
+
+// mcpRoute 注册表中 MCP 工具到服务器的映射
+type mcpRoute struct {
+	client *MCPClient
+	tool   string // 服务器上的原始工具名
+}
+
+// AttachMCPClients 将已连接的 MCP 服务器的工具注册到执行器（连接由会话管理，执行器不负责关闭）
+func (e *ToolExecutor) AttachMCPClients(clients []*MCPClient) {
+	for _, client := range clients {
+		tools, err := client.ListTools()
+		if err != nil {
+			log.Printf("获取 MCP 工具列表失败: %s, %v", client.server.Name, err)
+			continue
+		}
+		for _, tool := range tools {
+			if err := e.registerMCPTool(client, tool); err != nil {
+				log.Printf("注册 MCP 工具失败: %v", err)
+			}
+		}
+	}
+}
+
+// registerMCPTool 注册单个 MCP 工具
+func (e *ToolExecutor) registerMCPTool(client *MCPClient, tool MCPToolInfo) error {
+	schema := string(tool.InputSchema)
+	if strings.TrimSpace(schema) == "" || schema == "null" {
+		schema = `{"type": "object", "properties": {}}`
+	}
+	name := mcpToolName(client.server.Name, tool.Name)
+
+	err := e.registry.Register(AgentTool{
+		Name:        name,
+		Description: tool.Description,
+		Type:        "mcp",
+		Schema:      schema,
+	})
+	if err != nil {
+		return err
+	}
+	e.mcpRoutes[name] = mcpRoute{client: client, tool: tool.Name}
+	return nil
+}
+
+// executeMCPTool 将调用转发到 MCP 服务器
+func (e *ToolExecutor) executeMCPTool(route mcpRoute, inputJSON string) ToolResult {
+	result, err := route.client.CallTool(route.tool, json.RawMessage(inputJSON))
+	if err != nil {
+		return ToolResult{Success: false, Error: fmt.Sprintf("MCP 工具调用失败: %v", err)}
+	}
+	if result.IsError {
+		return ToolResult{Success: false, Output: result.text(), Error: "MCP 工具返回错误"}
+	}
+	return ToolResult{Success: true, Output: result.text()}
+}
+
+// ========== 会话级连接 ==========
+
+// mcpSessions 各会话连接的 MCP 服务器：同一会话的多轮执行（如回复 ask_user 后继续）复用同一个服务器进程，
+// 会话结束（完成、失败或停止）时关闭
+var mcpSessions = struct {
+	sync.Mutex
+	clients map[int64]map[string]*MCPClient // 会话ID → 服务器名称 → 客户端
+}{clients: make(map[int64]map[string]*MCPClient)}
+
+// conversationMCPClients 获取会话的 MCP 客户端，尚未连接或连接已断开的服务器重新连接
+func conversationMCPClients(conversationID int64, servers []MCPServer) []*MCPClient {
+	var clients []*MCPClient
+	for _, server := range servers {
+		mcpSessions.Lock()
+		client := mcpSessions.clients[conversationID][server.Name]
+		mcpSessions.Unlock()
+		if client != nil && client.alive() {
+			clients = append(clients, client)
+			continue
+		}
+		if client != nil {
+			client.Close()
+		}
+
+		client, err := ConnectMCPServer(server)
+		if err != nil {
+			log.Printf("连接 MCP 服务器失败: %v", err)
+			continue
+		}
+		mcpSessions.Lock()
+		if mcpSessions.clients[conversationID] == nil {
+			mcpSessions.clients[conversationID] = make(map[string]*MCPClient)
+		}
+		mcpSessions.clients[conversationID][server.Name] = client
+		mcpSessions.Unlock()
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// closeConversationMCP 关闭会话连接的全部 MCP 服务器
+func closeConversationMCP(conversationID int64) {
+	mcpSessions.Lock()
+	session := mcpSessions.clients[conversationID]
+	delete(mcpSessions.clients, conversationID)
+	mcpSessions.Unlock()
+
+	for name, client := range session {
+		client.Close()
+		log.Printf("已关闭会话 %d 的 MCP 服务器: %s", conversationID, name)
+	}
+}
+
+// releaseConversationMCP 会话已结束时关闭其 MCP 服务器，等待用户回复或仍在执行时保留
+func releaseConversationMCP(conversationID int64) {
+	var status string
+	if db != nil {
+		db.QueryRow(`SELECT status FROM task_conversations WHERE id = ?`, conversationID).Scan(&status)
+	}
+	if status == ConversationStatusWaitingUser || status == ConversationStatusActive {
+		return
+	}
+	closeConversationMCP(conversationID)
+}
+
+// closeAllMCPSessions 关闭全部会话的 MCP 服务器（应用退出时调用）
+func closeAllMCPSessions() {
+	mcpSessions.Lock()
+	ids := make([]int64, 0, len(mcpSessions.clients))
+	for id := range mcpSessions.clients {
+		ids = append(ids, id)
+	}
+	mcpSessions.Unlock()
+
+	for _, id := range ids {
+		closeConversationMCP(id)
+	}
+}
+
+// mcpServersForTools 从工具列表中找出引用的 MCP 服务器名称（mcp__<server> 或 mcp__<server>__<tool>）
+func mcpServersForTools(toolNames []string) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, name := range toolNames {
+		if !strings.HasPrefix(name, mcpToolPrefix) {
+			continue
+		}
+		server := strings.SplitN(strings.TrimPrefix(name, mcpToolPrefix), "__", 2)[0]
+		if server != "" && !seen[server] {
+			seen[server] = true
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// expandMCPToolNames 将 mcp__<server> 展开为该服务器已注册的全部工具
+func expandMCPToolNames(toolNames []string, registry *ToolRegistry) []string {
+	var result []string
+	for _, name := range toolNames {
+		rest := strings.TrimPrefix(name, mcpToolPrefix)
+		if !strings.HasPrefix(name, mcpToolPrefix) || strings.Contains(rest, "__") {
+			result = append(result, name)
+			continue
+		}
+
+		var expanded []string
+		for toolName := range registry.tools {
+			if strings.HasPrefix(toolName, name+"__") {
+				expanded = append(expanded, toolName)
+			}
+		}
+		sort.Strings(expanded)
+		result = append(result, expanded...)
+	}
+	return result
+}
+
+// ========== MCP 服务器配置 CRUD ==========
+
+// 查询 MCP 服务器的基础 SQL
+const mcpServerSelectSQL = `
+	SELECT id, name, transport, command, args, env, url, enabled, created_at
+	FROM mcp_servers
+`
+
+// scanMCPServer 扫描 MCP 服务器
+func scanMCPServer(row interface{ Scan(...any) error }) (MCPServer, error) {
+	var s MCPServer
+	err := row.Scan(&s.ID, &s.Name, &s.Transport, &s.Command, &s.Args, &s.Env, &s.URL, &s.Enabled, &s.CreatedAt)
+	return s, err
+}
+
+// GetMCPServers 获取所有 MCP 服务器配置
+func (a *App) GetMCPServers() ([]MCPServer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(mcpServerSelectSQL + `ORDER BY name`)
+	if err != nil {
+		log.Printf("查询MCP服务器失败: %v", err)
+		return nil, fmt.Errorf("查询MCP服务器失败: %v", err)
+	}
+	defer rows.Close()
+
+	var servers []MCPServer
+	for rows.Next() {
+		s, err := scanMCPServer(rows)
+		if err != nil {
+			log.Printf("扫描MCP服务器失败: %v", err)
+			return nil, fmt.Errorf("扫描MCP服务器失败: %v", err)
+		}
+		servers = append(servers, s)
+	}
+	return servers, nil
+}
+
+// getEnabledMCPServersByName 按名称获取已启用的 MCP 服务器
+func getEnabledMCPServersByName(names []string) ([]MCPServer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	var servers []MCPServer
+	for _, name := range names {
+		s, err := scanMCPServer(db.QueryRow(mcpServerSelectSQL+`WHERE name = ? AND enabled = 1`, name))
+		if err != nil {
+			log.Printf("MCP服务器不存在或未启用: %s", name)
+			continue
+		}
+		servers = append(servers, s)
+	}
+	return servers, nil
+}
+
+// validateMCPServerInput 校验 MCP 服务器配置
+func validateMCPServerInput(input *MCPServerInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if !mcpServerNamePattern.MatchString(input.Name) {
+		return fmt.Errorf("服务器名称只能包含小写字母、数字、下划线和短横线，且以字母开头")
+	}
+	if strings.Contains(input.Name, "__") {
+		return fmt.Errorf("服务器名称不能包含连续下划线")
+	}
+
+	if input.Transport == "" {
+		input.Transport = MCPTransportStdio
+	}
+	switch input.Transport {
+	case MCPTransportStdio:
+		if strings.TrimSpace(input.Command) == "" {
+			return fmt.Errorf("stdio 模式必须配置启动命令")
+		}
+	case MCPTransportHTTP:
+		if !strings.HasPrefix(input.URL, "http://") && !strings.HasPrefix(input.URL, "https://") {
+			return fmt.Errorf("http 模式必须配置有效的服务地址")
+		}
+	default:
+		return fmt.Errorf("不支持的传输方式: %s", input.Transport)
+	}
+
+	if input.Args == "" {
+		input.Args = "[]"
+	}
+	var args []string
+	if err := json.Unmarshal([]byte(input.Args), &args); err != nil {
+		return fmt.Errorf("命令参数必须是字符串数组: %v", err)
+	}
+	if input.Env == "" {
+		input.Env = "{}"
+	}
+	var env map[string]string
+	if err := json.Unmarshal([]byte(input.Env), &env); err != nil {
+		return fmt.Errorf("环境变量必须是字符串对象: %v", err)
+	}
+	return nil
+}
+
+// CreateMCPServer 创建 MCP 服务器配置
+func (a *App) CreateMCPServer(input MCPServerInput) (*MCPServer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	if err := validateMCPServerInput(&input); err != nil {
+		return nil, err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO mcp_servers (name, transport, command, args, env, url, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Transport, input.Command, input.Args, input.Env, input.URL, input.Enabled)
+	if err != nil {
+		log.Printf("创建MCP服务器失败: %v", err)
+		return nil, fmt.Errorf("创建MCP服务器失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取MCP服务器ID失败: %v", err)
+	}
+
+	s, err := scanMCPServer(db.QueryRow(mcpServerSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("查询MCP服务器失败: %v", err)
+	}
+
+	log.Printf("创建MCP服务器成功: %s (ID: %d)", input.Name, id)
+	return &s, nil
+}
+
+// UpdateMCPServer 更新 MCP 服务器配置
+func (a *App) UpdateMCPServer(input MCPServerInput) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+	if err := validateMCPServerInput(&input); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE mcp_servers
+		SET name = ?, transport = ?, command = ?, args = ?, env = ?, url = ?, enabled = ?
+		WHERE id = ?
+	`, input.Name, input.Transport, input.Command, input.Args, input.Env, input.URL, input.Enabled, input.ID)
+	if err != nil {
+		log.Printf("更新MCP服务器失败: %v", err)
+		return fmt.Errorf("更新MCP服务器失败: %v", err)
+	}
+
+	log.Printf("更新MCP服务器成功: ID=%d", input.ID)
+	return nil
+}
+
+// DeleteMCPServer 删除 MCP 服务器配置
+func (a *App) DeleteMCPServer(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	_, err := db.Exec(`DELETE FROM mcp_servers WHERE id = ?`, id)
+	if err != nil {
+		log.Printf("删除MCP服务器失败: %v", err)
+		return fmt.Errorf("删除MCP服务器失败: %v", err)
+	}
+
+	log.Printf("删除MCP服务器成功: ID=%d", id)
+	return nil
+}
+
+// DiscoverMCPTools 连接 MCP 服务器并返回其提供的工具（用于测试连接和选择工具）
+func (a *App) DiscoverMCPTools(id int64) ([]MCPToolInfo, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	server, err := scanMCPServer(db.QueryRow(mcpServerSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("MCP服务器不存在: %v", err)
+	}
+
+	client, err := ConnectMCPServer(server)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools()
+	if err != nil {
+		return nil, fmt.Errorf("获取MCP工具列表失败: %v", err)
+	}
+	for i := range tools {
+		tools[i].Name = mcpToolName(server.Name, tools[i].Name)
+	}
+	return tools, nil
+}
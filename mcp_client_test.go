@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// StubMCPTool 桩服务器上的工具：固定描述、Schema 和处理函数
+type StubMCPTool struct {
+	Info    MCPToolInfo
+	Handler func(args json.RawMessage) MCPCallResult
+}
+
+// stubMCPHandler 桩 MCP 服务器
+type stubMCPHandler struct {
+	name  string
+	tools []StubMCPTool
+}
+
+func (h *stubMCPHandler) ServerName() string { return h.name }
+
+func (h *stubMCPHandler) ListTools() []MCPToolInfo {
+	var infos []MCPToolInfo
+	for _, t := range h.tools {
+		infos = append(infos, t.Info)
+	}
+	return infos
+}
+
+func (h *stubMCPHandler) CallTool(name string, args json.RawMessage) MCPCallResult {
+	for _, t := range h.tools {
+		if t.Info.Name == name {
+			return t.Handler(args)
+		}
+	}
+	return mcpTextResult("未知工具: "+name, true)
+}
+
+// NewStubMCPServer 启动本地桩 MCP 服务器（HTTP 传输），测试结束时自动关闭
+func NewStubMCPServer(t *testing.T, name string, tools ...StubMCPTool) *httptest.Server {
+	d := &mcpDispatcher{handler: &stubMCPHandler{name: name, tools: tools}}
+	server := httptest.NewServer(mcpHTTPHandler(d))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// echoStubTool 原样返回 msg 参数
+var echoStubTool = StubMCPTool{
+	Info: MCPToolInfo{
+		Name:        "echo",
+		Description: "返回输入的文本",
+		InputSchema: json.RawMessage(`{"type": "object", "properties": {"msg": {"type": "string"}}, "required": ["msg"]}`),
+	},
+	Handler: func(args json.RawMessage) MCPCallResult {
+		var p struct {
+			Msg string `json:"msg"`
+		}
+		json.Unmarshal(args, &p)
+		return mcpTextResult(p.Msg, false)
+	},
+}
+
+// helperStubTools 测试辅助进程提供的工具
+func helperStubTools() []StubMCPTool {
+	return []StubMCPTool{
+		echoStubTool,
+		{
+			Info: MCPToolInfo{Name: "pid", Description: "返回进程号"},
+			Handler: func(json.RawMessage) MCPCallResult {
+				return mcpTextResult(fmt.Sprint(os.Getpid()), false)
+			},
+		},
+		{
+			Info: MCPToolInfo{Name: "noisy", Description: "向 stderr 输出大量日志"},
+			Handler: func(json.RawMessage) MCPCallResult {
+				for i := 0; i < 200; i++ {
+					fmt.Fprintf(os.Stderr, "log line %d\n", i)
+				}
+				return mcpTextResult("ok", false)
+			},
+		},
+		{
+			Info: MCPToolInfo{Name: "hang", Description: "永不返回"},
+			Handler: func(json.RawMessage) MCPCallResult {
+				select {}
+			},
+		},
+		{
+			Info: MCPToolInfo{Name: "crash", Description: "输出错误后退出"},
+			Handler: func(json.RawMessage) MCPCallResult {
+				fmt.Fprintln(os.Stderr, "boom: fatal error")
+				os.Exit(3)
+				return MCPCallResult{}
+			},
+		},
+	}
+}
+
+// TestMCPHelperProcess 作为 stdio MCP 服务器运行的辅助进程（仅在设置环境变量时生效）
+func TestMCPHelperProcess(t *testing.T) {
+	if os.Getenv("WORKBENCH_MCP_HELPER") != "1" {
+		return
+	}
+	d := &mcpDispatcher{handler: &stubMCPHandler{name: "helper", tools: helperStubTools()}}
+	serveMCPStdio(os.Stdin, os.Stdout, d)
+	os.Exit(0)
+}
+
+// helperMCPServer 以测试程序自身作为 stdio MCP 服务器
+func helperMCPServer() MCPServer {
+	return MCPServer{
+		Name:      "helper",
+		Transport: MCPTransportStdio,
+		Command:   os.Args[0],
+		Args:      `["-test.run=^TestMCPHelperProcess$"]`,
+		Env:       `{"WORKBENCH_MCP_HELPER": "1"}`,
+		Enabled:   true,
+	}
+}
+
+// connectHelper 连接辅助进程，测试结束时关闭
+func connectHelper(t *testing.T) *MCPClient {
+	t.Helper()
+	client, err := ConnectMCPServer(helperMCPServer())
+	if err != nil {
+		t.Fatalf("连接辅助进程失败: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestMCPClientHTTP(t *testing.T) {
+	failing := StubMCPTool{
+		Info:    MCPToolInfo{Name: "fail", Description: "总是失败"},
+		Handler: func(json.RawMessage) MCPCallResult { return mcpTextResult("出错了", true) },
+	}
+	server := NewStubMCPServer(t, "stub", echoStubTool, failing)
+
+	client, err := ConnectMCPServer(MCPServer{Name: "stub", Transport: MCPTransportHTTP, URL: server.URL})
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools()
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("工具列表不符合预期: %+v, %v", tools, err)
+	}
+
+	executor := NewToolExecutor(t.TempDir())
+	executor.AttachMCPClients([]*MCPClient{client})
+
+	result := executor.Execute("mcp__stub__echo", `{"msg": "你好"}`)
+	if !result.Success || result.Output != "你好" {
+		t.Fatalf("echo 结果不符合预期: %+v", result)
+	}
+	if result := executor.Execute("mcp__stub__echo", `{}`); result.Success {
+		t.Fatalf("缺少必填参数时应校验失败")
+	}
+	if result := executor.Execute("mcp__stub__fail", `{}`); result.Success || result.Output != "出错了" {
+		t.Fatalf("工具返回错误时应失败: %+v", result)
+	}
+
+	names := expandMCPToolNames([]string{"mcp__stub", ToolReadFile}, executor.registry)
+	if strings.Join(names, ",") != "mcp__stub__echo,mcp__stub__fail,read_file" {
+		t.Fatalf("展开的工具名称不符合预期: %v", names)
+	}
+}
+
+func TestMCPClientStdio(t *testing.T) {
+	client := connectHelper(t)
+
+	tools, err := client.ListTools()
+	if err != nil || len(tools) != len(helperStubTools()) {
+		t.Fatalf("工具列表不符合预期: %+v, %v", tools, err)
+	}
+
+	result, err := client.CallTool("echo", json.RawMessage(`{"msg": "stdio"}`))
+	if err != nil || result.text() != "stdio" {
+		t.Fatalf("echo 结果不符合预期: %+v, %v", result, err)
+	}
+
+	// 进程写 stderr 的同时读取错误信息（配合 -race 检查）
+	for i := 0; i < 5; i++ {
+		if _, err := client.CallTool("noisy", nil); err != nil {
+			t.Fatalf("noisy 调用失败: %v", err)
+		}
+	}
+
+	// 进程退出时返回错误，并附带 stderr 输出
+	_, err = client.CallTool("crash", nil)
+	if err == nil || !strings.Contains(err.Error(), "boom: fatal error") {
+		t.Fatalf("进程退出时应返回带 stderr 的错误，实际: %v", err)
+	}
+	if client.alive() {
+		t.Fatalf("进程退出后连接应不可用")
+	}
+	if _, err := client.CallTool("echo", json.RawMessage(`{"msg": "x"}`)); err == nil {
+		t.Fatalf("进程退出后调用应失败")
+	}
+}
+
+func TestMCPStdioTimeout(t *testing.T) {
+	client := connectHelper(t)
+	transport := client.transport.(*mcpStdioTransport)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	msg := &jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage(`"hang-1"`), Method: "tools/call",
+		Params: json.RawMessage(`{"name": "hang", "arguments": {}}`)}
+	if _, err := transport.roundTrip(ctx, msg); err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Fatalf("应返回超时错误，实际: %v", err)
+	}
+
+	// 超时结束进程后读取协程应退出
+	select {
+	case <-transport.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("超时后读取协程未退出")
+	}
+	if client.alive() {
+		t.Fatalf("超时后连接应不可用")
+	}
+
+	start := time.Now()
+	if _, err := client.CallTool("echo", json.RawMessage(`{"msg": "x"}`)); err == nil {
+		t.Fatalf("超时后调用应失败")
+	}
+	client.Close()
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("超时后的调用和关闭应立即返回，耗时 %v", time.Since(start))
+	}
+}
+
+func TestMCPSessionPerConversation(t *testing.T) {
+	servers := []MCPServer{helperMCPServer()}
+	defer closeAllMCPSessions()
+
+	pid := func(client *MCPClient) string {
+		result, err := client.CallTool("pid", nil)
+		if err != nil {
+			t.Fatalf("pid 调用失败: %v", err)
+		}
+		return result.text()
+	}
+
+	first := conversationMCPClients(1, servers)
+	again := conversationMCPClients(1, servers)
+	other := conversationMCPClients(2, servers)
+	if len(first) != 1 || len(again) != 1 || len(other) != 1 {
+		t.Fatalf("应各连接1个服务器")
+	}
+	if first[0] != again[0] || pid(first[0]) != pid(again[0]) {
+		t.Fatalf("同一会话应复用服务器进程")
+	}
+	if pid(first[0]) == pid(other[0]) {
+		t.Fatalf("不同会话应使用各自的服务器进程")
+	}
+
+	// 进程退出后下次获取时重新连接
+	first[0].CallTool("crash", nil)
+	reconnected := conversationMCPClients(1, servers)
+	if len(reconnected) != 1 || reconnected[0] == first[0] || !reconnected[0].alive() {
+		t.Fatalf("连接断开后应重新连接")
+	}
+
+	closeConversationMCP(1)
+	if reconnected[0].alive() {
+		t.Fatalf("会话结束后服务器进程应关闭")
+	}
+	if !other[0].alive() {
+		t.Fatalf("关闭一个会话不应影响其他会话")
+	}
+}
+
+// TestReActMCPAcrossTurns 同一会话在 ask_user 前后使用同一个 MCP 服务器进程，会话完成后关闭
+func TestReActMCPAcrossTurns(t *testing.T) {
+	a := openTestDB(t)
+	defer closeAllMCPSessions()
+
+	helper := helperMCPServer()
+	if _, err := a.CreateMCPServer(MCPServerInput{Name: helper.Name, Transport: helper.Transport,
+		Command: helper.Command, Args: helper.Args, Env: helper.Env, Enabled: true}); err != nil {
+		t.Fatalf("创建MCP服务器失败: %v", err)
+	}
+
+	server := NewScriptedLLMServer(t,
+		ScriptedLLMReply{Content: `{"thought": "查看进程", "action": "mcp__helper__pid", "action_input": {}}`},
+		ScriptedLLMReply{Content: `{"thought": "需要确认", "action": "ask_user", "action_input": {"question": "继续吗？"}}`},
+		ScriptedLLMReply{Content: `{"thought": "再次查看", "action": "mcp__helper__pid", "action_input": {}}`},
+		ScriptedLLMReply{Content: `{"thought": "完成", "action": "complete", "action_input": {"summary": "完成"}}`},
+	)
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	convID, agent := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID, Tools: `["mcp__helper"]`})
+
+	a.runAIConversation(convID, agent)
+	if status, _, _ := conversationResult(t, a, convID); status != ConversationStatusWaitingUser {
+		t.Fatalf("第一轮应等待用户，实际 %s", status)
+	}
+	mcpSessions.Lock()
+	kept := len(mcpSessions.clients[convID])
+	mcpSessions.Unlock()
+	if kept != 1 {
+		t.Fatalf("等待用户时应保留 MCP 服务器")
+	}
+
+	a.saveMessage(convID, "user", "继续", MessageTypeText, "{}")
+	a.updateConversationStatus(convID, ConversationStatusActive)
+	a.runAIConversation(convID, agent)
+
+	status, steps, _ := conversationResult(t, a, convID)
+	if status != ConversationStatusCompleted {
+		t.Fatalf("第二轮应完成，实际 %s", status)
+	}
+	var pids []string
+	for _, step := range steps {
+		if step.Action == "mcp__helper__pid" {
+			if step.Status != StepStatusSuccess {
+				t.Fatalf("MCP 工具调用失败: %+v", step)
+			}
+			pids = append(pids, step.Observation)
+		}
+	}
+	if len(pids) != 2 || pids[0] != pids[1] {
+		t.Fatalf("两轮执行应使用同一个服务器进程: %v", pids)
+	}
+
+	mcpSessions.Lock()
+	_, exists := mcpSessions.clients[convID]
+	mcpSessions.Unlock()
+	if exists {
+		t.Fatalf("会话完成后应关闭 MCP 服务器")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// mcpProtocolVersion 使用的 MCP 协议版本
+const mcpProtocolVersion = "2025-03-26"
+
+// JSON-RPC 错误码
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcInternalError  = -32603
+)
+
+// jsonrpcMessage JSON-RPC 2.0 消息（请求、通知、响应共用）
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// jsonrpcError JSON-RPC 错误
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonrpcError) Error() string {
+	return fmt.Sprintf("MCP错误 %d: %s", e.Code, e.Message)
+}
+
+// isResponse 是否为响应消息
+func (m *jsonrpcMessage) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// MCPContent 工具调用结果的内容块
+type MCPContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+// MCPCallResult tools/call 的结果
+type MCPCallResult struct {
+	Content []MCPContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// text 拼接结果中的文本内容
+func (r MCPCallResult) text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch c.Type {
+		case "text":
+			parts = append(parts, c.Text)
+		default:
+			parts = append(parts, fmt.Sprintf("[%s 内容 %s]", c.Type, c.MimeType))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// mcpTextResult 构建纯文本结果
+func mcpTextResult(text string, isError bool) MCPCallResult {
+	return MCPCallResult{Content: []MCPContent{{Type: "text", Text: text}}, IsError: isError}
+}
+
+// MCPHandler MCP 服务端需要实现的工具接口
+type MCPHandler interface {
+	ServerName() string
+	ListTools() []MCPToolInfo
+	CallTool(name string, args json.RawMessage) MCPCallResult
+}
+
+// mcpDispatcher 处理一条 JSON-RPC 消息，返回响应（通知返回 nil）
+type mcpDispatcher struct {
+	handler MCPHandler
+	extra   func(method string, params json.RawMessage) (interface{}, *jsonrpcError, bool) // 额外方法（如 resources），返回 false 表示未处理
+}
+
+func (d *mcpDispatcher) dispatch(msg *jsonrpcMessage) *jsonrpcMessage {
+	if len(msg.ID) == 0 {
+		// 通知无需响应
+		return nil
+	}
+
+	result, rpcErr := d.handle(msg.Method, msg.Params)
+	resp := &jsonrpcMessage{JSONRPC: "2.0", ID: msg.ID}
+	if rpcErr != nil {
+		resp.Error = rpcErr
+		return resp
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		resp.Error = &jsonrpcError{Code: jsonrpcInternalError, Message: err.Error()}
+		return resp
+	}
+	resp.Result = data
+	return resp
+}
+
+func (d *mcpDispatcher) handle(method string, params json.RawMessage) (interface{}, *jsonrpcError) {
+	switch method {
+	case "initialize":
+		capabilities := map[string]interface{}{"tools": map[string]interface{}{}}
+		if d.extra != nil {
+			capabilities["resources"] = map[string]interface{}{}
+		}
+		return map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    capabilities,
+			"serverInfo":      map[string]string{"name": d.handler.ServerName(), "version": "1.0.0"},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		tools := d.handler.ListTools()
+		if tools == nil {
+			tools = []MCPToolInfo{}
+		}
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || p.Name == "" {
+			return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: "缺少工具名称"}
+		}
+		if len(p.Arguments) == 0 {
+			p.Arguments = json.RawMessage("{}")
+		}
+		return d.handler.CallTool(p.Name, p.Arguments), nil
+	}
+
+	if d.extra != nil {
+		if result, rpcErr, ok := d.extra(method, params); ok {
+			return result, rpcErr
+		}
+	}
+	return nil, &jsonrpcError{Code: jsonrpcMethodNotFound, Message: "未知方法: " + method}
+}
+
+// serveMCPStdio 以换行分隔的 JSON-RPC 消息在 r/w 上提供 MCP 服务，直到输入结束
+func serveMCPStdio(r io.Reader, w io.Writer, d *mcpDispatcher) error {
+	reader := bufio.NewReader(r)
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var msg jsonrpcMessage
+			var resp *jsonrpcMessage
+			if jsonErr := json.Unmarshal(line, &msg); jsonErr != nil {
+				resp = &jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage("null"),
+					Error: &jsonrpcError{Code: jsonrpcParseError, Message: jsonErr.Error()}}
+			} else {
+				resp = d.dispatch(&msg)
+			}
+			if resp != nil {
+				mu.Lock()
+				encodeErr := encoder.Encode(resp)
+				mu.Unlock()
+				if encodeErr != nil {
+					return encodeErr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// mcpHTTPHandler 以 HTTP POST（JSON 响应）提供 MCP 服务
+func mcpHTTPHandler(d *mcpDispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var msg jsonrpcMessage
+		if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &jsonrpcError{Code: jsonrpcParseError, Message: err.Error()}})
+			return
+		}
+
+		resp := d.dispatch(&msg)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// workbenchMCPHandler 将工作台的任务、项目、报表以 MCP 工具/资源的形式提供给外部 AI 助手
+type workbenchMCPHandler struct {
+	app *App
+}
+
+// 工作台 MCP 资源
+const (
+	mcpResourceTodayTasks   = "workbench://tasks/today"
+	mcpResourcePendingTasks = "workbench://tasks/pending"
+	mcpResourceOverdueTasks = "workbench://tasks/overdue"
+	mcpResourceProjects     = "workbench://projects"
+)
+
+// RunMCPServer 以 MCP 服务器模式运行（stdio），使用与桌面应用相同的数据库
+func RunMCPServer() error {
+	// stdout 用于协议通信，日志只能写到 stderr
+	log.SetOutput(os.Stderr)
+
+	if err := InitDB(); err != nil {
+		return err
+	}
+	defer CloseDB()
+
+	log.Println("工作台 MCP 服务器已启动 (stdio)")
+	h := &workbenchMCPHandler{app: NewApp().withActor(auditActor{actorType: AuditActorAPI, client: "mcp"})}
+	return serveMCPStdio(os.Stdin, os.Stdout, &mcpDispatcher{handler: h, extra: h.handleResources})
+}
+
+func (h *workbenchMCPHandler) ServerName() string {
+	return "workbench"
+}
+
+// ListTools 工作台提供的 MCP 工具
+func (h *workbenchMCPHandler) ListTools() []MCPToolInfo {
+	return []MCPToolInfo{
+		{
+			Name:        "list_today_tasks",
+			Description: "获取今天的任务列表及统计（计划工时、已完成数量、待办数量）",
+			InputSchema: json.RawMessage(`{"type": "object", "properties": {}}`),
+		},
+		{
+			Name:        "list_tasks_by_date",
+			Description: "获取指定日期范围内的任务",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"start_date": {"type": "string", "description": "开始日期 YYYY-MM-DD"},
+					"end_date": {"type": "string", "description": "结束日期 YYYY-MM-DD，默认与开始日期相同"}
+				},
+				"required": ["start_date"]
+			}`),
+		},
+		{
+			Name:        "create_task",
+			Description: "创建任务。不指定日期时进入待办列表",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "description": "任务名称"},
+					"description": {"type": "string", "description": "任务描述"},
+					"project_id": {"type": "integer", "description": "所属项目ID"},
+					"date": {"type": "string", "description": "计划日期 YYYY-MM-DD"},
+					"start_time": {"type": "string", "description": "计划开始时间 HH:MM"},
+					"end_time": {"type": "string", "description": "计划结束时间 HH:MM"},
+					"hours": {"type": "number", "description": "预计工时"},
+					"deadline": {"type": "string", "description": "截止日期 YYYY-MM-DD"},
+					"priority": {"type": "string", "enum": ["high", "medium", "low"], "description": "重要程度"},
+					"urgency": {"type": "string", "enum": ["high", "medium", "low"], "description": "紧急程度"}
+				},
+				"required": ["name"]
+			}`),
+		},
+		{
+			Name:        "complete_task",
+			Description: "将任务标记为完成，并记录实际开始时间和实际工时",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"task_id": {"type": "integer", "description": "任务ID"},
+					"actual_hours": {"type": "number", "minimum": 0, "description": "实际工时"},
+					"actual_start": {"type": "string", "description": "实际开始时间 HH:MM"}
+				},
+				"required": ["task_id", "actual_hours"]
+			}`),
+		},
+		{
+			Name:        "reschedule_overdue_tasks",
+			Description: "将所有逾期未完成的任务顺延到今天",
+			InputSchema: json.RawMessage(`{"type": "object", "properties": {}}`),
+		},
+		{
+			Name:        "get_report",
+			Description: "获取指定日期范围的报表数据（项目工时占比、每日完成率、汇总）",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"start_date": {"type": "string", "description": "开始日期 YYYY-MM-DD"},
+					"end_date": {"type": "string", "description": "结束日期 YYYY-MM-DD"},
+					"project_ids": {"type": "array", "items": {"type": "integer"}, "description": "只统计这些项目"}
+				},
+				"required": ["start_date", "end_date"]
+			}`),
+		},
+	}
+}
+
+// CallTool 执行工作台 MCP 工具
+func (h *workbenchMCPHandler) CallTool(name string, args json.RawMessage) MCPCallResult {
+	for _, tool := range h.ListTools() {
+		if tool.Name != name {
+			continue
+		}
+		schema, err := ParseJSONSchema(string(tool.InputSchema))
+		if err == nil {
+			if errs := schema.ValidateJSON(args); len(errs) > 0 {
+				return mcpTextResult(fmt.Sprintf("参数错误: %v", errs), true)
+			}
+		}
+		result, err := h.callTool(name, args)
+		if err != nil {
+			return mcpTextResult(err.Error(), true)
+		}
+		return mcpJSONResult(result)
+	}
+	return mcpTextResult("未知工具: "+name, true)
+}
+
+func (h *workbenchMCPHandler) callTool(name string, args json.RawMessage) (interface{}, error) {
+	switch name {
+	case "list_today_tasks":
+		return h.app.GetWorkbenchData()
+	case "list_tasks_by_date":
+		var input struct {
+			StartDate string `json:"start_date"`
+			EndDate   string `json:"end_date"`
+		}
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, fmt.Errorf("解析参数失败: %v", err)
+		}
+		if input.EndDate == "" {
+			input.EndDate = input.StartDate
+		}
+		return h.app.GetTasksByDateRange(input.StartDate, input.EndDate)
+	case "create_task":
+		var input TaskInput
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, fmt.Errorf("解析参数失败: %v", err)
+		}
+		input.ID = 0
+		input.Status = ""
+		return h.app.CreateTask(input)
+	case "complete_task":
+		var input struct {
+			TaskID      int64   `json:"task_id"`
+			ActualHours float64 `json:"actual_hours"`
+			ActualStart *string `json:"actual_start"`
+		}
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, fmt.Errorf("解析参数失败: %v", err)
+		}
+		if _, err := h.app.GetTask(input.TaskID); err != nil {
+			return nil, err
+		}
+		err := h.app.CompleteTask(CompleteTaskInput{ID: input.TaskID, ActualStart: input.ActualStart, ActualHours: input.ActualHours})
+		if err != nil {
+			return nil, err
+		}
+		return h.app.GetTask(input.TaskID)
+	case "reschedule_overdue_tasks":
+		count, err := h.app.RescheduleAllOverdueTasks()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"rescheduled": count, "date": time.Now().Format("2006-01-02")}, nil
+	case "get_report":
+		var input struct {
+			StartDate  string  `json:"start_date"`
+			EndDate    string  `json:"end_date"`
+			ProjectIDs []int64 `json:"project_ids"`
+		}
+		if err := json.Unmarshal(args, &input); err != nil {
+			return nil, fmt.Errorf("解析参数失败: %v", err)
+		}
+		return h.app.GetReportData(input.StartDate, input.EndDate, input.ProjectIDs)
+	}
+	return nil, fmt.Errorf("未知工具: %s", name)
+}
+
+// handleResources 处理 resources/list 和 resources/read
+func (h *workbenchMCPHandler) handleResources(method string, params json.RawMessage) (interface{}, *jsonrpcError, bool) {
+	switch method {
+	case "resources/list":
+		return map[string]interface{}{
+			"resources": []map[string]string{
+				{"uri": mcpResourceTodayTasks, "name": "今日任务", "mimeType": "application/json"},
+				{"uri": mcpResourcePendingTasks, "name": "待办任务", "mimeType": "application/json"},
+				{"uri": mcpResourceOverdueTasks, "name": "逾期任务", "mimeType": "application/json"},
+				{"uri": mcpResourceProjects, "name": "项目列表", "mimeType": "application/json"},
+			},
+		}, nil, true
+	case "resources/read":
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+			return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: "缺少资源 uri"}, true
+		}
+
+		var data interface{}
+		var err error
+		switch p.URI {
+		case mcpResourceTodayTasks:
+			data, err = h.app.GetWorkbenchData()
+		case mcpResourcePendingTasks:
+			data, err = h.app.GetPendingTasks()
+		case mcpResourceOverdueTasks:
+			data, err = h.app.GetOverdueTasks()
+		case mcpResourceProjects:
+			data, err = h.app.GetProjects()
+		default:
+			return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: "未知资源: " + p.URI}, true
+		}
+		if err != nil {
+			return nil, &jsonrpcError{Code: jsonrpcInternalError, Message: err.Error()}, true
+		}
+
+		text, _ := json.MarshalIndent(data, "", "  ")
+		return map[string]interface{}{
+			"contents": []map[string]string{{"uri": p.URI, "mimeType": "application/json", "text": string(text)}},
+		}, nil, true
+	}
+	return nil, nil, false
+}
+
+// mcpJSONResult 将数据序列化为 JSON 文本结果
+func mcpJSONResult(data interface{}) MCPCallResult {
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return mcpTextResult(fmt.Sprintf("序列化结果失败: %v", err), true)
+	}
+	return mcpTextResult(string(text), false)
+}
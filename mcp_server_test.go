@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// callWorkbenchMCP 通过 MCP 分发器发送一条请求，返回结果
+func callWorkbenchMCP(t *testing.T, h *workbenchMCPHandler, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	raw, _ := json.Marshal(params)
+	d := &mcpDispatcher{handler: h, extra: h.handleResources}
+	resp := d.dispatch(&jsonrpcMessage{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: raw})
+	if resp.Error != nil {
+		t.Fatalf("%s 返回错误: %v", method, resp.Error)
+	}
+	return resp.Result
+}
+
+// callWorkbenchTool 调用工作台 MCP 工具，返回结果文本和是否出错
+func callWorkbenchTool(t *testing.T, h *workbenchMCPHandler, name string, args string) (string, bool) {
+	t.Helper()
+	var result MCPCallResult
+	raw := callWorkbenchMCP(t, h, "tools/call", map[string]interface{}{"name": name, "arguments": json.RawMessage(args)})
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("解析工具结果失败: %v", err)
+	}
+	return result.text(), result.IsError
+}
+
+func newTestWorkbenchMCP(t *testing.T) *workbenchMCPHandler {
+	a := openTestDB(t)
+	return &workbenchMCPHandler{app: a.withActor(auditActor{actorType: AuditActorAPI, client: "mcp"})}
+}
+
+func TestWorkbenchMCPToolsList(t *testing.T) {
+	h := newTestWorkbenchMCP(t)
+
+	var list struct {
+		Tools []MCPToolInfo `json:"tools"`
+	}
+	if err := json.Unmarshal(callWorkbenchMCP(t, h, "tools/list", nil), &list); err != nil {
+		t.Fatalf("解析工具列表失败: %v", err)
+	}
+	names := map[string]bool{}
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+		if _, err := ParseJSONSchema(string(tool.InputSchema)); err != nil {
+			t.Errorf("工具 %s 的参数 Schema 无效: %v", tool.Name, err)
+		}
+	}
+	for _, name := range []string{"list_today_tasks", "list_tasks_by_date", "create_task", "complete_task", "reschedule_overdue_tasks", "get_report"} {
+		if !names[name] {
+			t.Errorf("工具列表缺少 %s", name)
+		}
+	}
+}
+
+func TestWorkbenchMCPCreateAndCompleteTask(t *testing.T) {
+	h := newTestWorkbenchMCP(t)
+
+	text, isError := callWorkbenchTool(t, h, "create_task", `{"name": "回复邮件", "date": "2030-01-07", "hours": 1, "status": "completed"}`)
+	if isError {
+		t.Fatalf("创建任务失败: %s", text)
+	}
+	var created Task
+	if err := json.Unmarshal([]byte(text), &created); err != nil {
+		t.Fatalf("解析任务失败: %v", err)
+	}
+	if created.Name != "回复邮件" || created.Status == TaskStatusCompleted {
+		t.Fatalf("创建的任务不符合预期（不能直接创建已完成的任务）: %+v", created)
+	}
+
+	text, isError = callWorkbenchTool(t, h, "list_tasks_by_date", `{"start_date": "2030-01-07"}`)
+	if isError || !strings.Contains(text, "回复邮件") {
+		t.Fatalf("按日期查询应包含新任务: %s", text)
+	}
+
+	text, isError = callWorkbenchTool(t, h, "complete_task", fmt.Sprintf(`{"task_id": %d, "actual_hours": 1.5, "actual_start": "10:00"}`, created.ID))
+	if isError {
+		t.Fatalf("完成任务失败: %s", text)
+	}
+	task, _ := h.app.GetTask(created.ID)
+	if task.Status != TaskStatusCompleted || task.ActualHours != 1.5 {
+		t.Fatalf("任务应已完成并记录实际工时: %s %v", task.Status, task.ActualHours)
+	}
+
+	history, err := h.app.GetTaskHistory(created.ID)
+	if err != nil || len(history) == 0 {
+		t.Fatalf("查询修改历史失败: %v", err)
+	}
+	for _, entry := range history {
+		if entry.ActorType == AuditActorUser {
+			t.Errorf("MCP 的修改不应记录为用户操作: %+v", entry)
+		}
+	}
+	if last := history[len(history)-1]; last.Action != AuditActionCreate || last.ActorType != AuditActorAPI || last.Client != "mcp" {
+		t.Fatalf("创建记录应标记为 MCP 客户端: %+v", last)
+	}
+}
+
+func TestWorkbenchMCPRejectsInvalidArguments(t *testing.T) {
+	h := newTestWorkbenchMCP(t)
+
+	if text, isError := callWorkbenchTool(t, h, "create_task", `{"description": "没有名称"}`); !isError || !strings.Contains(text, "参数错误") {
+		t.Fatalf("缺少必填参数应返回参数错误: %s", text)
+	}
+	if text, isError := callWorkbenchTool(t, h, "complete_task", `{"task_id": 9999, "actual_hours": 1}`); !isError {
+		t.Fatalf("完成不存在的任务应返回错误: %s", text)
+	}
+	if text, isError := callWorkbenchTool(t, h, "delete_everything", `{}`); !isError || !strings.Contains(text, "未知工具") {
+		t.Fatalf("未知工具应返回错误: %s", text)
+	}
+}
+
+func TestWorkbenchMCPResources(t *testing.T) {
+	h := newTestWorkbenchMCP(t)
+	createTestTask(t, h.app, TaskInput{Name: "待办事项", Hours: 1})
+
+	var read struct {
+		Contents []struct {
+			URI  string `json:"uri"`
+			Text string `json:"text"`
+		} `json:"contents"`
+	}
+	raw := callWorkbenchMCP(t, h, "resources/read", map[string]string{"uri": mcpResourcePendingTasks})
+	if err := json.Unmarshal(raw, &read); err != nil {
+		t.Fatalf("解析资源失败: %v", err)
+	}
+	if len(read.Contents) != 1 || !strings.Contains(read.Contents[0].Text, "待办事项") {
+		t.Fatalf("待办任务资源应包含任务: %+v", read)
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Project 项目
 type Project struct {
@@ -8,7 +11,7 @@ type Project struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Color       string    `json:"color"`
-	Archived    bool      `json:"archived"`   // 是否归档
+	Archived    bool      `json:"archived"` // 是否归档
 	CreatedAt   time.Time `json:"created_at"`
 	TaskCount   int       `json:"task_count"` // 任务数量（查询时填充）
 }
@@ -16,11 +19,11 @@ type Project struct {
 // ModelProvider 模型提供商
 type ModelProvider struct {
 	ID        int64     `json:"id"`
-	Name      string    `json:"name"`      // 提供商名称: deepseek/tongyi/volcengine
-	Label     string    `json:"label"`     // 显示名称
-	APIKey    string    `json:"api_key"`   // API Key
-	BaseURL   string    `json:"base_url"`  // API Base URL (可选)
-	Enabled   bool      `json:"enabled"`   // 是否启用
+	Name      string    `json:"name"`     // 提供商名称: deepseek/tongyi/volcengine
+	Label     string    `json:"label"`    // 显示名称
+	APIKey    string    `json:"api_key"`  // API Key
+	BaseURL   string    `json:"base_url"` // API Base URL (可选)
+	Enabled   bool      `json:"enabled"`  // 是否启用
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -36,10 +39,24 @@ type Agent struct {
 	Tools       string    `json:"tools"`       // 可用工具列表 JSON ["claude_code", "shell"]
 	WorkingDir  string    `json:"working_dir"` // 默认工作目录
 	MaxRetries  int       `json:"max_retries"` // 最大重试次数
+	Fallbacks   string    `json:"fallbacks"`   // 备用模型链 JSON [{"provider_id":1,"model":"xxx"}]
+	TaskAccess  string    `json:"task_access"` // 任务管理权限: none/read/write
 	Enabled     bool      `json:"enabled"`     // 是否启用
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Agent类型常量
+const (
+	AgentTypePlanner  = "planner"  // 规划：将任务拆解为子任务计划
+	AgentTypeExecutor = "executor" // 执行：使用工具完成任务
+)
+
+// ModelFallback 备用模型配置（主模型故障时按顺序切换）
+type ModelFallback struct {
+	ProviderID int64  `json:"provider_id"`
+	Model      string `json:"model"`
+}
+
 // AgentTool 工具定义
 type AgentTool struct {
 	Name        string `json:"name"`        // 工具名称
@@ -48,6 +65,80 @@ type AgentTool struct {
 	Schema      string `json:"schema"`      // 参数 JSON Schema
 }
 
+// CustomTool 用户自定义的命令行工具（存储在 tools 表）
+type CustomTool struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`         // 工具名称（小写字母、数字、下划线）
+	Description string    `json:"description"`  // 描述（给LLM看）
+	Schema      string    `json:"schema"`       // 参数 JSON Schema
+	Command     string    `json:"command"`      // 命令模板，{{参数名}} 会替换为转义后的参数值
+	WorkingDir  string    `json:"working_dir"`  // 工作目录（为空使用Agent的工作目录）
+	Timeout     int       `json:"timeout"`      // 超时时间（秒）
+	OutputMode  string    `json:"output_mode"`  // 输出解析: text/json/lines
+	OutputField string    `json:"output_field"` // json 模式下提取的字段路径，如 data.items
+	Enabled     bool      `json:"enabled"`      // 是否启用
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CustomToolInput 创建/更新自定义工具的输入
+type CustomToolInput struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Schema      string `json:"schema"`
+	Command     string `json:"command"`
+	WorkingDir  string `json:"working_dir"`
+	Timeout     int    `json:"timeout"`
+	OutputMode  string `json:"output_mode"`
+	OutputField string `json:"output_field"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// 自定义工具输出解析方式
+const (
+	ToolOutputText  = "text"  // 原样返回
+	ToolOutputJSON  = "json"  // 解析为JSON，可提取字段
+	ToolOutputLines = "lines" // 按行返回，去除空行
+)
+
+// MCPServer 外部 MCP (Model Context Protocol) 工具服务器配置
+type MCPServer struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`      // 服务器名称，工具注册为 mcp__<name>__<tool>
+	Transport string    `json:"transport"` // 传输方式: stdio/http
+	Command   string    `json:"command"`   // stdio: 启动命令
+	Args      string    `json:"args"`      // stdio: 命令参数 JSON数组
+	Env       string    `json:"env"`       // stdio: 额外环境变量 JSON对象
+	URL       string    `json:"url"`       // http: 服务地址
+	Enabled   bool      `json:"enabled"`   // 是否启用
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MCPServerInput 创建/更新 MCP 服务器的输入
+type MCPServerInput struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Transport string `json:"transport"`
+	Command   string `json:"command"`
+	Args      string `json:"args"`
+	Env       string `json:"env"`
+	URL       string `json:"url"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// MCPToolInfo MCP 服务器提供的工具
+type MCPToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// MCP 传输方式常量
+const (
+	MCPTransportStdio = "stdio"
+	MCPTransportHTTP  = "http"
+)
+
 // AgentStep 执行步骤
 type AgentStep struct {
 	ID             int64     `json:"id"`
@@ -59,6 +150,8 @@ type AgentStep struct {
 	Observation    string    `json:"observation"`  // 执行结果
 	Status         string    `json:"status"`       // pending/running/success/failed
 	Error          string    `json:"error"`        // 错误信息
+	Model          string    `json:"model"`        // 实际应答的模型 provider/model
+	Children       []int64   `json:"children"`     // delegate 步骤创建的子会话ID（查询时填充）
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -79,6 +172,24 @@ const (
 	ToolListFiles  = "list_files"  // 列出文件
 	ToolAskUser    = "ask_user"    // 询问用户
 	ToolComplete   = "complete"    // 完成任务
+	ToolSubmitPlan = "submit_plan" // 提交子任务计划（planner 专用）
+	ToolDelegate   = "delegate"    // 委派子目标给其他Agent
+)
+
+// 任务管理工具名称常量
+const (
+	ToolCreateTask       = "create_task"        // 创建任务
+	ToolUpdateTask       = "update_task"        // 更新任务
+	ToolLogTime          = "log_time"           // 记录工时
+	ToolCompleteTask     = "complete_task"      // 完成任务
+	ToolListProjectTasks = "list_project_tasks" // 列出项目任务
+)
+
+// Agent任务管理权限常量
+const (
+	TaskAccessNone  = "none"  // 不能使用任务管理工具
+	TaskAccessRead  = "read"  // 只能查询任务
+	TaskAccessWrite = "write" // 可以创建、修改、完成任务
 )
 
 // ModelProviderInput 创建/更新模型提供商的输入
@@ -100,55 +211,230 @@ type AgentInput struct {
 	Prompt      string `json:"prompt"`
 	ProviderID  *int64 `json:"provider_id"`
 	Model       string `json:"model"`
-	Tools       string `json:"tools"`       // JSON数组
+	Tools       string `json:"tools"` // JSON数组
 	WorkingDir  string `json:"working_dir"`
 	MaxRetries  int    `json:"max_retries"`
+	Fallbacks   string `json:"fallbacks"` // JSON数组
+	TaskAccess  string `json:"task_access"`
 	Enabled     bool   `json:"enabled"`
 }
 
 // 模型提供商常量
 const (
-	ProviderDeepSeek    = "deepseek"
-	ProviderTongyi      = "tongyi"
-	ProviderVolcEngine  = "volcengine"
+	ProviderDeepSeek   = "deepseek"
+	ProviderTongyi     = "tongyi"
+	ProviderVolcEngine = "volcengine"
+)
+
+// ProviderTestResult 模型提供商连接测试结果
+type ProviderTestResult struct {
+	Success         bool   `json:"success"`
+	LatencyMs       int64  `json:"latency_ms"`       // 请求耗时（毫秒）
+	StatusCode      int    `json:"status_code"`      // HTTP状态码（网络错误时为0）
+	ErrorType       string `json:"error_type"`       // 错误分类: auth/url/network/quota/server/config/unknown
+	Error           string `json:"error"`            // 错误详情
+	ModelsSupported bool   `json:"models_supported"` // 是否支持 /models 接口
+	ModelCount      int    `json:"model_count"`      // 可用模型数量
+}
+
+// ProviderHealth 模型提供商健康状况（基于最近的调用记录）
+type ProviderHealth struct {
+	ProviderID   int64   `json:"provider_id"`
+	Name         string  `json:"name"`
+	Label        string  `json:"label"`
+	TotalCalls   int     `json:"total_calls"`    // 最近调用次数
+	ErrorCount   int     `json:"error_count"`    // 最近失败次数
+	ErrorRate    float64 `json:"error_rate"`     // 错误率 (0-100)
+	AvgLatencyMs int64   `json:"avg_latency_ms"` // 平均耗时（毫秒）
+	LastError    string  `json:"last_error"`     // 最近一次错误
+	LastCallAt   *string `json:"last_call_at"`   // 最近调用时间
+}
+
+// ProviderModel 提供商返回的模型信息
+type ProviderModel struct {
+	ID      string `json:"id"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// 提供商错误分类常量
+const (
+	ProviderErrorAuth    = "auth"    // API Key 无效或无权限
+	ProviderErrorURL     = "url"     // Base URL 错误
+	ProviderErrorNetwork = "network" // 网络不可达/超时
+	ProviderErrorQuota   = "quota"   // 额度不足或限流
+	ProviderErrorServer  = "server"  // 提供商服务端错误
+	ProviderErrorConfig  = "config"  // 本地配置不完整
+	ProviderErrorUnknown = "unknown" // 其他错误
 )
 
 // Task 任务
 type Task struct {
-	ID          int64     `json:"id"`
-	ProjectID   *int64    `json:"project_id"`
-	ProjectName string    `json:"project_name"` // 项目名称（查询时填充）
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Date        *string   `json:"date"`       // 计划日期 YYYY-MM-DD, nil=待办
-	StartTime   *string   `json:"start_time"` // 计划开始时间 HH:MM
-	EndTime     *string   `json:"end_time"`   // 计划结束时间 HH:MM
-	Hours       float64   `json:"hours"`      // 预计工时
-	Deadline    *string   `json:"deadline"`   // 截止日期 YYYY-MM-DD
-	Priority    string    `json:"priority"`   // 重要程度: high/medium/low
-	Urgency     string    `json:"urgency"`    // 紧急程度: high/medium/low
-	Status      string    `json:"status"`     // pending/scheduled/in_progress/completed
-	ActualStart *string   `json:"actual_start"` // 实际开始时间 HH:MM (完成时填写)
-	ActualHours float64   `json:"actual_hours"` // 实际工时 (完成时填写)
-	CreatedAt   time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	ProjectID      *int64    `json:"project_id"`
+	ProjectName    string    `json:"project_name"` // 项目名称（查询时填充）
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Date           *string   `json:"date"`            // 计划日期 YYYY-MM-DD, nil=待办
+	StartTime      *string   `json:"start_time"`      // 计划开始时间 HH:MM
+	EndTime        *string   `json:"end_time"`        // 计划结束时间 HH:MM
+	Hours          float64   `json:"hours"`           // 预计工时
+	Deadline       *string   `json:"deadline"`        // 截止日期 YYYY-MM-DD
+	Priority       string    `json:"priority"`        // 重要程度: high/medium/low
+	Urgency        string    `json:"urgency"`         // 紧急程度: high/medium/low
+	Status         string    `json:"status"`          // pending/scheduled/in_progress/completed
+	ActualStart    *string   `json:"actual_start"`    // 实际开始时间 HH:MM (完成时填写)
+	ActualHours    float64   `json:"actual_hours"`    // 实际工时 (完成时填写)
+	ParentID       *int64    `json:"parent_id"`       // 父任务ID
+	CompletionRule string    `json:"completion_rule"` // 子任务完成规则: manual/auto/block
+	RecurrenceID   *int64    `json:"recurrence_id"`   // 所属重复规则ID（重复任务实例）
+	OccurrenceDate *string   `json:"occurrence_date"` // 重复规则原定日期（实例改期后不变）
+	CreatedAt      time.Time `json:"created_at"`
+	// 子任务汇总（查询时填充）
+	ChildCount         int     `json:"child_count"`          // 直接子任务数
+	OpenChildCount     int     `json:"open_child_count"`     // 未完成的直接子任务数
+	SubtaskHours       float64 `json:"subtask_hours"`        // 全部子孙任务的预计工时
+	SubtaskActualHours float64 `json:"subtask_actual_hours"` // 全部子孙任务的实际工时
+	// 前置任务（查询时填充）
+	Blocked   bool    `json:"blocked"`    // 有未完成的前置任务
+	BlockedBy []int64 `json:"blocked_by"` // 未完成的前置任务ID
+	// 改期记录（查询时填充）
+	SlipCount int `json:"slip_count"` // 推迟次数
+	// 标签（查询时填充）
+	Tags []Tag `json:"tags"`
+	// 创建任务时的提醒（如当天工时超载）
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // TaskInput 创建/更新任务的输入
 type TaskInput struct {
-	ID          int64   `json:"id"`
-	ProjectID   *int64  `json:"project_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Date        *string `json:"date"`
-	StartTime   *string `json:"start_time"`
-	EndTime     *string `json:"end_time"`
-	Hours       float64 `json:"hours"`
-	Deadline    *string `json:"deadline"`
-	Priority    string  `json:"priority"`
-	Urgency     string  `json:"urgency"`
-	Status      string  `json:"status"`
+	ID             int64   `json:"id"`
+	ProjectID      *int64  `json:"project_id"`
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	Date           *string `json:"date"`
+	StartTime      *string `json:"start_time"`
+	EndTime        *string `json:"end_time"`
+	Hours          float64 `json:"hours"`
+	Deadline       *string `json:"deadline"`
+	Priority       string  `json:"priority"`
+	Urgency        string  `json:"urgency"`
+	Status         string  `json:"status"`
+	ParentID       *int64  `json:"parent_id"`       // 父任务ID（仅创建时使用，修改请用 SetTaskParent）
+	CompletionRule string  `json:"completion_rule"` // 子任务完成规则，更新时为空表示不修改
+	TagIDs         []int64 `json:"tag_ids"`         // 标签，更新时为 null 表示不修改
 }
 
+// 子任务完成规则常量
+const (
+	CompletionRuleManual = "manual" // 父任务手动完成，不受子任务影响
+	CompletionRuleAuto   = "auto"   // 子任务全部完成时自动完成父任务
+	CompletionRuleBlock  = "block"  // 有未完成的子任务时不能完成父任务
+)
+
+// TaskDependencies 任务的依赖关系（完成-开始）
+type TaskDependencies struct {
+	Predecessors []Task `json:"predecessors"` // 前置任务：完成后本任务才能开始
+	Successors   []Task `json:"successors"`   // 后续任务：等待本任务完成
+}
+
+// CriticalPathTask 关键路径计算中的任务（时间以从现在起的预计工时计）
+type CriticalPathTask struct {
+	Task           Task    `json:"task"`
+	EarliestStart  float64 `json:"earliest_start"`
+	EarliestFinish float64 `json:"earliest_finish"`
+	LatestStart    float64 `json:"latest_start"`
+	LatestFinish   float64 `json:"latest_finish"`
+	Slack          float64 `json:"slack"`          // 可推迟的工时，0 表示在关键路径上
+	Critical       bool    `json:"critical"`       // 是否为关键任务
+	ProjectedDate  string  `json:"projected_date"` // 按工作日历推算的预计完成日期（超出推算范围时为空）
+	Late           bool    `json:"late"`           // 预计完成日期晚于截止日期
+}
+
+// CriticalPath 项目关键路径
+type CriticalPath struct {
+	ProjectID       int64              `json:"project_id"`
+	Tasks           []CriticalPathTask `json:"tasks"`            // 项目中未完成的任务（拓扑顺序）
+	Path            []int64            `json:"path"`             // 关键路径上的任务ID（按执行顺序）
+	TotalHours      float64            `json:"total_hours"`      // 关键路径总工时
+	ProjectedFinish string             `json:"projected_finish"` // 预计完成日期
+	LateTasks       int                `json:"late_tasks"`       // 预计晚于截止日期的任务数
+}
+
+// TaskNode 任务树节点
+type TaskNode struct {
+	Task     Task       `json:"task"`
+	Children []TaskNode `json:"children"`
+}
+
+// TaskRecurrence 重复任务规则（任务模板 + RRULE）
+type TaskRecurrence struct {
+	ID             int64     `json:"id"`
+	ProjectID      *int64    `json:"project_id"`
+	ProjectName    string    `json:"project_name"` // 项目名称（查询时填充）
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	StartTime      *string   `json:"start_time"`
+	EndTime        *string   `json:"end_time"`
+	Hours          float64   `json:"hours"`
+	Priority       string    `json:"priority"`
+	Urgency        string    `json:"urgency"`
+	RRule          string    `json:"rrule"`           // RFC 5545 RRULE 子集，如 FREQ=WEEKLY;BYDAY=MO,WE
+	StartDate      string    `json:"start_date"`      // 首次日期 YYYY-MM-DD
+	EndDate        string    `json:"end_date"`        // 最后日期（“本次及以后”拆分后设置），空表示不限
+	GeneratedUntil string    `json:"generated_until"` // 实例已生成到的日期
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RecurrencePattern 常用重复方式（未填写 RRule 时用于生成 RRULE）
+type RecurrencePattern struct {
+	Type     string `json:"type"`      // daily/weekdays/weekly/monthly_day/monthly_nth
+	Interval int    `json:"interval"`  // 间隔，默认1
+	Weekdays []int  `json:"weekdays"`  // weekly: 星期几（0=周日 … 6=周六）
+	MonthDay int    `json:"month_day"` // monthly_day: 每月第几天，负数表示倒数
+	Nth      int    `json:"nth"`       // monthly_nth: 第几个（-1 表示最后一个）
+	Weekday  int    `json:"weekday"`   // monthly_nth: 星期几
+	Until    string `json:"until"`     // 结束日期 YYYY-MM-DD（可选）
+	Count    int    `json:"count"`     // 重复次数（可选）
+}
+
+// 重复方式常量
+const (
+	RecurrenceDaily      = "daily"
+	RecurrenceWeekdays   = "weekdays"
+	RecurrenceWeekly     = "weekly"
+	RecurrenceMonthlyDay = "monthly_day"
+	RecurrenceMonthlyNth = "monthly_nth"
+)
+
+// RecurrenceInput 创建重复任务的输入
+type RecurrenceInput struct {
+	ProjectID   *int64             `json:"project_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	StartTime   *string            `json:"start_time"`
+	EndTime     *string            `json:"end_time"`
+	Hours       float64            `json:"hours"`
+	Priority    string             `json:"priority"`
+	Urgency     string             `json:"urgency"`
+	RRule       string             `json:"rrule"`      // 直接指定 RRULE，优先于 pattern
+	Pattern     *RecurrencePattern `json:"pattern"`    // 常用重复方式
+	StartDate   string             `json:"start_date"` // 首次日期，默认今天
+}
+
+// UpdateOccurrenceInput 修改重复任务实例的输入
+type UpdateOccurrenceInput struct {
+	Task  TaskInput `json:"task"`  // 修改后的任务（ID 为实例ID）
+	Scope string    `json:"scope"` // this: 仅本次; future: 本次及以后
+	RRule string    `json:"rrule"` // scope 为 future 时可同时修改重复规则（为空保持不变）
+}
+
+// 重复任务修改范围常量
+const (
+	RecurrenceScopeThis   = "this"
+	RecurrenceScopeFuture = "future"
+)
+
 // CompleteTaskInput 完成任务时的输入
 type CompleteTaskInput struct {
 	ID          int64   `json:"id"`
@@ -156,14 +442,386 @@ type CompleteTaskInput struct {
 	ActualHours float64 `json:"actual_hours"` // 实际工时
 }
 
+// TimeEntry 工时记录（一段实际工作时间，可跨天）
+type TimeEntry struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	TaskName  string    `json:"task_name"`  // 任务名称（查询时填充）
+	ProjectID *int64    `json:"project_id"` // 任务所属项目（查询时填充）
+	StartAt   string    `json:"start_at"`   // 开始时间 YYYY-MM-DD HH:MM:SS
+	EndAt     *string   `json:"end_at"`     // 结束时间，nil 表示计时中
+	Hours     float64   `json:"hours"`      // 时长（计时中的记录算到当前时间）
+	Source    string    `json:"source"`     // timer/manual/migrated
+	Paused    bool      `json:"paused"`     // 是否由暂停结束
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TimeEntryInput 手动添加/修改工时记录的输入
+type TimeEntryInput struct {
+	ID      int64   `json:"id"`
+	TaskID  int64   `json:"task_id"`
+	StartAt string  `json:"start_at"` // YYYY-MM-DD HH:MM[:SS]
+	EndAt   *string `json:"end_at"`   // 修改计时中的记录时可为空
+	Note    string  `json:"note"`
+}
+
+// ActiveTimer 当前计时器（同一时间只有一个）
+type ActiveTimer struct {
+	Entry        TimeEntry `json:"entry"`         // 计时中或最近暂停的记录
+	Running      bool      `json:"running"`       // 计时中
+	Paused       bool      `json:"paused"`        // 已暂停，可继续
+	SessionHours float64   `json:"session_hours"` // 本段计时时长
+	TaskHours    float64   `json:"task_hours"`    // 任务累计工时（含计时中的时长）
+}
+
+// 工时记录来源常量
+const (
+	TimeEntrySourceTimer    = "timer"    // 计时器
+	TimeEntrySourceManual   = "manual"   // 手动添加/记录工时
+	TimeEntrySourceMigrated = "migrated" // 由旧的实际工时迁移
+)
+
+// AutoScheduleInput 自动排程的参数
+type AutoScheduleInput struct {
+	StartDate       string  `json:"start_date"`       // 开始日期，默认今天
+	Days            int     `json:"days"`             // 排程天数，默认7
+	WorkStart       string  `json:"work_start"`       // 每天工作开始时间 HH:MM，默认使用工作日历的开始时间
+	WorkEnd         string  `json:"work_end"`         // 每天工作结束时间 HH:MM，默认按工作日历当天的可用工时计算
+	IncludeWeekends bool    `json:"include_weekends"` // 周末（工作日历中无工时的非节假日）是否安排
+	TaskIDs         []int64 `json:"task_ids"`         // 只安排这些待办，为空表示全部待办
+}
+
+// ScheduleItem 排程结果中的一项（任务安排到具体时段）
+type ScheduleItem struct {
+	TaskID    int64   `json:"task_id"`
+	TaskName  string  `json:"task_name"`
+	Date      string  `json:"date"`       // 安排日期 YYYY-MM-DD
+	StartTime string  `json:"start_time"` // HH:MM
+	EndTime   string  `json:"end_time"`   // HH:MM
+	Hours     float64 `json:"hours"`
+	Deadline  *string `json:"deadline"`
+	Late      bool    `json:"late"`  // 安排日期晚于截止日期
+	Part      int     `json:"part"`  // 拆分到多天时的第几段（从1开始），未拆分为0
+	Parts     int     `json:"parts"` // 拆分的总段数，未拆分为0
+}
+
+// UnscheduledTask 未能安排的待办及原因
+type UnscheduledTask struct {
+	TaskID   int64  `json:"task_id"`
+	TaskName string `json:"task_name"`
+	Reason   string `json:"reason"`
+}
+
+// ScheduleProposal 自动排程的预览结果
+type ScheduleProposal struct {
+	Items       []ScheduleItem    `json:"items"`
+	Unscheduled []UnscheduledTask `json:"unscheduled"`
+	Warnings    []string          `json:"warnings"`
+}
+
+// ScoringConfig 任务优先级评分配置
+type ScoringConfig struct {
+	ImportanceWeight  float64 `json:"importance_weight"`   // 重要程度权重
+	UrgencyWeight     float64 `json:"urgency_weight"`      // 紧急程度权重
+	DeadlineWeight    float64 `json:"deadline_weight"`     // 截止日期临近权重
+	SizeWeight        float64 `json:"size_weight"`         // 工时越小得分越高（快速完成）
+	AgeWeight         float64 `json:"age_weight"`          // 创建越久得分越高
+	DeadlineHorizon   int     `json:"deadline_horizon"`    // 截止日期在多少天内开始计分
+	HighUrgencyDays   int     `json:"high_urgency_days"`   // 距截止日期不超过该天数时评分按高紧急程度计算
+	MediumUrgencyDays int     `json:"medium_urgency_days"` // 距截止日期不超过该天数时评分至少按中紧急程度计算
+	MatrixLevel       string  `json:"matrix_level"`        // 四象限中算作重要/紧急的最低级别: high/medium
+}
+
+// ScoreBreakdown 评分各项得分（0-1）
+type ScoreBreakdown struct {
+	Importance float64 `json:"importance"`
+	Urgency    float64 `json:"urgency"`
+	Deadline   float64 `json:"deadline"`
+	Size       float64 `json:"size"`
+	Age        float64 `json:"age"`
+}
+
+// ScoredTask 带评分的任务
+type ScoredTask struct {
+	Task             Task           `json:"task"`
+	Score            float64        `json:"score"` // 综合得分（0-100）
+	Breakdown        ScoreBreakdown `json:"breakdown"`
+	Quadrant         string         `json:"quadrant"`          // 四象限
+	EffectiveUrgency string         `json:"effective_urgency"` // 评分使用的紧急程度（截止日期临近时提升）
+}
+
+// EisenhowerMatrix 四象限（每个象限按得分从高到低）
+type EisenhowerMatrix struct {
+	DoFirst   []ScoredTask `json:"do_first"`  // 重要且紧急：立即做
+	Schedule  []ScoredTask `json:"schedule"`  // 重要不紧急：计划做
+	Delegate  []ScoredTask `json:"delegate"`  // 紧急不重要：委派
+	Eliminate []ScoredTask `json:"eliminate"` // 不重要不紧急：减少
+}
+
+// 四象限常量
+const (
+	QuadrantDoFirst   = "do_first"
+	QuadrantSchedule  = "schedule"
+	QuadrantDelegate  = "delegate"
+	QuadrantEliminate = "eliminate"
+)
+
+// TaskReschedule 任务改期记录
+type TaskReschedule struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	FromDate  string    `json:"from_date"` // 原日期
+	ToDate    *string   `json:"to_date"`   // 新日期（移回待办时为空）
+	Reason    string    `json:"reason"`    // manual/bulk_overdue/triage/edit
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 改期原因常量
+const (
+	RescheduleReasonManual      = "manual"       // 手动顺延
+	RescheduleReasonBulkOverdue = "bulk_overdue" // 逾期任务全部顺延到今天
+	RescheduleReasonTriage      = "triage"       // 逾期分流
+	RescheduleReasonEdit        = "edit"         // 编辑任务或拖动改期
+)
+
+// OverdueTriageInput 逾期分流的参数
+type OverdueTriageInput struct {
+	StartDate       string  `json:"start_date"`       // 开始日期，默认今天
+	Days            int     `json:"days"`             // 分流天数，默认7
+	DailyHours      float64 `json:"daily_hours"`      // 每天可用工时，默认按工作日历
+	IncludeWeekends bool    `json:"include_weekends"` // 周末（工作日历中无工时的非节假日）是否安排
+}
+
+// OverdueTriageItem 逾期任务的改期建议
+type OverdueTriageItem struct {
+	TaskID    int64   `json:"task_id"`
+	TaskName  string  `json:"task_name"`
+	FromDate  string  `json:"from_date"` // 原日期
+	ToDate    string  `json:"to_date"`   // 建议日期
+	Hours     float64 `json:"hours"`
+	Score     float64 `json:"score"`      // 优先级得分
+	SlipCount int     `json:"slip_count"` // 已推迟次数
+	Deadline  *string `json:"deadline"`
+	Late      bool    `json:"late"` // 建议日期晚于截止日期
+}
+
+// DayLoad 某天的工时负载
+type DayLoad struct {
+	Date           string  `json:"date"`
+	Capacity       float64 `json:"capacity"`        // 可用工时
+	ScheduledHours float64 `json:"scheduled_hours"` // 已安排的工时
+	AddedHours     float64 `json:"added_hours"`     // 本次建议新增的工时
+}
+
+// OverdueTriageProposal 逾期分流建议
+type OverdueTriageProposal struct {
+	Items    []OverdueTriageItem `json:"items"`
+	Unplaced []UnscheduledTask   `json:"unplaced"` // 范围内放不下的逾期任务
+	Days     []DayLoad           `json:"days"`
+}
+
+// DeferredTask 被反复推迟的任务
+type DeferredTask struct {
+	Task         Task             `json:"task"`
+	SlipCount    int              `json:"slip_count"`    // 推迟次数（改到更晚的日期）
+	OriginalDate string           `json:"original_date"` // 第一次安排的日期
+	History      []TaskReschedule `json:"history"`
+}
+
+// WorkCalendar 工作日历（每周默认工时）
+type WorkCalendar struct {
+	WeeklyHours []float64 `json:"weekly_hours"` // 周日到周六每天的可用工时（7项）
+	WorkStart   string    `json:"work_start"`   // 每天开始工作的时间 HH:MM（自动排程使用）
+}
+
+// CalendarDay 特定日期的工时设置（覆盖每周默认工时）
+type CalendarDay struct {
+	Date  string  `json:"date"`  // YYYY-MM-DD
+	Hours float64 `json:"hours"` // 当天可用工时（节假日为0，请假可为部分工时）
+	Kind  string  `json:"kind"`  // workday/holiday/leave
+	Name  string  `json:"name"`  // 节假日名称或说明
+}
+
+// 日历日期类型常量
+const (
+	CalendarDayWorkday = "workday" // 调整工时（含调休上班）
+	CalendarDayHoliday = "holiday" // 节假日
+	CalendarDayLeave   = "leave"   // 请假
+	CalendarDayWeekend = "weekend" // 每周默认不工作的日子（仅用于查询结果）
+)
+
+// DayCapacity 某天的计划工时与可用工时
+type DayCapacity struct {
+	Date       string  `json:"date"`
+	Available  float64 `json:"available"`  // 可用工时
+	Planned    float64 `json:"planned"`    // 已安排任务的预计工时
+	Remaining  float64 `json:"remaining"`  // 剩余工时（超载时为负）
+	Overloaded bool    `json:"overloaded"` // 计划工时超过可用工时
+	TaskCount  int     `json:"task_count"`
+	Kind       string  `json:"kind"` // workday/weekend/holiday/leave
+	Name       string  `json:"name"`
+}
+
+// SearchInput 全文搜索条件
+type SearchInput struct {
+	Query           string   `json:"query"`            // 搜索词，空格分隔的词都要匹配，双引号包裹的为短语
+	Types           []string `json:"types"`            // 搜索范围 task/project/message，为空表示全部
+	ProjectID       *int64   `json:"project_id"`       // 只搜索该项目
+	Status          string   `json:"status"`           // 任务状态（项目不参与状态过滤）
+	StartDate       string   `json:"start_date"`       // 开始日期（任务按安排日期，项目和消息按创建日期）
+	EndDate         string   `json:"end_date"`         // 结束日期
+	IncludeArchived bool     `json:"include_archived"` // 是否包含已归档的项目及其任务
+	Limit           int      `json:"limit"`            // 最多返回条数，默认50
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Type           string  `json:"type"` // task/project/message
+	ID             int64   `json:"id"`   // 任务、项目或消息ID
+	Title          string  `json:"title"`
+	Snippet        string  `json:"snippet"` // 匹配片段（HTML 转义，命中词用 <mark> 包裹）
+	Score          float64 `json:"score"`   // 相关度，越大越相关
+	ProjectID      *int64  `json:"project_id"`
+	ProjectName    string  `json:"project_name"`
+	TaskID         *int64  `json:"task_id"`
+	ConversationID *int64  `json:"conversation_id"`
+	Status         string  `json:"status"`
+	Date           *string `json:"date"`
+	Archived       bool    `json:"archived"`
+}
+
+// 搜索结果类型常量
+const (
+	SearchTypeTask    = "task"
+	SearchTypeProject = "project"
+	SearchTypeMessage = "message"
+)
+
+// TaskFilter 任务查询条件（各条件同时满足，列表条件为空表示不限）
+// 日期条件可以是 YYYY-MM-DD，也可以是动态日期：today、tomorrow、yesterday、+7d、-2w、+1m、this_week、next_month 等
+type TaskFilter struct {
+	ProjectIDs   []int64  `json:"project_ids"`   // 项目，0 表示未分类
+	Statuses     []string `json:"statuses"`      // 状态
+	Priorities   []string `json:"priorities"`    // 重要程度
+	Urgencies    []string `json:"urgencies"`     // 紧急程度
+	Scheduled    *bool    `json:"scheduled"`     // true 只查已安排日期的任务，false 只查待办
+	DateFrom     string   `json:"date_from"`     // 安排日期范围（开始）
+	DateTo       string   `json:"date_to"`       // 安排日期范围（结束）
+	Overdue      bool     `json:"overdue"`       // 只查逾期任务（日期早于今天且未完成）
+	DeadlineFrom string   `json:"deadline_from"` // 截止日期范围（开始）
+	DeadlineTo   string   `json:"deadline_to"`   // 截止日期范围（结束）
+	CreatedFrom  string   `json:"created_from"`  // 创建日期范围（开始）
+	CreatedTo    string   `json:"created_to"`    // 创建日期范围（结束）
+	Text         string   `json:"text"`          // 名称或描述包含的文字
+	ParentID     *int64   `json:"parent_id"`     // 只查该任务的子任务
+	TopLevel     bool     `json:"top_level"`     // 只查顶层任务
+	TagIDs       []int64  `json:"tag_ids"`       // 标签（带有其中任一标签）
+	TagMatchAll  bool     `json:"tag_match_all"` // 需要带有全部标签
+	SortBy       string   `json:"sort_by"`       // 排序字段，默认 date
+	SortDesc     bool     `json:"sort_desc"`     // 是否倒序
+	Limit        int      `json:"limit"`         // 每页条数，默认50
+	Cursor       string   `json:"cursor"`        // 上一页返回的游标，为空表示第一页
+}
+
+// TaskPage 任务查询的一页结果
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	Total      int    `json:"total"`       // 符合条件的任务总数
+	NextCursor string `json:"next_cursor"` // 下一页的游标，为空表示没有更多
+}
+
+// SavedView 保存的视图（智能列表）
+type SavedView struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Filter    TaskFilter `json:"filter"`   // 查询条件，日期可使用动态日期
+	Position  int        `json:"position"` // 排列顺序
+	Count     int        `json:"count"`    // 符合条件的任务数（查询时填充）
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SavedViewInput 保存视图的输入
+type SavedViewInput struct {
+	ID     int64      `json:"id"`
+	Name   string     `json:"name"`
+	Filter TaskFilter `json:"filter"`
+}
+
+// Tag 标签
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	TaskCount int       `json:"task_count"` // 使用该标签的任务数（查询标签时填充）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagTimeStats 按标签的工时统计（一个任务有多个标签时计入每个标签）
+type TagTimeStats struct {
+	TagID      int64   `json:"tag_id"` // 0 表示无标签
+	TagName    string  `json:"tag_name"`
+	Color      string  `json:"color"`
+	TotalHours float64 `json:"total_hours"` // 总工时
+	TaskCount  int     `json:"task_count"`  // 任务数量
+	Percentage float64 `json:"percentage"`  // 占全部工时的百分比
+}
+
+// AuditEntry 审计日志（一次操作对任务或项目的修改）
+type AuditEntry struct {
+	ID             int64         `json:"id"`
+	EntityType     string        `json:"entity_type"` // task/project
+	EntityID       int64         `json:"entity_id"`
+	Action         string        `json:"action"`          // create/update/delete/undo
+	Changes        []FieldChange `json:"changes"`         // 字段级修改
+	ActorType      string        `json:"actor_type"`      // user/agent/api
+	ConversationID *int64        `json:"conversation_id"` // Agent 修改时的会话ID
+	Client         string        `json:"client"`          // API 客户端名称
+	Undone         bool          `json:"undone"`          // 是否已撤销
+	UndoOf         *int64        `json:"undo_of"`         // 撤销的是哪条记录
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// FieldChange 字段修改前后的值（nil 表示空值或不存在）
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// 审计对象类型常量
+const (
+	AuditEntityTask    = "task"
+	AuditEntityProject = "project"
+)
+
+// 审计操作常量
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionUndo   = "undo"
+)
+
+// 审计操作者常量
+const (
+	AuditActorUser   = "user"   // 用户在界面中操作
+	AuditActorAgent  = "agent"  // Agent 通过任务管理工具操作
+	AuditActorAPI    = "api"    // 外部客户端通过 MCP 操作
+	AuditActorSystem = "system" // 系统自动联动修改（父任务自动完成、计时、工时汇总等）
+)
+
 // WorkbenchData 工作台数据
 type WorkbenchData struct {
-	TodayTasks      []Task  `json:"today_tasks"`
-	TotalCount      int     `json:"total_count"`
-	CompletedCount  int     `json:"completed_count"`
-	PlannedHours    float64 `json:"planned_hours"`
-	CompletedHours  float64 `json:"completed_hours"`
-	PendingCount    int     `json:"pending_count"` // 待处理任务数
+	TodayTasks     []Task  `json:"today_tasks"`
+	TotalCount     int     `json:"total_count"`
+	CompletedCount int     `json:"completed_count"`
+	PlannedHours   float64 `json:"planned_hours"`
+	CompletedHours float64 `json:"completed_hours"`
+	PendingCount   int     `json:"pending_count"`   // 待处理任务数
+	AvailableHours float64 `json:"available_hours"` // 今天的可用工时
+	Overloaded     bool    `json:"overloaded"`      // 今天计划工时超过可用工时
 }
 
 // 任务状态常量
@@ -204,6 +862,7 @@ type DailyTaskStats struct {
 	TotalCount     int     `json:"total_count"`     // 总任务数
 	CompletedCount int     `json:"completed_count"` // 完成数量
 	CompletionRate float64 `json:"completion_rate"` // 完成率 (0-100)
+	Hours          float64 `json:"hours"`           // 当天实际记录的工时
 }
 
 // ReportSummary 报表汇总
@@ -226,13 +885,15 @@ type ReportData struct {
 
 // TaskConversation 任务AI会话
 type TaskConversation struct {
-	ID        int64     `json:"id"`
-	TaskID    int64     `json:"task_id"`
-	AgentID   int64     `json:"agent_id"`
-	AgentName string    `json:"agent_name"` // Agent名称（查询时填充）
-	Status    string    `json:"status"`     // active/waiting_user/completed/failed
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                   int64     `json:"id"`
+	TaskID               int64     `json:"task_id"`
+	AgentID              int64     `json:"agent_id"`
+	AgentName            string    `json:"agent_name"`             // Agent名称（查询时填充）
+	Status               string    `json:"status"`                 // active/waiting_user/completed/failed
+	ParentConversationID *int64    `json:"parent_conversation_id"` // 委派出该会话的父会话
+	ParentStepID         *int64    `json:"parent_step_id"`         // 父会话中的 delegate 步骤
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 // ConversationMessage 会话消息
@@ -261,8 +922,54 @@ const (
 	MessageTypeQuestion = "question" // 询问用户
 	MessageTypeResult   = "result"   // 执行结果
 	MessageTypeError    = "error"    // 错误信息
+	MessageTypePlan     = "plan"     // 待审核的子任务计划
+)
+
+// PlanItem 计划中的一个子任务
+type PlanItem struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Hours       float64 `json:"hours"`      // 预计工时
+	Priority    string  `json:"priority"`   // 重要程度: high/medium/low
+	Urgency     string  `json:"urgency"`    // 紧急程度: high/medium/low
+	Date        *string `json:"date"`       // 计划日期 YYYY-MM-DD（可选）
+	DependsOn   []int   `json:"depends_on"` // 依赖的子任务序号（从1开始）
+}
+
+// TaskPlan planner Agent 生成的子任务计划
+type TaskPlan struct {
+	ID             int64      `json:"id"`
+	ConversationID int64      `json:"conversation_id"`
+	TaskID         int64      `json:"task_id"` // 被拆解的父任务
+	Summary        string     `json:"summary"`
+	Items          []PlanItem `json:"items"`
+	Status         string     `json:"status"`           // pending/approved/rejected
+	CreatedTaskIDs []int64    `json:"created_task_ids"` // 批准后创建的子任务ID，与 Items 一一对应
+	CreatedAt      time.Time  `json:"created_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	// 批准时的提醒（如子任务所在日期工时超载）
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// 计划状态常量
+const (
+	PlanStatusPending  = "pending"  // 待审核
+	PlanStatusApproved = "approved" // 已批准并创建子任务
+	PlanStatusRejected = "rejected" // 已驳回
 )
 
+// ApprovePlanInput 批准计划的输入
+type ApprovePlanInput struct {
+	PlanID int64      `json:"plan_id"`
+	Items  []PlanItem `json:"items"` // 审核时修改后的子任务，为空则使用原计划
+}
+
+// RejectPlanInput 驳回计划的输入
+type RejectPlanInput struct {
+	PlanID   int64  `json:"plan_id"`
+	Feedback string `json:"feedback"` // 修改意见，非空时 planner 会据此重新规划
+}
+
 // StartConversationInput 开始AI会话的输入
 type StartConversationInput struct {
 	TaskID       int64  `json:"task_id"`
@@ -282,3 +989,135 @@ type ConversationDetail struct {
 	Messages     []ConversationMessage `json:"messages"`
 	Task         Task                  `json:"task"`
 }
+
+// Pipeline 多阶段流水线定义
+type Pipeline struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Stages      []PipelineStage `json:"stages"` // 按顺序执行的阶段
+	Enabled     bool            `json:"enabled"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// PipelineStage 流水线阶段
+type PipelineStage struct {
+	Name            string         `json:"name"`             // 阶段名称，流水线内唯一
+	AgentID         int64          `json:"agent_id"`         // 执行该阶段的Agent
+	InputTemplate   string         `json:"input_template"`   // 阶段输入模板，支持 {{task.name}}、{{prev.summary}}、{{stages.<阶段>.summary}} 等
+	SuccessCriteria *StageCriteria `json:"success_criteria"` // 成功条件，为空时以Agent完成为准
+	OnFailure       string         `json:"on_failure"`       // 失败处理: stop/skip/goto:<阶段名>
+	MaxAttempts     int            `json:"max_attempts"`     // 最多尝试次数（含首次），默认1
+	Artifacts       []string       `json:"artifacts"`        // 阶段完成后收集的产物文件（相对Agent工作目录）
+}
+
+// StageCriteria 阶段成功条件
+type StageCriteria struct {
+	Type    string `json:"type"`    // command/file_exists/contains
+	Command string `json:"command"` // command: 在Agent工作目录执行，退出码为0视为成功
+	Path    string `json:"path"`    // file_exists: 文件路径（相对Agent工作目录）
+	Text    string `json:"text"`    // contains: 完成总结需包含的文本
+}
+
+// 阶段成功条件类型常量
+const (
+	StageCriteriaCommand    = "command"
+	StageCriteriaFileExists = "file_exists"
+	StageCriteriaContains   = "contains"
+)
+
+// 阶段失败处理常量
+const (
+	StageOnFailureStop = "stop" // 流水线失败
+	StageOnFailureSkip = "skip" // 跳过该阶段继续
+	StageOnFailureGoto = "goto" // 跳转到指定阶段，格式 goto:<阶段名>
+)
+
+// PipelineInput 创建/更新流水线的输入
+type PipelineInput struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Stages      []PipelineStage `json:"stages"`
+	Enabled     bool            `json:"enabled"`
+}
+
+// PipelineRun 流水线运行
+type PipelineRun struct {
+	ID           int64     `json:"id"`
+	PipelineID   int64     `json:"pipeline_id"`
+	PipelineName string    `json:"pipeline_name"` // 流水线名称（查询时填充）
+	TaskID       int64     `json:"task_id"`
+	Status       string    `json:"status"`        // running/paused/waiting_user/completed/failed
+	CurrentStage int       `json:"current_stage"` // 当前（或下一个待执行）阶段序号，从0开始
+	Context      string    `json:"context"`       // 启动时的补充说明
+	Error        string    `json:"error"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// 流水线运行状态常量
+const (
+	PipelineRunStatusRunning     = "running"      // 阶段执行中
+	PipelineRunStatusPaused      = "paused"       // 已暂停，当前阶段结束后不再继续
+	PipelineRunStatusWaitingUser = "waiting_user" // 阶段会话等待用户回复
+	PipelineRunStatusCompleted   = "completed"    // 全部阶段完成
+	PipelineRunStatusFailed      = "failed"       // 失败
+)
+
+// PipelineStageRun 阶段运行记录（每次尝试一条，对应一个会话）
+type PipelineStageRun struct {
+	ID             int64      `json:"id"`
+	RunID          int64      `json:"run_id"`
+	StageIndex     int        `json:"stage_index"`
+	StageName      string     `json:"stage_name"`
+	AgentID        int64      `json:"agent_id"`
+	ConversationID int64      `json:"conversation_id"`
+	Attempt        int        `json:"attempt"`
+	Status         string     `json:"status"` // running/waiting_user/success/failed
+	Summary        string     `json:"summary"`
+	Error          string     `json:"error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// 阶段运行状态常量
+const (
+	StageRunStatusRunning     = "running"
+	StageRunStatusWaitingUser = "waiting_user"
+	StageRunStatusSuccess     = "success"
+	StageRunStatusFailed      = "failed"
+)
+
+// PipelineArtifact 阶段产物（传递给后续阶段）
+type PipelineArtifact struct {
+	ID         int64     `json:"id"`
+	RunID      int64     `json:"run_id"`
+	StageRunID int64     `json:"stage_run_id"`
+	StageName  string    `json:"stage_name"`
+	Kind       string    `json:"kind"`    // summary/file
+	Name       string    `json:"name"`    // 产物名称（文件为路径）
+	Content    string    `json:"content"` // 总结文本或文件内容
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// 产物类型常量
+const (
+	ArtifactKindSummary = "summary" // 阶段完成总结
+	ArtifactKindFile    = "file"    // 文件
+)
+
+// StartPipelineInput 启动流水线的输入
+type StartPipelineInput struct {
+	PipelineID int64  `json:"pipeline_id"`
+	TaskID     int64  `json:"task_id"`
+	Context    string `json:"context"` // 补充说明，可在模板中用 {{context}} 引用
+}
+
+// PipelineRunDetail 流水线运行详情
+type PipelineRunDetail struct {
+	Run       PipelineRun        `json:"run"`
+	Stages    []PipelineStage    `json:"stages"`
+	StageRuns []PipelineStageRun `json:"stage_runs"`
+	Artifacts []PipelineArtifact `json:"artifacts"`
+}
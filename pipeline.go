@@ -0,0 +1,915 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxPipelineStageRuns  = 20               // 单次运行最多执行的阶段次数，防止 goto 循环
+	maxArtifactFileSize   = 64 * 1024        // 产物文件最多保存的字符数
+	stageCriteriaTimeout  = 10 * time.Minute // 成功条件命令的超时时间
+	maxStageAttemptsLimit = 5                // 单个阶段最多尝试次数
+)
+
+// pipelineTemplatePattern 阶段输入模板中的变量 {{name}}
+var pipelineTemplatePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// 流水线查询的基础 SQL
+const pipelineSelectSQL = `
+	SELECT id, name, description, stages, enabled, created_at
+	FROM pipelines
+`
+
+// 流水线运行查询的基础 SQL
+const pipelineRunSelectSQL = `
+	SELECT r.id, r.pipeline_id, COALESCE(p.name, '') as pipeline_name, r.task_id, r.status,
+	       r.current_stage, r.context, r.error, r.created_at, r.updated_at
+	FROM pipeline_runs r
+	LEFT JOIN pipelines p ON r.pipeline_id = p.id
+`
+
+// 阶段运行查询的基础 SQL
+const stageRunSelectSQL = `
+	SELECT id, run_id, stage_index, stage_name, agent_id, conversation_id, attempt,
+	       status, summary, error, created_at, finished_at
+	FROM pipeline_stage_runs
+`
+
+// scanPipeline 扫描流水线
+func scanPipeline(row interface{ Scan(...any) error }) (Pipeline, error) {
+	var p Pipeline
+	var stages string
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &stages, &p.Enabled, &p.CreatedAt)
+	if err != nil {
+		return p, err
+	}
+	json.Unmarshal([]byte(stages), &p.Stages)
+	if p.Stages == nil {
+		p.Stages = []PipelineStage{}
+	}
+	return p, nil
+}
+
+// scanPipelineRun 扫描流水线运行
+func scanPipelineRun(row interface{ Scan(...any) error }) (PipelineRun, error) {
+	var r PipelineRun
+	err := row.Scan(&r.ID, &r.PipelineID, &r.PipelineName, &r.TaskID, &r.Status,
+		&r.CurrentStage, &r.Context, &r.Error, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+// scanStageRun 扫描阶段运行
+func scanStageRun(row interface{ Scan(...any) error }) (PipelineStageRun, error) {
+	var s PipelineStageRun
+	var finishedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.RunID, &s.StageIndex, &s.StageName, &s.AgentID, &s.ConversationID,
+		&s.Attempt, &s.Status, &s.Summary, &s.Error, &s.CreatedAt, &finishedAt)
+	if finishedAt.Valid {
+		s.FinishedAt = &finishedAt.Time
+	}
+	return s, err
+}
+
+// GetPipelines 获取所有流水线
+func (a *App) GetPipelines() ([]Pipeline, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(pipelineSelectSQL + `ORDER BY created_at DESC`)
+	if err != nil {
+		log.Printf("查询流水线失败: %v", err)
+		return nil, fmt.Errorf("查询流水线失败: %v", err)
+	}
+	defer rows.Close()
+
+	var pipelines []Pipeline
+	for rows.Next() {
+		p, err := scanPipeline(rows)
+		if err != nil {
+			log.Printf("扫描流水线失败: %v", err)
+			return nil, fmt.Errorf("扫描流水线失败: %v", err)
+		}
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, nil
+}
+
+// GetPipeline 获取单个流水线
+func (a *App) GetPipeline(id int64) (*Pipeline, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	p, err := scanPipeline(db.QueryRow(pipelineSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("流水线不存在: %v", err)
+	}
+	return &p, nil
+}
+
+// CreatePipeline 创建流水线
+func (a *App) CreatePipeline(input PipelineInput) (*Pipeline, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if err := a.validatePipelineInput(&input); err != nil {
+		return nil, err
+	}
+
+	stagesJSON, _ := json.Marshal(input.Stages)
+	result, err := db.Exec(`
+		INSERT INTO pipelines (name, description, stages, enabled)
+		VALUES (?, ?, ?, ?)
+	`, input.Name, input.Description, string(stagesJSON), input.Enabled)
+	if err != nil {
+		log.Printf("创建流水线失败: %v", err)
+		return nil, fmt.Errorf("创建流水线失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取流水线ID失败: %v", err)
+	}
+
+	log.Printf("创建流水线成功: ID=%d, Name=%s", id, input.Name)
+	return a.GetPipeline(id)
+}
+
+// UpdatePipeline 更新流水线（不影响已启动的运行中的阶段）
+func (a *App) UpdatePipeline(input PipelineInput) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if err := a.validatePipelineInput(&input); err != nil {
+		return err
+	}
+
+	stagesJSON, _ := json.Marshal(input.Stages)
+	_, err := db.Exec(`
+		UPDATE pipelines SET name = ?, description = ?, stages = ?, enabled = ?
+		WHERE id = ?
+	`, input.Name, input.Description, string(stagesJSON), input.Enabled, input.ID)
+	if err != nil {
+		log.Printf("更新流水线失败: %v", err)
+		return fmt.Errorf("更新流水线失败: %v", err)
+	}
+
+	log.Printf("更新流水线成功: ID=%d", input.ID)
+	return nil
+}
+
+// DeletePipeline 删除流水线及其运行记录
+func (a *App) DeletePipeline(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	var active int
+	db.QueryRow(`SELECT COUNT(*) FROM pipeline_runs WHERE pipeline_id = ? AND status IN (?, ?)`,
+		id, PipelineRunStatusRunning, PipelineRunStatusWaitingUser).Scan(&active)
+	if active > 0 {
+		return fmt.Errorf("流水线有 %d 个运行中的实例，请先暂停或等待结束", active)
+	}
+
+	_, err := db.Exec(`
+		DELETE FROM pipeline_artifacts WHERE run_id IN (SELECT id FROM pipeline_runs WHERE pipeline_id = ?)
+	`, id)
+	if err == nil {
+		_, err = db.Exec(`
+			DELETE FROM pipeline_stage_runs WHERE run_id IN (SELECT id FROM pipeline_runs WHERE pipeline_id = ?)
+		`, id)
+	}
+	if err == nil {
+		_, err = db.Exec(`DELETE FROM pipeline_runs WHERE pipeline_id = ?`, id)
+	}
+	if err == nil {
+		_, err = db.Exec(`DELETE FROM pipelines WHERE id = ?`, id)
+	}
+	if err != nil {
+		log.Printf("删除流水线失败: %v", err)
+		return fmt.Errorf("删除流水线失败: %v", err)
+	}
+
+	log.Printf("删除流水线成功: ID=%d", id)
+	return nil
+}
+
+// validatePipelineInput 校验并补全流水线定义
+func (a *App) validatePipelineInput(input *PipelineInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return fmt.Errorf("流水线名称不能为空")
+	}
+	if len(input.Stages) == 0 {
+		return fmt.Errorf("流水线至少需要一个阶段")
+	}
+
+	names := make(map[string]bool)
+	for i := range input.Stages {
+		stage := &input.Stages[i]
+		stage.Name = strings.TrimSpace(stage.Name)
+		if stage.Name == "" {
+			stage.Name = fmt.Sprintf("阶段%d", i+1)
+		}
+		if names[stage.Name] {
+			return fmt.Errorf("阶段名称重复: %s", stage.Name)
+		}
+		names[stage.Name] = true
+
+		if _, err := a.GetAgent(stage.AgentID); err != nil {
+			return fmt.Errorf("阶段 %s 的Agent不存在", stage.Name)
+		}
+		if stage.MaxAttempts <= 0 {
+			stage.MaxAttempts = 1
+		}
+		if stage.MaxAttempts > maxStageAttemptsLimit {
+			return fmt.Errorf("阶段 %s 最多尝试 %d 次", stage.Name, maxStageAttemptsLimit)
+		}
+
+		if c := stage.SuccessCriteria; c != nil {
+			switch c.Type {
+			case StageCriteriaCommand:
+				if strings.TrimSpace(c.Command) == "" {
+					return fmt.Errorf("阶段 %s 的成功条件缺少命令", stage.Name)
+				}
+			case StageCriteriaFileExists:
+				if strings.TrimSpace(c.Path) == "" {
+					return fmt.Errorf("阶段 %s 的成功条件缺少文件路径", stage.Name)
+				}
+			case StageCriteriaContains:
+				if c.Text == "" {
+					return fmt.Errorf("阶段 %s 的成功条件缺少文本", stage.Name)
+				}
+			case "":
+				stage.SuccessCriteria = nil
+			default:
+				return fmt.Errorf("阶段 %s 的成功条件类型不支持: %s", stage.Name, c.Type)
+			}
+		}
+	}
+
+	// goto 目标需在全部阶段名称确定后校验
+	for i := range input.Stages {
+		stage := &input.Stages[i]
+		action, target := parseOnFailure(stage.OnFailure)
+		switch action {
+		case "":
+			stage.OnFailure = StageOnFailureStop
+		case StageOnFailureStop, StageOnFailureSkip:
+		case StageOnFailureGoto:
+			if !names[target] {
+				return fmt.Errorf("阶段 %s 的失败跳转目标不存在: %s", stage.Name, target)
+			}
+		default:
+			return fmt.Errorf("阶段 %s 的失败处理不支持: %s", stage.Name, stage.OnFailure)
+		}
+	}
+	return nil
+}
+
+// parseOnFailure 解析失败处理，goto:<阶段名> 返回目标阶段名
+func parseOnFailure(onFailure string) (action, target string) {
+	onFailure = strings.TrimSpace(onFailure)
+	if strings.HasPrefix(onFailure, StageOnFailureGoto+":") {
+		return StageOnFailureGoto, strings.TrimSpace(strings.TrimPrefix(onFailure, StageOnFailureGoto+":"))
+	}
+	return onFailure, ""
+}
+
+// stageIndexByName 按名称查找阶段序号
+func (p *Pipeline) stageIndexByName(name string) int {
+	for i, stage := range p.Stages {
+		if stage.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// StartPipelineRun 为任务启动一次流水线运行
+func (a *App) StartPipelineRun(input StartPipelineInput) (*PipelineRunDetail, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	pipeline, err := a.GetPipeline(input.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if !pipeline.Enabled {
+		return nil, fmt.Errorf("流水线未启用")
+	}
+	if len(pipeline.Stages) == 0 {
+		return nil, fmt.Errorf("流水线没有阶段")
+	}
+	if _, err := a.GetTask(input.TaskID); err != nil {
+		return nil, err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO pipeline_runs (pipeline_id, task_id, status, current_stage, context)
+		VALUES (?, ?, ?, 0, ?)
+	`, pipeline.ID, input.TaskID, PipelineRunStatusRunning, input.Context)
+	if err != nil {
+		log.Printf("创建流水线运行失败: %v", err)
+		return nil, fmt.Errorf("创建流水线运行失败: %v", err)
+	}
+
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取流水线运行ID失败: %v", err)
+	}
+
+	if err := a.startPipelineStage(runID, 0, 1, ""); err != nil {
+		a.failPipelineRun(runID, err.Error())
+		return nil, err
+	}
+
+	log.Printf("启动流水线成功: runID=%d, pipeline=%s", runID, pipeline.Name)
+	return a.GetPipelineRun(runID)
+}
+
+// PausePipelineRun 暂停流水线运行，当前阶段执行完后不再进入下一阶段
+func (a *App) PausePipelineRun(runID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	result, err := db.Exec(`
+		UPDATE pipeline_runs SET status = ?, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, PipelineRunStatusPaused, time.Now(), runID, PipelineRunStatusRunning, PipelineRunStatusWaitingUser)
+	if err != nil {
+		return fmt.Errorf("暂停流水线失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("流水线运行不存在或未在运行中")
+	}
+
+	log.Printf("暂停流水线: runID=%d", runID)
+	return nil
+}
+
+// ResumePipelineRun 继续已暂停或失败的流水线运行
+func (a *App) ResumePipelineRun(runID int64) (*PipelineRunDetail, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	run, err := a.getPipelineRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != PipelineRunStatusPaused && run.Status != PipelineRunStatusFailed {
+		return nil, fmt.Errorf("只能继续已暂停或失败的流水线")
+	}
+
+	// 暂停时阶段仍在执行，恢复状态即可，由阶段结束时继续推进
+	active, err := activeStageRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		status := PipelineRunStatusRunning
+		if active.Status == StageRunStatusWaitingUser {
+			status = PipelineRunStatusWaitingUser
+		}
+		a.updatePipelineRun(runID, status, run.CurrentStage, "")
+	} else {
+		// 暂停期间记录的待执行阶段（可能是失败后的重试）
+		attempt, note := 1, ""
+		db.QueryRow(`SELECT COALESCE(next_attempt, 1), COALESCE(next_note, '') FROM pipeline_runs WHERE id = ?`,
+			runID).Scan(&attempt, &note)
+		if err := a.startPipelineStage(runID, run.CurrentStage, attempt, note); err != nil {
+			a.failPipelineRun(runID, err.Error())
+			return nil, err
+		}
+	}
+
+	log.Printf("继续流水线: runID=%d, stage=%d", runID, run.CurrentStage)
+	return a.GetPipelineRun(runID)
+}
+
+// GetPipelineRun 获取流水线运行详情（阶段运行记录和产物）
+func (a *App) GetPipelineRun(runID int64) (*PipelineRunDetail, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	run, err := a.getPipelineRun(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &PipelineRunDetail{Run: *run, Stages: []PipelineStage{}}
+	if pipeline, err := a.GetPipeline(run.PipelineID); err == nil {
+		detail.Stages = pipeline.Stages
+	}
+
+	rows, err := db.Query(stageRunSelectSQL+`WHERE run_id = ? ORDER BY id`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("查询阶段运行失败: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s, err := scanStageRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描阶段运行失败: %v", err)
+		}
+		detail.StageRuns = append(detail.StageRuns, s)
+	}
+
+	detail.Artifacts, err = getPipelineArtifacts(`WHERE run_id = ? ORDER BY id`, runID)
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// GetTaskPipelineRuns 获取任务的所有流水线运行
+func (a *App) GetTaskPipelineRuns(taskID int64) ([]PipelineRun, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(pipelineRunSelectSQL+`WHERE r.task_id = ? ORDER BY r.created_at DESC, r.id DESC`, taskID)
+	if err != nil {
+		log.Printf("查询流水线运行失败: %v", err)
+		return nil, fmt.Errorf("查询流水线运行失败: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []PipelineRun
+	for rows.Next() {
+		r, err := scanPipelineRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描流水线运行失败: %v", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
+
+// getPipelineRun 获取流水线运行
+func (a *App) getPipelineRun(runID int64) (*PipelineRun, error) {
+	r, err := scanPipelineRun(db.QueryRow(pipelineRunSelectSQL+`WHERE r.id = ?`, runID))
+	if err != nil {
+		return nil, fmt.Errorf("流水线运行不存在: %v", err)
+	}
+	return &r, nil
+}
+
+// activeStageRun 获取运行中（或等待用户）的阶段，没有时返回 nil
+func activeStageRun(runID int64) (*PipelineStageRun, error) {
+	s, err := scanStageRun(db.QueryRow(stageRunSelectSQL+`WHERE run_id = ? AND status IN (?, ?) ORDER BY id DESC LIMIT 1`,
+		runID, StageRunStatusRunning, StageRunStatusWaitingUser))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询阶段运行失败: %v", err)
+	}
+	return &s, nil
+}
+
+// getPipelineArtifacts 查询产物
+func getPipelineArtifacts(where string, args ...interface{}) ([]PipelineArtifact, error) {
+	rows, err := db.Query(`
+		SELECT id, run_id, stage_run_id, stage_name, kind, name, content, created_at
+		FROM pipeline_artifacts
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询产物失败: %v", err)
+	}
+	defer rows.Close()
+
+	var artifacts []PipelineArtifact
+	for rows.Next() {
+		var art PipelineArtifact
+		if err := rows.Scan(&art.ID, &art.RunID, &art.StageRunID, &art.StageName, &art.Kind,
+			&art.Name, &art.Content, &art.CreatedAt); err != nil {
+			return nil, fmt.Errorf("扫描产物失败: %v", err)
+		}
+		artifacts = append(artifacts, art)
+	}
+	return artifacts, nil
+}
+
+// updatePipelineRun 更新流水线运行状态
+func (a *App) updatePipelineRun(runID int64, status string, currentStage int, errMsg string) {
+	_, err := db.Exec(`
+		UPDATE pipeline_runs SET status = ?, current_stage = ?, error = ?, updated_at = ?
+		WHERE id = ?
+	`, status, currentStage, errMsg, time.Now(), runID)
+	if err != nil {
+		log.Printf("更新流水线运行失败: %v", err)
+	}
+}
+
+// setPendingStage 记录继续运行时要执行的阶段、尝试次数和附加说明
+func setPendingStage(runID int64, stageIndex, attempt int, note string) {
+	_, err := db.Exec(`
+		UPDATE pipeline_runs SET current_stage = ?, next_attempt = ?, next_note = ?, updated_at = ?
+		WHERE id = ?
+	`, stageIndex, attempt, note, time.Now(), runID)
+	if err != nil {
+		log.Printf("记录待执行阶段失败: %v", err)
+	}
+}
+
+// failPipelineRun 将流水线运行标记为失败
+func (a *App) failPipelineRun(runID int64, errMsg string) {
+	_, err := db.Exec(`UPDATE pipeline_runs SET status = ?, error = ?, updated_at = ? WHERE id = ?`,
+		PipelineRunStatusFailed, errMsg, time.Now(), runID)
+	if err != nil {
+		log.Printf("更新流水线运行失败: %v", err)
+	}
+	log.Printf("流水线运行失败: runID=%d, %s", runID, errMsg)
+}
+
+// startPipelineStage 为阶段创建会话并异步执行
+func (a *App) startPipelineStage(runID int64, stageIndex, attempt int, note string) error {
+	run, err := a.getPipelineRun(runID)
+	if err != nil {
+		return err
+	}
+	pipeline, err := a.GetPipeline(run.PipelineID)
+	if err != nil {
+		return err
+	}
+	if stageIndex < 0 || stageIndex >= len(pipeline.Stages) {
+		return fmt.Errorf("阶段不存在: %d", stageIndex)
+	}
+
+	var stageRuns int
+	db.QueryRow(`SELECT COUNT(*) FROM pipeline_stage_runs WHERE run_id = ?`, runID).Scan(&stageRuns)
+	if stageRuns >= maxPipelineStageRuns {
+		return fmt.Errorf("阶段执行次数超过上限 (%d)", maxPipelineStageRuns)
+	}
+
+	stage := pipeline.Stages[stageIndex]
+	stageContext, err := a.buildStageContext(run, pipeline, stageIndex, note)
+	if err != nil {
+		return err
+	}
+
+	convID, agent, err := a.createConversation(StartConversationInput{
+		TaskID:       run.TaskID,
+		AgentID:      stage.AgentID,
+		ExtraContext: stageContext,
+	})
+	if err != nil {
+		return fmt.Errorf("阶段 %s 创建会话失败: %v", stage.Name, err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO pipeline_stage_runs (run_id, stage_index, stage_name, agent_id, conversation_id, attempt, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, runID, stageIndex, stage.Name, stage.AgentID, convID, attempt, StageRunStatusRunning)
+	if err != nil {
+		log.Printf("保存阶段运行失败: %v", err)
+		return fmt.Errorf("保存阶段运行失败: %v", err)
+	}
+
+	a.updatePipelineRun(runID, PipelineRunStatusRunning, stageIndex, "")
+	setPendingStage(runID, stageIndex, 1, "")
+	log.Printf("启动流水线阶段: runID=%d, stage=%s, attempt=%d, conversationID=%d", runID, stage.Name, attempt, convID)
+
+	go a.runAIConversation(convID, agent)
+	return nil
+}
+
+// buildStageContext 渲染阶段输入，并附上上一阶段的产物
+func (a *App) buildStageContext(run *PipelineRun, pipeline *Pipeline, stageIndex int, note string) (string, error) {
+	task, err := a.GetTask(run.TaskID)
+	if err != nil {
+		return "", err
+	}
+	stage := pipeline.Stages[stageIndex]
+
+	artifacts, err := getPipelineArtifacts(`WHERE run_id = ? ORDER BY id`, run.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// 每个阶段取最近一次成功运行的产物
+	latest := make(map[string]int64)
+	for _, art := range artifacts {
+		if art.StageRunID > latest[art.StageName] {
+			latest[art.StageName] = art.StageRunID
+		}
+	}
+	vars := map[string]string{
+		"task.name":        task.Name,
+		"task.description": task.Description,
+		"context":          run.Context,
+	}
+	var prevStage string
+	var prevRunID int64
+	for name, stageRunID := range latest {
+		if stageRunID > prevRunID {
+			prevStage, prevRunID = name, stageRunID
+		}
+	}
+	var prevArtifacts []PipelineArtifact
+	for _, art := range artifacts {
+		if art.StageRunID != latest[art.StageName] {
+			continue
+		}
+		key := "stages." + art.StageName
+		if art.Kind == ArtifactKindSummary {
+			vars[key+".summary"] = art.Content
+		} else {
+			vars[key+".files"] += art.Name + "\n"
+		}
+		if art.StageRunID == prevRunID {
+			prevArtifacts = append(prevArtifacts, art)
+		}
+	}
+	if prevStage != "" {
+		vars["prev.stage"] = prevStage
+		vars["prev.summary"] = vars["stages."+prevStage+".summary"]
+		vars["prev.files"] = vars["stages."+prevStage+".files"]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## 流水线阶段\n- 流水线: %s\n- 阶段: %d/%d %s\n",
+		pipeline.Name, stageIndex+1, len(pipeline.Stages), stage.Name))
+
+	if stage.InputTemplate != "" {
+		sb.WriteString("\n## 阶段输入\n")
+		sb.WriteString(renderPipelineTemplate(stage.InputTemplate, vars))
+		sb.WriteString("\n")
+	} else if run.Context != "" {
+		sb.WriteString("\n## 补充说明\n")
+		sb.WriteString(run.Context)
+		sb.WriteString("\n")
+	}
+
+	if len(prevArtifacts) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## 上一阶段（%s）产物\n", prevStage))
+		for _, art := range prevArtifacts {
+			if art.Kind == ArtifactKindSummary {
+				sb.WriteString("### 完成总结\n" + art.Content + "\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("### 文件 %s\n```\n%s\n```\n", art.Name, art.Content))
+			}
+		}
+	}
+
+	if note != "" {
+		sb.WriteString("\n## 注意\n" + note + "\n")
+	}
+	return sb.String(), nil
+}
+
+// renderPipelineTemplate 替换模板变量，未知变量替换为空
+func renderPipelineTemplate(tmpl string, vars map[string]string) string {
+	return pipelineTemplatePattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+		name := pipelineTemplatePattern.FindStringSubmatch(match)[1]
+		return strings.TrimRight(vars[name], "\n")
+	})
+}
+
+// onConversationFinished 会话一轮执行结束后推进所属的流水线（非流水线会话直接返回）
+func (a *App) onConversationFinished(conversationID int64) {
+	if db == nil {
+		return
+	}
+
+	stageRun, err := scanStageRun(db.QueryRow(stageRunSelectSQL+`WHERE conversation_id = ? AND status IN (?, ?)`,
+		conversationID, StageRunStatusRunning, StageRunStatusWaitingUser))
+	if err != nil {
+		return
+	}
+
+	var convStatus string
+	if err := db.QueryRow(`SELECT status FROM task_conversations WHERE id = ?`, conversationID).Scan(&convStatus); err != nil {
+		log.Printf("查询会话状态失败: %v", err)
+		return
+	}
+
+	switch convStatus {
+	case ConversationStatusWaitingUser:
+		db.Exec(`UPDATE pipeline_stage_runs SET status = ? WHERE id = ?`, StageRunStatusWaitingUser, stageRun.ID)
+		db.Exec(`UPDATE pipeline_runs SET status = ?, updated_at = ? WHERE id = ? AND status = ?`,
+			PipelineRunStatusWaitingUser, time.Now(), stageRun.RunID, PipelineRunStatusRunning)
+	case ConversationStatusCompleted:
+		a.finishPipelineStage(&stageRun)
+	case ConversationStatusActive:
+		// 用户回复后会话重新执行，由下一轮结束时处理
+	default:
+		a.handleStageFailure(&stageRun, conversationError(conversationID))
+	}
+}
+
+// finishPipelineStage 阶段会话完成：检查成功条件、保存产物并进入下一阶段
+func (a *App) finishPipelineStage(stageRun *PipelineStageRun) {
+	run, err := a.getPipelineRun(stageRun.RunID)
+	if err != nil {
+		log.Printf("获取流水线运行失败: %v", err)
+		return
+	}
+	pipeline, err := a.GetPipeline(run.PipelineID)
+	if err != nil || stageRun.StageIndex >= len(pipeline.Stages) {
+		a.failPipelineRun(run.ID, "流水线定义已变更，无法继续")
+		return
+	}
+	stage := pipeline.Stages[stageRun.StageIndex]
+
+	agent, err := a.GetAgent(stageRun.AgentID)
+	if err != nil {
+		a.handleStageFailure(stageRun, fmt.Sprintf("获取Agent失败: %v", err))
+		return
+	}
+	workingDir := agent.WorkingDir
+	if workingDir == "" {
+		workingDir = "."
+	}
+
+	summary := conversationSummary(stageRun.ConversationID)
+	if err := checkStageCriteria(stage.SuccessCriteria, workingDir, summary); err != nil {
+		a.handleStageFailure(stageRun, fmt.Sprintf("未满足成功条件: %v", err))
+		return
+	}
+
+	_, err = db.Exec(`UPDATE pipeline_stage_runs SET status = ?, summary = ?, finished_at = ? WHERE id = ?`,
+		StageRunStatusSuccess, summary, time.Now(), stageRun.ID)
+	if err != nil {
+		log.Printf("更新阶段运行失败: %v", err)
+	}
+	saveStageArtifacts(stageRun, stage, workingDir, summary)
+
+	log.Printf("流水线阶段完成: runID=%d, stage=%s", run.ID, stage.Name)
+	a.continuePipeline(run.ID, stageRun.StageIndex+1, "")
+}
+
+// handleStageFailure 阶段失败：按配置重试、跳过、跳转或终止流水线
+func (a *App) handleStageFailure(stageRun *PipelineStageRun, reason string) {
+	_, err := db.Exec(`UPDATE pipeline_stage_runs SET status = ?, error = ?, finished_at = ? WHERE id = ?`,
+		StageRunStatusFailed, reason, time.Now(), stageRun.ID)
+	if err != nil {
+		log.Printf("更新阶段运行失败: %v", err)
+	}
+	log.Printf("流水线阶段失败: runID=%d, stage=%s, %s", stageRun.RunID, stageRun.StageName, reason)
+
+	run, err := a.getPipelineRun(stageRun.RunID)
+	if err != nil {
+		return
+	}
+	pipeline, err := a.GetPipeline(run.PipelineID)
+	if err != nil || stageRun.StageIndex >= len(pipeline.Stages) {
+		a.failPipelineRun(run.ID, reason)
+		return
+	}
+	stage := pipeline.Stages[stageRun.StageIndex]
+
+	note := fmt.Sprintf("阶段 %s 上一次执行失败：%s", stage.Name, reason)
+	if stageRun.Attempt < stage.MaxAttempts {
+		if run.Status == PipelineRunStatusPaused {
+			setPendingStage(run.ID, stageRun.StageIndex, stageRun.Attempt+1, note)
+			return
+		}
+		if err := a.startPipelineStage(run.ID, stageRun.StageIndex, stageRun.Attempt+1, note); err != nil {
+			a.failPipelineRun(run.ID, err.Error())
+		}
+		return
+	}
+
+	action, target := parseOnFailure(stage.OnFailure)
+	switch action {
+	case StageOnFailureSkip:
+		a.continuePipeline(run.ID, stageRun.StageIndex+1, "")
+	case StageOnFailureGoto:
+		index := pipeline.stageIndexByName(target)
+		if index < 0 {
+			a.failPipelineRun(run.ID, fmt.Sprintf("失败跳转目标不存在: %s", target))
+			return
+		}
+		a.continuePipeline(run.ID, index, note)
+	default:
+		a.failPipelineRun(run.ID, fmt.Sprintf("阶段 %s 失败: %s", stage.Name, reason))
+	}
+}
+
+// continuePipeline 进入指定阶段；已暂停时只记录位置，全部阶段完成时结束运行
+func (a *App) continuePipeline(runID int64, nextIndex int, note string) {
+	run, err := a.getPipelineRun(runID)
+	if err != nil {
+		return
+	}
+	pipeline, err := a.GetPipeline(run.PipelineID)
+	if err != nil {
+		a.failPipelineRun(runID, err.Error())
+		return
+	}
+
+	if nextIndex >= len(pipeline.Stages) {
+		a.updatePipelineRun(runID, PipelineRunStatusCompleted, len(pipeline.Stages)-1, "")
+		log.Printf("流水线运行完成: runID=%d", runID)
+		return
+	}
+	if run.Status == PipelineRunStatusPaused {
+		setPendingStage(runID, nextIndex, 1, note)
+		return
+	}
+	if err := a.startPipelineStage(runID, nextIndex, 1, note); err != nil {
+		a.failPipelineRun(runID, err.Error())
+	}
+}
+
+// checkStageCriteria 检查阶段成功条件
+func checkStageCriteria(c *StageCriteria, workingDir, summary string) error {
+	if c == nil {
+		return nil
+	}
+
+	switch c.Type {
+	case StageCriteriaCommand:
+		ctx, cancel := context.WithTimeout(context.Background(), stageCriteriaTimeout)
+		defer cancel()
+		cmd := shellCommand(ctx, c.Command)
+		cmd.Dir = workingDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("命令 %s 执行失败: %v\n%s", c.Command, err, truncateString(string(output), 2000))
+		}
+	case StageCriteriaFileExists:
+		path := c.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("文件不存在: %s", c.Path)
+		}
+	case StageCriteriaContains:
+		if !strings.Contains(summary, c.Text) {
+			return fmt.Errorf("完成总结不包含: %s", c.Text)
+		}
+	}
+	return nil
+}
+
+// saveStageArtifacts 保存阶段的完成总结和产物文件
+func saveStageArtifacts(stageRun *PipelineStageRun, stage PipelineStage, workingDir, summary string) {
+	insert := func(kind, name, content string) {
+		_, err := db.Exec(`
+			INSERT INTO pipeline_artifacts (run_id, stage_run_id, stage_name, kind, name, content)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, stageRun.RunID, stageRun.ID, stage.Name, kind, name, content)
+		if err != nil {
+			log.Printf("保存产物失败: %v", err)
+		}
+	}
+
+	insert(ArtifactKindSummary, "summary", summary)
+	for _, file := range stage.Artifacts {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("读取产物文件失败: %v", err)
+			continue
+		}
+		insert(ArtifactKindFile, file, truncateString(string(content), maxArtifactFileSize))
+	}
+}
+
+// conversationSummary 获取会话 complete 时的总结
+func conversationSummary(conversationID int64) string {
+	var summary string
+	db.QueryRow(`
+		SELECT observation FROM agent_steps
+		WHERE conversation_id = ? AND action = ? AND status = ?
+		ORDER BY id DESC LIMIT 1
+	`, conversationID, ToolComplete, StepStatusSuccess).Scan(&summary)
+	return summary
+}
+
+// conversationError 获取会话最后一条错误消息
+func conversationError(conversationID int64) string {
+	var content string
+	err := db.QueryRow(`
+		SELECT content FROM conversation_messages
+		WHERE conversation_id = ? AND message_type = ?
+		ORDER BY id DESC LIMIT 1
+	`, conversationID, MessageTypeError).Scan(&content)
+	if err != nil || content == "" {
+		return "会话执行失败"
+	}
+	return content
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// completeReply 脚本化模型直接完成并给出总结
+func completeReply(summary string) ScriptedLLMReply {
+	return ScriptedLLMReply{Content: fmt.Sprintf(`{"thought": "", "action": "complete", "action_input": {"summary": %q}}`, summary)}
+}
+
+// createTestPipeline 创建使用脚本化模型的 Agent、任务和流水线（各阶段共用同一个 Agent）
+func createTestPipeline(t *testing.T, a *App, server *ScriptedLLMServer, stages []PipelineStage) (*Pipeline, *Task) {
+	t.Helper()
+	provider := insertTestProvider(t, a, "scripted", server.URL)
+	agent, err := a.CreateAgent(AgentInput{
+		Name: "流水线Agent", ProviderID: &provider.ID, Model: "scripted-model", WorkingDir: t.TempDir(), Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("创建Agent失败: %v", err)
+	}
+	for i := range stages {
+		stages[i].AgentID = agent.ID
+	}
+	pipeline, err := a.CreatePipeline(PipelineInput{Name: "测试流水线", Stages: stages, Enabled: true})
+	if err != nil {
+		t.Fatalf("创建流水线失败: %v", err)
+	}
+	return pipeline, createTestTask(t, a, TaskInput{Name: "实现功能", Hours: 2})
+}
+
+// waitPipelineRun 等待流水线运行结束（完成、失败或暂停）
+func waitPipelineRun(t *testing.T, a *App, runID int64) *PipelineRunDetail {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		detail, err := a.GetPipelineRun(runID)
+		if err != nil {
+			t.Fatalf("查询流水线运行失败: %v", err)
+		}
+		switch detail.Run.Status {
+		case PipelineRunStatusCompleted, PipelineRunStatusFailed, PipelineRunStatusPaused:
+			if active, _ := activeStageRun(runID); active == nil {
+				return detail
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("流水线运行 %d 超时未结束", runID)
+	return nil
+}
+
+// stageRunTrace 阶段运行记录的简要轨迹，例如 "设计:success 实现:failed"
+func stageRunTrace(detail *PipelineRunDetail) string {
+	var parts []string
+	for _, s := range detail.StageRuns {
+		parts = append(parts, s.StageName+":"+s.Status)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestPipelineStagesRunInOrder(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t, completeReply("接口设计完成"), completeReply("代码已提交"))
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{
+		{Name: "设计"},
+		{Name: "实现", InputTemplate: "按设计实现：{{prev.summary}}"},
+	})
+
+	started, err := a.StartPipelineRun(StartPipelineInput{PipelineID: pipeline.ID, TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("启动流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, started.Run.ID)
+
+	if detail.Run.Status != PipelineRunStatusCompleted {
+		t.Fatalf("流水线应完成，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if trace := stageRunTrace(detail); trace != "设计:success 实现:success" {
+		t.Fatalf("阶段轨迹不符合预期: %s", trace)
+	}
+	requests := server.Requests()
+	if len(requests) != 2 || !strings.Contains(fmt.Sprint(requests[1].Messages), "按设计实现：接口设计完成") {
+		t.Fatalf("第二阶段输入应包含上一阶段总结: %+v", requests)
+	}
+	var summaries int
+	for _, art := range detail.Artifacts {
+		if art.Kind == ArtifactKindSummary {
+			summaries++
+		}
+	}
+	if summaries != 2 {
+		t.Fatalf("每个阶段应保存一份总结产物，实际 %d", summaries)
+	}
+}
+
+func TestPipelineCriteriaFailureRetries(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t, completeReply("测试未通过"), completeReply("全部测试通过"))
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{{
+		Name:            "测试",
+		SuccessCriteria: &StageCriteria{Type: StageCriteriaContains, Text: "全部测试通过"},
+		MaxAttempts:     2,
+	}})
+
+	started, err := a.StartPipelineRun(StartPipelineInput{PipelineID: pipeline.ID, TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("启动流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, started.Run.ID)
+
+	if detail.Run.Status != PipelineRunStatusCompleted {
+		t.Fatalf("重试后流水线应完成，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if trace := stageRunTrace(detail); trace != "测试:failed 测试:success" {
+		t.Fatalf("阶段轨迹不符合预期: %s", trace)
+	}
+	if detail.StageRuns[1].Attempt != 2 {
+		t.Fatalf("第二次执行的尝试次数应为2，实际 %d", detail.StageRuns[1].Attempt)
+	}
+	if !strings.Contains(fmt.Sprint(server.Requests()[1].Messages), "上一次执行失败") {
+		t.Fatal("重试时应附上上一次失败的原因")
+	}
+}
+
+func TestPipelineOnFailureSkip(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t, completeReply("没写文档"), completeReply("已发布"))
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{
+		{Name: "文档", SuccessCriteria: &StageCriteria{Type: StageCriteriaFileExists, Path: "README.md"}, OnFailure: StageOnFailureSkip},
+		{Name: "发布"},
+	})
+
+	started, err := a.StartPipelineRun(StartPipelineInput{PipelineID: pipeline.ID, TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("启动流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, started.Run.ID)
+
+	if detail.Run.Status != PipelineRunStatusCompleted {
+		t.Fatalf("跳过失败阶段后流水线应完成，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if trace := stageRunTrace(detail); trace != "文档:failed 发布:success" {
+		t.Fatalf("阶段轨迹不符合预期: %s", trace)
+	}
+}
+
+func TestPipelineOnFailureGoto(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t,
+		completeReply("第一版实现"), completeReply("评审不通过"),
+		completeReply("第二版实现"), completeReply("评审通过"),
+	)
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{
+		{Name: "实现"},
+		{Name: "评审", SuccessCriteria: &StageCriteria{Type: StageCriteriaContains, Text: "评审通过"}, OnFailure: "goto:实现"},
+	})
+
+	started, err := a.StartPipelineRun(StartPipelineInput{PipelineID: pipeline.ID, TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("启动流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, started.Run.ID)
+
+	if detail.Run.Status != PipelineRunStatusCompleted {
+		t.Fatalf("跳转重做后流水线应完成，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if trace := stageRunTrace(detail); trace != "实现:success 评审:failed 实现:success 评审:success" {
+		t.Fatalf("阶段轨迹不符合预期: %s", trace)
+	}
+	if !strings.Contains(fmt.Sprint(server.Requests()[2].Messages), "评审 上一次执行失败") {
+		t.Fatal("跳转回的阶段应附上失败原因")
+	}
+}
+
+func TestPipelineStageRunLimit(t *testing.T) {
+	a := openTestDB(t)
+	replies := make([]ScriptedLLMReply, maxPipelineStageRuns+2)
+	for i := range replies {
+		replies[i] = completeReply("还没好")
+	}
+	server := NewScriptedLLMServer(t, replies...)
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{
+		{Name: "实现"},
+		{Name: "评审", SuccessCriteria: &StageCriteria{Type: StageCriteriaContains, Text: "评审通过"}, OnFailure: "goto:实现"},
+	})
+
+	started, err := a.StartPipelineRun(StartPipelineInput{PipelineID: pipeline.ID, TaskID: task.ID})
+	if err != nil {
+		t.Fatalf("启动流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, started.Run.ID)
+
+	if detail.Run.Status != PipelineRunStatusFailed || !strings.Contains(detail.Run.Error, "上限") {
+		t.Fatalf("超过阶段执行上限应失败，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if len(detail.StageRuns) != maxPipelineStageRuns {
+		t.Fatalf("阶段执行次数应为 %d，实际 %d", maxPipelineStageRuns, len(detail.StageRuns))
+	}
+}
+
+func TestPausedPipelineKeepsPendingRetry(t *testing.T) {
+	a := openTestDB(t)
+	server := NewScriptedLLMServer(t, completeReply("全部测试通过"))
+	pipeline, task := createTestPipeline(t, a, server, []PipelineStage{{
+		Name:            "测试",
+		SuccessCriteria: &StageCriteria{Type: StageCriteriaContains, Text: "全部测试通过"},
+		MaxAttempts:     2,
+	}})
+
+	// 构造第一次执行中途被暂停的运行（不启动模型）
+	result, err := db.Exec(`INSERT INTO pipeline_runs (pipeline_id, task_id, status, current_stage) VALUES (?, ?, ?, 0)`,
+		pipeline.ID, task.ID, PipelineRunStatusPaused)
+	if err != nil {
+		t.Fatalf("创建流水线运行失败: %v", err)
+	}
+	runID, _ := result.LastInsertId()
+	convID, _, err := a.createConversation(StartConversationInput{TaskID: task.ID, AgentID: pipeline.Stages[0].AgentID})
+	if err != nil {
+		t.Fatalf("创建会话失败: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO pipeline_stage_runs (run_id, stage_index, stage_name, agent_id, conversation_id, attempt, status)
+		VALUES (?, 0, '测试', ?, ?, 1, ?)
+	`, runID, pipeline.Stages[0].AgentID, convID, StageRunStatusRunning); err != nil {
+		t.Fatalf("创建阶段运行失败: %v", err)
+	}
+	stageRun, err := activeStageRun(runID)
+	if err != nil || stageRun == nil {
+		t.Fatalf("查询阶段运行失败: %v", err)
+	}
+
+	a.handleStageFailure(stageRun, "测试未通过")
+	if detail, _ := a.GetPipelineRun(runID); detail.Run.Status != PipelineRunStatusPaused || len(detail.StageRuns) != 1 {
+		t.Fatalf("暂停时不应立即重试: %s %s", detail.Run.Status, stageRunTrace(detail))
+	}
+
+	if _, err := a.ResumePipelineRun(runID); err != nil {
+		t.Fatalf("继续流水线失败: %v", err)
+	}
+	detail := waitPipelineRun(t, a, runID)
+	if detail.Run.Status != PipelineRunStatusCompleted {
+		t.Fatalf("继续后流水线应完成，实际 %s: %s", detail.Run.Status, detail.Run.Error)
+	}
+	if len(detail.StageRuns) != 2 || detail.StageRuns[1].Attempt != 2 {
+		t.Fatalf("继续后应执行第2次尝试: %+v", detail.StageRuns)
+	}
+	if !strings.Contains(fmt.Sprint(server.Requests()[0].Messages), "测试未通过") {
+		t.Fatal("继续后的重试应附上失败原因")
+	}
+}
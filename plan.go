@@ -0,0 +1,388 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 计划查询的基础 SQL
+const planSelectSQL = `
+	SELECT id, conversation_id, task_id, summary, items, status, created_task_ids, created_at, reviewed_at
+	FROM task_plans
+`
+
+// scanTaskPlan 扫描计划
+func scanTaskPlan(row interface{ Scan(...any) error }) (TaskPlan, error) {
+	var p TaskPlan
+	var items, createdTaskIDs string
+	var reviewedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.ConversationID, &p.TaskID, &p.Summary, &items, &p.Status,
+		&createdTaskIDs, &p.CreatedAt, &reviewedAt)
+	if err != nil {
+		return p, err
+	}
+	json.Unmarshal([]byte(items), &p.Items)
+	json.Unmarshal([]byte(createdTaskIDs), &p.CreatedTaskIDs)
+	if p.Items == nil {
+		p.Items = []PlanItem{}
+	}
+	if p.CreatedTaskIDs == nil {
+		p.CreatedTaskIDs = []int64{}
+	}
+	if reviewedAt.Valid {
+		p.ReviewedAt = &reviewedAt.Time
+	}
+	return p, nil
+}
+
+// submitPlanTool submit_plan 工具定义，planner Agent 自动可用
+func submitPlanTool() AgentTool {
+	return AgentTool{
+		Name:        ToolSubmitPlan,
+		Description: "提交子任务计划供用户审核。计划按执行顺序列出子任务，提交后等待用户批准。",
+		Type:        "builtin",
+		Schema: `{
+			"type": "object",
+			"properties": {
+				"summary": {"type": "string", "description": "计划概述"},
+				"items": {
+					"type": "array",
+					"description": "按执行顺序排列的子任务",
+					"items": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "minLength": 1, "description": "子任务名称"},
+							"description": {"type": "string", "description": "子任务说明和验收标准"},
+							"hours": {"type": "number", "minimum": 0, "description": "预计工时"},
+							"priority": {"type": "string", "enum": ["high", "medium", "low"], "description": "重要程度"},
+							"urgency": {"type": "string", "enum": ["high", "medium", "low"], "description": "紧急程度"},
+							"date": {"type": "string", "description": "计划日期 YYYY-MM-DD（可选）"},
+							"depends_on": {"type": "array", "items": {"type": "integer"}, "description": "依赖的子任务序号（从1开始，只能依赖排在前面的子任务）"}
+						},
+						"required": ["name", "hours"]
+					}
+				}
+			},
+			"required": ["summary", "items"]
+		}`,
+	}
+}
+
+// SubmitPlanInput submit_plan 工具输入
+type SubmitPlanInput struct {
+	Summary string     `json:"summary"`
+	Items   []PlanItem `json:"items"`
+}
+
+// normalizePlanItems 校验并补全计划中的子任务
+func normalizePlanItems(items []PlanItem) ([]PlanItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("计划至少需要一个子任务")
+	}
+
+	normalized := make([]PlanItem, len(items))
+	for i, item := range items {
+		num := i + 1
+		item.Name = strings.TrimSpace(item.Name)
+		if item.Name == "" {
+			return nil, fmt.Errorf("第 %d 个子任务名称不能为空", num)
+		}
+		if item.Hours < 0 {
+			return nil, fmt.Errorf("第 %d 个子任务预计工时不能为负数", num)
+		}
+		if item.Priority == "" {
+			item.Priority = PriorityMedium
+		}
+		if item.Urgency == "" {
+			item.Urgency = UrgencyMedium
+		}
+		item.Date = emptyToNil(item.Date)
+		if item.Date != nil {
+			if _, err := time.Parse("2006-01-02", *item.Date); err != nil {
+				return nil, fmt.Errorf("第 %d 个子任务日期格式错误，应为 YYYY-MM-DD: %s", num, *item.Date)
+			}
+		}
+		// 只允许依赖排在前面的子任务，保证计划顺序可执行且无环
+		for _, dep := range item.DependsOn {
+			if dep < 1 || dep >= num {
+				return nil, fmt.Errorf("第 %d 个子任务的依赖 %d 无效，只能依赖排在前面的子任务", num, dep)
+			}
+		}
+		if item.DependsOn == nil {
+			item.DependsOn = []int{}
+		}
+		normalized[i] = item
+	}
+	return normalized, nil
+}
+
+// createTaskPlan 保存 planner 提交的计划（待审核）
+func (a *App) createTaskPlan(conversationID int64, input SubmitPlanInput) (*TaskPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	items, err := normalizePlanItems(input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := getConversationTaskID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一会话只保留最新的待审核计划
+	db.Exec(`UPDATE task_plans SET status = ?, reviewed_at = ? WHERE conversation_id = ? AND status = ?`,
+		PlanStatusRejected, time.Now(), conversationID, PlanStatusPending)
+
+	itemsJSON, _ := json.Marshal(items)
+	result, err := db.Exec(`
+		INSERT INTO task_plans (conversation_id, task_id, summary, items, status)
+		VALUES (?, ?, ?, ?, ?)
+	`, conversationID, taskID, strings.TrimSpace(input.Summary), string(itemsJSON), PlanStatusPending)
+	if err != nil {
+		log.Printf("保存计划失败: %v", err)
+		return nil, fmt.Errorf("保存计划失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取计划ID失败: %v", err)
+	}
+
+	log.Printf("保存计划成功: ID=%d, 子任务数=%d", id, len(items))
+	return a.GetTaskPlan(id)
+}
+
+// GetTaskPlan 获取计划
+func (a *App) GetTaskPlan(id int64) (*TaskPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	p, err := scanTaskPlan(db.QueryRow(planSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("计划不存在: %v", err)
+	}
+	return &p, nil
+}
+
+// GetTaskPlans 获取任务的所有计划
+func (a *App) GetTaskPlans(taskID int64) ([]TaskPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(planSelectSQL+`WHERE task_id = ? ORDER BY created_at DESC, id DESC`, taskID)
+	if err != nil {
+		log.Printf("查询计划失败: %v", err)
+		return nil, fmt.Errorf("查询计划失败: %v", err)
+	}
+	defer rows.Close()
+
+	var plans []TaskPlan
+	for rows.Next() {
+		p, err := scanTaskPlan(rows)
+		if err != nil {
+			log.Printf("扫描计划失败: %v", err)
+			return nil, fmt.Errorf("扫描计划失败: %v", err)
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// ApprovePlan 批准计划，按计划创建父任务的子任务
+func (a *App) ApprovePlan(input ApprovePlanInput) (*TaskPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	plan, err := a.GetTaskPlan(input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != PlanStatusPending {
+		return nil, fmt.Errorf("计划已审核，不能重复批准")
+	}
+
+	// 审核时可以修改子任务
+	items := plan.Items
+	if len(input.Items) > 0 {
+		items, err = normalizePlanItems(input.Items)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parent, err := a.GetTask(plan.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 子任务、依赖和计划状态在同一事务中写入，中途失败时全部回滚，计划仍待审核
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 子任务继承父任务的项目、截止日期和标签
+	tagIDs := make([]int64, 0, len(parent.Tags))
+	for _, tag := range parent.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	createdIDs := make([]int64, 0, len(items))
+	for i, item := range items {
+		id, err := insertTask(tx, TaskInput{
+			ProjectID:      parent.ProjectID,
+			Name:           item.Name,
+			Description:    item.Description,
+			Date:           item.Date,
+			Hours:          item.Hours,
+			Deadline:       parent.Deadline,
+			Priority:       item.Priority,
+			Urgency:        item.Urgency,
+			ParentID:       &parent.ID,
+			CompletionRule: CompletionRuleManual,
+			TagIDs:         tagIDs,
+		})
+		if err != nil {
+			log.Printf("按计划创建第 %d 个子任务失败: %v", i+1, err)
+			return nil, fmt.Errorf("创建第 %d 个子任务失败: %v", i+1, err)
+		}
+		createdIDs = append(createdIDs, id)
+
+		// depends_on 只能指向排在前面的子任务，对应的任务已创建且不会形成环
+		for _, dep := range item.DependsOn {
+			_, err := tx.Exec(`INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)`,
+				id, createdIDs[dep-1])
+			if err != nil {
+				log.Printf("按计划添加任务依赖失败: %v", err)
+				return nil, fmt.Errorf("添加第 %d 个子任务的依赖失败: %v", i+1, err)
+			}
+		}
+	}
+
+	itemsJSON, _ := json.Marshal(items)
+	createdJSON, _ := json.Marshal(createdIDs)
+	result, err := tx.Exec(`
+		UPDATE task_plans SET items = ?, status = ?, created_task_ids = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?
+	`, string(itemsJSON), PlanStatusApproved, string(createdJSON), time.Now(), plan.ID, PlanStatusPending)
+	if err != nil {
+		log.Printf("更新计划状态失败: %v", err)
+		return nil, fmt.Errorf("更新计划状态失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("计划已审核，不能重复批准")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交计划失败: %v", err)
+	}
+
+	var warnings []string
+	checked := make(map[string]bool)
+	for i, id := range createdIDs {
+		a.auditTask(id, AuditActionCreate, nil)
+		if date := items[i].Date; date != nil && !checked[*date] {
+			checked[*date] = true
+			warnings = append(warnings, capacityWarnings(*date)...)
+		}
+	}
+
+	a.saveMessage(plan.ConversationID, "system",
+		fmt.Sprintf("计划已批准，已创建 %d 个子任务", len(createdIDs)), MessageTypeResult,
+		fmt.Sprintf(`{"plan_id":%d}`, plan.ID))
+	a.updateConversationStatus(plan.ConversationID, ConversationStatusCompleted)
+
+	log.Printf("批准计划成功: ID=%d, 创建子任务 %d 个", plan.ID, len(createdIDs))
+	approved, err := a.GetTaskPlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	approved.Warnings = warnings
+	return approved, nil
+}
+
+// RejectPlan 驳回计划；带修改意见时 planner 会据此重新规划
+func (a *App) RejectPlan(input RejectPlanInput) (*TaskPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	plan, err := a.GetTaskPlan(input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != PlanStatusPending {
+		return nil, fmt.Errorf("计划已审核，不能驳回")
+	}
+
+	_, err = db.Exec(`UPDATE task_plans SET status = ?, reviewed_at = ? WHERE id = ?`,
+		PlanStatusRejected, time.Now(), plan.ID)
+	if err != nil {
+		log.Printf("更新计划状态失败: %v", err)
+		return nil, fmt.Errorf("更新计划状态失败: %v", err)
+	}
+
+	feedback := strings.TrimSpace(input.Feedback)
+	if feedback != "" {
+		_, err = a.SendMessage(SendMessageInput{
+			ConversationID: plan.ConversationID,
+			Content:        "计划被驳回，请根据以下意见重新规划并提交：\n" + feedback,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		a.saveMessage(plan.ConversationID, "system", "计划已驳回", MessageTypeResult,
+			fmt.Sprintf(`{"plan_id":%d}`, plan.ID))
+	}
+
+	log.Printf("驳回计划: ID=%d", plan.ID)
+	return a.GetTaskPlan(plan.ID)
+}
+
+// renderPlan 将计划渲染为会话中展示的文本
+func renderPlan(plan *TaskPlan) string {
+	var sb strings.Builder
+	sb.WriteString("# 子任务计划\n")
+	if plan.Summary != "" {
+		sb.WriteString(plan.Summary)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	var total float64
+	for i, item := range plan.Items {
+		total += item.Hours
+		details := []string{
+			fmt.Sprintf("%.1f 小时", item.Hours),
+			"重要程度 " + item.Priority,
+			"紧急程度 " + item.Urgency,
+		}
+		if item.Date != nil {
+			details = append(details, "计划 "+*item.Date)
+		}
+		if len(item.DependsOn) > 0 {
+			var deps []string
+			for _, dep := range item.DependsOn {
+				deps = append(deps, fmt.Sprintf("%d", dep))
+			}
+			details = append(details, "依赖 "+strings.Join(deps, ","))
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s（%s）\n", i+1, item.Name, strings.Join(details, "，")))
+		if item.Description != "" {
+			sb.WriteString("   " + item.Description + "\n")
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n合计预计工时: %.1f 小时", total))
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// submitTestPlan 创建会话并提交计划
+func submitTestPlan(t *testing.T, a *App, items []PlanItem) *TaskPlan {
+	t.Helper()
+	convID, _ := startTestConversation(t, a, AgentInput{Type: AgentTypePlanner})
+	plan, err := a.createTaskPlan(convID, SubmitPlanInput{Summary: "拆分", Items: items})
+	if err != nil {
+		t.Fatalf("提交计划失败: %v", err)
+	}
+	return plan
+}
+
+func TestApprovePlanCreatesDependencies(t *testing.T) {
+	a := openTestDB(t)
+	plan := submitTestPlan(t, a, []PlanItem{
+		{Name: "设计", Hours: 2},
+		{Name: "实现", Hours: 4, DependsOn: []int{1}},
+		{Name: "测试", Hours: 2, DependsOn: []int{1, 2}},
+	})
+
+	approved, err := a.ApprovePlan(ApprovePlanInput{PlanID: plan.ID})
+	if err != nil {
+		t.Fatalf("批准计划失败: %v", err)
+	}
+	if approved.Status != PlanStatusApproved || len(approved.CreatedTaskIDs) != 3 {
+		t.Fatalf("计划状态不符合预期: %+v", approved)
+	}
+	ids := approved.CreatedTaskIDs
+
+	deps, err := a.GetTaskDependencies(ids[2])
+	if err != nil {
+		t.Fatalf("查询依赖失败: %v", err)
+	}
+	if len(deps.Predecessors) != 2 {
+		t.Fatalf("「测试」应依赖2个子任务，实际: %+v", deps.Predecessors)
+	}
+	task, _ := a.GetTask(ids[1])
+	if task.ParentID == nil || *task.ParentID != plan.TaskID || !task.Blocked {
+		t.Fatalf("「实现」应为原任务的子任务且被「设计」阻塞: %+v", task)
+	}
+
+	if _, err := a.ApprovePlan(ApprovePlanInput{PlanID: plan.ID}); err == nil {
+		t.Fatalf("已批准的计划不能重复批准")
+	}
+}
+
+func TestApprovePlanRollsBackOnFailure(t *testing.T) {
+	a := openTestDB(t)
+	plan := submitTestPlan(t, a, []PlanItem{
+		{Name: "第一步", Hours: 1},
+		{Name: "第二步", Hours: 1, DependsOn: []int{1}},
+		{Name: "第三步", Hours: 1},
+	})
+
+	// 第三个子任务写入失败
+	if _, err := db.Exec(`CREATE TRIGGER fail_third BEFORE INSERT ON tasks WHEN NEW.name = '第三步'
+		BEGIN SELECT RAISE(ABORT, '写入失败'); END`); err != nil {
+		t.Fatalf("创建触发器失败: %v", err)
+	}
+	if _, err := a.ApprovePlan(ApprovePlanInput{PlanID: plan.ID}); err == nil {
+		t.Fatalf("子任务写入失败时批准应失败")
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE parent_id = ?`, plan.TaskID).Scan(&count)
+	if count != 0 {
+		t.Fatalf("失败后不应留下子任务，实际 %d 个", count)
+	}
+	db.QueryRow(`SELECT COUNT(*) FROM task_dependencies`).Scan(&count)
+	if count != 0 {
+		t.Fatalf("失败后不应留下依赖，实际 %d 条", count)
+	}
+	if p, _ := a.GetTaskPlan(plan.ID); p.Status != PlanStatusPending {
+		t.Fatalf("失败后计划应仍待审核，实际 %s", p.Status)
+	}
+
+	// 重试不会重复创建
+	db.Exec(`DROP TRIGGER fail_third`)
+	approved, err := a.ApprovePlan(ApprovePlanInput{PlanID: plan.ID})
+	if err != nil {
+		t.Fatalf("重试批准失败: %v", err)
+	}
+	db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE parent_id = ?`, plan.TaskID).Scan(&count)
+	if count != 3 || len(approved.CreatedTaskIDs) != 3 {
+		t.Fatalf("重试后应有3个子任务，实际 %d 个", count)
+	}
+}
+
+func TestApprovePlanInheritsTagsAndWarns(t *testing.T) {
+	a := openTestDB(t)
+	day := "2030-01-07"
+	plan := submitTestPlan(t, a, []PlanItem{
+		{Name: "前端", Hours: 5, Date: &day},
+		{Name: "后端", Hours: 5, Date: &day},
+		{Name: "联调", Hours: 1},
+	})
+	tag, err := a.CreateTag("迭代", "")
+	if err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+	if err := a.SetTaskTags(plan.TaskID, []int64{tag.ID}); err != nil {
+		t.Fatalf("设置标签失败: %v", err)
+	}
+
+	approved, err := a.ApprovePlan(ApprovePlanInput{PlanID: plan.ID})
+	if err != nil {
+		t.Fatalf("批准计划失败: %v", err)
+	}
+	if len(approved.Warnings) != 1 || !strings.Contains(approved.Warnings[0], day) {
+		t.Fatalf("同一天超载只应提醒一次: %v", approved.Warnings)
+	}
+
+	for i, id := range approved.CreatedTaskIDs {
+		task, _ := a.GetTask(id)
+		if len(task.Tags) != 1 || task.Tags[0].ID != tag.ID {
+			t.Errorf("子任务应继承父任务的标签: %+v", task.Tags)
+		}
+		if task.Priority != PriorityMedium || task.Urgency != UrgencyMedium {
+			t.Errorf("子任务应使用默认优先级: %s %s", task.Priority, task.Urgency)
+		}
+		wantStatus := TaskStatusScheduled
+		if i == 2 {
+			wantStatus = TaskStatusPending
+		}
+		if task.Status != wantStatus {
+			t.Errorf("子任务 %s 状态应为 %s，实际 %s", task.Name, wantStatus, task.Status)
+		}
+	}
+}
@@ -95,6 +95,7 @@ func (a *App) CreateProject(name, description, color string) (*Project, error) {
 	if err != nil {
 		return nil, fmt.Errorf("获取项目ID失败: %v", err)
 	}
+	a.auditProject(id, AuditActionCreate, nil)
 
 	// 查询创建的项目
 	var p Project
@@ -120,6 +121,7 @@ func (a *App) UpdateProject(id int64, name, description, color string) error {
 		return fmt.Errorf("项目名称不能为空")
 	}
 
+	before := snapshotProject(id)
 	_, err := db.Exec(`
 		UPDATE projects
 		SET name = ?, description = ?, color = ?
@@ -129,6 +131,7 @@ func (a *App) UpdateProject(id int64, name, description, color string) error {
 		log.Printf("更新项目失败: %v", err)
 		return fmt.Errorf("更新项目失败: %v", err)
 	}
+	a.auditProject(id, AuditActionUpdate, before)
 
 	log.Printf("更新项目成功: ID=%d", id)
 	return nil
@@ -152,11 +155,13 @@ func (a *App) DeleteProject(id int64) error {
 		return fmt.Errorf("该项目下有 %d 个任务，无法删除。请先删除或转移任务，或将项目归档", taskCount)
 	}
 
+	before := snapshotProject(id)
 	_, err = db.Exec(`DELETE FROM projects WHERE id = ?`, id)
 	if err != nil {
 		log.Printf("删除项目失败: %v", err)
 		return fmt.Errorf("删除项目失败: %v", err)
 	}
+	a.auditProject(id, AuditActionDelete, before)
 
 	log.Printf("删除项目成功: ID=%d", id)
 	return nil
@@ -173,11 +178,13 @@ func (a *App) ArchiveProject(id int64, archived bool) error {
 		archivedInt = 1
 	}
 
+	before := snapshotProject(id)
 	_, err := db.Exec(`UPDATE projects SET archived = ? WHERE id = ?`, archivedInt, id)
 	if err != nil {
 		log.Printf("归档项目失败: %v", err)
 		return fmt.Errorf("归档项目失败: %v", err)
 	}
+	a.auditProject(id, AuditActionUpdate, before)
 
 	if archived {
 		log.Printf("项目已归档: ID=%d", id)
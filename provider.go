@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 // GetModelProviders 获取所有模型提供商
@@ -104,3 +114,261 @@ func (a *App) GetEnabledProviders() ([]ModelProvider, error) {
 
 	return providers, nil
 }
+
+// providerHTTPTimeout 连接测试/模型列表请求超时
+const providerHTTPTimeout = 15 * time.Second
+
+// defaultProviderModels 各提供商的默认模型（Agent未指定模型时使用）
+var defaultProviderModels = map[string]string{
+	ProviderDeepSeek: "deepseek-chat",
+	ProviderTongyi:   "qwen-plus",
+}
+
+// providerEndpoint 拼接提供商 API 地址
+func providerEndpoint(baseURL, path string) string {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + strings.TrimPrefix(path, "/")
+}
+
+// resolveModel 确定实际调用的模型名称
+func resolveModel(provider *ModelProvider, model string) (string, error) {
+	if model != "" {
+		return model, nil
+	}
+	if m, ok := defaultProviderModels[provider.Name]; ok {
+		return m, nil
+	}
+	return "", fmt.Errorf("未配置模型，且提供商 %s 没有默认模型", provider.Label)
+}
+
+// classifyProviderError 根据HTTP状态码和错误信息对提供商错误分类
+func classifyProviderError(statusCode int, err error) string {
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) {
+				return ProviderErrorURL
+			}
+			if strings.Contains(urlErr.Err.Error(), "unsupported protocol scheme") {
+				return ProviderErrorURL
+			}
+		}
+		return ProviderErrorNetwork
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ProviderErrorAuth
+	case statusCode == http.StatusNotFound || statusCode == http.StatusMethodNotAllowed:
+		return ProviderErrorURL
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusPaymentRequired:
+		return ProviderErrorQuota
+	case statusCode >= 500:
+		return ProviderErrorServer
+	case statusCode >= 400:
+		return ProviderErrorUnknown
+	}
+	return ""
+}
+
+// resolveProviderInput 将输入补全为可用于请求的提供商配置（未填写的字段使用已保存的值）
+func (a *App) resolveProviderInput(input ModelProviderInput) (*ModelProvider, error) {
+	p := &ModelProvider{
+		ID:      input.ID,
+		Name:    input.Name,
+		Label:   input.Label,
+		APIKey:  input.APIKey,
+		BaseURL: input.BaseURL,
+		Enabled: input.Enabled,
+	}
+
+	if input.ID > 0 {
+		saved, err := a.GetModelProvider(input.ID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Name == "" {
+			p.Name = saved.Name
+		}
+		if p.Label == "" {
+			p.Label = saved.Label
+		}
+		if p.APIKey == "" {
+			p.APIKey = saved.APIKey
+		}
+		if p.BaseURL == "" {
+			p.BaseURL = saved.BaseURL
+		}
+	}
+
+	if p.BaseURL == "" {
+		return nil, fmt.Errorf("未配置 API Base URL")
+	}
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("未配置 API Key")
+	}
+	return p, nil
+}
+
+// fetchProviderModels 调用提供商的 /models 接口
+func fetchProviderModels(p *ModelProvider) ([]ProviderModel, int, error) {
+	req, err := http.NewRequest("GET", providerEndpoint(p.BaseURL, "models"), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	client := newLLMClient(providerHTTPTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateString(string(body), 200))
+	}
+
+	var modelsResp struct {
+		Data []ProviderModel `json:"data"`
+	}
+	if err := json.Unmarshal(body, &modelsResp); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("解析模型列表失败: %v", err)
+	}
+
+	sort.Slice(modelsResp.Data, func(i, j int) bool {
+		return modelsResp.Data[i].ID < modelsResp.Data[j].ID
+	})
+	return modelsResp.Data, resp.StatusCode, nil
+}
+
+// probeProviderChat 通过最小的 chat/completions 请求探测提供商（不支持 /models 时使用）
+// 返回状态码和响应内容
+func probeProviderChat(p *ModelProvider, model string) (int, string, error) {
+	reqBody, _ := json.Marshal(ChatRequest{
+		Model:     model,
+		Messages:  []ChatMessage{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+
+	req, err := http.NewRequest("POST", providerEndpoint(p.BaseURL, "chat/completions"), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return 0, "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	client := newLLMClient(providerHTTPTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, string(body), fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateString(string(body), 200))
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// isModelNotFound 判断错误响应是否表示模型不存在（地址错误时返回的 404 不算）
+func isModelNotFound(body string) bool {
+	body = strings.ToLower(body)
+	if strings.Contains(body, "model_not_found") {
+		return true
+	}
+	return strings.Contains(body, "model") &&
+		(strings.Contains(body, "not found") || strings.Contains(body, "not exist") || strings.Contains(body, "does not exist"))
+}
+
+// TestModelProvider 测试模型提供商连接（鉴权、地址、网络、额度）
+// input 中未填写的 API Key / Base URL 使用已保存的值，便于在保存前测试
+func (a *App) TestModelProvider(input ModelProviderInput) (*ProviderTestResult, error) {
+	p, err := a.resolveProviderInput(input)
+	if err != nil {
+		return &ProviderTestResult{Success: false, ErrorType: ProviderErrorConfig, Error: err.Error()}, nil
+	}
+
+	start := time.Now()
+	models, statusCode, err := fetchProviderModels(p)
+	result := &ProviderTestResult{StatusCode: statusCode}
+
+	// 不支持 /models 的提供商，退回到 chat/completions 探测
+	if err != nil && (statusCode == http.StatusNotFound || statusCode == http.StatusMethodNotAllowed) {
+		model, modelErr := resolveModel(p, "")
+		if modelErr == nil {
+			start = time.Now()
+			var body string
+			statusCode, body, err = probeProviderChat(p, model)
+			result.StatusCode = statusCode
+			// 鉴权通过但请求参数或模型不对时，也视为连接正常；其余 404 说明地址错误
+			if statusCode == http.StatusBadRequest || (statusCode == http.StatusNotFound && isModelNotFound(body)) {
+				err = nil
+			}
+		}
+	} else {
+		result.ModelsSupported = err == nil
+		result.ModelCount = len(models)
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.ErrorType = classifyProviderError(statusCode, unwrapNetError(err, statusCode))
+		result.Error = err.Error()
+		log.Printf("测试模型提供商失败: %s, %s: %v", p.Label, result.ErrorType, err)
+		return result, nil
+	}
+
+	result.Success = true
+	log.Printf("测试模型提供商成功: %s, 耗时 %dms", p.Label, result.LatencyMs)
+	return result, nil
+}
+
+// ListProviderModels 获取提供商支持的模型列表（调用 /models 接口）
+func (a *App) ListProviderModels(providerID int64) ([]ProviderModel, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	p, err := a.resolveProviderInput(ModelProviderInput{ID: providerID})
+	if err != nil {
+		return nil, err
+	}
+
+	models, statusCode, err := fetchProviderModels(p)
+	if err != nil {
+		if statusCode == http.StatusNotFound || statusCode == http.StatusMethodNotAllowed {
+			return nil, fmt.Errorf("提供商 %s 不支持获取模型列表，请手动填写模型名称", p.Label)
+		}
+		log.Printf("获取模型列表失败: %v", err)
+		return nil, fmt.Errorf("获取模型列表失败: %v", err)
+	}
+
+	return models, nil
+}
+
+// unwrapNetError 对于已收到HTTP响应的错误，不再视为网络错误
+func unwrapNetError(err error, statusCode int) error {
+	if statusCode > 0 {
+		return nil
+	}
+	return err
+}
+
+// truncateString 截断过长的字符串
+func truncateString(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "..."
+}
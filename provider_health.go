@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// providerHealthWindow 每个提供商保留的最近调用记录数
+const providerHealthWindow = 50
+
+// providerCallSample 单次调用记录
+type providerCallSample struct {
+	at      time.Time
+	latency time.Duration
+	err     string
+}
+
+// providerHealthTracker 记录各模型提供商最近的调用情况（仅内存，重启后清空）
+type providerHealthTracker struct {
+	mu      sync.Mutex
+	samples map[int64][]providerCallSample
+}
+
+// providerHealth 全局提供商健康状况记录
+var providerHealth = &providerHealthTracker{
+	samples: make(map[int64][]providerCallSample),
+}
+
+// record 记录一次调用
+func (t *providerHealthTracker) record(providerID int64, latency time.Duration, err error) {
+	sample := providerCallSample{at: time.Now(), latency: latency}
+	if err != nil {
+		sample.err = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.samples[providerID], sample)
+	if len(samples) > providerHealthWindow {
+		samples = samples[len(samples)-providerHealthWindow:]
+	}
+	t.samples[providerID] = samples
+}
+
+// stats 汇总某个提供商的调用情况
+func (t *providerHealthTracker) stats(p ModelProvider) ProviderHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := ProviderHealth{ProviderID: p.ID, Name: p.Name, Label: p.Label}
+	samples := t.samples[p.ID]
+	if len(samples) == 0 {
+		return h
+	}
+
+	var totalLatency time.Duration
+	for _, s := range samples {
+		totalLatency += s.latency
+		if s.err != "" {
+			h.ErrorCount++
+			h.LastError = s.err
+		}
+	}
+
+	h.TotalCalls = len(samples)
+	h.ErrorRate = float64(h.ErrorCount) / float64(h.TotalCalls) * 100
+	h.AvgLatencyMs = (totalLatency / time.Duration(h.TotalCalls)).Milliseconds()
+	lastCallAt := samples[len(samples)-1].at.Format("2006-01-02 15:04:05")
+	h.LastCallAt = &lastCallAt
+	return h
+}
+
+// GetProviderHealth 获取各模型提供商的健康状况（最近错误率、平均耗时）
+func (a *App) GetProviderHealth() ([]ProviderHealth, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	providers, err := a.GetModelProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ProviderHealth
+	for _, p := range providers {
+		result = append(result, providerHealth.stats(p))
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestModelProviderChatFallback 不支持 /models 时按 chat/completions 的响应判断连接是否正常
+func TestModelProviderChatFallback(t *testing.T) {
+	cases := []struct {
+		name       string
+		chatStatus int
+		chatBody   string
+		success    bool
+		errorType  string
+	}{
+		{"正常", http.StatusOK, `{"choices":[]}`, true, ""},
+		{"参数错误", http.StatusBadRequest, `{"error":{"message":"max_tokens invalid"}}`, true, ""},
+		{"模型不存在", http.StatusNotFound, `{"error":{"code":"model_not_found","message":"The model does not exist"}}`, true, ""},
+		{"地址错误", http.StatusNotFound, `404 page not found`, false, ProviderErrorURL},
+		{"鉴权失败", http.StatusUnauthorized, `{"error":"invalid api key"}`, false, ProviderErrorAuth},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/chat/completions" {
+					w.WriteHeader(c.chatStatus)
+					w.Write([]byte(c.chatBody))
+					return
+				}
+				http.NotFound(w, r)
+			}))
+			defer server.Close()
+
+			app := &App{}
+			result, err := app.TestModelProvider(ModelProviderInput{
+				Name: ProviderDeepSeek, Label: "DeepSeek", APIKey: "sk-test", BaseURL: server.URL,
+			})
+			if err != nil {
+				t.Fatalf("TestModelProvider 返回错误: %v", err)
+			}
+			if result.Success != c.success || result.ErrorType != c.errorType {
+				t.Fatalf("结果为 success=%v errorType=%q，期望 success=%v errorType=%q",
+					result.Success, result.ErrorType, c.success, c.errorType)
+			}
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recurrenceHorizonDays 提前生成重复任务实例的天数
+const recurrenceHorizonDays = 30
+
+// maxRecurrenceRangeDays 查看日期范围时最多生成重复任务实例的天数
+const maxRecurrenceRangeDays = 366
+
+// recurrenceMu 避免并发生成同一批重复任务实例
+var recurrenceMu sync.Mutex
+
+const recurrenceSelectSQL = `
+	SELECT r.id, r.project_id, COALESCE(p.name, '') as project_name,
+		   r.name, r.description, r.start_time, r.end_time, r.hours,
+		   COALESCE(r.priority, 'medium'), COALESCE(r.urgency, 'medium'),
+		   r.rrule, r.start_date, COALESCE(r.end_date, ''), COALESCE(r.generated_until, ''),
+		   r.active, r.created_at
+	FROM task_recurrences r
+	LEFT JOIN projects p ON r.project_id = p.id
+`
+
+// scanRecurrence 扫描重复规则
+func scanRecurrence(row interface{ Scan(...any) error }) (TaskRecurrence, error) {
+	var r TaskRecurrence
+	var active int
+	err := row.Scan(&r.ID, &r.ProjectID, &r.ProjectName, &r.Name, &r.Description,
+		&r.StartTime, &r.EndTime, &r.Hours, &r.Priority, &r.Urgency,
+		&r.RRule, &r.StartDate, &r.EndDate, &r.GeneratedUntil, &active, &r.CreatedAt)
+	r.Active = active == 1
+	return r, err
+}
+
+// CreateRecurrence 创建重复任务，并生成近期的任务实例
+func (a *App) CreateRecurrence(input RecurrenceInput) (*TaskRecurrence, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if input.Name == "" {
+		return nil, fmt.Errorf("任务名称不能为空")
+	}
+
+	rrule := strings.TrimSpace(input.RRule)
+	if rrule == "" {
+		if input.Pattern == nil {
+			return nil, fmt.Errorf("请设置重复规则")
+		}
+		built, err := buildRRule(*input.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		rrule = built
+	}
+	if _, err := parseRRule(rrule); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	startDate := input.StartDate
+	if startDate == "" {
+		startDate = today
+	}
+	if _, err := time.Parse("2006-01-02", startDate); err != nil {
+		return nil, fmt.Errorf("首次日期格式错误: %s", startDate)
+	}
+
+	priority := input.Priority
+	if priority == "" {
+		priority = PriorityMedium
+	}
+	urgency := input.Urgency
+	if urgency == "" {
+		urgency = UrgencyMedium
+	}
+
+	// 只生成今天及以后的实例，开始日期在过去时不补生成
+	generatedUntil := addDays(maxDate(startDate, today), -1)
+
+	result, err := db.Exec(`
+		INSERT INTO task_recurrences (project_id, name, description, start_time, end_time, hours, priority, urgency, rrule, start_date, generated_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.ProjectID, input.Name, input.Description, input.StartTime, input.EndTime, input.Hours,
+		priority, urgency, strings.TrimPrefix(rrule, "RRULE:"), startDate, generatedUntil)
+	if err != nil {
+		log.Printf("创建重复任务失败: %v", err)
+		return nil, fmt.Errorf("创建重复任务失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取重复任务ID失败: %v", err)
+	}
+
+	if err := generateRecurringTasks(addDays(today, recurrenceHorizonDays)); err != nil {
+		log.Printf("生成重复任务实例失败: %v", err)
+	}
+
+	log.Printf("创建重复任务成功: ID=%d, 规则=%s", id, rrule)
+	return a.GetRecurrence(id)
+}
+
+// GetRecurrence 获取重复规则
+func (a *App) GetRecurrence(id int64) (*TaskRecurrence, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	r, err := scanRecurrence(db.QueryRow(recurrenceSelectSQL+`WHERE r.id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("重复任务不存在: %v", err)
+	}
+	return &r, nil
+}
+
+// GetRecurrences 获取全部有效的重复规则
+func (a *App) GetRecurrences() ([]TaskRecurrence, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(recurrenceSelectSQL + `
+		WHERE r.active = 1
+		ORDER BY r.start_date, r.id
+	`)
+	if err != nil {
+		log.Printf("查询重复任务失败: %v", err)
+		return nil, fmt.Errorf("查询重复任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	recurrences := []TaskRecurrence{}
+	for rows.Next() {
+		r, err := scanRecurrence(rows)
+		if err != nil {
+			log.Printf("扫描重复任务失败: %v", err)
+			continue
+		}
+		recurrences = append(recurrences, r)
+	}
+	return recurrences, nil
+}
+
+// DeleteRecurrence 停止重复任务，deleteFuture 为 true 时同时删除今天及以后未完成的实例
+func (a *App) DeleteRecurrence(id int64, deleteFuture bool) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if deleteFuture {
+		ids, err := queryTaskIDs(`
+			SELECT id FROM tasks
+			WHERE recurrence_id = ? AND occurrence_date >= ? AND status != ?
+		`, id, time.Now().Format("2006-01-02"), TaskStatusCompleted)
+		if err != nil {
+			return err
+		}
+		for _, taskID := range ids {
+			if err := a.DeleteTask(taskID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 保留的实例变为普通任务
+	if _, err := db.Exec(`UPDATE tasks SET recurrence_id = NULL WHERE recurrence_id = ?`, id); err != nil {
+		log.Printf("解除重复任务实例失败: %v", err)
+		return fmt.Errorf("删除重复任务失败: %v", err)
+	}
+	db.Exec(`DELETE FROM recurrence_exceptions WHERE recurrence_id = ?`, id)
+
+	if _, err := db.Exec(`DELETE FROM task_recurrences WHERE id = ?`, id); err != nil {
+		log.Printf("删除重复任务失败: %v", err)
+		return fmt.Errorf("删除重复任务失败: %v", err)
+	}
+
+	log.Printf("删除重复任务成功: ID=%d", id)
+	return nil
+}
+
+// SkipOccurrence 跳过某一天的重复任务（删除该天未完成的实例，以后不再生成）
+func (a *App) SkipOccurrence(recurrenceID int64, date string) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := a.GetRecurrence(recurrenceID); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`INSERT OR IGNORE INTO recurrence_exceptions (recurrence_id, date) VALUES (?, ?)`,
+		recurrenceID, date)
+	if err != nil {
+		log.Printf("跳过重复任务失败: %v", err)
+		return fmt.Errorf("跳过重复任务失败: %v", err)
+	}
+
+	ids, err := queryTaskIDs(`
+		SELECT id FROM tasks WHERE recurrence_id = ? AND occurrence_date = ? AND status != ?
+	`, recurrenceID, date, TaskStatusCompleted)
+	if err != nil {
+		return err
+	}
+	for _, taskID := range ids {
+		if err := a.DeleteTask(taskID); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("跳过重复任务: ID=%d, 日期=%s", recurrenceID, date)
+	return nil
+}
+
+// RestoreOccurrence 取消跳过，已生成范围内的日期会重新生成实例
+func (a *App) RestoreOccurrence(recurrenceID int64, date string) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	r, err := a.GetRecurrence(recurrenceID)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`DELETE FROM recurrence_exceptions WHERE recurrence_id = ? AND date = ?`, recurrenceID, date)
+	if err != nil {
+		log.Printf("恢复重复任务失败: %v", err)
+		return fmt.Errorf("恢复重复任务失败: %v", err)
+	}
+
+	if date <= r.GeneratedUntil {
+		dates, err := recurrenceDates(r, date, date)
+		if err != nil {
+			return err
+		}
+		for _, d := range dates {
+			if err := insertOccurrence(r, d); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("恢复重复任务: ID=%d, 日期=%s", recurrenceID, date)
+	return nil
+}
+
+// UpdateOccurrence 修改重复任务实例：仅本次，或本次及以后（拆分出新的重复规则）
+func (a *App) UpdateOccurrence(input UpdateOccurrenceInput) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	task, err := a.GetTask(input.Task.ID)
+	if err != nil {
+		return err
+	}
+
+	switch input.Scope {
+	case "", RecurrenceScopeThis:
+		return a.UpdateTask(input.Task)
+	case RecurrenceScopeFuture:
+	default:
+		return fmt.Errorf("不支持的修改范围: %s", input.Scope)
+	}
+
+	if task.RecurrenceID == nil || task.OccurrenceDate == nil {
+		return fmt.Errorf("该任务不是重复任务")
+	}
+	old, err := a.GetRecurrence(*task.RecurrenceID)
+	if err != nil {
+		return err
+	}
+	occurrence := *task.OccurrenceDate
+
+	rrule := strings.TrimSpace(input.RRule)
+	if rrule == "" {
+		rrule, err = remainingRRule(old, occurrence)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := parseRRule(rrule); err != nil {
+		return err
+	}
+
+	priority := input.Task.Priority
+	if priority == "" {
+		priority = old.Priority
+	}
+	urgency := input.Task.Urgency
+	if urgency == "" {
+		urgency = old.Urgency
+	}
+
+	// 新规则从本次开始，已生成范围沿用旧规则（下面重新生成）
+	result, err := db.Exec(`
+		INSERT INTO task_recurrences (project_id, name, description, start_time, end_time, hours, priority, urgency, rrule, start_date, end_date, generated_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Task.ProjectID, input.Task.Name, input.Task.Description, input.Task.StartTime, input.Task.EndTime,
+		input.Task.Hours, priority, urgency, strings.TrimPrefix(rrule, "RRULE:"), occurrence, old.EndDate,
+		addDays(occurrence, -1))
+	if err != nil {
+		log.Printf("拆分重复任务失败: %v", err)
+		return fmt.Errorf("拆分重复任务失败: %v", err)
+	}
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取重复任务ID失败: %v", err)
+	}
+
+	// 旧规则在本次之前结束
+	_, err = db.Exec(`UPDATE task_recurrences SET end_date = ? WHERE id = ?`, addDays(occurrence, -1), old.ID)
+	if err != nil {
+		log.Printf("结束旧重复规则失败: %v", err)
+		return fmt.Errorf("拆分重复任务失败: %v", err)
+	}
+	db.Exec(`UPDATE recurrence_exceptions SET recurrence_id = ? WHERE recurrence_id = ? AND date >= ?`,
+		newID, old.ID, occurrence)
+
+	// 本次实例归入新规则，其余未完成的后续实例按新规则重新生成
+	db.Exec(`UPDATE tasks SET recurrence_id = ? WHERE id = ?`, newID, task.ID)
+	ids, err := queryTaskIDs(`
+		SELECT id FROM tasks
+		WHERE recurrence_id = ? AND occurrence_date > ? AND status != ?
+	`, old.ID, occurrence, TaskStatusCompleted)
+	if err != nil {
+		return err
+	}
+	for _, taskID := range ids {
+		if _, err := db.Exec(`UPDATE tasks SET recurrence_id = NULL WHERE id = ?`, taskID); err != nil {
+			return fmt.Errorf("拆分重复任务失败: %v", err)
+		}
+		if err := a.DeleteTask(taskID); err != nil {
+			return err
+		}
+	}
+
+	if err := a.UpdateTask(input.Task); err != nil {
+		return err
+	}
+
+	until := maxDate(old.GeneratedUntil, addDays(time.Now().Format("2006-01-02"), recurrenceHorizonDays))
+	if err := generateRecurringTasks(until); err != nil {
+		log.Printf("生成重复任务实例失败: %v", err)
+	}
+
+	log.Printf("修改重复任务（本次及以后）: 旧规则=%d, 新规则=%d, 从 %s 开始", old.ID, newID, occurrence)
+	return nil
+}
+
+// remainingRRule 拆分规则时沿用旧规则；带 COUNT 的规则扣除拆分日之前已发生的次数
+func remainingRRule(r *TaskRecurrence, from string) (string, error) {
+	rule, err := parseRRule(r.RRule)
+	if err != nil {
+		return "", err
+	}
+	if rule.count == 0 {
+		return r.RRule, nil
+	}
+
+	before, err := recurrenceDates(&TaskRecurrence{RRule: r.RRule, StartDate: r.StartDate}, r.StartDate, addDays(from, -1))
+	if err != nil {
+		return "", err
+	}
+	remaining := rule.count - len(before)
+	if remaining < 1 {
+		remaining = 1
+	}
+
+	var parts []string
+	for _, part := range strings.Split(strings.TrimPrefix(r.RRule, "RRULE:"), ";") {
+		if strings.HasPrefix(strings.ToUpper(part), "COUNT=") {
+			part = fmt.Sprintf("COUNT=%d", remaining)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ";"), nil
+}
+
+// ensureRecurringTasks 查看日期范围时按需生成其中的重复任务实例（只生成今天及以后，最多一年）
+func ensureRecurringTasks(from, to string) {
+	today := time.Now().Format("2006-01-02")
+	if to < today {
+		return
+	}
+	from = maxDate(from, today)
+	if limit := addDays(from, maxRecurrenceRangeDays); to > limit {
+		to = limit
+	}
+	if err := generateRecurringRange(from, to); err != nil {
+		log.Printf("生成重复任务实例失败: %v", err)
+	}
+}
+
+// generateRecurringTasks 为全部有效的重复规则接着已生成的位置生成截至 until 的任务实例
+func generateRecurringTasks(until string) error {
+	return generateRecurringRange("", until)
+}
+
+// generateRecurringRange 为全部有效的重复规则生成 [from, until] 内的任务实例（from 为空时从已生成的位置开始）。
+// 与已生成范围相连时推进 generated_until；查看远期日期时只生成该范围，不补齐中间的实例
+func generateRecurringRange(from, until string) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	recurrenceMu.Lock()
+	defer recurrenceMu.Unlock()
+
+	rows, err := db.Query(recurrenceSelectSQL+`
+		WHERE r.active = 1 AND COALESCE(r.generated_until, '') < ?
+		  AND (COALESCE(r.end_date, '') = '' OR COALESCE(r.generated_until, '') < r.end_date)
+		ORDER BY r.id
+	`, until)
+	if err != nil {
+		return fmt.Errorf("查询重复任务失败: %v", err)
+	}
+	var recurrences []TaskRecurrence
+	for rows.Next() {
+		r, err := scanRecurrence(rows)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描重复任务失败: %v", err)
+		}
+		recurrences = append(recurrences, r)
+	}
+	rows.Close()
+
+	for i := range recurrences {
+		r := &recurrences[i]
+		next := maxDate(addDays(r.GeneratedUntil, 1), r.StartDate)
+		contiguous := from <= next
+		dates, err := recurrenceDates(r, maxDate(from, next), until)
+		if err != nil {
+			log.Printf("重复任务 %d 规则无效: %v", r.ID, err)
+			continue
+		}
+
+		skipped, err := recurrenceExceptions(r.ID)
+		if err != nil {
+			return err
+		}
+		created := 0
+		for _, d := range dates {
+			if skipped[d] {
+				continue
+			}
+			if err := insertOccurrence(r, d); err != nil {
+				return err
+			}
+			created++
+		}
+
+		if contiguous {
+			if _, err := db.Exec(`UPDATE task_recurrences SET generated_until = ? WHERE id = ?`, until, r.ID); err != nil {
+				return fmt.Errorf("更新重复任务生成进度失败: %v", err)
+			}
+		}
+		if created > 0 {
+			log.Printf("重复任务 %d 生成 %d 个实例（%s 至 %s）", r.ID, created, maxDate(from, next), until)
+		}
+	}
+	return nil
+}
+
+// recurrenceDates 重复规则在 [from, to] 内的日期（受 end_date 限制）
+func recurrenceDates(r *TaskRecurrence, from, to string) ([]string, error) {
+	rule, err := parseRRule(r.RRule)
+	if err != nil {
+		return nil, err
+	}
+	if r.EndDate != "" && r.EndDate < to {
+		to = r.EndDate
+	}
+
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("首次日期格式错误: %s", r.StartDate)
+	}
+	fromTime, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", from)
+	}
+	toTime, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", to)
+	}
+
+	var dates []string
+	for _, d := range rule.occurrences(start, fromTime, toTime) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates, nil
+}
+
+// recurrenceExceptions 重复规则跳过的日期
+func recurrenceExceptions(recurrenceID int64) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT date FROM recurrence_exceptions WHERE recurrence_id = ?`, recurrenceID)
+	if err != nil {
+		return nil, fmt.Errorf("查询重复任务例外失败: %v", err)
+	}
+	defer rows.Close()
+
+	skipped := make(map[string]bool)
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, fmt.Errorf("扫描重复任务例外失败: %v", err)
+		}
+		skipped[date] = true
+	}
+	return skipped, nil
+}
+
+// insertOccurrence 按模板生成某一天的任务实例（已存在则跳过）
+func insertOccurrence(r *TaskRecurrence, date string) error {
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO tasks (project_id, name, description, date, start_time, end_time, hours, priority, urgency, status, recurrence_id, occurrence_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.ProjectID, r.Name, r.Description, date, r.StartTime, r.EndTime, r.Hours,
+		r.Priority, r.Urgency, TaskStatusScheduled, r.ID, date)
+	if err != nil {
+		log.Printf("生成重复任务实例失败: %v", err)
+		return fmt.Errorf("生成重复任务实例失败: %v", err)
+	}
+	return nil
+}
+
+// queryTaskIDs 查询任务ID列表
+func queryTaskIDs(query string, args ...interface{}) ([]int64, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("扫描任务失败: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// addDays 日期加减天数（格式错误时原样返回）
+func addDays(date string, days int) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+// maxDate 较晚的日期
+func maxDate(a, b string) string {
+	if a > b {
+		return a
+	}
+	return b
+}
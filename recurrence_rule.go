@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrenceScanDays 计算重复日期时最多向后扫描的天数（约20年）
+const maxRecurrenceScanDays = 366 * 20
+
+// rruleWeekdays RRULE 星期缩写
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// rruleDay BYDAY 中的一项，nth 为 0 表示每个该星期几
+type rruleDay struct {
+	nth     int
+	weekday time.Weekday
+}
+
+// recurrenceRule 解析后的 RRULE（支持 FREQ/INTERVAL/BYDAY/BYMONTHDAY/COUNT/UNTIL/WKST）
+type recurrenceRule struct {
+	freq       string
+	interval   int
+	byDay      []rruleDay
+	byMonthDay []int
+	count      int
+	until      *time.Time
+}
+
+// parseRRule 解析 RRULE 字符串
+func parseRRule(s string) (*recurrenceRule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, fmt.Errorf("重复规则不能为空")
+	}
+
+	rule := &recurrenceRule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("重复规则格式错误: %s", part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.freq = value
+			default:
+				return nil, fmt.Errorf("不支持的重复频率: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("INTERVAL 必须是正整数: %s", value)
+			}
+			rule.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("COUNT 必须是正整数: %s", value)
+			}
+			rule.count = n
+		case "UNTIL":
+			until, err := parseRRuleDate(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.until = &until
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				day, err := parseRRuleDay(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.byDay = append(rule.byDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("BYMONTHDAY 取值错误: %s", item)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, n)
+			}
+		case "WKST":
+			// 周从周一开始计算，WKST 仅接受不做处理
+		default:
+			return nil, fmt.Errorf("不支持的 RRULE 字段: %s", key)
+		}
+	}
+
+	if rule.freq == "" {
+		return nil, fmt.Errorf("重复规则缺少 FREQ")
+	}
+	if rule.count > 0 && rule.until != nil {
+		return nil, fmt.Errorf("COUNT 和 UNTIL 不能同时使用")
+	}
+	for _, day := range rule.byDay {
+		if day.nth != 0 && rule.freq != "MONTHLY" {
+			return nil, fmt.Errorf("只有 MONTHLY 支持带序号的 BYDAY")
+		}
+	}
+	if len(rule.byMonthDay) > 0 && rule.freq != "MONTHLY" {
+		return nil, fmt.Errorf("只有 MONTHLY 支持 BYMONTHDAY")
+	}
+	return rule, nil
+}
+
+// parseRRuleDay 解析 BYDAY 项，如 MO、2TU、-1FR
+func parseRRuleDay(s string) (rruleDay, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return rruleDay{}, fmt.Errorf("BYDAY 取值错误: %s", s)
+	}
+	weekday, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return rruleDay{}, fmt.Errorf("BYDAY 取值错误: %s", s)
+	}
+
+	day := rruleDay{weekday: weekday}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return rruleDay{}, fmt.Errorf("BYDAY 序号错误: %s", s)
+		}
+		day.nth = n
+	}
+	return day, nil
+}
+
+// parseRRuleDate 解析 UNTIL，支持 YYYYMMDD、YYYYMMDDTHHMMSSZ 和 YYYY-MM-DD
+func parseRRuleDate(s string) (time.Time, error) {
+	if len(s) >= 8 && !strings.Contains(s, "-") {
+		if t, err := time.Parse("20060102", s[:8]); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL 日期格式错误: %s", s)
+}
+
+// buildRRule 将常用重复方式转换为 RRULE
+func buildRRule(p RecurrencePattern) (string, error) {
+	var parts []string
+	switch p.Type {
+	case RecurrenceDaily:
+		parts = append(parts, "FREQ=DAILY")
+	case RecurrenceWeekdays:
+		parts = append(parts, "FREQ=WEEKLY", "BYDAY=MO,TU,WE,TH,FR")
+	case RecurrenceWeekly:
+		parts = append(parts, "FREQ=WEEKLY")
+		if len(p.Weekdays) > 0 {
+			var days []string
+			for _, d := range p.Weekdays {
+				if d < 0 || d > 6 {
+					return "", fmt.Errorf("星期取值错误: %d", d)
+				}
+				days = append(days, rruleWeekdayName(time.Weekday(d)))
+			}
+			parts = append(parts, "BYDAY="+strings.Join(days, ","))
+		}
+	case RecurrenceMonthlyDay:
+		if p.MonthDay == 0 || p.MonthDay < -31 || p.MonthDay > 31 {
+			return "", fmt.Errorf("每月日期取值错误: %d", p.MonthDay)
+		}
+		parts = append(parts, "FREQ=MONTHLY", fmt.Sprintf("BYMONTHDAY=%d", p.MonthDay))
+	case RecurrenceMonthlyNth:
+		if p.Nth == 0 || p.Nth < -5 || p.Nth > 5 || p.Weekday < 0 || p.Weekday > 6 {
+			return "", fmt.Errorf("每月第几个星期几取值错误")
+		}
+		parts = append(parts, "FREQ=MONTHLY", fmt.Sprintf("BYDAY=%d%s", p.Nth, rruleWeekdayName(time.Weekday(p.Weekday))))
+	default:
+		return "", fmt.Errorf("不支持的重复方式: %s", p.Type)
+	}
+
+	if p.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", p.Interval))
+	}
+	if p.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", p.Count))
+	} else if p.Until != "" {
+		until, err := time.Parse("2006-01-02", p.Until)
+		if err != nil {
+			return "", fmt.Errorf("结束日期格式错误: %s", p.Until)
+		}
+		parts = append(parts, "UNTIL="+until.Format("20060102"))
+	}
+	return strings.Join(parts, ";"), nil
+}
+
+// rruleWeekdayName 星期的 RRULE 缩写
+func rruleWeekdayName(w time.Weekday) string {
+	for name, day := range rruleWeekdays {
+		if day == w {
+			return name
+		}
+	}
+	return ""
+}
+
+// occurrences 返回 [from, to] 内的重复日期，start 为首次日期（COUNT 从 start 起计数）
+func (r *recurrenceRule) occurrences(start, from, to time.Time) []time.Time {
+	var result []time.Time
+	if r.until != nil && r.until.Before(to) {
+		to = *r.until
+	}
+
+	matched := 0
+	for i, d := 0, start; !d.After(to) && i < maxRecurrenceScanDays; i, d = i+1, d.AddDate(0, 0, 1) {
+		if !r.matches(start, d) {
+			continue
+		}
+		matched++
+		if r.count > 0 && matched > r.count {
+			break
+		}
+		if !d.Before(from) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// matches 判断日期是否符合规则
+func (r *recurrenceRule) matches(start, d time.Time) bool {
+	switch r.freq {
+	case "DAILY":
+		if daysBetween(start, d)%r.interval != 0 {
+			return false
+		}
+		return len(r.byDay) == 0 || r.matchesWeekday(d)
+
+	case "WEEKLY":
+		if daysBetween(weekStart(start), weekStart(d))/7%r.interval != 0 {
+			return false
+		}
+		if len(r.byDay) == 0 {
+			return d.Weekday() == start.Weekday()
+		}
+		return r.matchesWeekday(d)
+
+	case "MONTHLY":
+		months := (d.Year()-start.Year())*12 + int(d.Month()) - int(start.Month())
+		if months%r.interval != 0 {
+			return false
+		}
+		if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+			return d.Day() == start.Day()
+		}
+		if len(r.byMonthDay) > 0 && !r.matchesMonthDay(d) {
+			return false
+		}
+		return len(r.byDay) == 0 || r.matchesWeekday(d)
+
+	case "YEARLY":
+		if (d.Year()-start.Year())%r.interval != 0 {
+			return false
+		}
+		return d.Month() == start.Month() && d.Day() == start.Day()
+	}
+	return false
+}
+
+// matchesWeekday 判断日期是否符合 BYDAY（带序号时按月内第几个计算）
+func (r *recurrenceRule) matchesWeekday(d time.Time) bool {
+	for _, day := range r.byDay {
+		if d.Weekday() != day.weekday {
+			continue
+		}
+		switch {
+		case day.nth == 0:
+			return true
+		case day.nth > 0 && (d.Day()-1)/7+1 == day.nth:
+			return true
+		case day.nth < 0 && (daysInMonth(d)-d.Day())/7+1 == -day.nth:
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay 判断日期是否符合 BYMONTHDAY（负数表示倒数第几天）
+func (r *recurrenceRule) matchesMonthDay(d time.Time) bool {
+	for _, n := range r.byMonthDay {
+		if n > 0 && d.Day() == n {
+			return true
+		}
+		if n < 0 && daysInMonth(d)+n+1 == d.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// daysInMonth 日期所在月份的天数
+func daysInMonth(d time.Time) int {
+	return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// weekStart 日期所在周的周一
+func weekStart(d time.Time) time.Time {
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+// daysBetween 两个日期相差的天数
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
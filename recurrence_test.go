@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// countOccurrences 重复规则已生成的实例数
+func countOccurrences(t *testing.T, recurrenceID int64) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE recurrence_id = ?`, recurrenceID).Scan(&count); err != nil {
+		t.Fatalf("查询实例失败: %v", err)
+	}
+	return count
+}
+
+func TestViewingFarDateMaterializesOnlyThatDate(t *testing.T) {
+	a := openTestDB(t)
+	r, err := a.CreateRecurrence(RecurrenceInput{Name: "站会", Hours: 0.5, RRule: "FREQ=DAILY"})
+	if err != nil {
+		t.Fatalf("创建重复任务失败: %v", err)
+	}
+	horizon := countOccurrences(t, r.ID)
+	if horizon != recurrenceHorizonDays+1 {
+		t.Fatalf("创建时应生成 %d 个实例，实际 %d", recurrenceHorizonDays+1, horizon)
+	}
+
+	far := time.Now().AddDate(10, 0, 0).Format("2006-01-02")
+	tasks, err := a.GetTasksByDate(far)
+	if err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].OccurrenceDate == nil || *tasks[0].OccurrenceDate != far {
+		t.Fatalf("远期日期应有一个实例: %+v", tasks)
+	}
+	if got := countOccurrences(t, r.ID); got != horizon+1 {
+		t.Fatalf("只应生成查看的日期，实际共 %d 个实例", got)
+	}
+	if got, _ := a.GetRecurrence(r.ID); got.GeneratedUntil != r.GeneratedUntil {
+		t.Fatalf("不相连的范围不应推进生成进度: %s → %s", r.GeneratedUntil, got.GeneratedUntil)
+	}
+
+	// 再次查看不重复生成；查看一周只生成这一周
+	a.GetTasksByDate(far)
+	weekStart := time.Now().AddDate(5, 0, 0)
+	if _, err := a.GetTasksByDateRange(weekStart.Format("2006-01-02"), weekStart.AddDate(0, 0, 6).Format("2006-01-02")); err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if got := countOccurrences(t, r.ID); got != horizon+1+7 {
+		t.Fatalf("应只生成查看范围内的实例，实际共 %d 个", got)
+	}
+}
+
+func TestViewingNextDayExtendsHorizon(t *testing.T) {
+	a := openTestDB(t)
+	r, err := a.CreateRecurrence(RecurrenceInput{Name: "日报", Hours: 0.5, RRule: "FREQ=DAILY"})
+	if err != nil {
+		t.Fatalf("创建重复任务失败: %v", err)
+	}
+
+	next := addDays(r.GeneratedUntil, 1)
+	if _, err := a.GetTasksByDate(next); err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if got, _ := a.GetRecurrence(r.ID); got.GeneratedUntil != next {
+		t.Fatalf("相连的日期应推进生成进度到 %s，实际 %s", next, got.GeneratedUntil)
+	}
+
+	// 跳过后不再生成
+	skip := addDays(next, 1)
+	if err := a.SkipOccurrence(r.ID, skip); err != nil {
+		t.Fatalf("跳过失败: %v", err)
+	}
+	if tasks, _ := a.GetTasksByDate(skip); len(tasks) != 0 {
+		t.Fatalf("跳过的日期不应生成实例: %+v", tasks)
+	}
+}
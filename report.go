@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 )
 
@@ -22,58 +23,111 @@ func buildProjectFilter(projectIDs []int64) (string, []interface{}) {
 	return fmt.Sprintf(" AND COALESCE(t.project_id, 0) IN (%s)", strings.Join(placeholders, ",")), args
 }
 
-// GetProjectTimeStats 获取项目时间占比统计
-func (a *App) GetProjectTimeStats(startDate, endDate string, projectIDs []int64) ([]ProjectTimeStats, error) {
-	if db == nil {
-		return nil, fmt.Errorf("数据库未初始化")
-	}
+// trackedHours 某任务某天的实际工时
+type trackedHours struct {
+	taskID      int64
+	projectID   int64
+	projectName string
+	color       string
+	completed   bool
+	date        string
+	hours       float64
+}
 
+// loadTrackedHours 统计日期范围内实际记录的工时（跨天的记录按天拆分到各自日期）
+func loadTrackedHours(startDate, endDate string, projectIDs []int64) ([]trackedHours, error) {
 	projectFilter, filterArgs := buildProjectFilter(projectIDs)
 
 	query := fmt.Sprintf(`
-		SELECT
+		SELECT e.id, e.task_id, e.start_at, e.end_at,
 			COALESCE(t.project_id, 0) as project_id,
 			COALESCE(p.name, '未分类') as project_name,
 			COALESCE(p.color, '#86909c') as color,
-			SUM(CASE WHEN t.status = 'completed' AND t.actual_hours > 0
-				THEN t.actual_hours ELSE t.hours END) as total_hours,
-			COUNT(*) as task_count
-		FROM tasks t
+			t.status
+		FROM time_entries e
+		JOIN tasks t ON e.task_id = t.id
 		LEFT JOIN projects p ON t.project_id = p.id
-		WHERE t.date >= ? AND t.date <= ?%s
-		GROUP BY COALESCE(t.project_id, 0)
-		ORDER BY total_hours DESC
+		WHERE e.start_at < ? AND (e.end_at IS NULL OR e.end_at > ?)%s
+		ORDER BY e.start_at
 	`, projectFilter)
 
-	args := []interface{}{startDate, endDate}
+	args := []interface{}{addDays(endDate, 1), startDate}
 	args = append(args, filterArgs...)
 
 	rows, err := db.Query(query, args...)
 	if err != nil {
-		log.Printf("查询项目时间统计失败: %v", err)
-		return nil, fmt.Errorf("查询项目时间统计失败: %v", err)
+		log.Printf("查询工时记录失败: %v", err)
+		return nil, fmt.Errorf("查询工时记录失败: %v", err)
 	}
 	defer rows.Close()
 
+	var result []trackedHours
+	for rows.Next() {
+		var e TimeEntry
+		var row trackedHours
+		var status string
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.StartAt, &e.EndAt,
+			&row.projectID, &row.projectName, &row.color, &status); err != nil {
+			return nil, fmt.Errorf("扫描工时记录失败: %v", err)
+		}
+		row.taskID = e.TaskID
+		row.completed = status == TaskStatusCompleted
+
+		for date, hours := range splitEntryByDay(e) {
+			if date < startDate || date > endDate || hours <= 0 {
+				continue
+			}
+			day := row
+			day.date = date
+			day.hours = hours
+			result = append(result, day)
+		}
+	}
+	return result, nil
+}
+
+// GetProjectTimeStats 获取项目时间占比统计（按工时记录实际发生的时间统计）
+func (a *App) GetProjectTimeStats(startDate, endDate string, projectIDs []int64) ([]ProjectTimeStats, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	tracked, err := loadTrackedHours(startDate, endDate, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	var stats []ProjectTimeStats
+	index := make(map[int64]int)
+	tasks := make(map[int64]map[int64]bool)
 	var totalHours float64
 
-	for rows.Next() {
-		var s ProjectTimeStats
-		if err := rows.Scan(&s.ProjectID, &s.ProjectName, &s.Color,
-			&s.TotalHours, &s.TaskCount); err != nil {
-			return nil, fmt.Errorf("扫描统计数据失败: %v", err)
+	for _, t := range tracked {
+		i, ok := index[t.projectID]
+		if !ok {
+			i = len(stats)
+			index[t.projectID] = i
+			tasks[t.projectID] = make(map[int64]bool)
+			stats = append(stats, ProjectTimeStats{
+				ProjectID:   t.projectID,
+				ProjectName: t.projectName,
+				Color:       t.color,
+			})
 		}
-		totalHours += s.TotalHours
-		stats = append(stats, s)
+		stats[i].TotalHours += t.hours
+		tasks[t.projectID][t.taskID] = true
+		totalHours += t.hours
 	}
 
-	// 计算百分比
+	// 计算任务数和百分比
 	for i := range stats {
+		stats[i].TaskCount = len(tasks[stats[i].ProjectID])
 		if totalHours > 0 {
 			stats[i].Percentage = (stats[i].TotalHours / totalHours) * 100
 		}
+		stats[i].TotalHours = roundHours(stats[i].TotalHours)
 	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].TotalHours > stats[j].TotalHours })
 
 	return stats, nil
 }
@@ -121,6 +175,30 @@ func (a *App) GetDailyTaskStats(startDate, endDate string, projectIDs []int64) (
 		}
 		stats = append(stats, s)
 	}
+	rows.Close()
+
+	// 填充每天实际记录的工时（没有计划任务但有工时的日期也列出）
+	tracked, err := loadTrackedHours(startDate, endDate, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i := range stats {
+		index[stats[i].Date] = i
+	}
+	for _, t := range tracked {
+		i, ok := index[t.date]
+		if !ok {
+			i = len(stats)
+			index[t.date] = i
+			stats = append(stats, DailyTaskStats{Date: t.date})
+		}
+		stats[i].Hours += t.hours
+	}
+	for i := range stats {
+		stats[i].Hours = roundHours(stats[i].Hours)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Date < stats[j].Date })
 
 	return stats, nil
 }
@@ -140,36 +218,38 @@ func (a *App) GetReportData(startDate, endDate string, projectIDs []int64) (*Rep
 	// 计算汇总数据
 	var summary ReportSummary
 	var totalRate float64
+	var plannedDays int
 
 	for _, d := range dailyStats {
 		summary.TotalTasks += d.TotalCount
 		summary.CompletedTasks += d.CompletedCount
-		totalRate += d.CompletionRate
+		if d.TotalCount > 0 {
+			totalRate += d.CompletionRate
+			plannedDays++
+		}
 	}
 
 	for _, p := range projectStats {
 		summary.TotalHours += p.TotalHours
 	}
+	summary.TotalHours = roundHours(summary.TotalHours)
 
-	// 查询已完成工时
+	// 已完成任务在该时间段内记录的工时
 	if db != nil {
-		projectFilter, filterArgs := buildProjectFilter(projectIDs)
-		projectFilter = strings.ReplaceAll(projectFilter, "t.project_id", "project_id")
-
-		query := fmt.Sprintf(`
-			SELECT COALESCE(SUM(CASE WHEN actual_hours > 0 THEN actual_hours ELSE hours END), 0)
-			FROM tasks
-			WHERE date >= ? AND date <= ? AND status = 'completed'%s
-		`, projectFilter)
-
-		args := []interface{}{startDate, endDate}
-		args = append(args, filterArgs...)
-
-		db.QueryRow(query, args...).Scan(&summary.CompletedHours)
+		tracked, err := loadTrackedHours(startDate, endDate, projectIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tracked {
+			if t.completed {
+				summary.CompletedHours += t.hours
+			}
+		}
+		summary.CompletedHours = roundHours(summary.CompletedHours)
 	}
 
-	if len(dailyStats) > 0 {
-		summary.AverageRate = totalRate / float64(len(dailyStats))
+	if plannedDays > 0 {
+		summary.AverageRate = totalRate / float64(plannedDays)
 	}
 
 	return &ReportData{
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	defaultTriageDays       = 7   // 逾期分流默认天数
+	defaultTriageDailyHours = 8.0 // 逾期分流选择周末安排时周末的可用工时
+	defaultDeferredSlips    = 2   // 推迟超过该次数视为长期拖延
+)
+
+const rescheduleSelectSQL = `
+	SELECT id, task_id, from_date, to_date, COALESCE(reason, 'manual'), created_at
+	FROM task_reschedules
+`
+
+// scanReschedule 扫描改期记录
+func scanReschedule(row interface{ Scan(...any) error }) (TaskReschedule, error) {
+	var r TaskReschedule
+	err := row.Scan(&r.ID, &r.TaskID, &r.FromDate, &r.ToDate, &r.Reason, &r.CreatedAt)
+	return r, err
+}
+
+// currentTaskDate 任务当前的日期（待办或不存在返回 nil）
+func currentTaskDate(taskID int64) *string {
+	var date *string
+	db.QueryRow(`SELECT date FROM tasks WHERE id = ?`, taskID).Scan(&date)
+	if date != nil && *date == "" {
+		return nil
+	}
+	return date
+}
+
+// recordReschedule 记录已安排日期的任务改期（日期未变或原本是待办时不记录）
+func recordReschedule(taskID int64, from, to *string, reason string) {
+	if from == nil || *from == "" {
+		return
+	}
+	if to != nil && *to == "" {
+		to = nil
+	}
+	if to != nil && *to == *from {
+		return
+	}
+
+	_, err := db.Exec(`INSERT INTO task_reschedules (task_id, from_date, to_date, reason) VALUES (?, ?, ?, ?)`,
+		taskID, *from, to, reason)
+	if err != nil {
+		log.Printf("记录任务改期失败: %v", err)
+	}
+}
+
+// attachSlipCounts 填充任务的推迟次数（改到更晚的日期）
+func attachSlipCounts(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	counts, err := loadSlipCounts(collectTaskIDs(tasks))
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].SlipCount = counts[tasks[i].ID]
+	}
+	return nil
+}
+
+// loadSlipCounts 任务的推迟次数（taskIDs 为空时统计全部任务）
+func loadSlipCounts(taskIDs []int64) (map[int64]int, error) {
+	filter := ""
+	if len(taskIDs) > 0 {
+		filter = " AND task_id IN (" + joinIDs(taskIDs) + ")"
+	}
+	rows, err := db.Query(`
+		SELECT task_id, COUNT(*) FROM task_reschedules
+		WHERE to_date IS NOT NULL AND to_date > from_date` + filter + `
+		GROUP BY task_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询改期记录失败: %v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var taskID int64
+		var count int
+		if err := rows.Scan(&taskID, &count); err != nil {
+			return nil, fmt.Errorf("扫描改期记录失败: %v", err)
+		}
+		counts[taskID] = count
+	}
+	return counts, nil
+}
+
+// GetTaskRescheduleHistory 获取任务的改期记录
+func (a *App) GetTaskRescheduleHistory(taskID int64) ([]TaskReschedule, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(rescheduleSelectSQL+`WHERE task_id = ? ORDER BY created_at, id`, taskID)
+	if err != nil {
+		log.Printf("查询改期记录失败: %v", err)
+		return nil, fmt.Errorf("查询改期记录失败: %v", err)
+	}
+	defer rows.Close()
+
+	history := []TaskReschedule{}
+	for rows.Next() {
+		r, err := scanReschedule(rows)
+		if err != nil {
+			log.Printf("扫描改期记录失败: %v", err)
+			continue
+		}
+		history = append(history, r)
+	}
+	return history, nil
+}
+
+// GetChronicallyDeferredTasks 获取推迟次数超过 minSlips 的任务（minSlips 为 0 时使用默认值），按推迟次数排序
+func (a *App) GetChronicallyDeferredTasks(minSlips int) ([]DeferredTask, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if minSlips <= 0 {
+		minSlips = defaultDeferredSlips
+	}
+
+	counts, err := loadSlipCounts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []DeferredTask{}
+	for taskID, count := range counts {
+		if count <= minSlips {
+			continue
+		}
+		task, err := a.GetTask(taskID)
+		if err != nil {
+			continue
+		}
+		history, err := a.GetTaskRescheduleHistory(taskID)
+		if err != nil {
+			return nil, err
+		}
+		deferred := DeferredTask{Task: *task, SlipCount: count, History: history}
+		if len(history) > 0 {
+			deferred.OriginalDate = history[0].FromDate
+		}
+		result = append(result, deferred)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SlipCount != result[j].SlipCount {
+			return result[i].SlipCount > result[j].SlipCount
+		}
+		return result[i].Task.ID < result[j].Task.ID
+	})
+	return result, nil
+}
+
+// PreviewOverdueTriage 预览逾期分流：按优先级得分把逾期任务分散到接下来几天的剩余工时中
+func (a *App) PreviewOverdueTriage(input OverdueTriageInput) (*OverdueTriageProposal, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	today := time.Now().Format("2006-01-02")
+	startDate := input.StartDate
+	if startDate == "" || startDate < today {
+		startDate = today
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式错误: %s", input.StartDate)
+	}
+	numDays := input.Days
+	if numDays <= 0 {
+		numDays = defaultTriageDays
+	}
+	if numDays > maxScheduleDays {
+		numDays = maxScheduleDays
+	}
+	capacities, err := loadCapacities(startDate, start.AddDate(0, 0, numDays-1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	// 未指定每天工时时按工作日历；节假日和请假不安排，周末只有选择了才安排
+	proposal := &OverdueTriageProposal{Items: []OverdueTriageItem{}, Unplaced: []UnscheduledTask{}, Days: []DayLoad{}}
+	for i := 0; i < numDays; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		c := capacities[date]
+		capacity := c.Available
+		if c.Kind == CalendarDayWeekend && input.IncludeWeekends {
+			capacity = defaultTriageDailyHours
+		}
+		if capacity == 0 {
+			continue
+		}
+		if input.DailyHours > 0 {
+			capacity = input.DailyHours
+		}
+		proposal.Days = append(proposal.Days, DayLoad{Date: date, Capacity: capacity})
+	}
+	if len(proposal.Days) == 0 {
+		return proposal, nil
+	}
+
+	// 已安排的未完成任务占用工时
+	scheduled, err := a.GetTasksByDateRange(proposal.Days[0].Date, proposal.Days[len(proposal.Days)-1].Date)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range scheduled {
+		if t.Status == TaskStatusCompleted || t.Date == nil {
+			continue
+		}
+		for i := range proposal.Days {
+			if proposal.Days[i].Date == *t.Date {
+				proposal.Days[i].ScheduledHours += t.Hours
+			}
+		}
+	}
+
+	overdue, err := a.GetOverdueTasks()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := a.GetScoringConfig()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	scored := make([]ScoredTask, 0, len(overdue))
+	for _, t := range overdue {
+		scored = append(scored, scoreTask(t, *cfg, now))
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+
+	// 得分高的任务先放入最早有足够剩余工时的一天；超过一天工时的任务放到最早的空闲日
+	for _, s := range scored {
+		t := s.Task
+		hours := estimateHours(t)
+		index := -1
+		for i, day := range proposal.Days {
+			free := day.Capacity - day.ScheduledHours - day.AddedHours
+			if free >= hours || (hours > day.Capacity && day.ScheduledHours+day.AddedHours == 0) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			proposal.Unplaced = append(proposal.Unplaced, UnscheduledTask{
+				TaskID:   t.ID,
+				TaskName: t.Name,
+				Reason:   fmt.Sprintf("接下来 %d 天没有足够的剩余工时（需要 %.1f 小时）", numDays, hours),
+			})
+			continue
+		}
+
+		day := &proposal.Days[index]
+		day.AddedHours += hours
+		item := OverdueTriageItem{
+			TaskID:    t.ID,
+			TaskName:  t.Name,
+			FromDate:  *t.Date,
+			ToDate:    day.Date,
+			Hours:     hours,
+			Score:     s.Score,
+			SlipCount: t.SlipCount,
+			Deadline:  t.Deadline,
+		}
+		item.Late = t.Deadline != nil && *t.Deadline != "" && item.ToDate > *t.Deadline
+		proposal.Items = append(proposal.Items, item)
+	}
+
+	for i := range proposal.Days {
+		proposal.Days[i].ScheduledHours = roundHours(proposal.Days[i].ScheduledHours)
+		proposal.Days[i].AddedHours = roundHours(proposal.Days[i].AddedHours)
+	}
+	return proposal, nil
+}
+
+// ApplyOverdueTriage 应用逾期分流建议（只更新仍在原日期且未完成的任务），返回改期的任务数
+func (a *App) ApplyOverdueTriage(items []OverdueTriageItem) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("数据库未初始化")
+	}
+
+	befores := make(map[int64]map[string]interface{}, len(items))
+	for _, item := range items {
+		befores[item.TaskID] = snapshotTask(item.TaskID)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	var applied []OverdueTriageItem
+	for _, item := range items {
+		if _, err := time.Parse("2006-01-02", item.ToDate); err != nil {
+			return 0, fmt.Errorf("日期格式错误: %s", item.ToDate)
+		}
+		// 原日期的开始/结束时间在新日期不一定空闲，清空后由用户或自动排程重新安排；进行中的任务保持进行中
+		result, err := tx.Exec(`
+			UPDATE tasks
+			SET date = ?, start_time = NULL, end_time = NULL,
+				status = CASE WHEN status = ? THEN status ELSE ? END
+			WHERE id = ? AND date = ? AND status != ?
+		`, item.ToDate, TaskStatusInProgress, TaskStatusScheduled, item.TaskID, item.FromDate, TaskStatusCompleted)
+		if err != nil {
+			log.Printf("应用逾期分流失败: %v", err)
+			return 0, fmt.Errorf("应用逾期分流失败: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			applied = append(applied, item)
+		} else {
+			log.Printf("任务 %d 已不在原日期，跳过分流", item.TaskID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交逾期分流失败: %v", err)
+	}
+
+	for _, item := range applied {
+		toDate := item.ToDate
+		recordReschedule(item.TaskID, &item.FromDate, &toDate, RescheduleReasonTriage)
+		a.auditTask(item.TaskID, AuditActionUpdate, befores[item.TaskID])
+	}
+
+	log.Printf("应用逾期分流: 改期了 %d 个任务", len(applied))
+	return len(applied), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestOverdueTriagePlacementAndApply(t *testing.T) {
+	a := openTestDB(t)
+	past := "2020-01-01"
+	monday := "2030-01-07"
+	startTime, endTime := "09:00", "11:00"
+	createTestTask(t, a, TaskInput{Name: "已安排", Hours: 2, Date: &monday})
+	high := createTestTask(t, a, TaskInput{Name: "重要", Hours: 3, Date: &past, Priority: "high", Urgency: "high"})
+	doing := createTestTask(t, a, TaskInput{Name: "进行中", Hours: 2, Date: &past, StartTime: &startTime, EndTime: &endTime})
+	low := createTestTask(t, a, TaskInput{Name: "不急", Hours: 3, Date: &past, Priority: "low", Urgency: "low"})
+	if err := a.UpdateTaskStatus(doing.ID, TaskStatusInProgress); err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+
+	proposal, err := a.PreviewOverdueTriage(OverdueTriageInput{StartDate: monday, Days: 5, DailyHours: 4})
+	if err != nil {
+		t.Fatalf("预览逾期分流失败: %v", err)
+	}
+	// 周一已有2小时，剩余2小时只放得下进行中的任务；重要的任务放到周二，不急的放到周三
+	want := map[int64]string{high.ID: "2030-01-08", doing.ID: "2030-01-07", low.ID: "2030-01-09"}
+	if len(proposal.Items) != len(want) || len(proposal.Unplaced) != 0 {
+		t.Fatalf("分流建议不符合预期: %+v %+v", proposal.Items, proposal.Unplaced)
+	}
+	for _, item := range proposal.Items {
+		if item.ToDate != want[item.TaskID] {
+			t.Errorf("任务 %s 应改到 %s，实际 %s", item.TaskName, want[item.TaskID], item.ToDate)
+		}
+	}
+
+	// 预览后被手动改期的任务不再应用
+	if _, err := a.AssignTaskToDate(low.ID, "2030-02-01"); err != nil {
+		t.Fatalf("改期失败: %v", err)
+	}
+	applied, err := a.ApplyOverdueTriage(proposal.Items)
+	if err != nil {
+		t.Fatalf("应用逾期分流失败: %v", err)
+	}
+	if applied != 2 {
+		t.Fatalf("应改期2个任务，实际 %d", applied)
+	}
+
+	got, _ := a.GetTask(doing.ID)
+	if got.Status != TaskStatusInProgress {
+		t.Fatalf("进行中的任务应保持进行中，实际 %s", got.Status)
+	}
+	if got.StartTime != nil || got.EndTime != nil {
+		t.Fatalf("改期后应清空原日期的开始/结束时间: %v %v", got.StartTime, got.EndTime)
+	}
+	if got, _ := a.GetTask(high.ID); *got.Date != "2030-01-08" || got.Status != TaskStatusScheduled {
+		t.Fatalf("重要任务改期结果不符合预期: %s %s", *got.Date, got.Status)
+	}
+	if got, _ := a.GetTask(low.ID); *got.Date != "2030-02-01" {
+		t.Fatalf("已手动改期的任务不应被覆盖: %s", *got.Date)
+	}
+
+	history, err := a.GetTaskRescheduleHistory(high.ID)
+	if err != nil || len(history) == 0 || history[len(history)-1].Reason != RescheduleReasonTriage {
+		t.Fatalf("应记录分流改期: %v %+v", err, history)
+	}
+}
+
+func TestGetChronicallyDeferredTasks(t *testing.T) {
+	a := openTestDB(t)
+	start := "2030-03-01"
+	chronic := createTestTask(t, a, TaskInput{Name: "写年终总结", Hours: 2, Date: &start})
+	twice := createTestTask(t, a, TaskInput{Name: "整理照片", Hours: 1, Date: &start})
+	pulledIn := createTestTask(t, a, TaskInput{Name: "提前的任务", Hours: 1, Date: &start})
+
+	for _, date := range []string{"2030-03-02", "2030-03-05", "2030-03-09"} {
+		a.AssignTaskToDate(chronic.ID, date)
+	}
+	for _, date := range []string{"2030-03-02", "2030-03-03"} {
+		a.AssignTaskToDate(twice.ID, date)
+	}
+	// 改到更早的日期不算推迟
+	for _, date := range []string{"2030-02-20", "2030-02-10", "2030-02-01"} {
+		a.AssignTaskToDate(pulledIn.ID, date)
+	}
+
+	deferred, err := a.GetChronicallyDeferredTasks(0)
+	if err != nil {
+		t.Fatalf("查询长期拖延任务失败: %v", err)
+	}
+	if len(deferred) != 1 || deferred[0].Task.ID != chronic.ID || deferred[0].SlipCount != 3 {
+		t.Fatalf("默认只应返回推迟超过2次的任务: %+v", deferred)
+	}
+	if deferred[0].OriginalDate != start || len(deferred[0].History) != 3 {
+		t.Fatalf("原定日期或改期记录不符合预期: %+v", deferred[0])
+	}
+
+	deferred, err = a.GetChronicallyDeferredTasks(1)
+	if err != nil || len(deferred) != 2 || deferred[0].Task.ID != chronic.ID || deferred[1].Task.ID != twice.ID {
+		t.Fatalf("应按推迟次数排序返回2个任务: %v %+v", err, deferred)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeBlockPattern Markdown 代码块
+var codeBlockPattern = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)```")
+
+// trailingCommaPattern 对象/数组结尾多余的逗号
+var trailingCommaPattern = regexp.MustCompile(`,\s*([}\]])`)
+
+// smartQuoteReplacer 中文/弯引号替换为英文引号（仅在 JSON 结构外层生效时有意义）
+var smartQuoteReplacer = strings.NewReplacer("“", `"`, "”", `"`, "‘", `'`, "’", `'`)
+
+// parseAgentResponse 从LLM响应中解析动作
+// 依次尝试：整体JSON → 代码块中的JSON → 文本中的平衡 {...} 对象，每个候选都会尝试常见错误修复
+func parseAgentResponse(response string) (*AgentAction, error) {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return nil, fmt.Errorf("响应为空")
+	}
+
+	var candidates []string
+	if strings.HasPrefix(response, "{") {
+		candidates = append(candidates, response)
+	}
+	for _, m := range codeBlockPattern.FindAllStringSubmatch(response, -1) {
+		candidates = append(candidates, strings.TrimSpace(m[1]))
+	}
+	candidates = append(candidates, extractJSONObjects(response)...)
+
+	var lastErr error
+	for _, candidate := range candidates {
+		action, err := decodeAgentAction(candidate)
+		if err == nil {
+			return action, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("未找到 JSON 对象")
+	}
+	return nil, fmt.Errorf("无法从响应中解析动作JSON: %v", lastErr)
+}
+
+// decodeAgentAction 解码单个候选 JSON，失败时尝试修复后再解码
+func decodeAgentAction(candidate string) (*AgentAction, error) {
+	action, err := unmarshalAgentAction(candidate)
+	if err == nil {
+		return action, nil
+	}
+
+	repaired := repairJSON(candidate)
+	if repaired != candidate {
+		if action, repairErr := unmarshalAgentAction(repaired); repairErr == nil {
+			return action, nil
+		}
+	}
+	return nil, err
+}
+
+// unmarshalAgentAction 解码并规范化动作
+func unmarshalAgentAction(s string) (*AgentAction, error) {
+	var action AgentAction
+	if err := json.Unmarshal([]byte(s), &action); err != nil {
+		return nil, err
+	}
+	if action.Action == "" {
+		return nil, fmt.Errorf("缺少 action 字段")
+	}
+	action.Action = strings.TrimSpace(action.Action)
+
+	// action_input 缺失或为 null 时视为空对象
+	input := strings.TrimSpace(string(action.ActionInput))
+	if input == "" || input == "null" {
+		action.ActionInput = json.RawMessage("{}")
+	} else if strings.HasPrefix(input, `"`) {
+		// 模型把 action_input 写成了 JSON 字符串
+		var inner string
+		if err := json.Unmarshal(action.ActionInput, &inner); err == nil && json.Valid([]byte(inner)) {
+			action.ActionInput = json.RawMessage(inner)
+		}
+	}
+
+	return &action, nil
+}
+
+// repairJSON 修复模型常见的 JSON 错误：弯引号、结尾逗号、Python 字面量
+func repairJSON(s string) string {
+	s = smartQuoteReplacer.Replace(s)
+	s = trailingCommaPattern.ReplaceAllString(s, "$1")
+	s = replaceOutsideStrings(s, map[string]string{"True": "true", "False": "false", "None": "null"})
+	return s
+}
+
+// replaceOutsideStrings 仅替换 JSON 字符串以外的标识符
+func replaceOutsideStrings(s string, words map[string]string) string {
+	var sb strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			sb.WriteByte(c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			sb.WriteByte(c)
+			continue
+		}
+		replaced := false
+		for from, to := range words {
+			if strings.HasPrefix(s[i:], from) && !isIdentByte(s, i-1) && !isIdentByte(s, i+len(from)) {
+				sb.WriteString(to)
+				i += len(from) - 1
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func isIdentByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// extractJSONObjects 提取文本中所有顶层的平衡 {...} 片段（忽略字符串内的括号）
+func extractJSONObjects(s string) []string {
+	var objects []string
+	depth, start := 0, -1
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			if depth > 0 {
+				inString = true
+			}
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+				if depth == 0 && start >= 0 {
+					objects = append(objects, s[start:i+1])
+					start = -1
+				}
+			}
+		}
+	}
+	return objects
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseAgentResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		wantAction string
+		wantInput  string
+	}{
+		{
+			name:       "标准JSON",
+			response:   `{"thought": "查看文件", "action": "read_file", "action_input": {"path": "main.go"}}`,
+			wantAction: "read_file",
+			wantInput:  `{"path": "main.go"}`,
+		},
+		{
+			name:       "代码块",
+			response:   "我来读取文件。\n```json\n{\"action\": \"read_file\", \"action_input\": {\"path\": \"a.txt\"}}\n```",
+			wantAction: "read_file",
+			wantInput:  `{"path": "a.txt"}`,
+		},
+		{
+			name:       "弯引号",
+			response:   `{“thought”: “完成了”, “action”: “complete”, “action_input”: {“summary”: “已完成”}}`,
+			wantAction: "complete",
+			wantInput:  `{"summary": "已完成"}`,
+		},
+		{
+			name:       "结尾逗号",
+			response:   `{"action": "shell", "action_input": {"command": "ls", "args": ["-l", "-a",],},}`,
+			wantAction: "shell",
+			wantInput:  `{"command": "ls", "args": ["-l", "-a"]}`,
+		},
+		{
+			name:       "Python字面量",
+			response:   `{"action": "update_task", "action_input": {"done": True, "archived": False, "deadline": None, "note": "True or None"}}`,
+			wantAction: "update_task",
+			wantInput:  `{"done": true, "archived": false, "deadline": null, "note": "True or None"}`,
+		},
+		{
+			name:       "action_input为字符串",
+			response:   `{"action": "read_file", "action_input": "{\"path\": \"b.txt\"}"}`,
+			wantAction: "read_file",
+			wantInput:  `{"path": "b.txt"}`,
+		},
+		{
+			name:       "缺少action_input",
+			response:   `{"action": "list_project_tasks", "action_input": null}`,
+			wantAction: "list_project_tasks",
+			wantInput:  `{}`,
+		},
+		{
+			name:       "文本中的平衡对象",
+			response:   `好的，下一步：{"thought": "字符串里的 } 括号", "action": "complete", "action_input": {"summary": "用了 {占位符}"}} 以上。`,
+			wantAction: "complete",
+			wantInput:  `{"summary": "用了 {占位符}"}`,
+		},
+		{
+			name:       "跳过无效对象",
+			response:   `先看 {示例} 再看 {"action": " ask_user ", "action_input": {"question": "继续吗？"}}`,
+			wantAction: "ask_user",
+			wantInput:  `{"question": "继续吗？"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, err := parseAgentResponse(tt.response)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if action.Action != tt.wantAction {
+				t.Errorf("action = %q，期望 %q", action.Action, tt.wantAction)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(action.ActionInput, &got); err != nil {
+				t.Fatalf("action_input 不是有效JSON: %s", action.ActionInput)
+			}
+			json.Unmarshal([]byte(tt.wantInput), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("action_input = %s，期望 %s", action.ActionInput, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestParseAgentResponseErrors(t *testing.T) {
+	for _, response := range []string{
+		"",
+		"我需要再想想",
+		`{"thought": "没有动作"}`,
+		`{"action": "complete", "action_input": {"summary": "没有结尾"`,
+	} {
+		if action, err := parseAgentResponse(response); err == nil {
+			t.Errorf("解析 %q 应失败，实际得到 %+v", response, action)
+		}
+	}
+}
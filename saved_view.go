@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const savedViewSelectSQL = `
+	SELECT id, name, COALESCE(filter, '{}'), COALESCE(position, 0), created_at, updated_at
+	FROM saved_views
+`
+
+// scanSavedView 扫描保存的视图
+func scanSavedView(row interface{ Scan(...any) error }) (SavedView, error) {
+	var v SavedView
+	var filter string
+	if err := row.Scan(&v.ID, &v.Name, &filter, &v.Position, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal([]byte(filter), &v.Filter); err != nil {
+		log.Printf("解析视图 %d 的查询条件失败: %v", v.ID, err)
+	}
+	return v, nil
+}
+
+// GetSavedViews 获取全部保存的视图及各视图的任务数
+func (a *App) GetSavedViews() ([]SavedView, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(savedViewSelectSQL + `ORDER BY position, id`)
+	if err != nil {
+		log.Printf("查询视图失败: %v", err)
+		return nil, fmt.Errorf("查询视图失败: %v", err)
+	}
+	defer rows.Close()
+
+	views := []SavedView{}
+	for rows.Next() {
+		v, err := scanSavedView(rows)
+		if err != nil {
+			log.Printf("扫描视图失败: %v", err)
+			continue
+		}
+		views = append(views, v)
+	}
+
+	// 结果集读完后再统计各视图的任务数
+	for i := range views {
+		count, err := countTasksByFilter(views[i].Filter)
+		if err != nil {
+			log.Printf("统计视图「%s」任务数失败: %v", views[i].Name, err)
+			continue
+		}
+		views[i].Count = count
+	}
+	return views, nil
+}
+
+// GetSavedView 获取单个视图
+func (a *App) GetSavedView(id int64) (*SavedView, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	v, err := scanSavedView(db.QueryRow(savedViewSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("视图不存在: %v", err)
+	}
+	if v.Count, err = countTasksByFilter(v.Filter); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateSavedView 保存视图
+func (a *App) CreateSavedView(input SavedViewInput) (*SavedView, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	filter, err := validateViewInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO saved_views (name, filter, position)
+		VALUES (?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM saved_views))
+	`, strings.TrimSpace(input.Name), filter)
+	if err != nil {
+		log.Printf("保存视图失败: %v", err)
+		return nil, fmt.Errorf("保存视图失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取视图ID失败: %v", err)
+	}
+
+	log.Printf("保存视图成功: %s (ID: %d)", input.Name, id)
+	return a.GetSavedView(id)
+}
+
+// UpdateSavedView 更新视图名称和查询条件
+func (a *App) UpdateSavedView(input SavedViewInput) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	filter, err := validateViewInput(input)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`
+		UPDATE saved_views SET name = ?, filter = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, strings.TrimSpace(input.Name), filter, input.ID)
+	if err != nil {
+		log.Printf("更新视图失败: %v", err)
+		return fmt.Errorf("更新视图失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("视图不存在")
+	}
+
+	log.Printf("更新视图成功: %s (ID: %d)", input.Name, input.ID)
+	return nil
+}
+
+// DeleteSavedView 删除视图
+func (a *App) DeleteSavedView(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := db.Exec(`DELETE FROM saved_views WHERE id = ?`, id); err != nil {
+		log.Printf("删除视图失败: %v", err)
+		return fmt.Errorf("删除视图失败: %v", err)
+	}
+
+	log.Printf("删除视图成功: ID=%d", id)
+	return nil
+}
+
+// ReorderSavedViews 按给定顺序排列视图
+func (a *App) ReorderSavedViews(ids []int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE saved_views SET position = ? WHERE id = ?`, i+1, id); err != nil {
+			log.Printf("排列视图失败: %v", err)
+			return fmt.Errorf("排列视图失败: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSavedViewTasks 获取视图中的任务（cursor 为上一页返回的游标，空表示第一页）
+func (a *App) GetSavedViewTasks(id int64, cursor string) (*TaskPage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	v, err := scanSavedView(db.QueryRow(savedViewSelectSQL+`WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("视图不存在: %v", err)
+	}
+
+	filter := v.Filter
+	filter.Cursor = cursor
+	return a.QueryTasks(filter)
+}
+
+// validateViewInput 检查视图名称和查询条件，返回保存用的 JSON
+func validateViewInput(input SavedViewInput) (string, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return "", fmt.Errorf("视图名称不能为空")
+	}
+
+	filter := input.Filter
+	filter.Cursor = ""
+	if filter.SortBy != "" {
+		if _, ok := taskSortFields[filter.SortBy]; !ok {
+			return "", fmt.Errorf("不支持的排序字段: %s", filter.SortBy)
+		}
+	}
+	resolved, err := resolveFilterDates(filter, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := taskFilterConditions(resolved); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", fmt.Errorf("序列化查询条件失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// resolveFilterDates 把查询条件中的动态日期换算为具体日期
+func resolveFilterDates(f TaskFilter, now time.Time) (TaskFilter, error) {
+	today, _ := time.Parse("2006-01-02", now.Format("2006-01-02"))
+	fields := []struct {
+		value *string
+		end   bool
+	}{
+		{&f.DateFrom, false}, {&f.DateTo, true},
+		{&f.DeadlineFrom, false}, {&f.DeadlineTo, true},
+		{&f.CreatedFrom, false}, {&f.CreatedTo, true},
+	}
+	for _, field := range fields {
+		if *field.value == "" {
+			continue
+		}
+		date, err := resolveDateToken(*field.value, field.end, today)
+		if err != nil {
+			return f, err
+		}
+		*field.value = date
+	}
+	return f, nil
+}
+
+// resolveDateToken 换算动态日期：today/tomorrow/yesterday、±N 天(d)/周(w)/月(m)、
+// this_/next_/last_ week/month（作为开始日期取第一天，作为结束日期取最后一天）
+func resolveDateToken(token string, end bool, today time.Time) (string, error) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	if _, err := time.Parse("2006-01-02", token); err == nil {
+		return token, nil
+	}
+
+	switch token {
+	case "today":
+		return today.Format("2006-01-02"), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+
+	if parts := strings.SplitN(token, "_", 2); len(parts) == 2 {
+		offset := map[string]int{"last": -1, "this": 0, "next": 1}
+		n, ok := offset[parts[0]]
+		if ok {
+			switch parts[1] {
+			case "week":
+				start := weekStart(today).AddDate(0, 0, 7*n)
+				if end {
+					return start.AddDate(0, 0, 6).Format("2006-01-02"), nil
+				}
+				return start.Format("2006-01-02"), nil
+			case "month":
+				start := time.Date(today.Year(), today.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+				if end {
+					return start.AddDate(0, 1, -1).Format("2006-01-02"), nil
+				}
+				return start.Format("2006-01-02"), nil
+			}
+		}
+	}
+
+	if len(token) >= 3 && (token[0] == '+' || token[0] == '-') {
+		n, err := strconv.Atoi(token[1 : len(token)-1])
+		if err == nil {
+			if token[0] == '-' {
+				n = -n
+			}
+			switch token[len(token)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n).Format("2006-01-02"), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n).Format("2006-01-02"), nil
+			case 'm':
+				return today.AddDate(0, n, 0).Format("2006-01-02"), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("无法识别的日期: %s", token)
+}
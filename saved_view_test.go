@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDateToken(t *testing.T) {
+	// 2026-01-14 是周三
+	today := time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		token string
+		end   bool
+		want  string
+	}{
+		{"today", false, "2026-01-14"},
+		{" Today ", true, "2026-01-14"},
+		{"tomorrow", false, "2026-01-15"},
+		{"yesterday", false, "2026-01-13"},
+		{"2025-06-01", true, "2025-06-01"},
+		{"+3d", false, "2026-01-17"},
+		{"-20d", false, "2025-12-25"},
+		{"+1w", false, "2026-01-21"},
+		{"-2w", false, "2025-12-31"},
+		{"+1m", false, "2026-02-14"},
+		{"-1m", false, "2025-12-14"},
+		{"this_week", false, "2026-01-12"},
+		{"this_week", true, "2026-01-18"},
+		{"last_week", false, "2026-01-05"},
+		{"next_week", true, "2026-01-25"},
+		{"this_month", false, "2026-01-01"},
+		{"this_month", true, "2026-01-31"},
+		{"next_month", false, "2026-02-01"},
+		{"next_month", true, "2026-02-28"},
+		{"last_month", false, "2025-12-01"},
+		{"last_month", true, "2025-12-31"},
+	}
+	for _, c := range cases {
+		got, err := resolveDateToken(c.token, c.end, today)
+		if err != nil || got != c.want {
+			t.Errorf("resolveDateToken(%q, end=%v) = %q, %v，期望 %q", c.token, c.end, got, err, c.want)
+		}
+	}
+
+	for _, token := range []string{"soon", "+xd", "+3y", "this_year", "2026-13-01", "+d"} {
+		if got, err := resolveDateToken(token, false, today); err == nil {
+			t.Errorf("%q 应无法识别，实际返回 %q", token, got)
+		}
+	}
+}
+
+func TestResolveFilterDates(t *testing.T) {
+	now := time.Date(2026, 1, 14, 18, 30, 0, 0, time.Local)
+	got, err := resolveFilterDates(TaskFilter{
+		DateFrom:     "this_week",
+		DateTo:       "this_week",
+		DeadlineTo:   "+7d",
+		CreatedFrom:  "last_month",
+		CreatedTo:    "last_month",
+		DeadlineFrom: "",
+	}, now)
+	if err != nil {
+		t.Fatalf("换算日期失败: %v", err)
+	}
+	if got.DateFrom != "2026-01-12" || got.DateTo != "2026-01-18" || got.DeadlineTo != "2026-01-21" ||
+		got.CreatedFrom != "2025-12-01" || got.CreatedTo != "2025-12-31" || got.DeadlineFrom != "" {
+		t.Fatalf("换算结果不符合预期: %+v", got)
+	}
+
+	if _, err := resolveFilterDates(TaskFilter{DeadlineTo: "someday"}, now); err == nil {
+		t.Fatal("无法识别的日期应返回错误")
+	}
+}
+
+func TestGetSavedViewsCount(t *testing.T) {
+	a := openTestDB(t)
+	today := time.Now().Format("2006-01-02")
+	createTestTask(t, a, TaskInput{Name: "今天的任务", Hours: 1, Date: &today})
+	createTestTask(t, a, TaskInput{Name: "今天的高优先级任务", Hours: 1, Date: &today, Priority: "high"})
+	createTestTask(t, a, TaskInput{Name: "待办", Hours: 1})
+
+	if _, err := a.CreateSavedView(SavedViewInput{Name: "今天", Filter: TaskFilter{DateFrom: "today", DateTo: "today"}}); err != nil {
+		t.Fatalf("保存视图失败: %v", err)
+	}
+	if _, err := a.CreateSavedView(SavedViewInput{Name: "今天重要", Filter: TaskFilter{DateFrom: "today", DateTo: "today", Priorities: []string{"high"}}}); err != nil {
+		t.Fatalf("保存视图失败: %v", err)
+	}
+
+	views, err := a.GetSavedViews()
+	if err != nil || len(views) != 2 {
+		t.Fatalf("查询视图失败: %v %d", err, len(views))
+	}
+	if views[0].Count != 2 || views[1].Count != 1 {
+		t.Fatalf("视图任务数不符合预期: %d %d", views[0].Count, views[1].Count)
+	}
+	if views[0].Filter.DateFrom != "today" {
+		t.Fatalf("保存的应是动态日期: %s", views[0].Filter.DateFrom)
+	}
+}
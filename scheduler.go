@@ -0,0 +1,577 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	defaultScheduleDays  = 7    // 默认排程天数
+	maxScheduleDays      = 60   // 排程天数上限
+	scheduleSlotMinutes  = 15   // 时段对齐粒度（分钟）
+	defaultEstimateHours = 1.0  // 未填写预计工时的任务按1小时安排
+	defaultWorkStart     = 540  // 09:00
+	defaultWorkEnd       = 1080 // 18:00
+	minSplitMinutes      = 60   // 长任务拆分到多天时每段至少的分钟数
+)
+
+// scheduleSlot 一段空闲时间（当天的分钟数）
+type scheduleSlot struct {
+	start int
+	end   int
+}
+
+// scheduleDay 排程中的一天
+type scheduleDay struct {
+	date string
+	free []scheduleSlot
+}
+
+// scheduleSegment 任务占用的一段时间（days 中的下标和时段）
+type scheduleSegment struct {
+	day  int
+	slot scheduleSlot
+}
+
+// schedulePoint 排程中的时间点（用于比较前置任务的结束时间）
+type schedulePoint struct {
+	date   string
+	minute int
+}
+
+// before 判断时间点是否早于另一个时间点
+func (p schedulePoint) before(o schedulePoint) bool {
+	return p.date < o.date || (p.date == o.date && p.minute < o.minute)
+}
+
+// PreviewSchedule 预览自动排程：按截止日期、优先级和依赖关系将待办安排到未来几天的空闲时段
+func (a *App) PreviewSchedule(input AutoScheduleInput) (*ScheduleProposal, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	days, err := buildScheduleDays(input)
+	if err != nil {
+		return nil, err
+	}
+	proposal := &ScheduleProposal{Items: []ScheduleItem{}, Unscheduled: []UnscheduledTask{}, Warnings: []string{}}
+	if len(days) == 0 {
+		proposal.Warnings = append(proposal.Warnings, "排程范围内没有工作日")
+		return proposal, nil
+	}
+
+	first, last := days[0].date, days[len(days)-1].date
+	scheduled, err := a.GetTasksByDateRange(first, last)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range scheduled {
+		if t.Status == TaskStatusCompleted {
+			continue
+		}
+		reserveTask(days, t)
+	}
+
+	candidates, err := scheduleCandidates(input.TaskIDs)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := loadDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	// 前置任务的状态：已完成、已安排（结束时间）、待安排（在候选中）
+	candidateIDs := make(map[int64]bool)
+	for _, t := range candidates {
+		candidateIDs[t.ID] = true
+	}
+	finished := make(map[int64]schedulePoint)
+	unresolved := make(map[int64]string)
+	for _, t := range candidates {
+		for _, pred := range deps[t.ID] {
+			if candidateIDs[pred] {
+				continue
+			}
+			p, err := a.GetTask(pred)
+			if err != nil || p.Status == TaskStatusCompleted {
+				continue
+			}
+			if p.Date == nil || *p.Date == "" {
+				unresolved[t.ID] = fmt.Sprintf("前置任务「%s」尚未安排", p.Name)
+				continue
+			}
+			finished[pred] = taskEndPoint(*p)
+		}
+	}
+
+	// readiness 判断任务能否安排：前置任务都已确定时返回最早开始时间，前置任务无法安排时返回原因
+	placed := make(map[int64]bool)
+	failed := make(map[int64]bool)
+	readiness := func(t Task) (bool, schedulePoint, string) {
+		if reason, ok := unresolved[t.ID]; ok {
+			return true, schedulePoint{}, reason
+		}
+		earliest := schedulePoint{date: first}
+		for _, pred := range deps[t.ID] {
+			if failed[pred] {
+				return true, earliest, fmt.Sprintf("前置任务 %d 未能安排", pred)
+			}
+			if candidateIDs[pred] && !placed[pred] {
+				return false, earliest, ""
+			}
+			if end, ok := finished[pred]; ok && earliest.before(end) {
+				earliest = end
+			}
+		}
+		return true, earliest, ""
+	}
+
+	// 按排序依次安排，每次取第一个前置任务都已确定的任务，保证优先的任务先占用时段
+	remaining := candidates
+	for len(remaining) > 0 {
+		index := -1
+		var earliest schedulePoint
+		var reason string
+		for i, t := range remaining {
+			var ready bool
+			if ready, earliest, reason = readiness(t); ready {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			for _, t := range remaining {
+				proposal.Unscheduled = append(proposal.Unscheduled, UnscheduledTask{TaskID: t.ID, TaskName: t.Name, Reason: "存在循环依赖"})
+			}
+			break
+		}
+
+		t := remaining[index]
+		remaining = append(remaining[:index:index], remaining[index+1:]...)
+		if reason != "" {
+			failed[t.ID] = true
+			proposal.Unscheduled = append(proposal.Unscheduled, UnscheduledTask{TaskID: t.ID, TaskName: t.Name, Reason: reason})
+			continue
+		}
+
+		items, ok := placeTask(days, t, earliest)
+		if !ok {
+			failed[t.ID] = true
+			proposal.Unscheduled = append(proposal.Unscheduled, UnscheduledTask{
+				TaskID:   t.ID,
+				TaskName: t.Name,
+				Reason:   fmt.Sprintf("%s 至 %s 没有足够的空闲时间（需要 %.1f 小时）", first, last, estimateHours(t)),
+			})
+			continue
+		}
+		item := items[len(items)-1]
+		placed[t.ID] = true
+		finished[t.ID] = schedulePoint{date: item.Date, minute: parseClock(item.EndTime)}
+		proposal.Items = append(proposal.Items, items...)
+		if item.Late {
+			proposal.Warnings = append(proposal.Warnings,
+				fmt.Sprintf("「%s」安排在 %s，晚于截止日期 %s", t.Name, item.Date, *t.Deadline))
+		}
+	}
+
+	sort.SliceStable(proposal.Items, func(i, j int) bool {
+		if proposal.Items[i].Date != proposal.Items[j].Date {
+			return proposal.Items[i].Date < proposal.Items[j].Date
+		}
+		return proposal.Items[i].StartTime < proposal.Items[j].StartTime
+	})
+	return proposal, nil
+}
+
+// ApplySchedule 应用排程结果（只更新仍为待办的任务），返回实际安排的任务数。
+// 拆分到多段的任务为每段创建一个子任务，子任务全部完成时自动完成原任务
+func (a *App) ApplySchedule(items []ScheduleItem) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("数据库未初始化")
+	}
+
+	var order []int64
+	parts := make(map[int64][]ScheduleItem)
+	befores := make(map[int64]map[string]interface{}, len(items))
+	for _, item := range items {
+		if _, err := time.Parse("2006-01-02", item.Date); err != nil {
+			return 0, fmt.Errorf("日期格式错误: %s", item.Date)
+		}
+		if _, ok := parts[item.TaskID]; !ok {
+			order = append(order, item.TaskID)
+			befores[item.TaskID] = snapshotTask(item.TaskID)
+		}
+		parts[item.TaskID] = append(parts[item.TaskID], item)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	var applied, created []int64
+	for _, taskID := range order {
+		taskParts := parts[taskID]
+		if len(taskParts) == 1 && taskParts[0].Parts == 0 {
+			item := taskParts[0]
+			result, err := tx.Exec(`
+				UPDATE tasks SET date = ?, start_time = ?, end_time = ?, status = ?
+				WHERE id = ? AND date IS NULL AND status != ?
+			`, item.Date, item.StartTime, item.EndTime, TaskStatusScheduled, item.TaskID, TaskStatusCompleted)
+			if err != nil {
+				log.Printf("应用排程失败: %v", err)
+				return 0, fmt.Errorf("应用排程失败: %v", err)
+			}
+			if n, _ := result.RowsAffected(); n > 0 {
+				applied = append(applied, item.TaskID)
+			} else {
+				log.Printf("任务 %d 已不是待办，跳过排程", item.TaskID)
+			}
+			continue
+		}
+
+		// 原任务仍为待办且没有子任务时才拆分；手动完成的原任务改为子任务全部完成时自动完成
+		result, err := tx.Exec(`
+			UPDATE tasks SET completion_rule = CASE WHEN COALESCE(completion_rule, 'manual') = ? THEN ? ELSE completion_rule END
+			WHERE id = ? AND date IS NULL AND status != ?
+			  AND NOT EXISTS (SELECT 1 FROM tasks c WHERE c.parent_id = tasks.id)
+		`, CompletionRuleManual, CompletionRuleAuto, taskID, TaskStatusCompleted)
+		if err != nil {
+			log.Printf("应用排程失败: %v", err)
+			return 0, fmt.Errorf("应用排程失败: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			log.Printf("任务 %d 已不是待办，跳过排程", taskID)
+			continue
+		}
+
+		sort.SliceStable(taskParts, func(i, j int) bool { return taskParts[i].Part < taskParts[j].Part })
+		for k, item := range taskParts {
+			result, err := tx.Exec(`
+				INSERT INTO tasks (project_id, name, description, date, start_time, end_time, hours, deadline, priority, urgency, status, parent_id, completion_rule)
+				SELECT project_id, name || ?, '', ?, ?, ?, ?, deadline, priority, urgency, ?, id, ?
+				FROM tasks WHERE id = ?
+			`, fmt.Sprintf("（%d/%d）", k+1, len(taskParts)), item.Date, item.StartTime, item.EndTime, item.Hours,
+				TaskStatusScheduled, CompletionRuleManual, taskID)
+			if err != nil {
+				log.Printf("创建拆分子任务失败: %v", err)
+				return 0, fmt.Errorf("应用排程失败: %v", err)
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return 0, fmt.Errorf("获取子任务ID失败: %v", err)
+			}
+			created = append(created, id)
+		}
+		applied = append(applied, taskID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交排程失败: %v", err)
+	}
+	for _, id := range applied {
+		a.auditTask(id, AuditActionUpdate, befores[id])
+	}
+	for _, id := range created {
+		a.auditTask(id, AuditActionCreate, nil)
+	}
+
+	log.Printf("应用自动排程: 安排了 %d 个任务（拆分出 %d 个子任务）", len(applied), len(created))
+	return len(applied), nil
+}
+
+// buildScheduleDays 生成排程范围内每个工作日的空闲时段（今天从当前时间开始）
+func buildScheduleDays(input AutoScheduleInput) ([]scheduleDay, error) {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+
+	startDate := input.StartDate
+	if startDate == "" || startDate < today {
+		startDate = today
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式错误: %s", input.StartDate)
+	}
+
+	numDays := input.Days
+	if numDays <= 0 {
+		numDays = defaultScheduleDays
+	}
+	if numDays > maxScheduleDays {
+		numDays = maxScheduleDays
+	}
+
+	cal := defaultWorkCalendar()
+	if _, err := loadSetting(settingWorkCalendar, &cal); err != nil {
+		return nil, err
+	}
+	workStart := parseClock(cal.WorkStart)
+	if workStart < 0 {
+		workStart = defaultWorkStart
+	}
+	if input.WorkStart != "" {
+		if workStart = parseClock(input.WorkStart); workStart < 0 {
+			return nil, fmt.Errorf("工作开始时间格式错误: %s", input.WorkStart)
+		}
+	}
+	workEnd := -1
+	if input.WorkEnd != "" {
+		if workEnd = parseClock(input.WorkEnd); workEnd < 0 {
+			return nil, fmt.Errorf("工作结束时间格式错误: %s", input.WorkEnd)
+		}
+		if workEnd <= workStart {
+			return nil, fmt.Errorf("工作结束时间必须晚于开始时间")
+		}
+	}
+
+	end := start.AddDate(0, 0, numDays-1)
+	capacities, err := loadCapacities(startDate, end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	var days []scheduleDay
+	for i := 0; i < numDays; i++ {
+		d := start.AddDate(0, 0, i)
+		c := capacities[d.Format("2006-01-02")]
+
+		// 按工作日历当天的可用工时确定结束时间；非工作日只有选择了周末安排才使用完整时段
+		dayEnd := workStart + int(c.Available*60)
+		if c.Available == 0 {
+			if c.Kind != CalendarDayWeekend || !input.IncludeWeekends {
+				continue
+			}
+			dayEnd = defaultWorkEnd
+		}
+		if workEnd >= 0 && (dayEnd > workEnd || c.Available == 0) {
+			dayEnd = workEnd
+		}
+		if dayEnd > 24*60 {
+			dayEnd = 24 * 60
+		}
+		if dayEnd <= workStart {
+			continue
+		}
+
+		day := scheduleDay{date: d.Format("2006-01-02"), free: []scheduleSlot{{workStart, dayEnd}}}
+		if day.date == today {
+			nowMinute := roundUpMinutes(now.Hour()*60 + now.Minute())
+			day.free = subtractSlot(day.free, scheduleSlot{0, nowMinute})
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// scheduleCandidates 需要安排的待办（无日期、未完成、不是有子任务的父任务），按截止日期和优先级排序
+func scheduleCandidates(taskIDs []int64) ([]Task, error) {
+	tasks, err := queryTasks(taskSelectSQL + `
+		WHERE t.date IS NULL AND t.status != 'completed'
+		  AND NOT EXISTS (SELECT 1 FROM tasks c WHERE c.parent_id = t.id)
+		ORDER BY
+			t.deadline ASC NULLS LAST,
+			CASE t.priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+			CASE t.urgency WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+			t.created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	if len(taskIDs) == 0 {
+		return tasks, nil
+	}
+
+	wanted := make(map[int64]bool)
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+	var filtered []Task
+	for _, t := range tasks {
+		if wanted[t.ID] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// reserveTask 已安排的任务占用时段：有起止时间的占用该时段，否则从当天最早的空闲时间占用预计工时
+func reserveTask(days []scheduleDay, t Task) {
+	for i := range days {
+		if t.Date == nil || days[i].date != *t.Date {
+			continue
+		}
+		if t.StartTime != nil && t.EndTime != nil {
+			start, end := parseClock(*t.StartTime), parseClock(*t.EndTime)
+			if start >= 0 && end > start {
+				days[i].free = subtractSlot(days[i].free, scheduleSlot{start, end})
+				return
+			}
+		}
+		need := int(t.Hours * 60)
+		for need > 0 && len(days[i].free) > 0 {
+			slot := days[i].free[0]
+			take := slot.end - slot.start
+			if take > need {
+				take = need
+			}
+			days[i].free = subtractSlot(days[i].free, scheduleSlot{slot.start, slot.start + take})
+			need -= take
+		}
+		return
+	}
+}
+
+// placeTask 将任务安排到 earliest 之后第一个足够长的空闲时段；没有足够长的时段时按顺序拆分到多段（可跨天）
+func placeTask(days []scheduleDay, t Task, earliest schedulePoint) ([]ScheduleItem, bool) {
+	hours := estimateHours(t)
+	need := roundUpMinutes(int(hours * 60))
+
+	segments := findContiguousSlot(days, need, earliest)
+	if segments == nil {
+		segments = findSplitSlots(days, need, earliest)
+	}
+	if segments == nil {
+		return nil, false
+	}
+
+	items := make([]ScheduleItem, len(segments))
+	var assigned float64
+	for k, seg := range segments {
+		days[seg.day].free = subtractSlot(days[seg.day].free, seg.slot)
+		item := ScheduleItem{
+			TaskID:    t.ID,
+			TaskName:  t.Name,
+			Date:      days[seg.day].date,
+			StartTime: formatClock(seg.slot.start),
+			EndTime:   formatClock(seg.slot.end),
+			Hours:     hours,
+			Deadline:  t.Deadline,
+		}
+		if len(segments) > 1 {
+			// 各段工时按占用时长计算，最后一段补齐余数，合计等于预计工时
+			item.Part, item.Parts = k+1, len(segments)
+			item.Hours = float64(seg.slot.end-seg.slot.start) / 60
+			if k == len(segments)-1 || assigned+item.Hours > hours {
+				item.Hours = roundHours(hours - assigned)
+			}
+			assigned += item.Hours
+		}
+		item.Late = t.Deadline != nil && *t.Deadline != "" && item.Date > *t.Deadline
+		items[k] = item
+	}
+	return items, true
+}
+
+// findContiguousSlot earliest 之后第一个不短于 need 分钟的空闲时段
+func findContiguousSlot(days []scheduleDay, need int, earliest schedulePoint) []scheduleSegment {
+	for i := range days {
+		for _, slot := range availableSlots(days[i], earliest) {
+			if slot.end-slot.start >= need {
+				return []scheduleSegment{{day: i, slot: scheduleSlot{slot.start, slot.start + need}}}
+			}
+		}
+	}
+	return nil
+}
+
+// findSplitSlots 从 earliest 开始依次占用空闲时段直到凑够 need 分钟（跳过过短的时段），不够时返回 nil
+func findSplitSlots(days []scheduleDay, need int, earliest schedulePoint) []scheduleSegment {
+	var segments []scheduleSegment
+	remaining := need
+	for i := range days {
+		for _, slot := range availableSlots(days[i], earliest) {
+			length := slot.end - slot.start
+			if length < minSplitMinutes && length < remaining {
+				continue
+			}
+			if length > remaining {
+				length = remaining
+			}
+			segments = append(segments, scheduleSegment{day: i, slot: scheduleSlot{slot.start, slot.start + length}})
+			if remaining -= length; remaining == 0 {
+				return segments
+			}
+		}
+	}
+	return nil
+}
+
+// availableSlots 某天在 earliest 之后的空闲时段
+func availableSlots(day scheduleDay, earliest schedulePoint) []scheduleSlot {
+	if day.date < earliest.date {
+		return nil
+	}
+	var slots []scheduleSlot
+	for _, slot := range day.free {
+		if day.date == earliest.date && slot.start < earliest.minute {
+			slot.start = roundUpMinutes(earliest.minute)
+		}
+		if slot.end > slot.start {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// taskEndPoint 已安排任务的结束时间点（没有结束时间按当天结束计算）
+func taskEndPoint(t Task) schedulePoint {
+	if t.EndTime != nil {
+		if end := parseClock(*t.EndTime); end >= 0 {
+			return schedulePoint{date: *t.Date, minute: end}
+		}
+	}
+	return schedulePoint{date: *t.Date, minute: 24 * 60}
+}
+
+// subtractSlot 从空闲时段中去掉占用的时段
+func subtractSlot(free []scheduleSlot, busy scheduleSlot) []scheduleSlot {
+	var result []scheduleSlot
+	for _, slot := range free {
+		if busy.end <= slot.start || busy.start >= slot.end {
+			result = append(result, slot)
+			continue
+		}
+		if busy.start > slot.start {
+			result = append(result, scheduleSlot{slot.start, busy.start})
+		}
+		if busy.end < slot.end {
+			result = append(result, scheduleSlot{busy.end, slot.end})
+		}
+	}
+	return result
+}
+
+// estimateHours 任务的预计工时（未填写按默认值）
+func estimateHours(t Task) float64 {
+	if t.Hours > 0 {
+		return t.Hours
+	}
+	return defaultEstimateHours
+}
+
+// parseClock 解析 HH:MM 为当天的分钟数，格式错误返回 -1
+func parseClock(s string) int {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return -1
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// formatClock 分钟数格式化为 HH:MM
+func formatClock(minutes int) string {
+	if minutes >= 24*60 {
+		minutes = 24*60 - 1
+	}
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// roundUpMinutes 分钟数向上对齐到时段粒度
+func roundUpMinutes(minutes int) int {
+	return (minutes + scheduleSlotMinutes - 1) / scheduleSlotMinutes * scheduleSlotMinutes
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleSplitsLongTaskAcrossDays(t *testing.T) {
+	a := openTestDB(t)
+	long := createTestTask(t, a, TaskInput{Name: "迁移数据", Hours: 20})
+	next := createTestTask(t, a, TaskInput{Name: "验收", Hours: 1})
+	if err := a.AddTaskDependency(next.ID, long.ID); err != nil {
+		t.Fatalf("添加依赖失败: %v", err)
+	}
+
+	input := AutoScheduleInput{
+		StartDate:       time.Now().AddDate(0, 0, 1).Format("2006-01-02"),
+		Days:            7,
+		WorkStart:       "09:00",
+		WorkEnd:         "17:00",
+		IncludeWeekends: true,
+	}
+	proposal, err := a.PreviewSchedule(input)
+	if err != nil {
+		t.Fatalf("预览排程失败: %v", err)
+	}
+	if len(proposal.Unscheduled) != 0 {
+		t.Fatalf("不应有未安排的任务: %+v", proposal.Unscheduled)
+	}
+
+	var parts []ScheduleItem
+	var follow *ScheduleItem
+	for i, item := range proposal.Items {
+		switch item.TaskID {
+		case long.ID:
+			parts = append(parts, item)
+		case next.ID:
+			follow = &proposal.Items[i]
+		}
+	}
+	if len(parts) != 3 {
+		t.Fatalf("20小时的任务应拆成3段（每天8小时），实际: %+v", parts)
+	}
+	var total float64
+	for i, p := range parts {
+		if p.Part != i+1 || p.Parts != 3 {
+			t.Fatalf("分段编号不正确: %+v", p)
+		}
+		total += p.Hours
+	}
+	if total != 20 {
+		t.Fatalf("各段工时合计应为20，实际 %v", total)
+	}
+	last := parts[len(parts)-1]
+	if follow == nil || follow.Date != last.Date || follow.StartTime < last.EndTime {
+		t.Fatalf("后续任务应排在最后一段之后: last=%+v follow=%+v", last, follow)
+	}
+
+	count, err := a.ApplySchedule(proposal.Items)
+	if err != nil || count != 2 {
+		t.Fatalf("应用排程失败: count=%d err=%v", count, err)
+	}
+	parent, _ := a.GetTask(long.ID)
+	if parent.ChildCount != 3 || parent.SubtaskHours != 20 || parent.CompletionRule != CompletionRuleAuto {
+		t.Fatalf("原任务应拆为3个子任务并自动完成: %+v", parent)
+	}
+
+	// 再次应用不会重复拆分
+	if count, _ := a.ApplySchedule(proposal.Items); count != 0 {
+		t.Fatalf("已安排的任务不应再次应用，实际 %d", count)
+	}
+	if parent, _ = a.GetTask(long.ID); parent.ChildCount != 3 {
+		t.Fatalf("不应重复创建子任务: %d", parent.ChildCount)
+	}
+}
+
+func TestScheduleKeepsShortTaskContiguous(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "写周报", Hours: 2})
+
+	proposal, err := a.PreviewSchedule(AutoScheduleInput{
+		StartDate:       time.Now().AddDate(0, 0, 1).Format("2006-01-02"),
+		WorkStart:       "09:00",
+		WorkEnd:         "17:00",
+		IncludeWeekends: true,
+	})
+	if err != nil {
+		t.Fatalf("预览排程失败: %v", err)
+	}
+	if len(proposal.Items) != 1 {
+		t.Fatalf("短任务不应拆分: %+v", proposal.Items)
+	}
+	item := proposal.Items[0]
+	if item.TaskID != task.ID || item.Parts != 0 || item.StartTime != "09:00" || item.EndTime != "11:00" {
+		t.Fatalf("排程结果不符合预期: %+v", item)
+	}
+}
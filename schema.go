@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// JSONSchema 工具参数的 JSON Schema（支持常用子集：type/properties/required/items/enum/范围/长度）
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Nullable             bool                   `json:"-"` // type 为 ["xxx", "null"] 时允许 null
+}
+
+// UnmarshalJSON 兼容 type 为数组的写法（如 ["string", "null"]，常见于 MCP 工具）
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	type plain JSONSchema
+	var aux struct {
+		*plain
+		Type json.RawMessage `json:"type,omitempty"`
+	}
+	aux.plain = (*plain)(s)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	s.Type = ""
+	if len(aux.Type) == 0 {
+		return nil
+	}
+	if aux.Type[0] == '"' {
+		return json.Unmarshal(aux.Type, &s.Type)
+	}
+
+	var types []string
+	if err := json.Unmarshal(aux.Type, &types); err != nil {
+		return fmt.Errorf("type 必须是字符串或字符串数组")
+	}
+	for _, t := range types {
+		if t == "null" {
+			s.Nullable = true
+		} else if s.Type == "" {
+			s.Type = t
+		} else {
+			// 多种非 null 类型时不限制类型
+			s.Type = ""
+			break
+		}
+	}
+	return nil
+}
+
+// ParseJSONSchema 解析 JSON Schema 字符串
+func ParseJSONSchema(raw string) (*JSONSchema, error) {
+	if strings.TrimSpace(raw) == "" {
+		return &JSONSchema{Type: "object"}, nil
+	}
+
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, fmt.Errorf("解析 JSON Schema 失败: %v", err)
+	}
+	if err := schema.check(""); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// check 检查 Schema 本身是否合法
+func (s *JSONSchema) check(path string) error {
+	switch s.Type {
+	case "", "object", "array", "string", "number", "integer", "boolean", "null":
+	default:
+		return fmt.Errorf("JSON Schema %s 不支持的类型: %s", displayPath(path), s.Type)
+	}
+	for _, name := range s.Required {
+		if s.Properties != nil {
+			if _, ok := s.Properties[name]; !ok {
+				return fmt.Errorf("JSON Schema %s 的必填字段 %s 未在 properties 中定义", displayPath(path), name)
+			}
+		}
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("JSON Schema %s 的字段 %s 定义为空", displayPath(path), name)
+		}
+		if err := prop.check(joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.check(path + "[]")
+	}
+	return nil
+}
+
+// ValidateJSON 校验 JSON 数据，返回全部错误（为空表示通过）
+func (s *JSONSchema) ValidateJSON(data []byte) []string {
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{fmt.Sprintf("不是合法的 JSON: %v", err)}
+	}
+	return s.Validate(value)
+}
+
+// Validate 校验已解码的值
+func (s *JSONSchema) Validate(value interface{}) []string {
+	var errs []string
+	s.validate("", value, &errs)
+	return errs
+}
+
+func (s *JSONSchema) validate(path string, value interface{}, errs *[]string) {
+	if value == nil && s.Nullable {
+		return
+	}
+	if s.Type != "" && !matchesSchemaType(s.Type, value) {
+		*errs = append(*errs, fmt.Sprintf("%s 类型错误: 期望 %s, 实际 %s", displayPath(path), s.Type, jsonTypeOf(value)))
+		return
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		var options []string
+		for _, e := range s.Enum {
+			b, _ := json.Marshal(e)
+			options = append(options, string(b))
+		}
+		*errs = append(*errs, fmt.Sprintf("%s 取值无效: 必须是 %s 之一", displayPath(path), strings.Join(options, ", ")))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if field, ok := v[name]; !ok || field == nil {
+				*errs = append(*errs, fmt.Sprintf("%s 缺少必填字段", displayPath(joinPath(path, name))))
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, fmt.Sprintf("%s 是未定义的字段", displayPath(joinPath(path, name))))
+				}
+				continue
+			}
+			if v[name] == nil {
+				continue
+			}
+			prop.validate(joinPath(path, name), v[name], errs)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			*errs = append(*errs, fmt.Sprintf("%s 长度不能少于 %d", displayPath(path), *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			*errs = append(*errs, fmt.Sprintf("%s 长度不能超过 %d", displayPath(path), *s.MaxLength))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			*errs = append(*errs, fmt.Sprintf("%s 不能小于 %v", displayPath(path), *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			*errs = append(*errs, fmt.Sprintf("%s 不能大于 %v", displayPath(path), *s.Maximum))
+		}
+	}
+}
+
+// matchesSchemaType 判断值是否符合 Schema 类型
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// jsonTypeOf 获取值的 JSON 类型名称
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// enumContains 判断值是否在枚举中
+func enumContains(enum []interface{}, value interface{}) bool {
+	vb, _ := json.Marshal(value)
+	for _, e := range enum {
+		eb, _ := json.Marshal(e)
+		if string(eb) == string(vb) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "参数"
+	}
+	return "字段 " + path
+}
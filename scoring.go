@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// settingScoring 评分配置的设置键
+const settingScoring = "scoring"
+
+// defaultScoringConfig 默认评分配置
+func defaultScoringConfig() ScoringConfig {
+	return ScoringConfig{
+		ImportanceWeight:  0.35,
+		UrgencyWeight:     0.25,
+		DeadlineWeight:    0.25,
+		SizeWeight:        0.05,
+		AgeWeight:         0.10,
+		DeadlineHorizon:   14,
+		HighUrgencyDays:   2,
+		MediumUrgencyDays: 7,
+		MatrixLevel:       PriorityHigh,
+	}
+}
+
+// GetScoringConfig 获取评分配置（未保存时返回默认配置）
+func (a *App) GetScoringConfig() (*ScoringConfig, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	cfg := defaultScoringConfig()
+	if _, err := loadSetting(settingScoring, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveScoringConfig 保存评分配置
+func (a *App) SaveScoringConfig(cfg ScoringConfig) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	weights := []float64{cfg.ImportanceWeight, cfg.UrgencyWeight, cfg.DeadlineWeight, cfg.SizeWeight, cfg.AgeWeight}
+	var total float64
+	for _, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("权重不能为负数")
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("权重不能全部为0")
+	}
+	if cfg.DeadlineHorizon <= 0 {
+		return fmt.Errorf("截止日期计分天数必须大于0")
+	}
+	if cfg.HighUrgencyDays < 0 || cfg.MediumUrgencyDays < cfg.HighUrgencyDays {
+		return fmt.Errorf("紧急程度升级天数设置错误：中级天数不能小于高级天数")
+	}
+	switch cfg.MatrixLevel {
+	case "":
+		cfg.MatrixLevel = PriorityHigh
+	case PriorityHigh, PriorityMedium:
+	default:
+		return fmt.Errorf("四象限级别只能是 high 或 medium")
+	}
+
+	if err := saveSetting(settingScoring, cfg); err != nil {
+		return err
+	}
+
+	log.Printf("保存评分配置成功")
+	return nil
+}
+
+// GetPrioritizedTasks 按综合得分从高到低获取未完成的任务（limit 为 0 表示全部）
+func (a *App) GetPrioritizedTasks(limit int) ([]ScoredTask, error) {
+	scored, err := a.scoreOpenTasks()
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored, nil
+}
+
+// GetEisenhowerMatrix 获取未完成任务的四象限分组
+func (a *App) GetEisenhowerMatrix() (*EisenhowerMatrix, error) {
+	scored, err := a.scoreOpenTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := &EisenhowerMatrix{
+		DoFirst:   []ScoredTask{},
+		Schedule:  []ScoredTask{},
+		Delegate:  []ScoredTask{},
+		Eliminate: []ScoredTask{},
+	}
+	for _, s := range scored {
+		switch s.Quadrant {
+		case QuadrantDoFirst:
+			matrix.DoFirst = append(matrix.DoFirst, s)
+		case QuadrantSchedule:
+			matrix.Schedule = append(matrix.Schedule, s)
+		case QuadrantDelegate:
+			matrix.Delegate = append(matrix.Delegate, s)
+		default:
+			matrix.Eliminate = append(matrix.Eliminate, s)
+		}
+	}
+	return matrix, nil
+}
+
+// scoreOpenTasks 为全部未完成任务评分并排序
+func (a *App) scoreOpenTasks() ([]ScoredTask, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	cfg, err := a.GetScoringConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := queryTasks(taskSelectSQL + `
+		WHERE t.status != 'completed'
+		ORDER BY t.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scored := make([]ScoredTask, 0, len(tasks))
+	for _, t := range tasks {
+		scored = append(scored, scoreTask(t, *cfg, now))
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	return scored, nil
+}
+
+// scoreTask 计算任务的综合得分和所在象限
+func scoreTask(t Task, cfg ScoringConfig, now time.Time) ScoredTask {
+	urgency := effectiveUrgency(t, cfg, now)
+	b := ScoreBreakdown{
+		Importance: levelScore(t.Priority),
+		Urgency:    levelScore(urgency),
+	}
+
+	if t.Deadline != nil && *t.Deadline != "" {
+		if daysLeft, ok := daysUntil(*t.Deadline, now); ok {
+			b.Deadline = math.Max(0, math.Min(1, 1-float64(daysLeft)/float64(cfg.DeadlineHorizon)))
+		}
+	}
+	hours := t.Hours + t.SubtaskHours
+	if hours > 0 {
+		b.Size = 1 / (1 + hours/4)
+	} else {
+		b.Size = 0.5
+	}
+	b.Age = math.Min(1, now.Sub(t.CreatedAt).Hours()/24/30)
+	if b.Age < 0 {
+		b.Age = 0
+	}
+
+	totalWeight := cfg.ImportanceWeight + cfg.UrgencyWeight + cfg.DeadlineWeight + cfg.SizeWeight + cfg.AgeWeight
+	var score float64
+	if totalWeight > 0 {
+		score = 100 * (cfg.ImportanceWeight*b.Importance + cfg.UrgencyWeight*b.Urgency +
+			cfg.DeadlineWeight*b.Deadline + cfg.SizeWeight*b.Size + cfg.AgeWeight*b.Age) / totalWeight
+	}
+
+	for _, v := range []*float64{&b.Importance, &b.Urgency, &b.Deadline, &b.Size, &b.Age} {
+		*v = math.Round(*v*100) / 100
+	}
+
+	return ScoredTask{
+		Task:             t,
+		Score:            math.Round(score*10) / 10,
+		Breakdown:        b,
+		Quadrant:         taskQuadrant(t.Priority, urgency, cfg),
+		EffectiveUrgency: urgency,
+	}
+}
+
+// taskQuadrant 按重要程度和紧急程度划分四象限
+func taskQuadrant(priority, urgency string, cfg ScoringConfig) string {
+	threshold := levelScore(cfg.MatrixLevel)
+	important := levelScore(priority) >= threshold
+	urgent := levelScore(urgency) >= threshold
+
+	switch {
+	case important && urgent:
+		return QuadrantDoFirst
+	case important:
+		return QuadrantSchedule
+	case urgent:
+		return QuadrantDelegate
+	}
+	return QuadrantEliminate
+}
+
+// levelScore 级别转换为 0-1 的得分
+func levelScore(level string) float64 {
+	switch level {
+	case PriorityHigh:
+		return 1
+	case PriorityLow:
+		return 0
+	}
+	return 0.5
+}
+
+// daysUntil 距截止日期的天数（已逾期为负数）
+func daysUntil(date string, now time.Time) (int, bool) {
+	deadline, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, false
+	}
+	today, _ := time.Parse("2006-01-02", now.Format("2006-01-02"))
+	return daysBetween(today, deadline), true
+}
+
+// effectiveUrgency 评分时使用的紧急程度：截止日期临近的任务按配置提升（只升不降，不修改任务本身）
+func effectiveUrgency(t Task, cfg ScoringConfig, now time.Time) string {
+	urgency := t.Urgency
+	if t.Deadline == nil || *t.Deadline == "" {
+		return urgency
+	}
+	daysLeft, ok := daysUntil(*t.Deadline, now)
+	if !ok {
+		return urgency
+	}
+	if daysLeft <= cfg.HighUrgencyDays {
+		return UrgencyHigh
+	}
+	if daysLeft <= cfg.MediumUrgencyDays && urgency == UrgencyLow {
+		return UrgencyMedium
+	}
+	return urgency
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveUrgency(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	cfg := ScoringConfig{HighUrgencyDays: 2, MediumUrgencyDays: 7}
+	deadline := func(d string) *string { return &d }
+
+	cases := []struct {
+		name     string
+		urgency  string
+		deadline *string
+		want     string
+	}{
+		{"无截止日期", UrgencyLow, nil, UrgencyLow},
+		{"已逾期", UrgencyLow, deadline("2026-03-01"), UrgencyHigh},
+		{"两天内", UrgencyMedium, deadline("2026-03-12"), UrgencyHigh},
+		{"一周内升为中", UrgencyLow, deadline("2026-03-15"), UrgencyMedium},
+		{"一周内不降级", UrgencyHigh, deadline("2026-03-15"), UrgencyHigh},
+		{"较远", UrgencyLow, deadline("2026-04-30"), UrgencyLow},
+	}
+	for _, c := range cases {
+		got := effectiveUrgency(Task{Urgency: c.urgency, Deadline: c.deadline}, cfg, now)
+		if got != c.want {
+			t.Errorf("%s: 得到 %s，期望 %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestScoringLeavesStoredUrgency(t *testing.T) {
+	a := openTestDB(t)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	task := createTestTask(t, a, TaskInput{Name: "提交报销", Hours: 1, Urgency: UrgencyLow, Deadline: &tomorrow})
+
+	scored, err := a.GetPrioritizedTasks(0)
+	if err != nil {
+		t.Fatalf("评分失败: %v", err)
+	}
+	if len(scored) != 1 || scored[0].EffectiveUrgency != UrgencyHigh || scored[0].Breakdown.Urgency != 1 {
+		t.Fatalf("临近截止日期应按高紧急程度评分: %+v", scored)
+	}
+
+	stored, _ := a.GetTask(task.ID)
+	if stored.Urgency != UrgencyLow {
+		t.Fatalf("评分不应修改任务的紧急程度，实际 %s", stored.Urgency)
+	}
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 50  // 默认返回条数
+	maxSearchLimit     = 200 // 返回条数上限
+	searchSnippetRunes = 60  // 匹配片段长度（字符）
+	trigramMinRunes    = 3   // trigram 索引能匹配的最短词长，更短的词退回 LIKE 匹配
+)
+
+// searchQuery 解析后的搜索条件
+type searchQuery struct {
+	terms   []string // 全部搜索词（用于高亮）
+	match   string   // FTS5 MATCH 表达式（不少于3个字的词）
+	likes   []string // 少于3个字的词（中文常见的双字词），用 LIKE 匹配
+	hasRank bool     // 是否有 bm25 排名
+}
+
+// Search 全文搜索任务、项目和会话消息
+func (a *App) Search(input SearchInput) ([]SearchResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	q := parseSearchQuery(input.Query)
+	if len(q.terms) == 0 {
+		return nil, fmt.Errorf("搜索词不能为空")
+	}
+	for _, date := range []string{input.StartDate, input.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("日期格式错误: %s", date)
+		}
+	}
+
+	types := map[string]bool{}
+	for _, t := range input.Types {
+		switch t {
+		case SearchTypeTask, SearchTypeProject, SearchTypeMessage:
+			types[t] = true
+		default:
+			return nil, fmt.Errorf("不支持的搜索范围: %s", t)
+		}
+	}
+	if len(types) == 0 {
+		types = map[string]bool{SearchTypeTask: true, SearchTypeProject: true, SearchTypeMessage: true}
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results := []SearchResult{}
+	if types[SearchTypeTask] {
+		found, err := searchTasks(q, input, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	if types[SearchTypeProject] && input.Status == "" {
+		found, err := searchProjects(q, input, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	if types[SearchTypeMessage] {
+		found, err := searchMessages(q, input, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	// 按完整精度排序，返回时得分保留4位小数
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		results[i].Score = math.Round(results[i].Score*10000) / 10000
+	}
+
+	log.Printf("搜索「%s」: %d 条结果", input.Query, len(results))
+	return results, nil
+}
+
+// searchTasks 搜索任务名称和描述
+func searchTasks(q searchQuery, input SearchInput, limit int) ([]SearchResult, error) {
+	where, args := q.conditions("tasks_fts", "t.name", "t.description")
+	if input.ProjectID != nil {
+		where = append(where, "t.project_id = ?")
+		args = append(args, *input.ProjectID)
+	}
+	if input.Status != "" {
+		where = append(where, "t.status = ?")
+		args = append(args, input.Status)
+	}
+	if input.StartDate != "" {
+		where = append(where, "t.date >= ?")
+		args = append(args, input.StartDate)
+	}
+	if input.EndDate != "" {
+		where = append(where, "t.date <= ?")
+		args = append(args, input.EndDate)
+	}
+	if !input.IncludeArchived {
+		where = append(where, "COALESCE(p.archived, 0) = 0")
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT t.id, t.name, COALESCE(t.description, ''), t.project_id, COALESCE(p.name, ''),
+			t.status, t.date, COALESCE(p.archived, 0), %s
+		FROM tasks t
+		LEFT JOIN projects p ON p.id = t.project_id
+		%s
+		WHERE %s
+		ORDER BY 9 DESC, t.id DESC
+		LIMIT ?
+	`, q.rankExpr("tasks_fts"), q.join("tasks_fts", "t.id"), strings.Join(where, " AND ")), append(args, limit)...)
+	if err != nil {
+		log.Printf("搜索任务失败: %v", err)
+		return nil, fmt.Errorf("搜索任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var r SearchResult
+		var description string
+		var taskID int64
+		if err := rows.Scan(&r.ID, &r.Title, &description, &r.ProjectID, &r.ProjectName,
+			&r.Status, &r.Date, &r.Archived, &r.Score); err != nil {
+			return nil, fmt.Errorf("扫描搜索结果失败: %v", err)
+		}
+		taskID = r.ID
+		r.Type = SearchTypeTask
+		r.TaskID = &taskID
+		r.Snippet, r.Score = q.result(r.Title, description, r.Score)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// searchProjects 搜索项目名称和描述
+func searchProjects(q searchQuery, input SearchInput, limit int) ([]SearchResult, error) {
+	where, args := q.conditions("projects_fts", "p.name", "p.description")
+	if input.ProjectID != nil {
+		where = append(where, "p.id = ?")
+		args = append(args, *input.ProjectID)
+	}
+	if input.StartDate != "" {
+		where = append(where, "date(p.created_at) >= ?")
+		args = append(args, input.StartDate)
+	}
+	if input.EndDate != "" {
+		where = append(where, "date(p.created_at) <= ?")
+		args = append(args, input.EndDate)
+	}
+	if !input.IncludeArchived {
+		where = append(where, "COALESCE(p.archived, 0) = 0")
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT p.id, p.name, COALESCE(p.description, ''), COALESCE(p.archived, 0), %s
+		FROM projects p
+		%s
+		WHERE %s
+		ORDER BY 5 DESC, p.id DESC
+		LIMIT ?
+	`, q.rankExpr("projects_fts"), q.join("projects_fts", "p.id"), strings.Join(where, " AND ")), append(args, limit)...)
+	if err != nil {
+		log.Printf("搜索项目失败: %v", err)
+		return nil, fmt.Errorf("搜索项目失败: %v", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var r SearchResult
+		var description string
+		if err := rows.Scan(&r.ID, &r.Title, &description, &r.Archived, &r.Score); err != nil {
+			return nil, fmt.Errorf("扫描搜索结果失败: %v", err)
+		}
+		projectID := r.ID
+		r.Type = SearchTypeProject
+		r.ProjectID = &projectID
+		r.ProjectName = r.Title
+		r.Snippet, r.Score = q.result(r.Title, description, r.Score)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// searchMessages 搜索会话消息内容
+func searchMessages(q searchQuery, input SearchInput, limit int) ([]SearchResult, error) {
+	where, args := q.conditions("messages_fts", "m.content")
+	if input.ProjectID != nil {
+		where = append(where, "t.project_id = ?")
+		args = append(args, *input.ProjectID)
+	}
+	if input.Status != "" {
+		where = append(where, "t.status = ?")
+		args = append(args, input.Status)
+	}
+	if input.StartDate != "" {
+		where = append(where, "date(m.created_at) >= ?")
+		args = append(args, input.StartDate)
+	}
+	if input.EndDate != "" {
+		where = append(where, "date(m.created_at) <= ?")
+		args = append(args, input.EndDate)
+	}
+	if !input.IncludeArchived {
+		where = append(where, "COALESCE(p.archived, 0) = 0")
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT m.id, COALESCE(m.content, ''), m.conversation_id, c.task_id, COALESCE(t.name, ''),
+			t.project_id, COALESCE(p.name, ''), COALESCE(t.status, ''), date(m.created_at), COALESCE(p.archived, 0), %s
+		FROM conversation_messages m
+		JOIN task_conversations c ON c.id = m.conversation_id
+		LEFT JOIN tasks t ON t.id = c.task_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		%s
+		WHERE %s
+		ORDER BY 11 DESC, m.id DESC
+		LIMIT ?
+	`, q.rankExpr("messages_fts"), q.join("messages_fts", "m.id"), strings.Join(where, " AND ")), append(args, limit)...)
+	if err != nil {
+		log.Printf("搜索会话消息失败: %v", err)
+		return nil, fmt.Errorf("搜索会话消息失败: %v", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var r SearchResult
+		var content string
+		var conversationID, taskID int64
+		if err := rows.Scan(&r.ID, &content, &conversationID, &taskID, &r.Title,
+			&r.ProjectID, &r.ProjectName, &r.Status, &r.Date, &r.Archived, &r.Score); err != nil {
+			return nil, fmt.Errorf("扫描搜索结果失败: %v", err)
+		}
+		r.Type = SearchTypeMessage
+		r.ConversationID = &conversationID
+		r.TaskID = &taskID
+		if r.Title == "" {
+			r.Title = fmt.Sprintf("会话 #%d", conversationID)
+		}
+		r.Snippet, r.Score = q.result("", content, r.Score)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// parseSearchQuery 解析搜索词：空格分隔，双引号包裹的作为短语
+func parseSearchQuery(query string) searchQuery {
+	var q searchQuery
+	seen := make(map[string]bool)
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		if term == "" || seen[strings.ToLower(term)] {
+			return
+		}
+		seen[strings.ToLower(term)] = true
+		q.terms = append(q.terms, term)
+	}
+
+	var current strings.Builder
+	inPhrase := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			add(current.String())
+			current.Reset()
+			inPhrase = !inPhrase
+		case unicode.IsSpace(r) && !inPhrase:
+			add(current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	add(current.String())
+
+	var matches []string
+	for _, term := range q.terms {
+		if utf8.RuneCountInString(term) < trigramMinRunes {
+			q.likes = append(q.likes, term)
+			continue
+		}
+		matches = append(matches, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	q.match = strings.Join(matches, " AND ")
+	q.hasRank = q.match != ""
+	return q
+}
+
+// conditions 搜索词对应的 WHERE 条件（FTS5 MATCH 和短词 LIKE）
+func (q searchQuery) conditions(ftsTable string, columns ...string) ([]string, []interface{}) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+	if q.hasRank {
+		where = append(where, ftsTable+" MATCH ?")
+		args = append(args, q.match)
+	}
+	likes, likeArgs := q.likeConditions(columns...)
+	return append(where, likes...), append(args, likeArgs...)
+}
+
+// likeConditions 短词的 LIKE 条件（每个词在任一列中出现即可）
+func (q searchQuery) likeConditions(columns ...string) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+	for _, term := range q.likes {
+		pattern := "%" + escapeLike(term) + "%"
+		var ors []string
+		for _, col := range columns {
+			ors = append(ors, fmt.Sprintf(`COALESCE(%s, '') LIKE ? ESCAPE '\'`, col))
+			args = append(args, pattern)
+		}
+		where = append(where, "("+strings.Join(ors, " OR ")+")")
+	}
+	return where, args
+}
+
+// join 连接全文索引表（只有短词时不需要）
+func (q searchQuery) join(ftsTable, idColumn string) string {
+	if !q.hasRank {
+		return ""
+	}
+	return fmt.Sprintf("JOIN %s ON %s.rowid = %s", ftsTable, ftsTable, idColumn)
+}
+
+// rankExpr 相关度表达式（bm25 越小越相关，取负数后越大越相关；名称列权重更高）
+func (q searchQuery) rankExpr(ftsTable string) string {
+	if !q.hasRank {
+		return "0"
+	}
+	if ftsTable == "messages_fts" {
+		return "-bm25(messages_fts)"
+	}
+	return fmt.Sprintf("-bm25(%s, 10.0, 1.0)", ftsTable)
+}
+
+// result 生成匹配片段和排序用的得分（没有 bm25 排名时按命中次数计分，名称命中权重更高）
+func (q searchQuery) result(title, body string, rank float64) (string, float64) {
+	titleHits, bodyHits := countMatches(title, q.terms), countMatches(body, q.terms)
+	if !q.hasRank {
+		rank = float64(titleHits*10 + bodyHits)
+	}
+
+	text := body
+	if bodyHits == 0 {
+		text = title
+	}
+	return highlightSnippet(text, q.terms), rank
+}
+
+// countMatches 统计搜索词在文本中出现的次数（不区分大小写）
+func countMatches(text string, terms []string) int {
+	lower := strings.ToLower(text)
+	count := 0
+	for _, term := range terms {
+		count += strings.Count(lower, strings.ToLower(term))
+	}
+	return count
+}
+
+// highlightSnippet 截取第一个命中词附近的片段，HTML 转义后用 <mark> 标出命中词
+func highlightSnippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+
+	// 标记每个字符是否属于命中词
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != string(t) {
+				continue
+			}
+			for j := i; j < i+len(t); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start := 0
+	if first > searchSnippetRunes/3 {
+		start = first - searchSnippetRunes/3
+	}
+	end := start + searchSnippetRunes
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// searchTitles 搜索并返回结果标题
+func searchTitles(t *testing.T, a *App, input SearchInput) []string {
+	t.Helper()
+	results, err := a.Search(input)
+	if err != nil {
+		t.Fatalf("搜索 %q 失败: %v", input.Query, err)
+	}
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Title)
+	}
+	return titles
+}
+
+func TestSearchFollowsTaskChanges(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "数据库迁移方案", Hours: 1})
+	if got := searchTitles(t, a, SearchInput{Query: "数据库迁移"}); len(got) != 1 {
+		t.Fatalf("新建任务应能搜到: %v", got)
+	}
+
+	if err := a.UpdateTask(TaskInput{ID: task.ID, Name: "缓存预热方案", Hours: 1}); err != nil {
+		t.Fatalf("修改任务失败: %v", err)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "数据库迁移"}); len(got) != 0 {
+		t.Fatalf("改名后旧名称不应再搜到: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "缓存预热"}); len(got) != 1 {
+		t.Fatalf("改名后新名称应能搜到: %v", got)
+	}
+
+	if err := a.DeleteTask(task.ID); err != nil {
+		t.Fatalf("删除任务失败: %v", err)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "缓存预热"}); len(got) != 0 {
+		t.Fatalf("删除后不应再搜到: %v", got)
+	}
+}
+
+func TestSearchShortChineseTermsUseLike(t *testing.T) {
+	a := openTestDB(t)
+	createTestTask(t, a, TaskInput{Name: "整理报销单", Hours: 1})
+	createTestTask(t, a, TaskInput{Name: "写周报", Hours: 1, Description: "本周报销进度"})
+	createTestTask(t, a, TaskInput{Name: "买咖啡", Hours: 1})
+
+	q := parseSearchQuery("报销")
+	if q.hasRank || len(q.likes) != 1 {
+		t.Fatalf("2个字的词应走 LIKE: %+v", q)
+	}
+	got := searchTitles(t, a, SearchInput{Query: "报销"})
+	if len(got) != 2 || got[0] != "整理报销单" {
+		t.Fatalf("名称命中应排在描述命中前面: %v", got)
+	}
+	// 长短词混合时两种条件都要满足
+	if got := searchTitles(t, a, SearchInput{Query: "报销 整理报销"}); len(got) != 1 || got[0] != "整理报销单" {
+		t.Fatalf("长短词混合搜索结果不符合预期: %v", got)
+	}
+}
+
+func TestSearchPhraseQuery(t *testing.T) {
+	a := openTestDB(t)
+	createTestTask(t, a, TaskInput{Name: "write unit tests", Hours: 1})
+	createTestTask(t, a, TaskInput{Name: "tests for the unit converter", Hours: 1})
+
+	if got := searchTitles(t, a, SearchInput{Query: "unit tests"}); len(got) != 2 {
+		t.Fatalf("分开的词应匹配两个任务: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: `"unit tests"`}); len(got) != 1 || got[0] != "write unit tests" {
+		t.Fatalf("短语只应匹配连续出现的任务: %v", got)
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	a := openTestDB(t)
+	active, _ := a.CreateProject("发布计划", "", "")
+	archived, _ := a.CreateProject("旧发布", "", "")
+	date := "2026-04-10"
+	createTestTask(t, a, TaskInput{Name: "发布检查清单", Hours: 1, ProjectID: &active.ID, Date: &date})
+	done := createTestTask(t, a, TaskInput{Name: "发布公告草稿", Hours: 1, ProjectID: &active.ID})
+	a.UpdateTaskStatus(done.ID, TaskStatusCompleted)
+	createTestTask(t, a, TaskInput{Name: "发布回滚演练", Hours: 1, ProjectID: &archived.ID})
+	if err := a.ArchiveProject(archived.ID, true); err != nil {
+		t.Fatalf("归档项目失败: %v", err)
+	}
+
+	if got := searchTitles(t, a, SearchInput{Query: "发布", Types: []string{SearchTypeTask}}); len(got) != 2 {
+		t.Fatalf("默认不包含归档项目的任务: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "发布", Types: []string{SearchTypeTask}, IncludeArchived: true}); len(got) != 3 {
+		t.Fatalf("包含归档后应有3个任务: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "发布", Status: TaskStatusCompleted}); len(got) != 1 || got[0] != "发布公告草稿" {
+		t.Fatalf("按状态过滤后只应有已完成的任务（项目不参与状态过滤）: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "发布", StartDate: "2026-04-01", EndDate: "2026-04-30", Types: []string{SearchTypeTask}}); len(got) != 1 || got[0] != "发布检查清单" {
+		t.Fatalf("按日期过滤结果不符合预期: %v", got)
+	}
+	if got := searchTitles(t, a, SearchInput{Query: "发布", Types: []string{SearchTypeProject}}); len(got) != 1 || got[0] != "发布计划" {
+		t.Fatalf("只搜项目时应只返回未归档项目: %v", got)
+	}
+	if _, err := a.Search(SearchInput{Query: "发布", Types: []string{"file"}}); err == nil {
+		t.Fatal("不支持的搜索范围应返回错误")
+	}
+}
+
+func TestSearchSnippetEscapesHTML(t *testing.T) {
+	a := openTestDB(t)
+	createTestTask(t, a, TaskInput{Name: "修复页面", Hours: 1, Description: `<b>紧急</b> 修复 XSS 漏洞`})
+
+	results, err := a.Search(SearchInput{Query: "XSS"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("搜索失败: %v %d", err, len(results))
+	}
+	snippet := results[0].Snippet
+	if strings.Contains(snippet, "<b>") || !strings.Contains(snippet, "&lt;b&gt;紧急&lt;/b&gt;") {
+		t.Fatalf("片段应转义 HTML: %s", snippet)
+	}
+	if !strings.Contains(snippet, "<mark>XSS</mark>") {
+		t.Fatalf("片段应标出命中词: %s", snippet)
+	}
+}
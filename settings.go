@@ -0,0 +1,42 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// loadSetting 读取 JSON 设置到 out，未设置时返回 false（out 保持不变）
+func loadSetting(key string, out interface{}) (bool, error) {
+	var value string
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("读取设置失败: %v", err)
+	}
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return false, fmt.Errorf("解析设置 %s 失败: %v", key, err)
+	}
+	return true, nil
+}
+
+// saveSetting 以 JSON 保存设置
+func saveSetting(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化设置失败: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+	`, key, string(data))
+	if err != nil {
+		log.Printf("保存设置失败: %v", err)
+		return fmt.Errorf("保存设置失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// shellWaitDelay 进程组被结束后等待输出管道关闭的最长时间
+const shellWaitDelay = 2 * time.Second
+
+// shellCommand 创建 sh -c 命令：在独立的进程组中运行，ctx 结束时结束整个进程组（包括命令启动的子进程），
+// 子进程仍占用输出管道时最多再等待 shellWaitDelay
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = shellWaitDelay
+	return cmd
+}
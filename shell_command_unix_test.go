@@ -0,0 +1,59 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processAlive 进程是否仍在运行（僵尸进程视为已结束）
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat)[strings.LastIndex(string(stat), ")")+1:])
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestCustomToolTimeoutKillsChildProcesses(t *testing.T) {
+	dir := t.TempDir()
+	executor := NewToolExecutor(dir)
+	tool := CustomTool{
+		Name:    "slow_tool",
+		Command: `sh -c 'echo $$ > child.pid; sleep 30'; echo late`,
+		Schema:  `{"type": "object", "properties": {}}`,
+		Timeout: 1,
+	}
+	if err := executor.registry.Register(tool.agentTool()); err != nil {
+		t.Fatalf("注册工具失败: %v", err)
+	}
+
+	started := time.Now()
+	result := executor.executeCustomTool(tool, `{}`)
+	elapsed := time.Since(started)
+	if result.Success || !strings.Contains(result.Error, "超时") {
+		t.Fatalf("应返回超时错误: %+v", result)
+	}
+	if elapsed > 1*time.Second+shellWaitDelay {
+		t.Fatalf("超时后应立即返回，实际耗时 %v", elapsed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "child.pid"))
+	if err != nil {
+		t.Fatalf("读取子进程ID失败: %v", err)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	deadline := time.Now().Add(time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		t.Fatalf("命令启动的子进程 %d 在超时后仍在运行", pid)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+// killProcessGroupOnCancel Windows 没有进程组信号，取消时只结束命令本身（由 WaitDelay 兜底）
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// defaultTagColor 标签默认颜色
+const defaultTagColor = "#86909c"
+
+const tagSelectSQL = `
+	SELECT g.id, g.name, COALESCE(g.color, '#86909c'),
+		(SELECT COUNT(*) FROM task_tags tt WHERE tt.tag_id = g.id) AS task_count,
+		g.created_at
+	FROM tags g
+`
+
+// scanTag 扫描标签
+func scanTag(row interface{ Scan(...any) error }) (Tag, error) {
+	var g Tag
+	err := row.Scan(&g.ID, &g.Name, &g.Color, &g.TaskCount, &g.CreatedAt)
+	return g, err
+}
+
+// queryTags 执行标签查询
+func queryTags(query string, args ...interface{}) ([]Tag, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("查询标签失败: %v", err)
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		g, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描标签失败: %v", err)
+		}
+		tags = append(tags, g)
+	}
+	return tags, nil
+}
+
+// GetTags 获取全部标签及使用次数
+func (a *App) GetTags() ([]Tag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return queryTags(tagSelectSQL + `ORDER BY g.name COLLATE NOCASE`)
+}
+
+// SuggestTags 标签自动补全：以输入开头的排在前面，其次是包含输入的，同类按使用次数排序
+func (a *App) SuggestTags(prefix string, limit int) ([]Tag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	prefix = escapeLike(strings.TrimSpace(prefix))
+	return queryTags(tagSelectSQL+`
+		WHERE g.name LIKE ? ESCAPE '\'
+		ORDER BY CASE WHEN g.name LIKE ? ESCAPE '\' THEN 0 ELSE 1 END, task_count DESC, g.name COLLATE NOCASE
+		LIMIT ?
+	`, "%"+prefix+"%", prefix+"%", limit)
+}
+
+// CreateTag 创建标签
+func (a *App) CreateTag(name, color string) (*Tag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("标签名称不能为空")
+	}
+	if color == "" {
+		color = defaultTagColor
+	}
+
+	result, err := db.Exec(`INSERT INTO tags (name, color) VALUES (?, ?)`, name, color)
+	if err != nil {
+		log.Printf("创建标签失败: %v", err)
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("标签「%s」已存在", name)
+		}
+		return nil, fmt.Errorf("创建标签失败: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取标签ID失败: %v", err)
+	}
+
+	g, err := scanTag(db.QueryRow(tagSelectSQL+`WHERE g.id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	log.Printf("创建标签成功: %s (ID: %d)", name, id)
+	return &g, nil
+}
+
+// UpdateTag 重命名标签或修改颜色（名称与其他标签相同时请用 MergeTags）
+func (a *App) UpdateTag(id int64, name, color string) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("标签名称不能为空")
+	}
+	if color == "" {
+		color = defaultTagColor
+	}
+
+	var existing int64
+	db.QueryRow(`SELECT id FROM tags WHERE name = ? AND id != ?`, name, id).Scan(&existing)
+	if existing != 0 {
+		return fmt.Errorf("标签「%s」已存在，可以合并到该标签", name)
+	}
+
+	result, err := db.Exec(`UPDATE tags SET name = ?, color = ? WHERE id = ?`, name, color, id)
+	if err != nil {
+		log.Printf("更新标签失败: %v", err)
+		return fmt.Errorf("更新标签失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("标签不存在")
+	}
+
+	log.Printf("更新标签成功: %s (ID: %d)", name, id)
+	return nil
+}
+
+// DeleteTag 删除标签（任务上的该标签一并移除）
+func (a *App) DeleteTag(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := db.Exec(`DELETE FROM task_tags WHERE tag_id = ?`, id); err != nil {
+		log.Printf("删除任务标签失败: %v", err)
+		return fmt.Errorf("删除标签失败: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM tags WHERE id = ?`, id); err != nil {
+		log.Printf("删除标签失败: %v", err)
+		return fmt.Errorf("删除标签失败: %v", err)
+	}
+
+	log.Printf("删除标签成功: ID=%d", id)
+	return nil
+}
+
+// MergeTags 把多个标签合并到目标标签（任务改为带有目标标签，原标签删除）
+func (a *App) MergeTags(sourceIDs []int64, targetID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	// 目标和全部源标签都必须存在，避免部分合并
+	for _, id := range append([]int64{targetID}, sourceIDs...) {
+		var exists int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM tags WHERE id = ?`, id).Scan(&exists); err != nil {
+			log.Printf("查询标签失败: %v", err)
+			return fmt.Errorf("查询标签失败: %v", err)
+		}
+		if exists == 0 {
+			if id == targetID {
+				return fmt.Errorf("目标标签不存在")
+			}
+			return fmt.Errorf("标签不存在: ID=%d", id)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, sourceID := range sourceIDs {
+		if sourceID == targetID {
+			continue
+		}
+		statements := []string{
+			`INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ?`,
+			`DELETE FROM task_tags WHERE tag_id = ?`,
+			`DELETE FROM tags WHERE id = ?`,
+		}
+		for i, stmt := range statements {
+			args := []interface{}{sourceID}
+			if i == 0 {
+				args = []interface{}{targetID, sourceID}
+			}
+			if _, err := tx.Exec(stmt, args...); err != nil {
+				log.Printf("合并标签失败: %v", err)
+				return fmt.Errorf("合并标签失败: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交合并标签失败: %v", err)
+	}
+
+	log.Printf("已将 %d 个标签合并到标签 %d", len(sourceIDs), targetID)
+	return nil
+}
+
+// SetTaskTags 设置任务的全部标签
+func (a *App) SetTaskTags(taskID int64, tagIDs []int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	return setTaskTags(db, taskID, tagIDs)
+}
+
+// AddTaskTag 按名称给任务添加标签（标签不存在时自动创建）
+func (a *App) AddTaskTag(taskID int64, name string) (*Tag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("标签名称不能为空")
+	}
+
+	g, err := scanTag(db.QueryRow(tagSelectSQL+`WHERE g.name = ?`, name))
+	if err != nil {
+		created, err := a.CreateTag(name, "")
+		if err != nil {
+			return nil, err
+		}
+		g = *created
+	}
+
+	if _, err := db.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)`, taskID, g.ID); err != nil {
+		log.Printf("添加任务标签失败: %v", err)
+		return nil, fmt.Errorf("添加任务标签失败: %v", err)
+	}
+	return &g, nil
+}
+
+// RemoveTaskTag 移除任务的标签
+func (a *App) RemoveTaskTag(taskID, tagID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := db.Exec(`DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?`, taskID, tagID); err != nil {
+		log.Printf("移除任务标签失败: %v", err)
+		return fmt.Errorf("移除任务标签失败: %v", err)
+	}
+	return nil
+}
+
+// GetTagTimeStats 获取按标签的工时统计
+func (a *App) GetTagTimeStats(startDate, endDate string, projectIDs []int64) ([]TagTimeStats, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	tracked, err := loadTrackedHours(startDate, endDate, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	var taskIDs []int64
+	seen := make(map[int64]bool)
+	for _, t := range tracked {
+		if !seen[t.taskID] {
+			seen[t.taskID] = true
+			taskIDs = append(taskIDs, t.taskID)
+		}
+	}
+	taskTags, err := loadTaskTags(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []TagTimeStats{}
+	index := make(map[int64]int)
+	tasks := make(map[int64]map[int64]bool)
+	var totalHours float64
+
+	for _, t := range tracked {
+		totalHours += t.hours
+		tags := taskTags[t.taskID]
+		if len(tags) == 0 {
+			tags = []Tag{{Name: "无标签", Color: defaultTagColor}}
+		}
+		for _, g := range tags {
+			i, ok := index[g.ID]
+			if !ok {
+				i = len(stats)
+				index[g.ID] = i
+				tasks[g.ID] = make(map[int64]bool)
+				stats = append(stats, TagTimeStats{TagID: g.ID, TagName: g.Name, Color: g.Color})
+			}
+			stats[i].TotalHours += t.hours
+			tasks[g.ID][t.taskID] = true
+		}
+	}
+
+	for i := range stats {
+		stats[i].TaskCount = len(tasks[stats[i].TagID])
+		if totalHours > 0 {
+			stats[i].Percentage = (stats[i].TotalHours / totalHours) * 100
+		}
+		stats[i].TotalHours = roundHours(stats[i].TotalHours)
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].TotalHours > stats[j].TotalHours })
+
+	return stats, nil
+}
+
+// setTaskTags 替换任务的标签（忽略不存在的标签）
+func setTaskTags(exec sqlExecer, taskID int64, tagIDs []int64) error {
+	if _, err := exec.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		log.Printf("设置任务标签失败: %v", err)
+		return fmt.Errorf("设置任务标签失败: %v", err)
+	}
+	for _, tagID := range tagIDs {
+		_, err := exec.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT ?, id FROM tags WHERE id = ?`, taskID, tagID)
+		if err != nil {
+			log.Printf("设置任务标签失败: %v", err)
+			return fmt.Errorf("设置任务标签失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// attachTaskTags 填充任务的标签
+func attachTaskTags(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	taskTags, err := loadTaskTags(collectTaskIDs(tasks))
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Tags = taskTags[tasks[i].ID]
+		if tasks[i].Tags == nil {
+			tasks[i].Tags = []Tag{}
+		}
+	}
+	return nil
+}
+
+// loadTaskTags 任务的标签（任务ID → 标签，taskIDs 为空时返回空）
+func loadTaskTags(taskIDs []int64) (map[int64][]Tag, error) {
+	result := make(map[int64][]Tag)
+	if len(taskIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT tt.task_id, g.id, g.name, COALESCE(g.color, '#86909c'), g.created_at
+		FROM task_tags tt
+		JOIN tags g ON g.id = tt.tag_id
+		WHERE tt.task_id IN (` + joinIDs(taskIDs) + `)
+		ORDER BY g.name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询任务标签失败: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var g Tag
+		if err := rows.Scan(&taskID, &g.ID, &g.Name, &g.Color, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("扫描任务标签失败: %v", err)
+		}
+		result[taskID] = append(result[taskID], g)
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+// createTestTag 创建测试标签
+func createTestTag(t *testing.T, a *App, name string) *Tag {
+	t.Helper()
+	g, err := a.CreateTag(name, "")
+	if err != nil {
+		t.Fatalf("创建标签 %s 失败: %v", name, err)
+	}
+	return g
+}
+
+// taskTagNames 任务当前的标签名称（已排序）
+func taskTagNames(t *testing.T, a *App, taskID int64) []string {
+	t.Helper()
+	task, err := a.GetTask(taskID)
+	if err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	var names []string
+	for _, g := range task.Tags {
+		names = append(names, g.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestMergeTags(t *testing.T) {
+	a := openTestDB(t)
+	bug := createTestTag(t, a, "bug")
+	defect := createTestTag(t, a, "缺陷")
+	other := createTestTag(t, a, "文档")
+	both := createTestTask(t, a, TaskInput{Name: "修复崩溃", Hours: 1, TagIDs: []int64{bug.ID, defect.ID}})
+	onlyBug := createTestTask(t, a, TaskInput{Name: "修复乱码", Hours: 1, TagIDs: []int64{bug.ID}})
+	docs := createTestTask(t, a, TaskInput{Name: "补充文档", Hours: 1, TagIDs: []int64{other.ID}})
+
+	if err := a.MergeTags([]int64{bug.ID, 9999}, defect.ID); err == nil {
+		t.Fatal("源标签不存在时应返回错误")
+	}
+	if err := a.MergeTags([]int64{bug.ID}, 9999); err == nil {
+		t.Fatal("目标标签不存在时应返回错误")
+	}
+	if got := taskTagNames(t, a, onlyBug.ID); len(got) != 1 || got[0] != "bug" {
+		t.Fatalf("合并失败时不应修改任务标签: %v", got)
+	}
+
+	if err := a.MergeTags([]int64{bug.ID, defect.ID}, defect.ID); err != nil {
+		t.Fatalf("合并标签失败: %v", err)
+	}
+	// 已同时带有两个标签的任务只保留一个目标标签
+	if got := taskTagNames(t, a, both.ID); len(got) != 1 || got[0] != "缺陷" {
+		t.Fatalf("合并后标签不符合预期: %v", got)
+	}
+	if got := taskTagNames(t, a, onlyBug.ID); len(got) != 1 || got[0] != "缺陷" {
+		t.Fatalf("合并后标签不符合预期: %v", got)
+	}
+	if got := taskTagNames(t, a, docs.ID); len(got) != 1 || got[0] != "文档" {
+		t.Fatalf("无关任务的标签不应改变: %v", got)
+	}
+
+	tags, err := a.GetTags()
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("源标签应被删除: %v %+v", err, tags)
+	}
+	for _, g := range tags {
+		if g.ID == defect.ID && g.TaskCount != 2 {
+			t.Fatalf("目标标签应有2个任务，实际 %d", g.TaskCount)
+		}
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	a := openTestDB(t)
+	work := createTestTag(t, a, "工作")
+	createTestTag(t, a, "生活")
+
+	if err := a.UpdateTag(work.ID, " 公司 ", "#ff0000"); err != nil {
+		t.Fatalf("重命名标签失败: %v", err)
+	}
+	tags, _ := a.GetTags()
+	var renamed *Tag
+	for i := range tags {
+		if tags[i].ID == work.ID {
+			renamed = &tags[i]
+		}
+	}
+	if renamed == nil || renamed.Name != "公司" || renamed.Color != "#ff0000" {
+		t.Fatalf("重命名结果不符合预期: %+v", renamed)
+	}
+
+	if err := a.UpdateTag(work.ID, "生活", ""); err == nil {
+		t.Fatal("与其他标签重名时应返回错误")
+	}
+	if err := a.UpdateTag(work.ID, "  ", ""); err == nil {
+		t.Fatal("名称为空时应返回错误")
+	}
+	if err := a.UpdateTag(9999, "新标签", ""); err == nil {
+		t.Fatal("标签不存在时应返回错误")
+	}
+}
+
+func TestGetTagTimeStats(t *testing.T) {
+	a := openTestDB(t)
+	backend := createTestTag(t, a, "后端")
+	urgent := createTestTag(t, a, "紧急")
+	api := createTestTask(t, a, TaskInput{Name: "接口开发", Hours: 3, TagIDs: []int64{backend.ID, urgent.ID}})
+	tuning := createTestTask(t, a, TaskInput{Name: "数据库优化", Hours: 2, TagIDs: []int64{backend.ID}})
+	misc := createTestTask(t, a, TaskInput{Name: "杂事", Hours: 1})
+
+	entries := []struct {
+		taskID     int64
+		start, end string
+	}{
+		{api.ID, "2026-02-02 09:00", "2026-02-02 11:00"},
+		{tuning.ID, "2026-02-03 09:00", "2026-02-03 10:00"},
+		{misc.ID, "2026-02-03 14:00", "2026-02-03 15:00"},
+		{misc.ID, "2026-03-01 09:00", "2026-03-01 10:00"}, // 统计范围之外
+	}
+	for _, e := range entries {
+		end := e.end
+		if _, err := a.AddTimeEntry(TimeEntryInput{TaskID: e.taskID, StartAt: e.start, EndAt: &end}); err != nil {
+			t.Fatalf("添加工时记录失败: %v", err)
+		}
+	}
+
+	stats, err := a.GetTagTimeStats("2026-02-01", "2026-02-28", nil)
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	want := []TagTimeStats{
+		{TagID: backend.ID, TotalHours: 3, TaskCount: 2, Percentage: 75},
+		{TagID: urgent.ID, TotalHours: 2, TaskCount: 1, Percentage: 50},
+		{TagID: 0, TotalHours: 1, TaskCount: 1, Percentage: 25},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("应有 %d 项统计，实际 %+v", len(want), stats)
+	}
+	for i, w := range want {
+		s := stats[i]
+		if s.TagID != w.TagID || s.TotalHours != w.TotalHours || s.TaskCount != w.TaskCount || s.Percentage != w.Percentage {
+			t.Errorf("第 %d 项统计为 %+v，期望 %+v", i, s, w)
+		}
+	}
+	if stats[2].TagName != "无标签" {
+		t.Fatalf("没有标签的任务应计入“无标签”: %s", stats[2].TagName)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,7 +15,9 @@ const taskSelectSQL = `
 		   t.name, t.description, t.date, t.start_time, t.end_time,
 		   t.hours, t.deadline, COALESCE(t.priority, 'medium') as priority,
 		   COALESCE(t.urgency, 'medium') as urgency, t.status,
-		   t.actual_start, COALESCE(t.actual_hours, 0) as actual_hours, t.created_at
+		   t.actual_start, COALESCE(t.actual_hours, 0) as actual_hours, t.parent_id,
+		   COALESCE(t.completion_rule, 'manual') as completion_rule,
+		   t.recurrence_id, t.occurrence_date, t.created_at
 	FROM tasks t
 	LEFT JOIN projects p ON t.project_id = p.id
 `
@@ -24,96 +28,72 @@ func (a *App) GetTask(id int64) (*Task, error) {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	var t Task
-	err := db.QueryRow(taskSelectSQL+`WHERE t.id = ?`, id).Scan(
-		&t.ID, &t.ProjectID, &t.ProjectName, &t.Name, &t.Description,
-		&t.Date, &t.StartTime, &t.EndTime, &t.Hours, &t.Deadline, &t.Priority,
-		&t.Urgency, &t.Status, &t.ActualStart, &t.ActualHours, &t.CreatedAt)
+	t, err := scanTask(db.QueryRow(taskSelectSQL+`WHERE t.id = ?`, id))
 	if err != nil {
 		return nil, fmt.Errorf("任务不存在: %v", err)
 	}
 
-	return &t, nil
+	tasks := []Task{t}
+	if err := decorateTasks(tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
 }
 
 // GetTasksByDate 根据日期获取任务
 func (a *App) GetTasksByDate(date string) ([]Task, error) {
-	if db == nil {
-		return nil, fmt.Errorf("数据库未初始化")
-	}
-
-	rows, err := db.Query(taskSelectSQL+`
-		WHERE t.date = ?
-		ORDER BY
-			CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END,
-			t.start_time NULLS LAST,
-			t.created_at
-	`, date)
-	if err != nil {
-		log.Printf("查询任务失败: %v", err)
-		return nil, fmt.Errorf("查询任务失败: %v", err)
-	}
-	defer rows.Close()
-
-	return scanTasks(rows)
+	return listTasks(TaskFilter{DateFrom: date, DateTo: date}, `
+		CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END,
+		t.start_time NULLS LAST,
+		t.created_at
+	`)
 }
 
 // GetTasksByDateRange 根据日期范围获取任务
 func (a *App) GetTasksByDateRange(startDate, endDate string) ([]Task, error) {
-	if db == nil {
-		return nil, fmt.Errorf("数据库未初始化")
-	}
-
-	rows, err := db.Query(taskSelectSQL+`
-		WHERE t.date >= ? AND t.date <= ?
-		ORDER BY t.date, t.start_time, t.created_at
-	`, startDate, endDate)
-	if err != nil {
-		log.Printf("查询任务失败: %v", err)
-		return nil, fmt.Errorf("查询任务失败: %v", err)
-	}
-	defer rows.Close()
-
-	return scanTasks(rows)
+	return listTasks(TaskFilter{DateFrom: startDate, DateTo: endDate}, `t.date, t.start_time, t.created_at`)
 }
 
 // GetPendingTasks 获取待办任务（无日期）
 func (a *App) GetPendingTasks() ([]Task, error) {
-	if db == nil {
-		return nil, fmt.Errorf("数据库未初始化")
-	}
-
-	rows, err := db.Query(taskSelectSQL + `
-		WHERE t.date IS NULL
-		ORDER BY
-			CASE t.priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
-			CASE t.urgency WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
-			t.deadline ASC NULLS LAST,
-			t.created_at DESC
+	scheduled := false
+	return listTasks(TaskFilter{Scheduled: &scheduled}, `
+		CASE t.priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+		CASE t.urgency WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+		t.deadline ASC NULLS LAST,
+		t.created_at DESC
 	`)
-	if err != nil {
-		log.Printf("查询待办任务失败: %v", err)
-		return nil, fmt.Errorf("查询待办任务失败: %v", err)
-	}
-	defer rows.Close()
-
-	return scanTasks(rows)
 }
 
 // GetOverdueTasks 获取逾期任务（日期早于今天且未完成）
 func (a *App) GetOverdueTasks() ([]Task, error) {
+	return listTasks(TaskFilter{Overdue: true}, `t.date DESC, t.priority DESC`)
+}
+
+// GetTasksByProject 获取项目下的任务（projectID 为 0 表示未分类任务，status 为空表示全部状态）
+func (a *App) GetTasksByProject(projectID int64, status string) ([]Task, error) {
 	if db == nil {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
-	today := time.Now().Format("2006-01-02")
-	rows, err := db.Query(taskSelectSQL+`
-		WHERE t.date < ? AND t.status != ?
-		ORDER BY t.date DESC, t.priority DESC
-	`, today, TaskStatusCompleted)
+	query := taskSelectSQL + `WHERE COALESCE(t.project_id, 0) = ?`
+	args := []interface{}{projectID}
+	if status != "" {
+		query += ` AND t.status = ?`
+		args = append(args, status)
+	}
+	query += `
+		ORDER BY
+			CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END,
+			t.date NULLS LAST,
+			t.start_time NULLS LAST,
+			t.created_at
+	`
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		log.Printf("查询逾期任务失败: %v", err)
-		return nil, fmt.Errorf("查询逾期任务失败: %v", err)
+		log.Printf("查询项目任务失败: %v", err)
+		return nil, fmt.Errorf("查询项目任务失败: %v", err)
 	}
 	defer rows.Close()
 
@@ -126,6 +106,8 @@ func (a *App) RescheduleTask(taskID int64, newDate string) error {
 		return fmt.Errorf("数据库未初始化")
 	}
 
+	before := snapshotTask(taskID)
+	oldDate := currentTaskDate(taskID)
 	_, err := db.Exec(`
 		UPDATE tasks SET date = ?, status = ? WHERE id = ?
 	`, newDate, TaskStatusScheduled, taskID)
@@ -133,6 +115,8 @@ func (a *App) RescheduleTask(taskID int64, newDate string) error {
 		log.Printf("顺延任务失败: %v", err)
 		return fmt.Errorf("顺延任务失败: %v", err)
 	}
+	recordReschedule(taskID, oldDate, &newDate, RescheduleReasonManual)
+	a.auditTask(taskID, AuditActionUpdate, before)
 
 	log.Printf("任务 %d 已顺延到 %s", taskID, newDate)
 	return nil
@@ -145,6 +129,15 @@ func (a *App) RescheduleAllOverdueTasks() (int64, error) {
 	}
 
 	today := time.Now().Format("2006-01-02")
+	overdue, err := a.GetOverdueTasks()
+	if err != nil {
+		return 0, err
+	}
+	befores := make(map[int64]map[string]interface{}, len(overdue))
+	for _, t := range overdue {
+		befores[t.ID] = snapshotTask(t.ID)
+	}
+
 	result, err := db.Exec(`
 		UPDATE tasks SET date = ?, status = ?
 		WHERE date < ? AND status != ?
@@ -153,6 +146,10 @@ func (a *App) RescheduleAllOverdueTasks() (int64, error) {
 		log.Printf("批量顺延任务失败: %v", err)
 		return 0, fmt.Errorf("批量顺延任务失败: %v", err)
 	}
+	for _, t := range overdue {
+		recordReschedule(t.ID, t.Date, &today, RescheduleReasonBulkOverdue)
+		a.auditTask(t.ID, AuditActionUpdate, befores[t.ID])
+	}
 
 	count, _ := result.RowsAffected()
 	log.Printf("已将 %d 个逾期任务顺延到今天", count)
@@ -160,29 +157,118 @@ func (a *App) RescheduleAllOverdueTasks() (int64, error) {
 }
 
 // scanTasks 扫描任务结果集
-func scanTasks(rows interface{ Next() bool; Scan(...any) error }) ([]Task, error) {
+func scanTasks(rows interface {
+	Next() bool
+	Scan(...any) error
+}) ([]Task, error) {
 	var tasks []Task
 	for rows.Next() {
-		var t Task
-		if err := rows.Scan(&t.ID, &t.ProjectID, &t.ProjectName, &t.Name, &t.Description,
-			&t.Date, &t.StartTime, &t.EndTime, &t.Hours, &t.Deadline, &t.Priority,
-			&t.Urgency, &t.Status, &t.ActualStart, &t.ActualHours, &t.CreatedAt); err != nil {
+		t, err := scanTask(rows)
+		if err != nil {
 			log.Printf("扫描任务失败: %v", err)
 			return nil, fmt.Errorf("扫描任务失败: %v", err)
 		}
 		tasks = append(tasks, t)
 	}
+	// 结果集读完后连接已释放，再查询计算字段
+	if err := decorateTasks(tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
+// collectTaskIDs 结果集中的任务ID
+func collectTaskIDs(tasks []Task) []int64 {
+	ids := make([]int64, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// taskIDList 结果集中的任务ID（逗号分隔，用于拼接 IN 子句）
+func taskIDList(tasks []Task) string {
+	return joinIDs(collectTaskIDs(tasks))
+}
+
+// joinIDs 将ID拼接为逗号分隔的字符串（均为整数，可直接拼入 SQL）
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decorateTasks 填充任务的计算字段（子任务汇总、前置任务阻塞状态、推迟次数、标签）
+func decorateTasks(tasks []Task) error {
+	if err := attachTaskRollups(tasks); err != nil {
+		return err
+	}
+	if err := attachTaskBlocked(tasks); err != nil {
+		return err
+	}
+	if err := attachSlipCounts(tasks); err != nil {
+		return err
+	}
+	return attachTaskTags(tasks)
+}
+
+// scanTask 扫描单个任务
+func scanTask(row interface{ Scan(...any) error }) (Task, error) {
+	var t Task
+	err := row.Scan(&t.ID, &t.ProjectID, &t.ProjectName, &t.Name, &t.Description,
+		&t.Date, &t.StartTime, &t.EndTime, &t.Hours, &t.Deadline, &t.Priority,
+		&t.Urgency, &t.Status, &t.ActualStart, &t.ActualHours, &t.ParentID,
+		&t.CompletionRule, &t.RecurrenceID, &t.OccurrenceDate, &t.CreatedAt)
+	return t, err
+}
+
 // CreateTask 创建任务
 func (a *App) CreateTask(input TaskInput) (*Task, error) {
 	if db == nil {
 		return nil, fmt.Errorf("数据库未初始化")
 	}
 
+	if input.ParentID != nil {
+		if _, err := a.GetTask(*input.ParentID); err != nil {
+			return nil, fmt.Errorf("父任务不存在: %v", err)
+		}
+	}
+
+	id, err := insertTask(db, input)
+	if err != nil {
+		return nil, err
+	}
+	a.auditTask(id, AuditActionCreate, nil)
+
+	// 查询创建的任务
+	t, err := scanTask(db.QueryRow(taskSelectSQL+`WHERE t.id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("查询任务失败: %v", err)
+	}
+	created := []Task{t}
+	if err := attachTaskTags(created); err != nil {
+		return nil, err
+	}
+	t = created[0]
+	if t.Date != nil {
+		t.Warnings = capacityWarnings(*t.Date)
+	}
+
+	log.Printf("创建任务成功: %s (ID: %d)", input.Name, id)
+	return &t, nil
+}
+
+// insertTask 写入新任务及其标签，补全默认的状态、优先级、紧急程度和完成规则（exec 可以是事务）
+func insertTask(exec sqlExecer, input TaskInput) (int64, error) {
 	if input.Name == "" {
-		return nil, fmt.Errorf("任务名称不能为空")
+		return 0, fmt.Errorf("任务名称不能为空")
+	}
+
+	completionRule, err := normalizeCompletionRule(input.CompletionRule)
+	if err != nil {
+		return 0, err
 	}
 
 	// 确定状态
@@ -205,32 +291,26 @@ func (a *App) CreateTask(input TaskInput) (*Task, error) {
 		urgency = UrgencyMedium
 	}
 
-	result, err := db.Exec(`
-		INSERT INTO tasks (project_id, name, description, date, start_time, end_time, hours, deadline, priority, urgency, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, input.ProjectID, input.Name, input.Description, input.Date, input.StartTime, input.EndTime, input.Hours, input.Deadline, priority, urgency, status)
+	result, err := exec.Exec(`
+		INSERT INTO tasks (project_id, name, description, date, start_time, end_time, hours, deadline, priority, urgency, status, parent_id, completion_rule)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.ProjectID, input.Name, input.Description, input.Date, input.StartTime, input.EndTime, input.Hours, input.Deadline, priority, urgency, status, input.ParentID, completionRule)
 	if err != nil {
 		log.Printf("创建任务失败: %v", err)
-		return nil, fmt.Errorf("创建任务失败: %v", err)
+		return 0, fmt.Errorf("创建任务失败: %v", err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("获取任务ID失败: %v", err)
+		return 0, fmt.Errorf("获取任务ID失败: %v", err)
 	}
 
-	// 查询创建的任务
-	var t Task
-	err = db.QueryRow(taskSelectSQL+`WHERE t.id = ?`, id).Scan(
-		&t.ID, &t.ProjectID, &t.ProjectName, &t.Name, &t.Description,
-		&t.Date, &t.StartTime, &t.EndTime, &t.Hours, &t.Deadline, &t.Priority,
-		&t.Urgency, &t.Status, &t.ActualStart, &t.ActualHours, &t.CreatedAt)
-	if err != nil {
-		return nil, fmt.Errorf("查询任务失败: %v", err)
+	if len(input.TagIDs) > 0 {
+		if err := setTaskTags(exec, id, input.TagIDs); err != nil {
+			return 0, err
+		}
 	}
-
-	log.Printf("创建任务成功: %s (ID: %d)", input.Name, id)
-	return &t, nil
+	return id, nil
 }
 
 // UpdateTask 更新任务
@@ -243,19 +323,49 @@ func (a *App) UpdateTask(input TaskInput) error {
 		return fmt.Errorf("任务名称不能为空")
 	}
 
+	// 完成规则为空时保留原值
+	completionRule := ""
+	if input.CompletionRule != "" {
+		rule, err := normalizeCompletionRule(input.CompletionRule)
+		if err != nil {
+			return err
+		}
+		completionRule = rule
+	}
+	if input.Status == TaskStatusCompleted {
+		if err := checkCanComplete(input.ID); err != nil {
+			return err
+		}
+	}
+
+	before := snapshotTask(input.ID)
+	oldDate := currentTaskDate(input.ID)
 	_, err := db.Exec(`
 		UPDATE tasks
 		SET project_id = ?, name = ?, description = ?, date = ?,
 			start_time = ?, end_time = ?, hours = ?, deadline = ?,
-			priority = ?, urgency = ?, status = ?
+			priority = ?, urgency = ?, status = ?,
+			completion_rule = COALESCE(NULLIF(?, ''), completion_rule)
 		WHERE id = ?
 	`, input.ProjectID, input.Name, input.Description, input.Date,
 		input.StartTime, input.EndTime, input.Hours, input.Deadline,
-		input.Priority, input.Urgency, input.Status, input.ID)
+		input.Priority, input.Urgency, input.Status, completionRule, input.ID)
 	if err != nil {
 		log.Printf("更新任务失败: %v", err)
 		return fmt.Errorf("更新任务失败: %v", err)
 	}
+	recordReschedule(input.ID, oldDate, input.Date, RescheduleReasonEdit)
+	a.auditTask(input.ID, AuditActionUpdate, before)
+
+	if input.TagIDs != nil {
+		if err := setTaskTags(db, input.ID, input.TagIDs); err != nil {
+			return err
+		}
+	}
+
+	if input.Status == TaskStatusCompleted {
+		autoCompleteParents(input.ID)
+	}
 
 	log.Printf("更新任务成功: ID=%d", input.ID)
 	return nil
@@ -267,33 +377,101 @@ func (a *App) DeleteTask(id int64) error {
 		return fmt.Errorf("数据库未初始化")
 	}
 
-	_, err := db.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	before := snapshotTask(id)
+	childIDs, err := queryTaskIDs(`SELECT id FROM tasks WHERE parent_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+	childBefores := make(map[int64]map[string]interface{}, len(childIDs))
+	for _, childID := range childIDs {
+		childBefores[childID] = snapshotTask(childID)
+	}
+
+	// 子任务上移到被删除任务的父任务下
+	_, err = db.Exec(`
+		UPDATE tasks SET parent_id = (SELECT parent_id FROM tasks WHERE id = ?)
+		WHERE parent_id = ?
+	`, id, id)
+	if err != nil {
+		log.Printf("调整子任务失败: %v", err)
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+	for _, childID := range childIDs {
+		auditSystemTask(childID, AuditActionUpdate, childBefores[childID])
+	}
+
+	_, err = db.Exec(`DELETE FROM task_dependencies WHERE task_id = ? OR depends_on_id = ?`, id, id)
+	if err != nil {
+		log.Printf("删除任务依赖失败: %v", err)
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+
+	_, err = db.Exec(`DELETE FROM time_entries WHERE task_id = ?`, id)
+	if err != nil {
+		log.Printf("删除工时记录失败: %v", err)
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+
+	_, err = db.Exec(`DELETE FROM task_reschedules WHERE task_id = ?`, id)
+	if err != nil {
+		log.Printf("删除改期记录失败: %v", err)
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+
+	_, err = db.Exec(`DELETE FROM task_tags WHERE task_id = ?`, id)
+	if err != nil {
+		log.Printf("删除任务标签失败: %v", err)
+		return fmt.Errorf("删除任务失败: %v", err)
+	}
+
+	// 删除的重复任务实例记为例外，不再重新生成
+	_, err = db.Exec(`
+		INSERT OR IGNORE INTO recurrence_exceptions (recurrence_id, date)
+		SELECT recurrence_id, occurrence_date FROM tasks
+		WHERE id = ? AND recurrence_id IS NOT NULL AND occurrence_date IS NOT NULL
+	`, id)
+	if err != nil {
+		log.Printf("记录重复任务例外失败: %v", err)
+	}
+
+	_, err = db.Exec(`DELETE FROM tasks WHERE id = ?`, id)
 	if err != nil {
 		log.Printf("删除任务失败: %v", err)
 		return fmt.Errorf("删除任务失败: %v", err)
 	}
+	a.auditTask(id, AuditActionDelete, before)
 
 	log.Printf("删除任务成功: ID=%d", id)
 	return nil
 }
 
-// AssignTaskToDate 将任务分配到指定日期
-func (a *App) AssignTaskToDate(taskID int64, date string) error {
+// AssignTaskToDate 将任务分配到指定日期，返回与前置任务日期冲突及当天工时超载的提醒
+func (a *App) AssignTaskToDate(taskID int64, date string) ([]string, error) {
 	if db == nil {
-		return fmt.Errorf("数据库未初始化")
+		return nil, fmt.Errorf("数据库未初始化")
 	}
 
+	before := snapshotTask(taskID)
+	oldDate := currentTaskDate(taskID)
 	status := TaskStatusScheduled
 	_, err := db.Exec(`
 		UPDATE tasks SET date = ?, status = ? WHERE id = ?
 	`, date, status, taskID)
 	if err != nil {
 		log.Printf("分配任务日期失败: %v", err)
-		return fmt.Errorf("分配任务日期失败: %v", err)
+		return nil, fmt.Errorf("分配任务日期失败: %v", err)
+	}
+	recordReschedule(taskID, oldDate, &date, RescheduleReasonEdit)
+	a.auditTask(taskID, AuditActionUpdate, before)
+
+	warnings, err := dependencyScheduleWarnings(taskID, date)
+	if err != nil {
+		log.Printf("检查前置任务失败: %v", err)
 	}
+	warnings = append(warnings, capacityWarnings(date)...)
 
 	log.Printf("任务 %d 已分配到 %s", taskID, date)
-	return nil
+	return warnings, nil
 }
 
 // UpdateTaskStatus 更新任务状态（简单状态切换，不记录实际工时）
@@ -302,11 +480,26 @@ func (a *App) UpdateTaskStatus(id int64, status string) error {
 		return fmt.Errorf("数据库未初始化")
 	}
 
+	if status == TaskStatusCompleted {
+		if err := checkCanComplete(id); err != nil {
+			return err
+		}
+		if err := stopTaskTimer(id); err != nil {
+			return err
+		}
+	}
+
+	before := snapshotTask(id)
 	_, err := db.Exec(`UPDATE tasks SET status = ? WHERE id = ?`, status, id)
 	if err != nil {
 		log.Printf("更新任务状态失败: %v", err)
 		return fmt.Errorf("更新任务状态失败: %v", err)
 	}
+	a.auditTask(id, AuditActionUpdate, before)
+
+	if status == TaskStatusCompleted {
+		autoCompleteParents(id)
+	}
 
 	log.Printf("任务 %d 状态已更新为 %s", id, status)
 	return nil
@@ -318,20 +511,98 @@ func (a *App) CompleteTask(input CompleteTaskInput) error {
 		return fmt.Errorf("数据库未初始化")
 	}
 
-	_, err := db.Exec(`
-		UPDATE tasks
-		SET status = ?, actual_start = ?, actual_hours = ?
-		WHERE id = ?
-	`, TaskStatusCompleted, input.ActualStart, input.ActualHours, input.ID)
+	if err := checkCanComplete(input.ID); err != nil {
+		return err
+	}
+	if err := stopTaskTimer(input.ID); err != nil {
+		return err
+	}
+
+	// 没有工时记录时，填写的实际工时记为一条手动记录；已有记录时以记录为准（实际工时由系统汇总）
+	if countTimeEntries(input.ID) == 0 && input.ActualHours > 0 {
+		if err := addManualTimeEntry(input.ID, completionStartTime(input), input.ActualHours, ""); err != nil {
+			return err
+		}
+	}
+
+	before := snapshotTask(input.ID)
+	var err error
+	if countTimeEntries(input.ID) > 0 {
+		_, err = db.Exec(`UPDATE tasks SET status = ? WHERE id = ?`, TaskStatusCompleted, input.ID)
+	} else {
+		_, err = db.Exec(`
+			UPDATE tasks
+			SET status = ?, actual_start = ?, actual_hours = ?
+			WHERE id = ?
+		`, TaskStatusCompleted, input.ActualStart, input.ActualHours, input.ID)
+	}
 	if err != nil {
 		log.Printf("完成任务失败: %v", err)
 		return fmt.Errorf("完成任务失败: %v", err)
 	}
+	a.auditTask(input.ID, AuditActionUpdate, before)
 
-	log.Printf("任务 %d 已完成，实际工时: %.1f", input.ID, input.ActualHours)
+	autoCompleteParents(input.ID)
+
+	log.Printf("任务 %d 已完成", input.ID)
 	return nil
 }
 
+// LogTaskHours 为任务补记一段截止到当前时间的工时（未开始的任务转为进行中）
+func (a *App) LogTaskHours(taskID int64, hours float64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if hours <= 0 {
+		return fmt.Errorf("工时必须大于0")
+	}
+
+	before := snapshotTask(taskID)
+	result, err := db.Exec(`
+		UPDATE tasks
+		SET status = CASE WHEN status = ? THEN status ELSE ? END
+		WHERE id = ?
+	`, TaskStatusCompleted, TaskStatusInProgress, taskID)
+	if err != nil {
+		log.Printf("记录工时失败: %v", err)
+		return fmt.Errorf("记录工时失败: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("任务不存在: ID=%d", taskID)
+	}
+
+	a.auditTask(taskID, AuditActionUpdate, before)
+
+	// 实际工时由工时记录汇总（以系统身份记录）
+	if err := addManualTimeEntry(taskID, nil, hours, ""); err != nil {
+		return err
+	}
+
+	log.Printf("任务 %d 记录工时: %.1f", taskID, hours)
+	return nil
+}
+
+// completionStartTime 完成任务时填写的实际开始时间（任务日期 + HH:MM），未填写返回 nil
+func completionStartTime(input CompleteTaskInput) *time.Time {
+	if input.ActualStart == nil || *input.ActualStart == "" {
+		return nil
+	}
+
+	date := time.Now().Format("2006-01-02")
+	var taskDate *string
+	db.QueryRow(`SELECT date FROM tasks WHERE id = ?`, input.ID).Scan(&taskDate)
+	if taskDate != nil && *taskDate != "" {
+		date = *taskDate
+	}
+
+	start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+*input.ActualStart, time.Local)
+	if err != nil {
+		return nil
+	}
+	return &start
+}
+
 // CalculateHours 根据开始和结束时间计算工时
 func (a *App) CalculateHours(startTime, endTime string) float64 {
 	start, err1 := time.Parse("15:04", startTime)
@@ -388,6 +659,14 @@ func (a *App) GetWorkbenchData() (*WorkbenchData, error) {
 		log.Printf("查询待办任务数失败: %v", err)
 	}
 
+	// 今日可用工时
+	var availableHours float64
+	if capacities, err := loadCapacities(today, today); err != nil {
+		log.Printf("读取工作日历失败: %v", err)
+	} else {
+		availableHours = capacities[today].Available
+	}
+
 	return &WorkbenchData{
 		TodayTasks:     todayTasks,
 		TotalCount:     totalCount,
@@ -395,5 +674,7 @@ func (a *App) GetWorkbenchData() (*WorkbenchData, error) {
 		PlannedHours:   plannedHours,
 		CompletedHours: completedHours,
 		PendingCount:   pendingCount,
+		AvailableHours: availableHours,
+		Overloaded:     plannedHours > availableHours,
 	}, nil
 }
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	capacityChunkDays = 90      // 推算完成日期时每次加载的日历天数
+	maxProjectionDays = 366 * 3 // 推算完成日期的最大范围
+)
+
+// AddTaskDependency 添加依赖：taskID 在 dependsOnID 完成后才能开始
+func (a *App) AddTaskDependency(taskID, dependsOnID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if taskID == dependsOnID {
+		return fmt.Errorf("任务不能依赖自己")
+	}
+	if _, err := a.GetTask(taskID); err != nil {
+		return err
+	}
+	if _, err := a.GetTask(dependsOnID); err != nil {
+		return fmt.Errorf("前置任务不存在: %v", err)
+	}
+
+	// 若 dependsOnID 已直接或间接依赖 taskID，添加后会形成环
+	cycle, err := dependencyReachable(dependsOnID, taskID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return fmt.Errorf("添加该依赖会形成循环依赖")
+	}
+
+	_, err = db.Exec(`INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)`,
+		taskID, dependsOnID)
+	if err != nil {
+		log.Printf("添加任务依赖失败: %v", err)
+		return fmt.Errorf("添加任务依赖失败: %v", err)
+	}
+
+	log.Printf("任务 %d 依赖任务 %d", taskID, dependsOnID)
+	return nil
+}
+
+// RemoveTaskDependency 删除依赖
+func (a *App) RemoveTaskDependency(taskID, dependsOnID int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	_, err := db.Exec(`DELETE FROM task_dependencies WHERE task_id = ? AND depends_on_id = ?`, taskID, dependsOnID)
+	if err != nil {
+		log.Printf("删除任务依赖失败: %v", err)
+		return fmt.Errorf("删除任务依赖失败: %v", err)
+	}
+
+	log.Printf("任务 %d 不再依赖任务 %d", taskID, dependsOnID)
+	return nil
+}
+
+// GetTaskDependencies 获取任务的前置任务和后续任务
+func (a *App) GetTaskDependencies(taskID int64) (*TaskDependencies, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	predecessors, err := queryTasks(taskSelectSQL+`
+		WHERE t.id IN (SELECT depends_on_id FROM task_dependencies WHERE task_id = ?)
+		ORDER BY t.date NULLS LAST, t.id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	successors, err := queryTasks(taskSelectSQL+`
+		WHERE t.id IN (SELECT task_id FROM task_dependencies WHERE depends_on_id = ?)
+		ORDER BY t.date NULLS LAST, t.id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if predecessors == nil {
+		predecessors = []Task{}
+	}
+	if successors == nil {
+		successors = []Task{}
+	}
+	return &TaskDependencies{Predecessors: predecessors, Successors: successors}, nil
+}
+
+// queryTasks 查询任务列表
+func queryTasks(query string, args ...interface{}) ([]Task, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("查询任务失败: %v", err)
+		return nil, fmt.Errorf("查询任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+// loadDependencies 加载全部依赖（任务ID → 前置任务ID）
+func loadDependencies() (map[int64][]int64, error) {
+	rows, err := db.Query(`SELECT task_id, depends_on_id FROM task_dependencies ORDER BY task_id, depends_on_id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询任务依赖失败: %v", err)
+	}
+	defer rows.Close()
+
+	deps := make(map[int64][]int64)
+	for rows.Next() {
+		var taskID, dependsOnID int64
+		if err := rows.Scan(&taskID, &dependsOnID); err != nil {
+			return nil, fmt.Errorf("扫描任务依赖失败: %v", err)
+		}
+		deps[taskID] = append(deps[taskID], dependsOnID)
+	}
+	return deps, nil
+}
+
+// dependencyReachable 判断从 from 沿前置任务能否到达 target
+func dependencyReachable(from, target int64) (bool, error) {
+	deps, err := loadDependencies()
+	if err != nil {
+		return false, err
+	}
+
+	visited := map[int64]bool{from: true}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			return true, nil
+		}
+		for _, pred := range deps[current] {
+			if !visited[pred] {
+				visited[pred] = true
+				queue = append(queue, pred)
+			}
+		}
+	}
+	return false, nil
+}
+
+// attachTaskBlocked 填充任务的阻塞状态（存在未完成的前置任务）
+func attachTaskBlocked(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`
+		SELECT d.task_id, d.depends_on_id
+		FROM task_dependencies d
+		JOIN tasks p ON p.id = d.depends_on_id
+		WHERE d.task_id IN (`+taskIDList(tasks)+`) AND p.status != ?
+		ORDER BY d.task_id, d.depends_on_id
+	`, TaskStatusCompleted)
+	if err != nil {
+		return fmt.Errorf("查询任务依赖失败: %v", err)
+	}
+	defer rows.Close()
+
+	open := make(map[int64][]int64)
+	for rows.Next() {
+		var taskID, dependsOnID int64
+		if err := rows.Scan(&taskID, &dependsOnID); err != nil {
+			return fmt.Errorf("扫描任务依赖失败: %v", err)
+		}
+		open[taskID] = append(open[taskID], dependsOnID)
+	}
+
+	for i := range tasks {
+		tasks[i].BlockedBy = open[tasks[i].ID]
+		if tasks[i].BlockedBy == nil {
+			tasks[i].BlockedBy = []int64{}
+		}
+		tasks[i].Blocked = len(tasks[i].BlockedBy) > 0
+	}
+	return nil
+}
+
+// dependencyScheduleWarnings 检查任务安排的日期是否早于未完成的前置任务
+func dependencyScheduleWarnings(taskID int64, date string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT p.name, p.date
+		FROM task_dependencies d
+		JOIN tasks p ON p.id = d.depends_on_id
+		WHERE d.task_id = ? AND p.status != ?
+		ORDER BY p.date NULLS FIRST, p.id
+	`, taskID, TaskStatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("查询前置任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	warnings := []string{}
+	for rows.Next() {
+		var name string
+		var predDate *string
+		if err := rows.Scan(&name, &predDate); err != nil {
+			return nil, fmt.Errorf("扫描前置任务失败: %v", err)
+		}
+		if predDate == nil || *predDate == "" {
+			warnings = append(warnings, fmt.Sprintf("前置任务「%s」尚未安排日期", name))
+		} else if *predDate > date {
+			warnings = append(warnings, fmt.Sprintf("前置任务「%s」安排在 %s，晚于 %s", name, *predDate, date))
+		}
+	}
+	return warnings, nil
+}
+
+// GetProjectCriticalPath 计算项目未完成任务的关键路径（projectID 为 0 表示未分类任务）
+func (a *App) GetProjectCriticalPath(projectID int64) (*CriticalPath, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	tasks, err := a.GetTasksByProject(projectID, "")
+	if err != nil {
+		return nil, err
+	}
+	deps, err := loadDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CriticalPath{ProjectID: projectID, Tasks: []CriticalPathTask{}, Path: []int64{}}
+
+	// 只计算未完成的任务，项目外和已完成的前置任务视为已满足
+	nodes := make(map[int64]*CriticalPathTask)
+	var ids []int64
+	for _, t := range tasks {
+		if t.Status == TaskStatusCompleted {
+			continue
+		}
+		nodes[t.ID] = &CriticalPathTask{Task: t}
+		ids = append(ids, t.ID)
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	preds := make(map[int64][]int64)
+	succs := make(map[int64][]int64)
+	indegree := make(map[int64]int)
+	for _, id := range ids {
+		for _, pred := range deps[id] {
+			if _, ok := nodes[pred]; ok {
+				preds[id] = append(preds[id], pred)
+				succs[pred] = append(succs[pred], id)
+				indegree[id]++
+			}
+		}
+	}
+
+	// 拓扑排序（Kahn），同时计算最早开始/完成
+	var order []int64
+	queue := []int64{}
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+
+		node := nodes[id]
+		for _, pred := range preds[id] {
+			node.EarliestStart = math.Max(node.EarliestStart, nodes[pred].EarliestFinish)
+		}
+		node.EarliestFinish = node.EarliestStart + node.Task.Hours
+
+		for _, succ := range succs[id] {
+			indegree[succ]--
+			if indegree[succ] == 0 {
+				queue = append(queue, succ)
+			}
+		}
+	}
+	if len(order) != len(ids) {
+		return nil, fmt.Errorf("项目任务存在循环依赖")
+	}
+
+	var end float64
+	for _, id := range order {
+		end = math.Max(end, nodes[id].EarliestFinish)
+	}
+
+	// 反向计算最晚开始/完成
+	for i := len(order) - 1; i >= 0; i-- {
+		node := nodes[order[i]]
+		node.LatestFinish = end
+		for _, succ := range succs[order[i]] {
+			node.LatestFinish = math.Min(node.LatestFinish, nodes[succ].LatestStart)
+		}
+		node.LatestStart = node.LatestFinish - node.Task.Hours
+		node.Slack = roundHours(node.LatestStart - node.EarliestStart)
+		node.Critical = node.Slack == 0
+	}
+
+	timeline, err := loadCapacityTimeline(time.Now(), end)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range order {
+		node := nodes[id]
+		node.ProjectedDate = timeline.finishDate(node.EarliestFinish)
+		if node.Task.Deadline != nil && *node.Task.Deadline != "" && node.ProjectedDate > *node.Task.Deadline {
+			node.Late = true
+			result.LateTasks++
+		}
+		result.Tasks = append(result.Tasks, *node)
+	}
+
+	// 从最晚完成的关键任务沿关键前置任务回溯出关键路径
+	var last *CriticalPathTask
+	for _, id := range order {
+		node := nodes[id]
+		if node.Critical && (last == nil || node.EarliestFinish > last.EarliestFinish) {
+			last = node
+		}
+	}
+	for last != nil {
+		result.Path = append([]int64{last.Task.ID}, result.Path...)
+		var prev *CriticalPathTask
+		for _, pred := range preds[last.Task.ID] {
+			node := nodes[pred]
+			if node.Critical && roundHours(node.EarliestFinish-last.EarliestStart) == 0 {
+				prev = node
+				break
+			}
+		}
+		last = prev
+	}
+
+	result.TotalHours = roundHours(end)
+	result.ProjectedFinish = timeline.finishDate(end)
+	return result, nil
+}
+
+// capacityTimeline 从某天起逐日累计的可用工时（按工作日历，含节假日和请假）
+type capacityTimeline struct {
+	dates      []string
+	cumulative []float64
+}
+
+// loadCapacityTimeline 从 from 当天起加载日历，直到累计可用工时达到 hours 或超出推算范围
+func loadCapacityTimeline(from time.Time, hours float64) (*capacityTimeline, error) {
+	timeline := &capacityTimeline{}
+	var total float64
+	start := from
+	for len(timeline.dates) < maxProjectionDays {
+		end := start.AddDate(0, 0, capacityChunkDays-1)
+		capacities, err := loadCapacities(start.Format("2006-01-02"), end.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			date := d.Format("2006-01-02")
+			total += math.Max(capacities[date].Available, 0)
+			timeline.dates = append(timeline.dates, date)
+			timeline.cumulative = append(timeline.cumulative, roundHours(total))
+		}
+		if total >= roundHours(hours) {
+			break
+		}
+		start = end.AddDate(0, 0, 1)
+	}
+	return timeline, nil
+}
+
+// finishDate 累计可用工时达到 hours 的日期，超出推算范围时返回空
+func (t *capacityTimeline) finishDate(hours float64) string {
+	hours = roundHours(hours)
+	for i, c := range t.cumulative {
+		if c >= hours {
+			return t.dates[i]
+		}
+	}
+	return ""
+}
+
+// roundHours 工时保留两位小数，避免浮点误差
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddTaskDependencyRejectsCycle(t *testing.T) {
+	a := openTestDB(t)
+	design := createTestTask(t, a, TaskInput{Name: "设计", Hours: 1})
+	build := createTestTask(t, a, TaskInput{Name: "开发", Hours: 1})
+	release := createTestTask(t, a, TaskInput{Name: "发布", Hours: 1})
+
+	if err := a.AddTaskDependency(build.ID, design.ID); err != nil {
+		t.Fatalf("添加依赖失败: %v", err)
+	}
+	if err := a.AddTaskDependency(release.ID, build.ID); err != nil {
+		t.Fatalf("添加依赖失败: %v", err)
+	}
+	if err := a.AddTaskDependency(design.ID, release.ID); err == nil {
+		t.Fatal("间接成环的依赖应被拒绝")
+	}
+	if err := a.AddTaskDependency(design.ID, design.ID); err == nil {
+		t.Fatal("依赖自己应被拒绝")
+	}
+}
+
+func TestTaskBlockedByOpenPredecessors(t *testing.T) {
+	a := openTestDB(t)
+	first := createTestTask(t, a, TaskInput{Name: "准备数据", Hours: 1})
+	second := createTestTask(t, a, TaskInput{Name: "采购设备", Hours: 1})
+	task := createTestTask(t, a, TaskInput{Name: "跑实验", Hours: 1})
+	a.AddTaskDependency(task.ID, first.ID)
+	a.AddTaskDependency(task.ID, second.ID)
+
+	got, _ := a.GetTask(task.ID)
+	if !got.Blocked || len(got.BlockedBy) != 2 {
+		t.Fatalf("有两个未完成的前置任务时应被阻塞: %+v", got.BlockedBy)
+	}
+
+	a.UpdateTaskStatus(first.ID, TaskStatusCompleted)
+	got, _ = a.GetTask(task.ID)
+	if !got.Blocked || len(got.BlockedBy) != 1 || got.BlockedBy[0] != second.ID {
+		t.Fatalf("只剩采购设备未完成: %+v", got.BlockedBy)
+	}
+
+	a.UpdateTaskStatus(second.ID, TaskStatusCompleted)
+	got, _ = a.GetTask(task.ID)
+	if got.Blocked || len(got.BlockedBy) != 0 {
+		t.Fatalf("前置任务全部完成后不应阻塞: %+v", got.BlockedBy)
+	}
+}
+
+func TestProjectCriticalPathSlack(t *testing.T) {
+	a := openTestDB(t)
+	project, err := a.CreateProject("上线", "", "")
+	if err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	// A(4) → B(2) → D(1)，A(4) → C(6) → D(1)：关键路径 A-C-D，B 有 4 小时余量
+	taskA := createTestTask(t, a, TaskInput{Name: "A", Hours: 4, ProjectID: &project.ID})
+	taskB := createTestTask(t, a, TaskInput{Name: "B", Hours: 2, ProjectID: &project.ID})
+	taskC := createTestTask(t, a, TaskInput{Name: "C", Hours: 6, ProjectID: &project.ID})
+	taskD := createTestTask(t, a, TaskInput{Name: "D", Hours: 1, ProjectID: &project.ID})
+	for _, dep := range [][2]int64{{taskB.ID, taskA.ID}, {taskC.ID, taskA.ID}, {taskD.ID, taskB.ID}, {taskD.ID, taskC.ID}} {
+		if err := a.AddTaskDependency(dep[0], dep[1]); err != nil {
+			t.Fatalf("添加依赖失败: %v", err)
+		}
+	}
+
+	result, err := a.GetProjectCriticalPath(project.ID)
+	if err != nil {
+		t.Fatalf("计算关键路径失败: %v", err)
+	}
+	if result.TotalHours != 11 {
+		t.Fatalf("总工时应为11，实际 %v", result.TotalHours)
+	}
+	want := []int64{taskA.ID, taskC.ID, taskD.ID}
+	if len(result.Path) != len(want) {
+		t.Fatalf("关键路径应为 A-C-D，实际 %v", result.Path)
+	}
+	for i := range want {
+		if result.Path[i] != want[i] {
+			t.Fatalf("关键路径应为 A-C-D，实际 %v", result.Path)
+		}
+	}
+	for _, node := range result.Tasks {
+		wantSlack := 0.0
+		if node.Task.ID == taskB.ID {
+			wantSlack = 4
+		}
+		if node.Slack != wantSlack || node.Critical != (wantSlack == 0) {
+			t.Fatalf("任务 %s 的余量应为 %v，实际 %v", node.Task.Name, wantSlack, node.Slack)
+		}
+	}
+	if result.ProjectedFinish == "" {
+		t.Fatal("应推算出预计完成日期")
+	}
+}
+
+func TestCapacityTimelineFollowsWorkCalendar(t *testing.T) {
+	a := openTestDB(t)
+	// 2026-03-02 是周一，周二请假
+	if err := a.SetCalendarDay(CalendarDay{Date: "2026-03-03", Hours: 0, Kind: CalendarDayLeave}); err != nil {
+		t.Fatalf("设置日历失败: %v", err)
+	}
+	from := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	timeline, err := loadCapacityTimeline(from, 48)
+	if err != nil {
+		t.Fatalf("加载日历失败: %v", err)
+	}
+
+	cases := []struct {
+		hours float64
+		want  string
+	}{
+		{0, "2026-03-02"},
+		{8, "2026-03-02"},
+		{8.5, "2026-03-04"}, // 跳过请假的周二
+		{20, "2026-03-05"},
+		{33, "2026-03-09"}, // 跳过周末
+		{48, "2026-03-10"},
+	}
+	for _, c := range cases {
+		if got := timeline.finishDate(c.hours); got != c.want {
+			t.Errorf("%v 小时应在 %s 完成，实际 %s", c.hours, c.want, got)
+		}
+	}
+}
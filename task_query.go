@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTaskPageSize = 50  // 默认每页条数
+	maxTaskPageSize     = 500 // 每页条数上限
+)
+
+// taskSortFields 可排序字段及其排序表达式（空值折算为排在最后的值，保证游标比较有效）
+var taskSortFields = map[string]string{
+	"id":         "t.id",
+	"name":       "t.name",
+	"project":    "COALESCE(p.name, '')",
+	"date":       "COALESCE(t.date, '9999-12-31')",
+	"start_time": "COALESCE(t.start_time, '99:99')",
+	"deadline":   "COALESCE(t.deadline, '9999-12-31')",
+	"priority":   "CASE t.priority WHEN 'high' THEN 3 WHEN 'low' THEN 1 ELSE 2 END",
+	"urgency":    "CASE t.urgency WHEN 'high' THEN 3 WHEN 'low' THEN 1 ELSE 2 END",
+	"status":     "t.status",
+	"hours":      "COALESCE(t.hours, 0)",
+	"created_at": "datetime(t.created_at)",
+}
+
+// taskCursor 分页游标：上一页最后一个任务的排序值和ID
+type taskCursor struct {
+	SortBy string      `json:"s"`
+	Desc   bool        `json:"d"`
+	Value  interface{} `json:"v"`
+	ID     int64       `json:"id"`
+}
+
+// QueryTasks 按条件查询任务，支持排序和游标分页
+func (a *App) QueryTasks(filter TaskFilter) (*TaskPage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	filter, where, args, err := resolveTaskFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "date"
+	}
+	sortExpr, ok := taskSortFields[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("不支持的排序字段: %s", sortBy)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultTaskPageSize
+	}
+	if limit > maxTaskPageSize {
+		limit = maxTaskPageSize
+	}
+
+	if filter.DateTo != "" {
+		ensureRecurringTasks(filter.DateFrom, filter.DateTo)
+	}
+
+	page := &TaskPage{Tasks: []Task{}}
+	if page.Total, err = countTasks(where, args); err != nil {
+		return nil, err
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	// 游标之后的任务
+	op, dir := ">", "ASC"
+	if filter.SortDesc {
+		op, dir = "<", "DESC"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeTaskCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != sortBy || cursor.Desc != filter.SortDesc {
+			return nil, fmt.Errorf("游标与排序方式不一致，请从第一页重新查询")
+		}
+		whereSQL += fmt.Sprintf(` AND (%s %s ? OR (%s = ? AND t.id %s ?))`, sortExpr, op, sortExpr, op)
+		args = append(args, cursor.Value, cursor.Value, cursor.ID)
+	}
+
+	// 多查一条用于判断是否还有下一页
+	tasks, err := queryTasks(taskSelectSQL+fmt.Sprintf(`
+		WHERE %s
+		ORDER BY %s %s, t.id %s
+		LIMIT ?
+	`, whereSQL, sortExpr, dir, dir), append(args, limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		last := tasks[len(tasks)-1]
+		cursor := taskCursor{SortBy: sortBy, Desc: filter.SortDesc, ID: last.ID}
+		err := db.QueryRow(`SELECT `+sortExpr+` FROM tasks t LEFT JOIN projects p ON t.project_id = p.id WHERE t.id = ?`, last.ID).Scan(&cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("生成分页游标失败: %v", err)
+		}
+		page.NextCursor = encodeTaskCursor(cursor)
+	}
+	if tasks != nil {
+		page.Tasks = tasks
+	}
+	return page, nil
+}
+
+// listTasks 按查询条件获取全部任务（不分页），orderBy 为 ORDER BY 子句
+func listTasks(filter TaskFilter, orderBy string) ([]Task, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	filter, where, args, err := resolveTaskFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.DateTo != "" {
+		ensureRecurringTasks(filter.DateFrom, filter.DateTo)
+	}
+	return queryTasks(taskSelectSQL+`WHERE `+strings.Join(where, " AND ")+` ORDER BY `+orderBy, args...)
+}
+
+// countTasksByFilter 统计符合查询条件的任务数
+func countTasksByFilter(filter TaskFilter) (int, error) {
+	_, where, args, err := resolveTaskFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	return countTasks(where, args)
+}
+
+// resolveTaskFilter 解析相对日期并生成 WHERE 条件
+func resolveTaskFilter(filter TaskFilter) (TaskFilter, []string, []interface{}, error) {
+	filter, err := resolveFilterDates(filter, time.Now())
+	if err != nil {
+		return filter, nil, nil, err
+	}
+	where, args, err := taskFilterConditions(filter)
+	return filter, where, args, err
+}
+
+// countTasks 统计满足 WHERE 条件的任务数
+func countTasks(where []string, args []interface{}) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM tasks t LEFT JOIN projects p ON t.project_id = p.id WHERE `+strings.Join(where, " AND "), args...).Scan(&count)
+	if err != nil {
+		log.Printf("统计任务数失败: %v", err)
+		return 0, fmt.Errorf("统计任务数失败: %v", err)
+	}
+	return count, nil
+}
+
+// taskFilterConditions 把查询条件转换为 WHERE 条件（表别名 t 为任务，p 为项目）
+func taskFilterConditions(f TaskFilter) ([]string, []interface{}, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	in := func(column string, values []interface{}) {
+		if len(values) == 0 {
+			return
+		}
+		where = append(where, fmt.Sprintf("%s IN (%s)", column, strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")))
+		args = append(args, values...)
+	}
+	strs := func(values []string) []interface{} {
+		result := make([]interface{}, len(values))
+		for i, v := range values {
+			result[i] = v
+		}
+		return result
+	}
+	ids := make([]interface{}, len(f.ProjectIDs))
+	for i, id := range f.ProjectIDs {
+		ids[i] = id
+	}
+
+	in("COALESCE(t.project_id, 0)", ids)
+	in("t.status", strs(f.Statuses))
+	in("COALESCE(t.priority, 'medium')", strs(f.Priorities))
+	in("COALESCE(t.urgency, 'medium')", strs(f.Urgencies))
+
+	ranges := []struct {
+		column string
+		from   string
+		to     string
+	}{
+		{"t.date", f.DateFrom, f.DateTo},
+		{"t.deadline", f.DeadlineFrom, f.DeadlineTo},
+		{"date(t.created_at)", f.CreatedFrom, f.CreatedTo},
+	}
+	for _, r := range ranges {
+		for i, date := range []string{r.from, r.to} {
+			if date == "" {
+				continue
+			}
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return nil, nil, fmt.Errorf("日期格式错误: %s", date)
+			}
+			op := ">="
+			if i == 1 {
+				op = "<="
+			}
+			where = append(where, fmt.Sprintf("%s %s ?", r.column, op))
+			args = append(args, date)
+		}
+	}
+
+	if f.Scheduled != nil {
+		if *f.Scheduled {
+			where = append(where, "t.date IS NOT NULL")
+		} else {
+			where = append(where, "t.date IS NULL")
+		}
+	}
+	if f.Overdue {
+		where = append(where, "t.date < ? AND t.status != ?")
+		args = append(args, time.Now().Format("2006-01-02"), TaskStatusCompleted)
+	}
+	if f.ParentID != nil {
+		where = append(where, "t.parent_id = ?")
+		args = append(args, *f.ParentID)
+	}
+	if f.TopLevel {
+		where = append(where, "t.parent_id IS NULL")
+	}
+
+	if len(f.TagIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.TagIDs)), ", ")
+		if f.TagMatchAll {
+			where = append(where, fmt.Sprintf("(SELECT COUNT(DISTINCT tag_id) FROM task_tags WHERE task_id = t.id AND tag_id IN (%s)) = ?", placeholders))
+		} else {
+			where = append(where, fmt.Sprintf("t.id IN (SELECT task_id FROM task_tags WHERE tag_id IN (%s))", placeholders))
+		}
+		distinct := make(map[int64]bool)
+		for _, id := range f.TagIDs {
+			args = append(args, id)
+			distinct[id] = true
+		}
+		if f.TagMatchAll {
+			args = append(args, len(distinct))
+		}
+	}
+
+	// 文字条件复用全文索引，不足3个字的词用 LIKE
+	if text := strings.TrimSpace(f.Text); text != "" {
+		q := parseSearchQuery(text)
+		if q.hasRank {
+			where = append(where, "t.id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH ?)")
+			args = append(args, q.match)
+		}
+		likes, likeArgs := q.likeConditions("t.name", "t.description")
+		where = append(where, likes...)
+		args = append(args, likeArgs...)
+	}
+
+	return where, args, nil
+}
+
+// encodeTaskCursor 编码分页游标
+func encodeTaskCursor(c taskCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTaskCursor 解码分页游标
+func decodeTaskCursor(s string) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("分页游标无效")
+	}
+	var c taskCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("分页游标无效")
+	}
+	return &c, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestQueryTasksCursorPagination(t *testing.T) {
+	a := openTestDB(t)
+	for _, date := range []string{"2026-03-03", "2026-03-01", "2026-03-02", "2026-03-02", "2026-03-05"} {
+		createTestTask(t, a, TaskInput{Name: "任务" + date, Hours: 1, Date: &date})
+	}
+	createTestTask(t, a, TaskInput{Name: "待办", Hours: 1})
+
+	var dates []string
+	filter := TaskFilter{DateFrom: "2026-03-01", DateTo: "2026-03-31", Limit: 2}
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("分页没有结束")
+		}
+		result, err := a.QueryTasks(filter)
+		if err != nil {
+			t.Fatalf("查询任务失败: %v", err)
+		}
+		if result.Total != 5 {
+			t.Fatalf("总数应为5，实际 %d", result.Total)
+		}
+		for _, task := range result.Tasks {
+			dates = append(dates, *task.Date)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		filter.Cursor = result.NextCursor
+	}
+
+	want := []string{"2026-03-01", "2026-03-02", "2026-03-02", "2026-03-03", "2026-03-05"}
+	if len(dates) != len(want) {
+		t.Fatalf("分页结果应有 %d 条，实际 %v", len(want), dates)
+	}
+	for i := range want {
+		if dates[i] != want[i] {
+			t.Fatalf("分页结果顺序不符合预期: %v", dates)
+		}
+	}
+
+	// 游标与排序方式不一致时拒绝
+	filter.SortDesc = true
+	if _, err := a.QueryTasks(filter); err == nil {
+		t.Fatal("排序方式变化后沿用旧游标应返回错误")
+	}
+}
+
+func TestQueryTasksTagAndTextFilters(t *testing.T) {
+	a := openTestDB(t)
+	urgent, err := a.CreateTag("紧急", "")
+	if err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+	backend, err := a.CreateTag("后端", "")
+	if err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+	both := createTestTask(t, a, TaskInput{Name: "修复登录接口", Hours: 1})
+	onlyUrgent := createTestTask(t, a, TaskInput{Name: "回复客户邮件", Hours: 1})
+	createTestTask(t, a, TaskInput{Name: "整理登录文档", Hours: 1})
+	a.SetTaskTags(both.ID, []int64{urgent.ID, backend.ID})
+	a.SetTaskTags(onlyUrgent.ID, []int64{urgent.ID})
+
+	names := func(filter TaskFilter) map[string]bool {
+		t.Helper()
+		page, err := a.QueryTasks(filter)
+		if err != nil {
+			t.Fatalf("查询任务失败: %v", err)
+		}
+		result := make(map[string]bool)
+		for _, task := range page.Tasks {
+			result[task.Name] = true
+		}
+		return result
+	}
+
+	if got := names(TaskFilter{TagIDs: []int64{urgent.ID, backend.ID}}); len(got) != 2 {
+		t.Fatalf("带任一标签应返回2个任务: %v", got)
+	}
+	if got := names(TaskFilter{TagIDs: []int64{urgent.ID, backend.ID}, TagMatchAll: true}); len(got) != 1 || !got["修复登录接口"] {
+		t.Fatalf("带全部标签应只返回修复登录接口: %v", got)
+	}
+	// 2个字的中文走 LIKE，3个字以上走全文索引
+	if got := names(TaskFilter{Text: "登录"}); len(got) != 2 || got["回复客户邮件"] {
+		t.Fatalf("文字“登录”应匹配2个任务: %v", got)
+	}
+	if got := names(TaskFilter{Text: "客户邮件"}); len(got) != 1 || !got["回复客户邮件"] {
+		t.Fatalf("文字“客户邮件”应只匹配回复客户邮件: %v", got)
+	}
+	if got := names(TaskFilter{Text: "登录", TagIDs: []int64{urgent.ID}}); len(got) != 1 || !got["修复登录接口"] {
+		t.Fatalf("文字和标签条件应同时生效: %v", got)
+	}
+}
+
+func TestLegacyTaskListsUseFilter(t *testing.T) {
+	a := openTestDB(t)
+	past := "2020-01-01"
+	overdue := createTestTask(t, a, TaskInput{Name: "逾期", Hours: 1, Date: &past})
+	done := createTestTask(t, a, TaskInput{Name: "已完成", Hours: 1, Date: &past})
+	a.UpdateTaskStatus(done.ID, TaskStatusCompleted)
+	createTestTask(t, a, TaskInput{Name: "待办", Hours: 1})
+
+	tasks, err := a.GetOverdueTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].ID != overdue.ID {
+		t.Fatalf("逾期任务不符合预期: %v %+v", err, tasks)
+	}
+	tasks, err = a.GetPendingTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].Name != "待办" {
+		t.Fatalf("待办任务不符合预期: %v %+v", err, tasks)
+	}
+	tasks, err = a.GetTasksByDate(past)
+	if err != nil || len(tasks) != 2 || tasks[1].ID != done.ID {
+		t.Fatalf("按日期查询应返回2个任务且已完成的排在最后: %v %+v", err, tasks)
+	}
+}
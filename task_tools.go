@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// taskToolContext 任务管理工具的执行上下文（当前会话、任务和Agent权限）
+type taskToolContext struct {
+	app            *App
+	conversationID int64
+	taskID         int64  // 会话关联的任务
+	access         string // Agent的任务管理权限
+}
+
+// taskToolAccess 各任务管理工具需要的权限
+var taskToolAccess = map[string]string{
+	ToolListProjectTasks: TaskAccessRead,
+	ToolCreateTask:       TaskAccessWrite,
+	ToolUpdateTask:       TaskAccessWrite,
+	ToolLogTime:          TaskAccessWrite,
+	ToolCompleteTask:     TaskAccessWrite,
+}
+
+// taskAccessAllows 判断权限是否允许使用某个任务管理工具（非任务管理工具总是允许）
+func taskAccessAllows(access, toolName string) bool {
+	required, ok := taskToolAccess[toolName]
+	if !ok {
+		return true
+	}
+	switch required {
+	case TaskAccessRead:
+		return access == TaskAccessRead || access == TaskAccessWrite
+	case TaskAccessWrite:
+		return access == TaskAccessWrite
+	}
+	return false
+}
+
+// taskTools 任务管理工具定义
+func taskTools() []AgentTool {
+	return []AgentTool{
+		{
+			Name:        ToolListProjectTasks,
+			Description: "列出项目下的任务。默认为当前任务所属的项目。",
+			Type:        "builtin",
+			Schema: `{
+				"type": "object",
+				"properties": {
+					"project_id": {"type": "integer", "description": "项目ID，0 表示未分类任务，默认当前任务所属项目"},
+					"status": {"type": "string", "enum": ["pending", "scheduled", "in_progress", "completed"], "description": "只列出该状态的任务"}
+				}
+			}`,
+		},
+		{
+			Name:        ToolCreateTask,
+			Description: "创建任务（如拆分出的子任务）。默认归属当前任务所属的项目。",
+			Type:        "builtin",
+			Schema: `{
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "minLength": 1, "description": "任务名称"},
+					"description": {"type": "string", "description": "任务描述"},
+					"parent_id": {"type": "integer", "description": "父任务ID，创建子任务时填写"},
+					"date": {"type": "string", "description": "计划日期 YYYY-MM-DD，不填进入待办"},
+					"start_time": {"type": "string", "description": "计划开始时间 HH:MM"},
+					"end_time": {"type": "string", "description": "计划结束时间 HH:MM"},
+					"hours": {"type": "number", "minimum": 0, "description": "预计工时"},
+					"deadline": {"type": "string", "description": "截止日期 YYYY-MM-DD"},
+					"priority": {"type": "string", "enum": ["high", "medium", "low"], "description": "重要程度"},
+					"urgency": {"type": "string", "enum": ["high", "medium", "low"], "description": "紧急程度"}
+				},
+				"required": ["name"]
+			}`,
+		},
+		{
+			Name:        ToolUpdateTask,
+			Description: "更新任务的字段，只修改传入的字段。默认更新当前任务。",
+			Type:        "builtin",
+			Schema: `{
+				"type": "object",
+				"properties": {
+					"task_id": {"type": "integer", "description": "任务ID，默认当前任务"},
+					"name": {"type": "string", "minLength": 1, "description": "任务名称"},
+					"description": {"type": "string", "description": "任务描述"},
+					"date": {"type": "string", "description": "计划日期 YYYY-MM-DD"},
+					"hours": {"type": "number", "minimum": 0, "description": "预计工时"},
+					"deadline": {"type": "string", "description": "截止日期 YYYY-MM-DD"},
+					"priority": {"type": "string", "enum": ["high", "medium", "low"], "description": "重要程度"},
+					"urgency": {"type": "string", "enum": ["high", "medium", "low"], "description": "紧急程度"},
+					"status": {"type": "string", "enum": ["pending", "scheduled", "in_progress"], "description": "任务状态（完成请使用 complete_task）"}
+				}
+			}`,
+		},
+		{
+			Name:        ToolLogTime,
+			Description: "为任务记录实际花费的工时（累加）。默认记录到当前任务。",
+			Type:        "builtin",
+			Schema: `{
+				"type": "object",
+				"properties": {
+					"task_id": {"type": "integer", "description": "任务ID，默认当前任务"},
+					"hours": {"type": "number", "minimum": 0.1, "description": "本次花费的工时"}
+				},
+				"required": ["hours"]
+			}`,
+		},
+		{
+			Name:        ToolCompleteTask,
+			Description: "将任务标记为已完成。默认完成当前任务；完成当前任务后仍需调用 complete 结束会话。",
+			Type:        "builtin",
+			Schema: `{
+				"type": "object",
+				"properties": {
+					"task_id": {"type": "integer", "description": "任务ID，默认当前任务"},
+					"actual_hours": {"type": "number", "minimum": 0, "description": "实际工时，不填保留已记录的工时"},
+					"actual_start": {"type": "string", "description": "实际开始时间 HH:MM"}
+				}
+			}`,
+		},
+	}
+}
+
+// 任务管理工具输入
+type (
+	// ListProjectTasksInput list_project_tasks 工具输入
+	ListProjectTasksInput struct {
+		ProjectID *int64 `json:"project_id"`
+		Status    string `json:"status"`
+	}
+
+	// CreateTaskToolInput create_task 工具输入
+	CreateTaskToolInput struct {
+		Name        string  `json:"name"`
+		Description string  `json:"description"`
+		ParentID    *int64  `json:"parent_id"`
+		Date        *string `json:"date"`
+		StartTime   *string `json:"start_time"`
+		EndTime     *string `json:"end_time"`
+		Hours       float64 `json:"hours"`
+		Deadline    *string `json:"deadline"`
+		Priority    string  `json:"priority"`
+		Urgency     string  `json:"urgency"`
+	}
+
+	// UpdateTaskToolInput update_task 工具输入（指针字段为 nil 表示不修改）
+	UpdateTaskToolInput struct {
+		TaskID      int64    `json:"task_id"`
+		Name        *string  `json:"name"`
+		Description *string  `json:"description"`
+		Date        *string  `json:"date"`
+		Hours       *float64 `json:"hours"`
+		Deadline    *string  `json:"deadline"`
+		Priority    *string  `json:"priority"`
+		Urgency     *string  `json:"urgency"`
+		Status      *string  `json:"status"`
+	}
+
+	// LogTimeInput log_time 工具输入
+	LogTimeInput struct {
+		TaskID int64   `json:"task_id"`
+		Hours  float64 `json:"hours"`
+	}
+
+	// CompleteTaskToolInput complete_task 工具输入
+	CompleteTaskToolInput struct {
+		TaskID      int64    `json:"task_id"`
+		ActualHours *float64 `json:"actual_hours"`
+		ActualStart *string  `json:"actual_start"`
+	}
+)
+
+// SetTaskContext 设置任务管理工具的上下文，未设置时任务管理工具不可用
+func (e *ToolExecutor) SetTaskContext(app *App, conversationID, taskID int64, access string) {
+	// 通过工具修改任务时，审计日志记录为该 Agent 会话的操作
+	agentApp := app.withActor(auditActor{actorType: AuditActorAgent, conversationID: &conversationID})
+	e.taskCtx = &taskToolContext{app: agentApp, conversationID: conversationID, taskID: taskID, access: access}
+}
+
+// executeTaskTool 执行任务管理工具
+func (e *ToolExecutor) executeTaskTool(toolName, inputJSON string) ToolResult {
+	ctx := e.taskCtx
+	if ctx == nil {
+		return ToolResult{Success: false, Error: "当前会话不支持任务管理工具"}
+	}
+	if !taskAccessAllows(ctx.access, toolName) {
+		return ToolResult{Success: false, Error: fmt.Sprintf("Agent没有使用 %s 的权限", toolName)}
+	}
+
+	var result interface{}
+	var err error
+	switch toolName {
+	case ToolListProjectTasks:
+		var input ListProjectTasksInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		result, err = ctx.listProjectTasks(input)
+	case ToolCreateTask:
+		var input CreateTaskToolInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		result, err = ctx.createTask(input)
+	case ToolUpdateTask:
+		var input UpdateTaskToolInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		result, err = ctx.updateTask(input)
+	case ToolLogTime:
+		var input LogTimeInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		result, err = ctx.logTime(input)
+	case ToolCompleteTask:
+		var input CompleteTaskToolInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		result, err = ctx.completeTask(input)
+	default:
+		return ToolResult{Success: false, Error: fmt.Sprintf("未知工具: %s", toolName)}
+	}
+
+	if err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	return ToolResult{Success: true, Output: string(output)}
+}
+
+// resolveTaskID 未指定任务ID时使用当前任务
+func (c *taskToolContext) resolveTaskID(taskID int64) int64 {
+	if taskID == 0 {
+		return c.taskID
+	}
+	return taskID
+}
+
+func (c *taskToolContext) listProjectTasks(input ListProjectTasksInput) ([]Task, error) {
+	var projectID int64
+	if input.ProjectID != nil {
+		projectID = *input.ProjectID
+	} else {
+		current, err := c.app.GetTask(c.taskID)
+		if err != nil {
+			return nil, err
+		}
+		if current.ProjectID != nil {
+			projectID = *current.ProjectID
+		}
+	}
+	tasks, err := c.app.GetTasksByProject(projectID, input.Status)
+	if tasks == nil && err == nil {
+		tasks = []Task{}
+	}
+	return tasks, err
+}
+
+func (c *taskToolContext) createTask(input CreateTaskToolInput) (*Task, error) {
+	current, err := c.app.GetTask(c.taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.app.CreateTask(TaskInput{
+		ProjectID:   current.ProjectID,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		ParentID:    input.ParentID,
+		Date:        emptyToNil(input.Date),
+		StartTime:   emptyToNil(input.StartTime),
+		EndTime:     emptyToNil(input.EndTime),
+		Hours:       input.Hours,
+		Deadline:    emptyToNil(input.Deadline),
+		Priority:    input.Priority,
+		Urgency:     input.Urgency,
+	})
+}
+
+func (c *taskToolContext) updateTask(input UpdateTaskToolInput) (*Task, error) {
+	taskID := c.resolveTaskID(input.TaskID)
+	t, err := c.app.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	update := TaskInput{
+		ID: t.ID, ProjectID: t.ProjectID, Name: t.Name, Description: t.Description,
+		Date: t.Date, StartTime: t.StartTime, EndTime: t.EndTime, Hours: t.Hours,
+		Deadline: t.Deadline, Priority: t.Priority, Urgency: t.Urgency, Status: t.Status,
+	}
+	if input.Name != nil {
+		update.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		update.Description = *input.Description
+	}
+	if input.Date != nil {
+		update.Date = emptyToNil(input.Date)
+		if update.Date != nil && update.Status == TaskStatusPending {
+			update.Status = TaskStatusScheduled
+		}
+	}
+	if input.Hours != nil {
+		update.Hours = *input.Hours
+	}
+	if input.Deadline != nil {
+		update.Deadline = emptyToNil(input.Deadline)
+	}
+	if input.Priority != nil {
+		update.Priority = *input.Priority
+	}
+	if input.Urgency != nil {
+		update.Urgency = *input.Urgency
+	}
+	if input.Status != nil {
+		update.Status = *input.Status
+	}
+
+	if err := c.app.UpdateTask(update); err != nil {
+		return nil, err
+	}
+	return c.app.GetTask(taskID)
+}
+
+func (c *taskToolContext) logTime(input LogTimeInput) (*Task, error) {
+	taskID := c.resolveTaskID(input.TaskID)
+	if err := c.app.LogTaskHours(taskID, input.Hours); err != nil {
+		return nil, err
+	}
+	return c.app.GetTask(taskID)
+}
+
+func (c *taskToolContext) completeTask(input CompleteTaskToolInput) (*Task, error) {
+	taskID := c.resolveTaskID(input.TaskID)
+	t, err := c.app.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	actualHours := t.ActualHours
+	if input.ActualHours != nil {
+		actualHours = *input.ActualHours
+	}
+	actualStart := t.ActualStart
+	if input.ActualStart != nil {
+		actualStart = emptyToNil(input.ActualStart)
+	}
+
+	if err := c.app.CompleteTask(CompleteTaskInput{ID: taskID, ActualStart: actualStart, ActualHours: actualHours}); err != nil {
+		return nil, err
+	}
+	return c.app.GetTask(taskID)
+}
+
+// emptyToNil 空字符串指针转为 nil
+func emptyToNil(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	return s
+}
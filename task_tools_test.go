@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newTestTaskToolExecutor 创建带任务管理上下文的工具执行器，返回执行器、会话ID和会话关联的任务ID
+func newTestTaskToolExecutor(t *testing.T, a *App, access string) (*ToolExecutor, int64, int64) {
+	t.Helper()
+	provider := insertTestProvider(t, a, "scripted", "http://127.0.0.1:1")
+	convID, _ := startTestConversation(t, a, AgentInput{ProviderID: &provider.ID})
+	taskID, err := getConversationTaskID(convID)
+	if err != nil {
+		t.Fatalf("查询会话任务失败: %v", err)
+	}
+	executor := NewToolExecutor(t.TempDir())
+	executor.SetTaskContext(a, convID, taskID, access)
+	return executor, convID, taskID
+}
+
+func TestTaskAccessAllows(t *testing.T) {
+	tests := []struct {
+		access string
+		tool   string
+		want   bool
+	}{
+		{TaskAccessNone, ToolListProjectTasks, false},
+		{TaskAccessRead, ToolListProjectTasks, true},
+		{TaskAccessRead, ToolCreateTask, false},
+		{TaskAccessRead, ToolCompleteTask, false},
+		{TaskAccessWrite, ToolCreateTask, true},
+		{TaskAccessWrite, ToolListProjectTasks, true},
+		{"", ToolUpdateTask, false},
+		{TaskAccessNone, ToolShell, true},
+	}
+	for _, tt := range tests {
+		if got := taskAccessAllows(tt.access, tt.tool); got != tt.want {
+			t.Errorf("taskAccessAllows(%q, %q) = %v，期望 %v", tt.access, tt.tool, got, tt.want)
+		}
+	}
+}
+
+func TestTaskToolsDeniedWithoutAccess(t *testing.T) {
+	a := openTestDB(t)
+	executor, _, taskID := newTestTaskToolExecutor(t, a, TaskAccessRead)
+
+	result := executor.Execute(ToolCompleteTask, `{}`)
+	if result.Success || !strings.Contains(result.Error, "权限") {
+		t.Fatalf("只读权限不能完成任务: %+v", result)
+	}
+	if task, _ := a.GetTask(taskID); task.Status == TaskStatusCompleted {
+		t.Fatal("权限不足时任务不应被修改")
+	}
+
+	result = executor.Execute(ToolListProjectTasks, `{}`)
+	if !result.Success || !strings.Contains(result.Output, "整理笔记") {
+		t.Fatalf("只读权限应能列出任务: %+v", result)
+	}
+}
+
+func TestTaskToolsCreateAndComplete(t *testing.T) {
+	a := openTestDB(t)
+	executor, convID, taskID := newTestTaskToolExecutor(t, a, TaskAccessWrite)
+
+	result := executor.Execute(ToolCreateTask, fmt.Sprintf(`{"name": "  拆分出的子任务 ", "parent_id": %d, "hours": 0.5}`, taskID))
+	if !result.Success {
+		t.Fatalf("创建任务失败: %s", result.Error)
+	}
+	var created Task
+	if err := json.Unmarshal([]byte(result.Output), &created); err != nil {
+		t.Fatalf("解析任务失败: %v", err)
+	}
+	if created.Name != "拆分出的子任务" || created.ParentID == nil || *created.ParentID != taskID {
+		t.Fatalf("创建的子任务不符合预期: %+v", created)
+	}
+
+	if result := executor.Execute(ToolCreateTask, `{"name": ""}`); result.Success {
+		t.Fatal("空名称应被参数校验拒绝")
+	}
+
+	result = executor.Execute(ToolCompleteTask, fmt.Sprintf(`{"task_id": %d, "actual_hours": 0.5}`, created.ID))
+	if !result.Success {
+		t.Fatalf("完成任务失败: %s", result.Error)
+	}
+	task, _ := a.GetTask(created.ID)
+	if task.Status != TaskStatusCompleted || task.ActualHours != 0.5 {
+		t.Fatalf("任务应已完成并记录实际工时: %s %v", task.Status, task.ActualHours)
+	}
+
+	// 通过工具的修改在审计日志中记录为该会话的 Agent 操作
+	history, err := a.GetTaskHistory(created.ID)
+	if err != nil || len(history) == 0 {
+		t.Fatalf("查询修改历史失败: %v", err)
+	}
+	var agentChanges int
+	for _, entry := range history {
+		if entry.ActorType == AuditActorAgent {
+			agentChanges++
+			if entry.ConversationID == nil || *entry.ConversationID != convID {
+				t.Errorf("Agent 的修改应记录会话ID: %+v", entry)
+			}
+		}
+	}
+	if agentChanges < 2 {
+		t.Fatalf("创建和完成都应记录为 Agent 操作，实际 %d 条", agentChanges)
+	}
+}
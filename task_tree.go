@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// maxTaskTreeDepth 沿父任务链向上遍历的最大层数，防止异常数据导致死循环
+const maxTaskTreeDepth = 100
+
+// normalizeCompletionRule 校验子任务完成规则
+func normalizeCompletionRule(rule string) (string, error) {
+	switch rule {
+	case "":
+		return CompletionRuleManual, nil
+	case CompletionRuleManual, CompletionRuleAuto, CompletionRuleBlock:
+		return rule, nil
+	}
+	return "", fmt.Errorf("不支持的子任务完成规则: %s", rule)
+}
+
+// attachTaskRollups 填充任务的子任务数量和工时汇总（只查询结果集中任务的子孙任务）
+func attachTaskRollups(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := taskIDList(tasks)
+
+	type childCount struct{ total, open int }
+	counts := make(map[int64]childCount)
+	rows, err := db.Query(`
+		SELECT parent_id, COUNT(*), COALESCE(SUM(status != ?), 0)
+		FROM tasks
+		WHERE parent_id IN (`+ids+`)
+		GROUP BY parent_id
+	`, TaskStatusCompleted)
+	if err != nil {
+		return fmt.Errorf("查询子任务失败: %v", err)
+	}
+	for rows.Next() {
+		var parentID int64
+		var c childCount
+		if err := rows.Scan(&parentID, &c.total, &c.open); err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描子任务失败: %v", err)
+		}
+		counts[parentID] = c
+	}
+	rows.Close()
+
+	// 逐级展开子孙任务（UNION 去重，父子关系成环时也能结束），汇总到结果集中的祖先任务
+	type hoursSum struct{ hours, actual float64 }
+	sums := make(map[int64]hoursSum)
+	rows, err = db.Query(`
+		WITH RECURSIVE sub(root, id) AS (
+			SELECT parent_id, id FROM tasks WHERE parent_id IN (` + ids + `)
+			UNION
+			SELECT sub.root, c.id FROM tasks c JOIN sub ON c.parent_id = sub.id
+		)
+		SELECT sub.root, COALESCE(SUM(t.hours), 0), COALESCE(SUM(t.actual_hours), 0)
+		FROM sub
+		JOIN tasks t ON t.id = sub.id
+		WHERE sub.id != sub.root
+		GROUP BY sub.root
+	`)
+	if err != nil {
+		return fmt.Errorf("查询子任务工时失败: %v", err)
+	}
+	for rows.Next() {
+		var rootID int64
+		var sum hoursSum
+		if err := rows.Scan(&rootID, &sum.hours, &sum.actual); err != nil {
+			rows.Close()
+			return fmt.Errorf("扫描子任务工时失败: %v", err)
+		}
+		sums[rootID] = sum
+	}
+	rows.Close()
+
+	for i := range tasks {
+		t := &tasks[i]
+		t.ChildCount = counts[t.ID].total
+		t.OpenChildCount = counts[t.ID].open
+		t.SubtaskHours = sums[t.ID].hours
+		t.SubtaskActualHours = sums[t.ID].actual
+	}
+	return nil
+}
+
+// checkCanComplete 父任务规则为 block 时，有未完成的子任务不能完成
+func checkCanComplete(taskID int64) error {
+	var rule string
+	err := db.QueryRow(`SELECT COALESCE(completion_rule, 'manual') FROM tasks WHERE id = ?`, taskID).Scan(&rule)
+	if err != nil || rule != CompletionRuleBlock {
+		return nil
+	}
+
+	var open int
+	db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE parent_id = ? AND status != ?`,
+		taskID, TaskStatusCompleted).Scan(&open)
+	if open > 0 {
+		return fmt.Errorf("还有 %d 个未完成的子任务，不能完成该任务", open)
+	}
+	return nil
+}
+
+// autoCompleteParents 任务完成后，规则为 auto 的父任务在子任务全部完成时自动完成（逐级向上）
+func autoCompleteParents(taskID int64) {
+	current := taskID
+	for depth := 0; depth < maxTaskTreeDepth; depth++ {
+		var parentID *int64
+		if err := db.QueryRow(`SELECT parent_id FROM tasks WHERE id = ?`, current).Scan(&parentID); err != nil || parentID == nil {
+			return
+		}
+
+		var rule, status string
+		if err := db.QueryRow(`SELECT COALESCE(completion_rule, 'manual'), status FROM tasks WHERE id = ?`,
+			*parentID).Scan(&rule, &status); err != nil {
+			return
+		}
+		if rule != CompletionRuleAuto || status == TaskStatusCompleted {
+			return
+		}
+
+		var open int
+		db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE parent_id = ? AND status != ?`,
+			*parentID, TaskStatusCompleted).Scan(&open)
+		if open > 0 {
+			return
+		}
+
+		before := snapshotTask(*parentID)
+		if _, err := db.Exec(`UPDATE tasks SET status = ? WHERE id = ?`, TaskStatusCompleted, *parentID); err != nil {
+			log.Printf("自动完成父任务失败: %v", err)
+			return
+		}
+		auditSystemTask(*parentID, AuditActionUpdate, before)
+		log.Printf("子任务全部完成，父任务 %d 自动完成", *parentID)
+		current = *parentID
+	}
+}
+
+// SetTaskParent 设置或清除（parentID 为 nil）任务的父任务
+func (a *App) SetTaskParent(taskID int64, parentID *int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := a.GetTask(taskID); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if *parentID == taskID {
+			return fmt.Errorf("不能将任务设为自己的子任务")
+		}
+		if _, err := a.GetTask(*parentID); err != nil {
+			return fmt.Errorf("父任务不存在: %v", err)
+		}
+
+		if err := checkParentCycle(taskID, *parentID); err != nil {
+			return err
+		}
+	}
+
+	before := snapshotTask(taskID)
+	_, err := db.Exec(`UPDATE tasks SET parent_id = ? WHERE id = ?`, parentID, taskID)
+	if err != nil {
+		log.Printf("设置父任务失败: %v", err)
+		return fmt.Errorf("设置父任务失败: %v", err)
+	}
+	a.auditTask(taskID, AuditActionUpdate, before)
+
+	log.Printf("任务 %d 的父任务设置为 %v", taskID, safeInt64(parentID))
+	return nil
+}
+
+// checkParentCycle 检查新父任务不是该任务的子孙任务（遍历带层数上限和访问记录）
+func checkParentCycle(taskID, parentID int64) error {
+	if parentID == taskID {
+		return fmt.Errorf("不能将任务设为自己的子任务")
+	}
+	visited := map[int64]bool{parentID: true}
+	current := parentID
+	for depth := 0; depth < maxTaskTreeDepth; depth++ {
+		var next *int64
+		if err := db.QueryRow(`SELECT parent_id FROM tasks WHERE id = ?`, current).Scan(&next); err != nil || next == nil {
+			return nil
+		}
+		if *next == taskID {
+			return fmt.Errorf("不能将任务移动到自己的子任务下")
+		}
+		if visited[*next] {
+			return fmt.Errorf("父任务链中存在循环，请先修复任务层级")
+		}
+		visited[*next] = true
+		current = *next
+	}
+	return fmt.Errorf("任务层级超过 %d 层", maxTaskTreeDepth)
+}
+
+// GetSubtasks 获取直接子任务
+func (a *App) GetSubtasks(taskID int64) ([]Task, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	rows, err := db.Query(taskSelectSQL+`
+		WHERE t.parent_id = ?
+		ORDER BY t.date NULLS LAST, t.start_time NULLS LAST, t.id
+	`, taskID)
+	if err != nil {
+		log.Printf("查询子任务失败: %v", err)
+		return nil, fmt.Errorf("查询子任务失败: %v", err)
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+// GetTaskTree 获取以任务为根的任务树
+func (a *App) GetTaskTree(taskID int64) (*TaskNode, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	root, err := a.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	forest, err := buildTaskForest([]Task{*root})
+	if err != nil {
+		return nil, err
+	}
+	return &forest[0], nil
+}
+
+// GetTasksByDateTree 按日期获取任务，并以树的形式附带它们的全部子任务
+func (a *App) GetTasksByDateTree(date string) ([]TaskNode, error) {
+	tasks, err := a.GetTasksByDate(date)
+	if err != nil {
+		return nil, err
+	}
+	return buildTaskForest(tasks)
+}
+
+// GetPendingTasksTree 获取待办任务，并以树的形式附带它们的全部子任务
+func (a *App) GetPendingTasksTree() ([]TaskNode, error) {
+	tasks, err := a.GetPendingTasks()
+	if err != nil {
+		return nil, err
+	}
+	return buildTaskForest(tasks)
+}
+
+// buildTaskForest 将查询结果组织为任务树：结果中父任务也在结果里的任务挂到父任务下，
+// 其余作为根节点（保持原有顺序），并补充未在结果中的子孙任务
+func buildTaskForest(tasks []Task) ([]TaskNode, error) {
+	if len(tasks) == 0 {
+		return []TaskNode{}, nil
+	}
+
+	byID := make(map[int64]Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	rows, err := db.Query(`
+		WITH RECURSIVE sub(id) AS (
+			SELECT id FROM tasks WHERE parent_id IN (` + taskIDList(tasks) + `)
+			UNION
+			SELECT c.id FROM tasks c JOIN sub ON c.parent_id = sub.id
+		)
+	` + taskSelectSQL + `
+		WHERE t.id IN (SELECT id FROM sub)
+		ORDER BY t.date NULLS LAST, t.start_time NULLS LAST, t.id
+	`)
+	if err != nil {
+		log.Printf("查询子任务失败: %v", err)
+		return nil, fmt.Errorf("查询子任务失败: %v", err)
+	}
+	descendants, err := scanTasks(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	childrenOf := make(map[int64][]Task)
+	for _, t := range descendants {
+		if _, ok := byID[t.ID]; ok {
+			continue
+		}
+		byID[t.ID] = t
+	}
+	for _, t := range append(tasks, descendants...) {
+		if t.ParentID == nil {
+			continue
+		}
+		if _, ok := byID[*t.ParentID]; ok && !containsTask(childrenOf[*t.ParentID], t.ID) {
+			childrenOf[*t.ParentID] = append(childrenOf[*t.ParentID], byID[t.ID])
+		}
+	}
+
+	var build func(t Task, visited map[int64]bool) TaskNode
+	build = func(t Task, visited map[int64]bool) TaskNode {
+		node := TaskNode{Task: t, Children: []TaskNode{}}
+		visited[t.ID] = true
+		for _, child := range childrenOf[t.ID] {
+			if !visited[child.ID] {
+				node.Children = append(node.Children, build(child, visited))
+			}
+		}
+		return node
+	}
+
+	forest := []TaskNode{}
+	visited := make(map[int64]bool)
+	for _, t := range tasks {
+		if t.ParentID != nil {
+			if _, ok := byID[*t.ParentID]; ok {
+				continue
+			}
+		}
+		forest = append(forest, build(t, visited))
+	}
+	return forest, nil
+}
+
+// containsTask 判断任务列表中是否已有该任务
+func containsTask(tasks []Task, id int64) bool {
+	for _, t := range tasks {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// safeInt64 安全获取整数指针的值（用于日志）
+func safeInt64(v *int64) string {
+	if v == nil {
+		return "无"
+	}
+	return fmt.Sprintf("%d", *v)
+}
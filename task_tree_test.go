@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+)
+
+// createTestTask 创建测试任务
+func createTestTask(t *testing.T, a *App, input TaskInput) *Task {
+	t.Helper()
+	task, err := a.CreateTask(input)
+	if err != nil {
+		t.Fatalf("创建任务 %s 失败: %v", input.Name, err)
+	}
+	return task
+}
+
+func TestTaskRollupsIncludeDescendants(t *testing.T) {
+	a := openTestDB(t)
+	root := createTestTask(t, a, TaskInput{Name: "发布", Hours: 1})
+	child := createTestTask(t, a, TaskInput{Name: "开发", Hours: 3, ParentID: &root.ID})
+	createTestTask(t, a, TaskInput{Name: "编码", Hours: 5, ParentID: &child.ID})
+	done := createTestTask(t, a, TaskInput{Name: "评审", Hours: 2, ParentID: &root.ID})
+	if err := a.UpdateTaskStatus(done.ID, TaskStatusCompleted); err != nil {
+		t.Fatalf("完成任务失败: %v", err)
+	}
+	createTestTask(t, a, TaskInput{Name: "无关任务", Hours: 8})
+
+	got, err := a.GetTask(root.ID)
+	if err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if got.ChildCount != 2 || got.OpenChildCount != 1 || got.SubtaskHours != 10 {
+		t.Fatalf("汇总不符合预期: child=%d open=%d hours=%v", got.ChildCount, got.OpenChildCount, got.SubtaskHours)
+	}
+
+	got, _ = a.GetTask(child.ID)
+	if got.ChildCount != 1 || got.SubtaskHours != 5 {
+		t.Fatalf("中间任务汇总不符合预期: child=%d hours=%v", got.ChildCount, got.SubtaskHours)
+	}
+}
+
+func TestTaskRollupsTerminateOnCycle(t *testing.T) {
+	a := openTestDB(t)
+	first := createTestTask(t, a, TaskInput{Name: "甲", Hours: 1})
+	second := createTestTask(t, a, TaskInput{Name: "乙", Hours: 2, ParentID: &first.ID})
+	// 绕过校验构造环
+	if _, err := db.Exec(`UPDATE tasks SET parent_id = ? WHERE id = ?`, second.ID, first.ID); err != nil {
+		t.Fatalf("构造环失败: %v", err)
+	}
+
+	got, err := a.GetTask(first.ID)
+	if err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if got.ChildCount != 1 || got.SubtaskHours != 2 {
+		t.Fatalf("成环时汇总不符合预期: child=%d hours=%v", got.ChildCount, got.SubtaskHours)
+	}
+}
+
+func TestSetTaskParentTerminatesOnExistingCycle(t *testing.T) {
+	a := openTestDB(t)
+	first := createTestTask(t, a, TaskInput{Name: "甲", Hours: 1})
+	second := createTestTask(t, a, TaskInput{Name: "乙", Hours: 1, ParentID: &first.ID})
+	other := createTestTask(t, a, TaskInput{Name: "丙", Hours: 1})
+	// 绕过校验构造不包含 other 的环
+	if _, err := db.Exec(`UPDATE tasks SET parent_id = ? WHERE id = ?`, second.ID, first.ID); err != nil {
+		t.Fatalf("构造环失败: %v", err)
+	}
+
+	if err := a.SetTaskParent(other.ID, &first.ID); err == nil {
+		t.Fatal("父任务链成环时应返回错误")
+	}
+	if err := a.SetTaskParent(first.ID, &other.ID); err != nil {
+		t.Fatalf("移到无关任务下应成功: %v", err)
+	}
+	if err := a.SetTaskParent(other.ID, &second.ID); err == nil {
+		t.Fatal("移动到自己的子孙任务下应返回错误")
+	}
+}
+
+func TestDecorateTasksOnlyForResult(t *testing.T) {
+	a := openTestDB(t)
+	pred := createTestTask(t, a, TaskInput{Name: "前置", Hours: 1})
+	task := createTestTask(t, a, TaskInput{Name: "后续", Hours: 1})
+	other := createTestTask(t, a, TaskInput{Name: "其他", Hours: 1})
+	if err := a.AddTaskDependency(task.ID, pred.ID); err != nil {
+		t.Fatalf("添加依赖失败: %v", err)
+	}
+	tag, err := a.CreateTag("后端", "")
+	if err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+	if err := setTaskTags(db, task.ID, []int64{tag.ID}); err != nil {
+		t.Fatalf("设置标签失败: %v", err)
+	}
+	from, to := "2026-01-01", "2026-01-05"
+	recordReschedule(task.ID, &from, &to, RescheduleReasonManual)
+
+	tasks := []Task{{ID: task.ID}, {ID: other.ID}}
+	if err := decorateTasks(tasks); err != nil {
+		t.Fatalf("填充计算字段失败: %v", err)
+	}
+	if !tasks[0].Blocked || len(tasks[0].Tags) != 1 || tasks[0].SlipCount != 1 {
+		t.Fatalf("任务计算字段不符合预期: %+v", tasks[0])
+	}
+	if tasks[1].Blocked || len(tasks[1].Tags) != 0 || tasks[1].SlipCount != 0 {
+		t.Fatalf("无关任务不应有计算字段: %+v", tasks[1])
+	}
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// timeEntryLayout 工时记录的时间格式（本地时间）
+const timeEntryLayout = "2006-01-02 15:04:05"
+
+const timeEntrySelectSQL = `
+	SELECT e.id, e.task_id, COALESCE(t.name, '') as task_name, t.project_id,
+		   e.start_at, e.end_at, COALESCE(e.source, 'timer'), COALESCE(e.paused, 0),
+		   COALESCE(e.note, ''), e.created_at
+	FROM time_entries e
+	LEFT JOIN tasks t ON e.task_id = t.id
+`
+
+// scanTimeEntry 扫描工时记录，并计算时长
+func scanTimeEntry(row interface{ Scan(...any) error }) (TimeEntry, error) {
+	var e TimeEntry
+	var paused int
+	err := row.Scan(&e.ID, &e.TaskID, &e.TaskName, &e.ProjectID, &e.StartAt, &e.EndAt,
+		&e.Source, &paused, &e.Note, &e.CreatedAt)
+	if err != nil {
+		return e, err
+	}
+	e.Paused = paused == 1
+
+	start, end, err := timeEntrySpan(e)
+	if err == nil {
+		e.Hours = roundHours(end.Sub(start).Hours())
+	}
+	return e, nil
+}
+
+// queryTimeEntries 查询工时记录列表
+func queryTimeEntries(query string, args ...interface{}) ([]TimeEntry, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("查询工时记录失败: %v", err)
+		return nil, fmt.Errorf("查询工时记录失败: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			log.Printf("扫描工时记录失败: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// timeEntrySpan 工时记录的起止时间（计时中的记录截止到当前时间）
+func timeEntrySpan(e TimeEntry) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(timeEntryLayout, e.StartAt, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始时间格式错误: %s", e.StartAt)
+	}
+	end := time.Now()
+	if e.EndAt != nil {
+		end, err = time.ParseInLocation(timeEntryLayout, *e.EndAt, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("结束时间格式错误: %s", *e.EndAt)
+		}
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end, nil
+}
+
+// parseEntryTime 解析输入的时间，支持 YYYY-MM-DD HH:MM[:SS]，统一为存储格式
+func parseEntryTime(s string) (string, error) {
+	s = strings.TrimSpace(strings.Replace(s, "T", " ", 1))
+	for _, layout := range []string{timeEntryLayout, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Format(timeEntryLayout), nil
+		}
+	}
+	return "", fmt.Errorf("时间格式错误: %s，应为 YYYY-MM-DD HH:MM", s)
+}
+
+// StartTimer 开始为任务计时（同一时间只有一个计时器，其他任务的计时会先停止）
+func (a *App) StartTimer(taskID int64) (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	task, err := a.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := runningTimeEntry()
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		if running.TaskID == taskID {
+			return running, nil
+		}
+		if err := closeTimeEntry(running, false); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := db.Exec(`INSERT INTO time_entries (task_id, start_at, source) VALUES (?, ?, ?)`,
+		taskID, time.Now().Format(timeEntryLayout), TimeEntrySourceTimer)
+	if err != nil {
+		log.Printf("开始计时失败: %v", err)
+		return nil, fmt.Errorf("开始计时失败: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取工时记录ID失败: %v", err)
+	}
+
+	if task.Status != TaskStatusCompleted && task.Status != TaskStatusInProgress {
+		before := snapshotTask(taskID)
+		if _, err := db.Exec(`UPDATE tasks SET status = ? WHERE id = ?`, TaskStatusInProgress, taskID); err != nil {
+			log.Printf("更新任务状态失败: %v", err)
+		} else {
+			auditSystemTask(taskID, AuditActionUpdate, before)
+		}
+	}
+
+	log.Printf("任务 %d 开始计时", taskID)
+	return getTimeEntry(id)
+}
+
+// PauseTimer 暂停当前计时（可用 ResumeTimer 继续）
+func (a *App) PauseTimer() (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	running, err := runningTimeEntry()
+	if err != nil {
+		return nil, err
+	}
+	if running == nil {
+		return nil, fmt.Errorf("没有正在计时的任务")
+	}
+	if err := closeTimeEntry(running, true); err != nil {
+		return nil, err
+	}
+
+	log.Printf("任务 %d 暂停计时", running.TaskID)
+	return getTimeEntry(running.ID)
+}
+
+// ResumeTimer 继续最近暂停的计时
+func (a *App) ResumeTimer() (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	latest, err := latestTimeEntry()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.EndAt == nil || !latest.Paused {
+		return nil, fmt.Errorf("没有已暂停的计时")
+	}
+	return a.StartTimer(latest.TaskID)
+}
+
+// StopTimer 停止计时（计时中或已暂停），返回最后一段工时记录
+func (a *App) StopTimer() (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	latest, err := latestTimeEntry()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || (latest.EndAt != nil && !latest.Paused) {
+		return nil, fmt.Errorf("没有进行中的计时")
+	}
+
+	if latest.EndAt == nil {
+		if err := closeTimeEntry(latest, false); err != nil {
+			return nil, err
+		}
+	} else {
+		// 已暂停：清除暂停标记，计时结束
+		if _, err := db.Exec(`UPDATE time_entries SET paused = 0 WHERE id = ?`, latest.ID); err != nil {
+			log.Printf("停止计时失败: %v", err)
+			return nil, fmt.Errorf("停止计时失败: %v", err)
+		}
+	}
+
+	log.Printf("任务 %d 停止计时", latest.TaskID)
+	return getTimeEntry(latest.ID)
+}
+
+// GetActiveTimer 获取当前计时器，没有计时中或已暂停的计时返回 nil
+func (a *App) GetActiveTimer() (*ActiveTimer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	latest, err := latestTimeEntry()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || (latest.EndAt != nil && !latest.Paused) {
+		return nil, nil
+	}
+
+	timer := &ActiveTimer{
+		Entry:        *latest,
+		Running:      latest.EndAt == nil,
+		Paused:       latest.EndAt != nil,
+		SessionHours: latest.Hours,
+	}
+	entries, err := a.GetTimeEntries(latest.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		timer.TaskHours += e.Hours
+	}
+	timer.TaskHours = roundHours(timer.TaskHours)
+	return timer, nil
+}
+
+// GetTimeEntries 获取任务的工时记录
+func (a *App) GetTimeEntries(taskID int64) ([]TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return queryTimeEntries(timeEntrySelectSQL+`
+		WHERE e.task_id = ?
+		ORDER BY e.start_at, e.id
+	`, taskID)
+}
+
+// GetTimeEntriesByDateRange 获取与日期范围有交集的工时记录
+func (a *App) GetTimeEntriesByDateRange(startDate, endDate string) ([]TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	return queryTimeEntries(timeEntrySelectSQL+`
+		WHERE e.start_at < ? AND (e.end_at IS NULL OR e.end_at >= ?)
+		ORDER BY e.start_at, e.id
+	`, addDays(endDate, 1), startDate)
+}
+
+// AddTimeEntry 手动添加工时记录
+func (a *App) AddTimeEntry(input TimeEntryInput) (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	if _, err := a.GetTask(input.TaskID); err != nil {
+		return nil, err
+	}
+	if input.EndAt == nil || *input.EndAt == "" {
+		return nil, fmt.Errorf("结束时间不能为空")
+	}
+	startAt, endAt, err := validateEntryRange(input.StartAt, input.EndAt)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.Exec(`INSERT INTO time_entries (task_id, start_at, end_at, source, note) VALUES (?, ?, ?, ?, ?)`,
+		input.TaskID, startAt, endAt, TimeEntrySourceManual, input.Note)
+	if err != nil {
+		log.Printf("添加工时记录失败: %v", err)
+		return nil, fmt.Errorf("添加工时记录失败: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取工时记录ID失败: %v", err)
+	}
+
+	if err := recalcTaskActualHours(input.TaskID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("任务 %d 添加工时记录: %s ~ %s", input.TaskID, startAt, *endAt)
+	return getTimeEntry(id)
+}
+
+// UpdateTimeEntry 修改工时记录的起止时间和备注（计时中的记录只能修改开始时间）
+func (a *App) UpdateTimeEntry(input TimeEntryInput) (*TimeEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	entry, err := getTimeEntry(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	endAt := input.EndAt
+	if entry.EndAt == nil {
+		endAt = nil
+	} else if endAt == nil || *endAt == "" {
+		return nil, fmt.Errorf("结束时间不能为空")
+	}
+	startAt, endAt, err := validateEntryRange(input.StartAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`UPDATE time_entries SET start_at = ?, end_at = ?, note = ? WHERE id = ?`,
+		startAt, endAt, input.Note, input.ID)
+	if err != nil {
+		log.Printf("修改工时记录失败: %v", err)
+		return nil, fmt.Errorf("修改工时记录失败: %v", err)
+	}
+
+	if err := recalcTaskActualHours(entry.TaskID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("修改工时记录成功: ID=%d", input.ID)
+	return getTimeEntry(input.ID)
+}
+
+// DeleteTimeEntry 删除工时记录
+func (a *App) DeleteTimeEntry(id int64) error {
+	if db == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+
+	entry, err := getTimeEntry(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM time_entries WHERE id = ?`, id); err != nil {
+		log.Printf("删除工时记录失败: %v", err)
+		return fmt.Errorf("删除工时记录失败: %v", err)
+	}
+
+	if err := recalcTaskActualHours(entry.TaskID); err != nil {
+		return err
+	}
+
+	log.Printf("删除工时记录成功: ID=%d", id)
+	return nil
+}
+
+// validateEntryRange 校验并规范化起止时间（endAt 为 nil 表示计时中）
+func validateEntryRange(start string, end *string) (string, *string, error) {
+	startAt, err := parseEntryTime(start)
+	if err != nil {
+		return "", nil, err
+	}
+	if end == nil {
+		return startAt, nil, nil
+	}
+
+	endAt, err := parseEntryTime(*end)
+	if err != nil {
+		return "", nil, err
+	}
+	if endAt <= startAt {
+		return "", nil, fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	return startAt, &endAt, nil
+}
+
+// getTimeEntry 获取单条工时记录
+func getTimeEntry(id int64) (*TimeEntry, error) {
+	e, err := scanTimeEntry(db.QueryRow(timeEntrySelectSQL+`WHERE e.id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("工时记录不存在: %v", err)
+	}
+	return &e, nil
+}
+
+// runningTimeEntry 计时中的工时记录，没有返回 nil
+func runningTimeEntry() (*TimeEntry, error) {
+	entries, err := queryTimeEntries(timeEntrySelectSQL + `
+		WHERE e.end_at IS NULL
+		ORDER BY e.start_at DESC, e.id DESC
+		LIMIT 1
+	`)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// latestTimeEntry 最近一条计时器记录（用于判断计时器是否计时中/已暂停）
+func latestTimeEntry() (*TimeEntry, error) {
+	if running, err := runningTimeEntry(); err != nil || running != nil {
+		return running, err
+	}
+
+	entries, err := queryTimeEntries(timeEntrySelectSQL+`
+		WHERE e.source = ?
+		ORDER BY e.end_at DESC, e.id DESC
+		LIMIT 1
+	`, TimeEntrySourceTimer)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// closeTimeEntry 结束计时中的记录，paused 表示由暂停结束
+func closeTimeEntry(e *TimeEntry, paused bool) error {
+	now := time.Now().Format(timeEntryLayout)
+	if now < e.StartAt {
+		now = e.StartAt
+	}
+	pausedFlag := 0
+	if paused {
+		pausedFlag = 1
+	}
+
+	_, err := db.Exec(`UPDATE time_entries SET end_at = ?, paused = ? WHERE id = ?`, now, pausedFlag, e.ID)
+	if err != nil {
+		log.Printf("结束计时失败: %v", err)
+		return fmt.Errorf("结束计时失败: %v", err)
+	}
+	return recalcTaskActualHours(e.TaskID)
+}
+
+// stopTaskTimer 任务正在计时时停止计时
+func stopTaskTimer(taskID int64) error {
+	running, err := runningTimeEntry()
+	if err != nil || running == nil || running.TaskID != taskID {
+		return err
+	}
+	return closeTimeEntry(running, false)
+}
+
+// addManualTimeEntry 按时长补一条手动工时记录，start 为空时以当前时间为结束时间往前推
+func addManualTimeEntry(taskID int64, start *time.Time, hours float64, note string) error {
+	duration := time.Duration(hours * float64(time.Hour))
+	end := time.Now()
+	begin := end.Add(-duration)
+	if start != nil {
+		begin = *start
+		end = begin.Add(duration)
+	}
+
+	_, err := db.Exec(`INSERT INTO time_entries (task_id, start_at, end_at, source, note) VALUES (?, ?, ?, ?, ?)`,
+		taskID, begin.Format(timeEntryLayout), end.Format(timeEntryLayout), TimeEntrySourceManual, note)
+	if err != nil {
+		log.Printf("添加工时记录失败: %v", err)
+		return fmt.Errorf("添加工时记录失败: %v", err)
+	}
+	return recalcTaskActualHours(taskID)
+}
+
+// recalcTaskActualHours 由已结束的工时记录重新计算任务的实际工时和实际开始时间
+func recalcTaskActualHours(taskID int64) error {
+	var hours float64
+	var firstStart *string
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM((julianday(end_at) - julianday(start_at)) * 24), 0), MIN(start_at)
+		FROM time_entries
+		WHERE task_id = ? AND end_at IS NOT NULL
+	`, taskID).Scan(&hours, &firstStart)
+	if err != nil {
+		return fmt.Errorf("统计工时失败: %v", err)
+	}
+
+	var actualStart *string
+	if firstStart != nil && len(*firstStart) >= 16 {
+		hm := (*firstStart)[11:16]
+		actualStart = &hm
+	}
+
+	before := snapshotTask(taskID)
+	_, err = db.Exec(`UPDATE tasks SET actual_hours = ?, actual_start = ? WHERE id = ?`,
+		roundHours(hours), actualStart, taskID)
+	if err != nil {
+		log.Printf("更新实际工时失败: %v", err)
+		return fmt.Errorf("更新实际工时失败: %v", err)
+	}
+	auditSystemTask(taskID, AuditActionUpdate, before)
+	return nil
+}
+
+// countTimeEntries 任务的工时记录数量
+func countTimeEntries(taskID int64) int {
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM time_entries WHERE task_id = ?`, taskID).Scan(&count)
+	return count
+}
+
+// splitEntryByDay 将工时记录按天拆分（跨天的记录计入各自的日期）
+func splitEntryByDay(e TimeEntry) map[string]float64 {
+	result := make(map[string]float64)
+	start, end, err := timeEntrySpan(e)
+	if err != nil {
+		return result
+	}
+
+	for start.Before(end) {
+		y, m, d := start.Date()
+		nextDay := time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+		segmentEnd := end
+		if nextDay.Before(end) {
+			segmentEnd = nextDay
+		}
+		result[start.Format("2006-01-02")] += segmentEnd.Sub(start).Hours()
+		start = segmentEnd
+	}
+	return result
+}
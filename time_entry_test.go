@@ -0,0 +1,193 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTimerStartPauseResumeStop(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "写周报", Hours: 1})
+
+	entry, err := a.StartTimer(task.ID)
+	if err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	if entry.EndAt != nil || entry.Source != TimeEntrySourceTimer {
+		t.Fatalf("开始计时应创建计时中的记录: %+v", entry)
+	}
+	if got, _ := a.GetTask(task.ID); got.Status != TaskStatusInProgress {
+		t.Fatalf("开始计时后任务应为进行中，实际 %s", got.Status)
+	}
+	if again, _ := a.StartTimer(task.ID); again.ID != entry.ID {
+		t.Fatal("同一任务重复开始计时应返回当前记录")
+	}
+
+	if _, err := a.PauseTimer(); err != nil {
+		t.Fatalf("暂停计时失败: %v", err)
+	}
+	timer, err := a.GetActiveTimer()
+	if err != nil || timer == nil || !timer.Paused || timer.Running {
+		t.Fatalf("暂停后计时器应为已暂停: %v %+v", err, timer)
+	}
+
+	resumed, err := a.ResumeTimer()
+	if err != nil {
+		t.Fatalf("继续计时失败: %v", err)
+	}
+	if resumed.ID == entry.ID || resumed.TaskID != task.ID || resumed.EndAt != nil {
+		t.Fatalf("继续计时应新建一段计时记录: %+v", resumed)
+	}
+
+	stopped, err := a.StopTimer()
+	if err != nil {
+		t.Fatalf("停止计时失败: %v", err)
+	}
+	if stopped.EndAt == nil || stopped.Paused {
+		t.Fatalf("停止后记录应结束且不是暂停: %+v", stopped)
+	}
+	if timer, _ := a.GetActiveTimer(); timer != nil {
+		t.Fatalf("停止后不应有计时器: %+v", timer)
+	}
+	if _, err := a.StopTimer(); err == nil {
+		t.Fatal("没有计时时停止应返回错误")
+	}
+	if entries, _ := a.GetTimeEntries(task.ID); len(entries) != 2 {
+		t.Fatalf("应有2段工时记录，实际 %d", len(entries))
+	}
+}
+
+func TestStopPausedTimer(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "读论文", Hours: 1})
+	a.StartTimer(task.ID)
+	a.PauseTimer()
+
+	stopped, err := a.StopTimer()
+	if err != nil {
+		t.Fatalf("停止已暂停的计时失败: %v", err)
+	}
+	if stopped.Paused {
+		t.Fatal("停止后应清除暂停标记")
+	}
+	if _, err := a.ResumeTimer(); err == nil {
+		t.Fatal("停止后不能再继续计时")
+	}
+}
+
+func TestOnlyOneRunningTimer(t *testing.T) {
+	a := openTestDB(t)
+	first := createTestTask(t, a, TaskInput{Name: "任务一", Hours: 1})
+	second := createTestTask(t, a, TaskInput{Name: "任务二", Hours: 1})
+
+	a.StartTimer(first.ID)
+	if _, err := a.StartTimer(second.ID); err != nil {
+		t.Fatalf("开始第二个计时失败: %v", err)
+	}
+
+	var running int
+	db.QueryRow(`SELECT COUNT(*) FROM time_entries WHERE end_at IS NULL`).Scan(&running)
+	if running != 1 {
+		t.Fatalf("同一时间只能有一个计时中的记录，实际 %d", running)
+	}
+	timer, _ := a.GetActiveTimer()
+	if timer == nil || timer.Entry.TaskID != second.ID {
+		t.Fatalf("当前计时器应属于任务二: %+v", timer)
+	}
+	entries, _ := a.GetTimeEntries(first.ID)
+	if len(entries) != 1 || entries[0].EndAt == nil || entries[0].Paused {
+		t.Fatalf("任务一的计时应被停止（不是暂停）: %+v", entries)
+	}
+}
+
+func TestSplitEntryByDay(t *testing.T) {
+	end := "2030-01-02 01:30:00"
+	days := splitEntryByDay(TimeEntry{StartAt: "2030-01-01 22:00:00", EndAt: &end})
+	if len(days) != 2 || days["2030-01-01"] != 2 || days["2030-01-02"] != 1.5 {
+		t.Fatalf("跨天记录应拆分到各自日期: %v", days)
+	}
+
+	end = "2030-01-01 10:15:00"
+	days = splitEntryByDay(TimeEntry{StartAt: "2030-01-01 09:00:00", EndAt: &end})
+	if len(days) != 1 || days["2030-01-01"] != 1.25 {
+		t.Fatalf("当天记录不应拆分: %v", days)
+	}
+}
+
+func TestManualEntriesRecalcActualHours(t *testing.T) {
+	a := openTestDB(t)
+	task := createTestTask(t, a, TaskInput{Name: "整理文档", Hours: 3})
+
+	end := "2030-01-01 10:30"
+	entry, err := a.AddTimeEntry(TimeEntryInput{TaskID: task.ID, StartAt: "2030-01-01 09:00", EndAt: &end})
+	if err != nil {
+		t.Fatalf("添加工时记录失败: %v", err)
+	}
+	end = "2030-01-02 15:00"
+	if _, err := a.AddTimeEntry(TimeEntryInput{TaskID: task.ID, StartAt: "2030-01-02 14:00", EndAt: &end}); err != nil {
+		t.Fatalf("添加工时记录失败: %v", err)
+	}
+	got, _ := a.GetTask(task.ID)
+	if got.ActualHours != 2.5 || got.ActualStart == nil || *got.ActualStart != "09:00" {
+		t.Fatalf("实际工时应由工时记录计算: %v %v", got.ActualHours, got.ActualStart)
+	}
+
+	if err := a.DeleteTimeEntry(entry.ID); err != nil {
+		t.Fatalf("删除工时记录失败: %v", err)
+	}
+	got, _ = a.GetTask(task.ID)
+	if got.ActualHours != 1 || *got.ActualStart != "14:00" {
+		t.Fatalf("删除后应重新计算实际工时: %v %v", got.ActualHours, *got.ActualStart)
+	}
+
+	bad := "2030-01-01 08:00"
+	if _, err := a.AddTimeEntry(TimeEntryInput{TaskID: task.ID, StartAt: "2030-01-01 09:00", EndAt: &bad}); err == nil {
+		t.Fatal("结束时间早于开始时间应返回错误")
+	}
+}
+
+func TestMigrateLegacyActualHours(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := InitDBAt(path); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDB()
+		db = nil
+	})
+
+	// 旧版本只记录了实际开始时间和实际工时
+	result, err := db.Exec(`INSERT INTO tasks (name, hours, date, actual_start, actual_hours) VALUES ('旧任务', 2, '2024-05-06', '14:00', 1.5)`)
+	if err != nil {
+		t.Fatalf("创建任务失败: %v", err)
+	}
+	withStart, _ := result.LastInsertId()
+	result, err = db.Exec(`INSERT INTO tasks (name, hours, date, actual_hours) VALUES ('没有开始时间', 1, '2024-05-07', 0.5)`)
+	if err != nil {
+		t.Fatalf("创建任务失败: %v", err)
+	}
+	noStart, _ := result.LastInsertId()
+
+	// 重新打开数据库时执行迁移，再次打开不应重复迁移
+	for i := 0; i < 2; i++ {
+		CloseDB()
+		if err := InitDBAt(path); err != nil {
+			t.Fatalf("重新打开数据库失败: %v", err)
+		}
+	}
+
+	a := &App{}
+	entries, err := a.GetTimeEntries(withStart)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("应迁移出1条工时记录: %v %+v", err, entries)
+	}
+	e := entries[0]
+	if e.Source != TimeEntrySourceMigrated || e.StartAt != "2024-05-06 14:00:00" || e.EndAt == nil || *e.EndAt != "2024-05-06 15:30:00" {
+		t.Fatalf("迁移的工时记录不符合预期: %+v", e)
+	}
+
+	entries, _ = a.GetTimeEntries(noStart)
+	if len(entries) != 1 || entries[0].StartAt != "2024-05-07 09:00:00" || entries[0].Hours != 0.5 {
+		t.Fatalf("没有开始时间的任务应从当天9点开始: %+v", entries)
+	}
+}
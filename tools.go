@@ -9,27 +9,61 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 // ToolRegistry 工具注册表
 type ToolRegistry struct {
-	tools map[string]AgentTool
+	tools   map[string]AgentTool
+	schemas map[string]*JSONSchema // 注册时解析的参数 Schema
+	custom  map[string]CustomTool  // 自定义命令行工具
 }
 
 // NewToolRegistry 创建工具注册表
 func NewToolRegistry() *ToolRegistry {
 	registry := &ToolRegistry{
-		tools: make(map[string]AgentTool),
+		tools:   make(map[string]AgentTool),
+		schemas: make(map[string]*JSONSchema),
+		custom:  make(map[string]CustomTool),
 	}
 	registry.registerBuiltinTools()
+	registry.registerCustomTools()
 	return registry
 }
 
+// Register 注册工具，参数 Schema 无效时拒绝注册
+func (r *ToolRegistry) Register(tool AgentTool) error {
+	if tool.Name == "" {
+		return fmt.Errorf("工具名称不能为空")
+	}
+	schema, err := ParseJSONSchema(tool.Schema)
+	if err != nil {
+		return fmt.Errorf("工具 %s 的参数定义无效: %v", tool.Name, err)
+	}
+	if schema.Type != "" && schema.Type != "object" {
+		return fmt.Errorf("工具 %s 的参数定义必须是 object 类型", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	r.schemas[tool.Name] = schema
+	return nil
+}
+
 // registerBuiltinTools 注册内置工具
 func (r *ToolRegistry) registerBuiltinTools() {
-	r.tools[ToolClaudeCode] = AgentTool{
+	for _, tool := range append(builtinTools(), append(taskTools(), submitPlanTool())...) {
+		if err := r.Register(tool); err != nil {
+			log.Printf("注册内置工具失败: %v", err)
+		}
+	}
+}
+
+// builtinTools 内置工具定义
+func builtinTools() []AgentTool {
+	var tools []AgentTool
+
+	tools = append(tools, AgentTool{
 		Name:        ToolClaudeCode,
 		Description: "调用 Claude Code CLI 执行复杂的代码任务。适用于需要阅读、修改代码或执行多步骤开发任务。",
 		Type:        "cli",
@@ -41,9 +75,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["task"]
 		}`,
-	}
+	})
 
-	r.tools[ToolShell] = AgentTool{
+	tools = append(tools, AgentTool{
 		Name:        ToolShell,
 		Description: "执行 shell 命令。用于运行构建、测试、安装依赖等操作。",
 		Type:        "builtin",
@@ -55,9 +89,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["command"]
 		}`,
-	}
+	})
 
-	r.tools[ToolReadFile] = AgentTool{
+	tools = append(tools, AgentTool{
 		Name:        ToolReadFile,
 		Description: "读取文件内容。",
 		Type:        "builtin",
@@ -68,9 +102,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["path"]
 		}`,
-	}
+	})
 
-	r.tools[ToolWriteFile] = AgentTool{
+	tools = append(tools, AgentTool{
 		Name:        ToolWriteFile,
 		Description: "写入内容到文件。",
 		Type:        "builtin",
@@ -82,9 +116,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["path", "content"]
 		}`,
-	}
+	})
 
-	r.tools[ToolListFiles] = AgentTool{
+	tools = append(tools, AgentTool{
 		Name:        ToolListFiles,
 		Description: "列出目录下的文件。",
 		Type:        "builtin",
@@ -96,9 +130,37 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["path"]
 		}`,
-	}
+	})
 
-	r.tools[ToolAskUser] = AgentTool{
+	tools = append(tools, AgentTool{
+		Name:        ToolDelegate,
+		Description: "将子目标委派给其他Agent执行，等待其完成并返回总结。可一次委派多个子目标并行执行。",
+		Type:        "builtin",
+		Schema: `{
+			"type": "object",
+			"properties": {
+				"agent": {"type": "string", "description": "执行子目标的Agent名称（单个委派）"},
+				"goal": {"type": "string", "description": "子目标，应具体、可独立完成（单个委派）"},
+				"context": {"type": "string", "description": "完成子目标需要的背景信息（单个委派）"},
+				"tasks": {
+					"type": "array",
+					"description": "多个委派，填写后忽略 agent/goal/context",
+					"items": {
+						"type": "object",
+						"properties": {
+							"agent": {"type": "string", "description": "Agent名称"},
+							"goal": {"type": "string", "minLength": 1, "description": "子目标"},
+							"context": {"type": "string", "description": "背景信息"}
+						},
+						"required": ["agent", "goal"]
+					}
+				},
+				"max_parallel": {"type": "integer", "minimum": 1, "maximum": 4, "description": "同时执行的子会话数，默认1（依次执行）"}
+			}
+		}`,
+	})
+
+	tools = append(tools, AgentTool{
 		Name:        ToolAskUser,
 		Description: "向用户提问，获取额外信息或确认。当需要澄清需求或做重要决定时使用。",
 		Type:        "builtin",
@@ -110,9 +172,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["question"]
 		}`,
-	}
+	})
 
-	r.tools[ToolComplete] = AgentTool{
+	tools = append(tools, AgentTool{
 		Name:        ToolComplete,
 		Description: "标记任务完成。当任务已经完成时调用此工具。",
 		Type:        "builtin",
@@ -123,7 +185,9 @@ func (r *ToolRegistry) registerBuiltinTools() {
 			},
 			"required": ["summary"]
 		}`,
-	}
+	})
+
+	return tools
 }
 
 // GetTool 获取工具
@@ -143,6 +207,24 @@ func (r *ToolRegistry) GetTools(names []string) []AgentTool {
 	return result
 }
 
+// Validate 按工具的参数 Schema 校验输入
+func (r *ToolRegistry) Validate(name string, input json.RawMessage) error {
+	schema, ok := r.schemas[name]
+	if !ok {
+		return fmt.Errorf("未知工具: %s", name)
+	}
+	if errs := schema.ValidateJSON(input); len(errs) > 0 {
+		return fmt.Errorf("工具 %s 的参数不符合定义:\n- %s", name, strings.Join(errs, "\n- "))
+	}
+	return nil
+}
+
+// GetSchema 获取工具已解析的参数 Schema
+func (r *ToolRegistry) GetSchema(name string) (*JSONSchema, bool) {
+	schema, ok := r.schemas[name]
+	return schema, ok
+}
+
 // GetAllTools 获取所有工具
 func (r *ToolRegistry) GetAllTools() []AgentTool {
 	var result []AgentTool
@@ -152,17 +234,58 @@ func (r *ToolRegistry) GetAllTools() []AgentTool {
 	return result
 }
 
-// ToolInput 工具调用输入
-type ToolInput struct {
-	Task       string   `json:"task,omitempty"`
-	WorkingDir string   `json:"working_dir,omitempty"`
-	Command    string   `json:"command,omitempty"`
-	Path       string   `json:"path,omitempty"`
-	Content    string   `json:"content,omitempty"`
-	Pattern    string   `json:"pattern,omitempty"`
-	Question   string   `json:"question,omitempty"`
-	Options    []string `json:"options,omitempty"`
-	Summary    string   `json:"summary,omitempty"`
+// ClaudeCodeInput claude_code 工具输入
+type ClaudeCodeInput struct {
+	Task       string `json:"task"`
+	WorkingDir string `json:"working_dir,omitempty"`
+}
+
+// ShellInput shell 工具输入
+type ShellInput struct {
+	Command    string `json:"command"`
+	WorkingDir string `json:"working_dir,omitempty"`
+}
+
+// ReadFileInput read_file 工具输入
+type ReadFileInput struct {
+	Path string `json:"path"`
+}
+
+// WriteFileInput write_file 工具输入
+type WriteFileInput struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ListFilesInput list_files 工具输入
+type ListFilesInput struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// DelegateTask 委派给子Agent的目标
+type DelegateTask struct {
+	Agent   string `json:"agent"`
+	Goal    string `json:"goal"`
+	Context string `json:"context"`
+}
+
+// DelegateInput delegate 工具输入
+type DelegateInput struct {
+	DelegateTask
+	Tasks       []DelegateTask `json:"tasks"`
+	MaxParallel int            `json:"max_parallel"`
+}
+
+// AskUserInput ask_user 工具输入
+type AskUserInput struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// CompleteInput complete 工具输入
+type CompleteInput struct {
+	Summary string `json:"summary"`
 }
 
 // ToolResult 工具执行结果
@@ -177,7 +300,9 @@ type ToolResult struct {
 // ToolExecutor 工具执行器
 type ToolExecutor struct {
 	registry   *ToolRegistry
-	workingDir string // 默认工作目录
+	workingDir string              // 默认工作目录
+	mcpRoutes  map[string]mcpRoute // MCP 工具路由
+	taskCtx    *taskToolContext    // 任务管理工具上下文
 }
 
 // NewToolExecutor 创建工具执行器
@@ -185,45 +310,93 @@ func NewToolExecutor(workingDir string) *ToolExecutor {
 	return &ToolExecutor{
 		registry:   NewToolRegistry(),
 		workingDir: workingDir,
+		mcpRoutes:  make(map[string]mcpRoute),
 	}
 }
 
-// Execute 执行工具
+// Execute 执行工具（先按参数 Schema 校验输入，校验失败的错误作为观察结果返回）
 func (e *ToolExecutor) Execute(toolName string, inputJSON string) ToolResult {
 	log.Printf("执行工具: %s, 输入: %s", toolName, inputJSON)
 
-	var input ToolInput
-	if err := json.Unmarshal([]byte(inputJSON), &input); err != nil {
-		return ToolResult{Success: false, Error: fmt.Sprintf("解析输入失败: %v", err)}
+	if strings.TrimSpace(inputJSON) == "" {
+		inputJSON = "{}"
 	}
-
-	// 设置默认工作目录
-	if input.WorkingDir == "" {
-		input.WorkingDir = e.workingDir
+	if err := e.registry.Validate(toolName, json.RawMessage(inputJSON)); err != nil {
+		return ToolResult{Success: false, Error: err.Error()}
 	}
 
 	switch toolName {
 	case ToolClaudeCode:
+		var input ClaudeCodeInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		if input.WorkingDir == "" {
+			input.WorkingDir = e.workingDir
+		}
 		return e.executeClaudeCode(input)
 	case ToolShell:
+		var input ShellInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
+		if input.WorkingDir == "" {
+			input.WorkingDir = e.workingDir
+		}
 		return e.executeShell(input)
 	case ToolReadFile:
+		var input ReadFileInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
 		return e.executeReadFile(input)
 	case ToolWriteFile:
+		var input WriteFileInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
 		return e.executeWriteFile(input)
 	case ToolListFiles:
+		var input ListFilesInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
 		return e.executeListFiles(input)
 	case ToolAskUser:
+		var input AskUserInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
 		return e.executeAskUser(input)
 	case ToolComplete:
+		var input CompleteInput
+		if err := decodeToolInput(inputJSON, &input); err != nil {
+			return ToolResult{Success: false, Error: err.Error()}
+		}
 		return e.executeComplete(input)
+	case ToolListProjectTasks, ToolCreateTask, ToolUpdateTask, ToolLogTime, ToolCompleteTask:
+		return e.executeTaskTool(toolName, inputJSON)
 	default:
+		if custom, ok := e.registry.custom[toolName]; ok {
+			return e.executeCustomTool(custom, inputJSON)
+		}
+		if route, ok := e.mcpRoutes[toolName]; ok {
+			return e.executeMCPTool(route, inputJSON)
+		}
 		return ToolResult{Success: false, Error: fmt.Sprintf("未知工具: %s", toolName)}
 	}
 }
 
+// decodeToolInput 解码工具输入到对应的参数结构体
+func decodeToolInput(inputJSON string, v interface{}) error {
+	if err := json.Unmarshal([]byte(inputJSON), v); err != nil {
+		return fmt.Errorf("解析输入失败: %v", err)
+	}
+	return nil
+}
+
 // executeClaudeCode 执行 Claude Code CLI
-func (e *ToolExecutor) executeClaudeCode(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeClaudeCode(input ClaudeCodeInput) ToolResult {
 	// 检查 claude 命令是否存在
 	claudePath, err := exec.LookPath("claude")
 	if err != nil {
@@ -290,7 +463,7 @@ func (e *ToolExecutor) executeClaudeCode(input ToolInput) ToolResult {
 }
 
 // executeShell 执行 shell 命令
-func (e *ToolExecutor) executeShell(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeShell(input ShellInput) ToolResult {
 	cmd := exec.Command("sh", "-c", input.Command)
 	if input.WorkingDir != "" {
 		cmd.Dir = input.WorkingDir
@@ -309,7 +482,7 @@ func (e *ToolExecutor) executeShell(input ToolInput) ToolResult {
 }
 
 // executeReadFile 读取文件
-func (e *ToolExecutor) executeReadFile(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeReadFile(input ReadFileInput) ToolResult {
 	path := input.Path
 	if !filepath.IsAbs(path) && e.workingDir != "" {
 		path = filepath.Join(e.workingDir, path)
@@ -324,7 +497,7 @@ func (e *ToolExecutor) executeReadFile(input ToolInput) ToolResult {
 }
 
 // executeWriteFile 写入文件
-func (e *ToolExecutor) executeWriteFile(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeWriteFile(input WriteFileInput) ToolResult {
 	path := input.Path
 	if !filepath.IsAbs(path) && e.workingDir != "" {
 		path = filepath.Join(e.workingDir, path)
@@ -344,7 +517,7 @@ func (e *ToolExecutor) executeWriteFile(input ToolInput) ToolResult {
 }
 
 // executeListFiles 列出文件
-func (e *ToolExecutor) executeListFiles(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeListFiles(input ListFilesInput) ToolResult {
 	path := input.Path
 	if !filepath.IsAbs(path) && e.workingDir != "" {
 		path = filepath.Join(e.workingDir, path)
@@ -381,7 +554,7 @@ func (e *ToolExecutor) executeListFiles(input ToolInput) ToolResult {
 }
 
 // executeAskUser 询问用户
-func (e *ToolExecutor) executeAskUser(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeAskUser(input AskUserInput) ToolResult {
 	// 返回需要用户输入的标记
 	output := input.Question
 	if len(input.Options) > 0 {
@@ -396,7 +569,7 @@ func (e *ToolExecutor) executeAskUser(input ToolInput) ToolResult {
 }
 
 // executeComplete 完成任务
-func (e *ToolExecutor) executeComplete(input ToolInput) ToolResult {
+func (e *ToolExecutor) executeComplete(input CompleteInput) ToolResult {
 	return ToolResult{
 		Success:     true,
 		Output:      input.Summary,
@@ -404,13 +577,17 @@ func (e *ToolExecutor) executeComplete(input ToolInput) ToolResult {
 	}
 }
 
-// BuildToolsPrompt 构建工具描述的 prompt
-func BuildToolsPrompt(tools []AgentTool) string {
+// BuildToolsPrompt 构建指定工具描述的 prompt（参数说明使用注册时解析的 Schema）
+func (r *ToolRegistry) BuildToolsPrompt(names []string) string {
 	var sb strings.Builder
 	sb.WriteString("你可以使用以下工具:\n\n")
 
-	for _, tool := range tools {
-		sb.WriteString(fmt.Sprintf("## %s\n%s\n\n", tool.Name, tool.Description))
+	for _, tool := range r.GetTools(names) {
+		sb.WriteString(fmt.Sprintf("## %s\n%s\n", tool.Name, tool.Description))
+		if schema, ok := r.schemas[tool.Name]; ok {
+			sb.WriteString(renderSchemaParams(schema))
+		}
+		sb.WriteString("\n")
 	}
 
 	sb.WriteString(`
@@ -433,3 +610,51 @@ func BuildToolsPrompt(tools []AgentTool) string {
 
 	return sb.String()
 }
+
+// renderSchemaParams 将参数 Schema 渲染为给 LLM 看的参数说明
+func renderSchemaParams(schema *JSONSchema) string {
+	if len(schema.Properties) == 0 {
+		return "参数: 无\n"
+	}
+
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	// 必填参数在前，其余按名称排序
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("参数:\n")
+	for _, name := range names {
+		prop := schema.Properties[name]
+		typ := prop.Type
+		if typ == "array" && prop.Items != nil && prop.Items.Type != "" {
+			typ = prop.Items.Type + "[]"
+		}
+		flag := "可选"
+		if required[name] {
+			flag = "必填"
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%s, %s)", name, typ, flag))
+		if prop.Description != "" {
+			sb.WriteString(": " + prop.Description)
+		}
+		if len(prop.Enum) > 0 {
+			enumJSON, _ := json.Marshal(prop.Enum)
+			sb.WriteString(fmt.Sprintf("，可选值 %s", enumJSON))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
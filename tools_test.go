@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildToolsPromptUsesRegisteredSchemas(t *testing.T) {
+	r := &ToolRegistry{tools: map[string]AgentTool{}, schemas: map[string]*JSONSchema{}, custom: map[string]CustomTool{}}
+	err := r.Register(AgentTool{
+		Name:        "fetch_issue",
+		Description: "获取缺陷详情",
+		Schema: `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "description": "缺陷编号"},
+				"fields": {"type": "array", "items": {"type": "string"}},
+				"format": {"type": "string", "enum": ["text", "json"]}
+			},
+			"required": ["id"]
+		}`,
+	})
+	if err != nil {
+		t.Fatalf("注册工具失败: %v", err)
+	}
+	if err := r.Register(AgentTool{Name: "ping", Description: "检查连通性"}); err != nil {
+		t.Fatalf("注册工具失败: %v", err)
+	}
+
+	prompt := r.BuildToolsPrompt([]string{"fetch_issue", "ping", "missing_tool"})
+	for _, want := range []string{
+		"## fetch_issue\n获取缺陷详情\n参数:\n- id (integer, 必填): 缺陷编号\n- fields (string[], 可选)\n- format (string, 可选)，可选值 [\"text\",\"json\"]\n",
+		"## ping\n检查连通性\n参数: 无\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("工具说明缺少:\n%s\n实际:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "missing_tool") {
+		t.Error("未注册的工具不应出现在说明中")
+	}
+}